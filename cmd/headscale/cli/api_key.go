@@ -3,9 +3,11 @@ package cli
 import (
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/juanfont/headscale/hscontrol/util"
 	"github.com/prometheus/common/model"
 	"github.com/pterm/pterm"
@@ -25,6 +27,10 @@ func init() {
 
 	createAPIKeyCmd.Flags().
 		StringP("expiration", "e", DefaultAPIKeyExpiry, "Human-readable expiration of the key (e.g. 30m, 24h)")
+	createAPIKeyCmd.Flags().
+		StringP("scope", "s", string(types.APIKeyScopeAdmin), fmt.Sprintf("Scope of the key, one of %v", types.APIKeyScopes))
+	createAPIKeyCmd.Flags().
+		UintSliceP("user", "u", nil, "Restrict the key to the given users (ID), can be repeated")
 
 	apiKeysCmd.AddCommand(createAPIKeyCmd)
 
@@ -74,7 +80,7 @@ var listAPIKeys = &cobra.Command{
 		}
 
 		tableData := pterm.TableData{
-			{"ID", "Prefix", "Expiration", "Created"},
+			{"ID", "Prefix", "Scope", "Users", "Expiration", "Created"},
 		}
 		for _, key := range response.GetApiKeys() {
 			expiration := "-"
@@ -86,6 +92,8 @@ var listAPIKeys = &cobra.Command{
 			tableData = append(tableData, []string{
 				strconv.FormatUint(key.GetId(), util.Base10),
 				key.GetPrefix(),
+				key.GetScope(),
+				usersString(key.GetUserIds()),
 				expiration,
 				key.GetCreatedAt().AsTime().Format(HeadscaleDateTimeFormat),
 			})
@@ -130,6 +138,12 @@ If you loose a key, create a new one and revoke (expire) the old one.`,
 
 		request.Expiration = timestamppb.New(expiration)
 
+		request.Scope, _ = cmd.Flags().GetString("scope")
+		userIDs, _ := cmd.Flags().GetUintSlice("user")
+		for _, id := range userIDs {
+			request.UserIds = append(request.UserIds, uint64(id))
+		}
+
 		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
 		defer cancel()
 		defer conn.Close()
@@ -220,3 +234,17 @@ var deleteAPIKeyCmd = &cobra.Command{
 		SuccessOutput(response, "Key deleted", output)
 	},
 }
+
+// usersString formats the users an API key is restricted to.
+func usersString(ids []uint64) string {
+	if len(ids) == 0 {
+		return "all"
+	}
+
+	users := make([]string, len(ids))
+	for i, id := range ids {
+		users[i] = strconv.FormatUint(id, util.Base10)
+	}
+
+	return strings.Join(users, ",")
+}
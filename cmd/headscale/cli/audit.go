@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/prometheus/common/model"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	listAuditEventsCmd.Flags().String("actor", "", "Only list events by this actor (e.g. api-key:<prefix>, unix-socket)")
+	listAuditEventsCmd.Flags().String("action", "", "Only list events of this action (e.g. node.delete)")
+	listAuditEventsCmd.Flags().String("target", "", "Only list events on this target (e.g. node:1)")
+	listAuditEventsCmd.Flags().String("since", "", "Only list events newer than this human-readable duration (e.g. 30m, 24h)")
+	listAuditEventsCmd.Flags().Uint32("limit", 100, "Maximum number of events to list, 0 for no limit")
+	auditCmd.AddCommand(listAuditEventsCmd)
+}
+
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Inspect the audit log of changes made to headscale",
+}
+
+var listAuditEventsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the audit events, newest first",
+	Aliases: []string{"ls", "show"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		request := &v1.ListAuditEventsRequest{}
+		request.Actor, _ = cmd.Flags().GetString("actor")
+		request.Action, _ = cmd.Flags().GetString("action")
+		request.Target, _ = cmd.Flags().GetString("target")
+		request.Limit, _ = cmd.Flags().GetUint32("limit")
+
+		if since, _ := cmd.Flags().GetString("since"); since != "" {
+			duration, err := model.ParseDuration(since)
+			if err != nil {
+				ErrorOutput(err, fmt.Sprintf("Could not parse duration: %s", err), output)
+			}
+
+			request.Since = timestamppb.New(time.Now().Add(-time.Duration(duration)))
+		}
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListAuditEvents(ctx, request)
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Error getting the audit events: %s", err), output)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetEvents(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"ID", "Time", "Actor", "Action", "Target"},
+		}
+		for _, event := range response.GetEvents() {
+			tableData = append(tableData, []string{
+				strconv.FormatUint(event.GetId(), util.Base10),
+				event.GetTime().AsTime().Format(HeadscaleDateTimeFormat),
+				event.GetActor(),
+				event.GetAction(),
+				event.GetTarget(),
+			})
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Failed to render pterm table: %s", err), output)
+		}
+	},
+}
@@ -0,0 +1,381 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/pterm/pterm"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(derpCmd)
+
+	derpCmd.AddCommand(derpRegionsCmd)
+	derpRegionsCmd.AddCommand(listDERPRegionsCmd)
+
+	createDERPRegionCmd.Flags().Int32P("identifier", "i", 0, "Region ID")
+	createDERPRegionCmd.Flags().StringP("code", "c", "", "Short region code, e.g. office")
+	createDERPRegionCmd.Flags().StringP("name", "n", "", "Region name shown in the clients (default the code)")
+	if err := createDERPRegionCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := createDERPRegionCmd.MarkFlagRequired("code"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	derpRegionsCmd.AddCommand(createDERPRegionCmd)
+
+	for _, cmd := range []*cobra.Command{deleteDERPRegionCmd, disableDERPRegionCmd, enableDERPRegionCmd} {
+		cmd.Flags().Int32P("identifier", "i", 0, "Region ID")
+		if err := cmd.MarkFlagRequired("identifier"); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		derpRegionsCmd.AddCommand(cmd)
+	}
+
+	derpCmd.AddCommand(derpNodesCmd)
+
+	createDERPNodeCmd.Flags().StringP("name", "n", "", "Unique name of the DERP server, e.g. 900a")
+	createDERPNodeCmd.Flags().Int32P("region", "r", 0, "Region ID")
+	createDERPNodeCmd.Flags().String("hostname", "", "Host name of the DERP server, must match its TLS certificate")
+	createDERPNodeCmd.Flags().String("ipv4", "", "IPv4 address to connect to instead of resolving the host name")
+	createDERPNodeCmd.Flags().String("ipv6", "", "IPv6 address to connect to instead of resolving the host name")
+	createDERPNodeCmd.Flags().Int32("stun-port", 0, "STUN port, -1 to disable STUN (default 3478)")
+	createDERPNodeCmd.Flags().Int32("derp-port", 0, "HTTPS port of the DERP server (default 443)")
+	createDERPNodeCmd.Flags().Bool("stun-only", false, "Only use the server for STUN")
+	for _, flag := range []string{"name", "region", "hostname"} {
+		if err := createDERPNodeCmd.MarkFlagRequired(flag); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+	}
+	derpNodesCmd.AddCommand(createDERPNodeCmd)
+
+	for _, cmd := range []*cobra.Command{deleteDERPNodeCmd, disableDERPNodeCmd, enableDERPNodeCmd} {
+		cmd.Flags().StringP("name", "n", "", "Name of the DERP server")
+		if err := cmd.MarkFlagRequired("name"); err != nil {
+			log.Fatal().Err(err).Msg("")
+		}
+		derpNodesCmd.AddCommand(cmd)
+	}
+}
+
+var derpCmd = &cobra.Command{
+	Use:   "derp",
+	Short: "Manage the DERP map of Headscale",
+	Long: `
+Manage the DERP regions and servers sent to the nodes. Regions and servers
+can be added to the DERP map of the configuration, and the regions and
+servers of the configuration can be disabled.`,
+}
+
+var derpRegionsCmd = &cobra.Command{
+	Use:     "regions",
+	Short:   "Manage the DERP regions",
+	Aliases: []string{"region"},
+}
+
+var listDERPRegionsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the DERP regions and servers with their probe results",
+	Aliases: []string{"ls", "show"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListDERPRegions(ctx, &v1.ListDERPRegionsRequest{})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error getting the list of DERP regions: %s", err),
+				output,
+			)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetRegions(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"Region", "Code", "Status", "Source", "Server", "Host name", "Server status", "DERP latency", "STUN latency", "Error"},
+		}
+		for _, region := range response.GetRegions() {
+			regionColumns := []string{
+				strconv.FormatInt(int64(region.GetRegionId()), util.Base10),
+				region.GetCode(),
+				derpRegionStatus(region),
+				region.GetSource(),
+			}
+
+			if len(region.GetNodes()) == 0 {
+				tableData = append(tableData, append(regionColumns, "", "", "", "", "", ""))
+			}
+
+			for _, node := range region.GetNodes() {
+				probe := node.GetProbe()
+				latency := func(msec float64) string {
+					if probe == nil || msec == 0 {
+						return ""
+					}
+
+					return fmt.Sprintf("%.1fms", msec)
+				}
+
+				tableData = append(tableData, append(slices.Clone(regionColumns),
+					node.GetName(),
+					node.GetHostName(),
+					derpNodeStatus(node),
+					latency(probe.GetDerpLatencyMsec()),
+					latency(probe.GetStunLatencyMsec()),
+					probe.GetError(),
+				))
+			}
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Failed to render pterm table: %s", err),
+				output,
+			)
+		}
+	},
+}
+
+func derpRegionStatus(region *v1.DERPRegion) string {
+	switch {
+	case region.GetDisabled():
+		return pterm.LightYellow("disabled")
+	case region.GetAvoid():
+		return pterm.LightRed("avoid")
+	default:
+		return pterm.LightGreen("enabled")
+	}
+}
+
+func derpNodeStatus(node *v1.DERPNode) string {
+	switch {
+	case node.GetDisabled():
+		return pterm.LightYellow("disabled")
+	case node.GetProbe() == nil:
+		return "unknown"
+	case node.GetProbe().GetHealthy():
+		return pterm.LightGreen("healthy")
+	default:
+		return pterm.LightRed("unhealthy")
+	}
+}
+
+var createDERPRegionCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create a DERP region",
+	Aliases: []string{"c", "new", "add"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetInt32("identifier")
+		code, _ := cmd.Flags().GetString("code")
+		name, _ := cmd.Flags().GetString("name")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.CreateDERPRegion(ctx, &v1.CreateDERPRegionRequest{
+			RegionId: id,
+			Code:     code,
+			Name:     name,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot create DERP region: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetRegion(), "DERP region created", output)
+	},
+}
+
+var deleteDERPRegionCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete a DERP region created via the API and its servers",
+	Aliases: []string{"remove", "del", "rm"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetInt32("identifier")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.DeleteDERPRegion(ctx, &v1.DeleteDERPRegionRequest{RegionId: id})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot delete DERP region: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "DERP region deleted", output)
+	},
+}
+
+var disableDERPRegionCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Remove a DERP region from the DERP map of the nodes",
+	Run:   setDERPRegionDisabled(true),
+}
+
+var enableDERPRegionCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Add a disabled DERP region to the DERP map of the nodes again",
+	Run:   setDERPRegionDisabled(false),
+}
+
+func setDERPRegionDisabled(disabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetInt32("identifier")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.SetDERPRegionDisabled(ctx, &v1.SetDERPRegionDisabledRequest{
+			RegionId: id,
+			Disabled: disabled,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot change DERP region: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "DERP region "+cmd.Name()+"d", output)
+	}
+}
+
+var derpNodesCmd = &cobra.Command{
+	Use:     "nodes",
+	Short:   "Manage the DERP servers of the regions",
+	Aliases: []string{"node", "servers", "server"},
+}
+
+var createDERPNodeCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Add a DERP server to a region",
+	Aliases: []string{"c", "new", "add"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		name, _ := cmd.Flags().GetString("name")
+		region, _ := cmd.Flags().GetInt32("region")
+		hostName, _ := cmd.Flags().GetString("hostname")
+		ipv4, _ := cmd.Flags().GetString("ipv4")
+		ipv6, _ := cmd.Flags().GetString("ipv6")
+		stunPort, _ := cmd.Flags().GetInt32("stun-port")
+		derpPort, _ := cmd.Flags().GetInt32("derp-port")
+		stunOnly, _ := cmd.Flags().GetBool("stun-only")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.CreateDERPNode(ctx, &v1.CreateDERPNodeRequest{
+			Name:     name,
+			RegionId: region,
+			HostName: hostName,
+			Ipv4:     ipv4,
+			Ipv6:     ipv6,
+			StunPort: stunPort,
+			DerpPort: derpPort,
+			StunOnly: stunOnly,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot create DERP server: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetNode(), "DERP server created", output)
+	},
+}
+
+var deleteDERPNodeCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete a DERP server created via the API",
+	Aliases: []string{"remove", "del", "rm"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		name, _ := cmd.Flags().GetString("name")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.DeleteDERPNode(ctx, &v1.DeleteDERPNodeRequest{Name: name})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot delete DERP server: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "DERP server deleted", output)
+	},
+}
+
+var disableDERPNodeCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Remove a DERP server from the DERP map of the nodes",
+	Run:   setDERPNodeDisabled(true),
+}
+
+var enableDERPNodeCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Add a disabled DERP server to the DERP map of the nodes again",
+	Run:   setDERPNodeDisabled(false),
+}
+
+func setDERPNodeDisabled(disabled bool) func(cmd *cobra.Command, args []string) {
+	return func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		name, _ := cmd.Flags().GetString("name")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.SetDERPNodeDisabled(ctx, &v1.SetDERPNodeDisabledRequest{
+			Name:     name,
+			Disabled: disabled,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot change DERP server: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "DERP server "+cmd.Name()+"d", output)
+	}
+}
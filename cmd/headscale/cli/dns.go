@@ -0,0 +1,531 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/pterm/pterm"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(dnsCmd)
+
+	dnsCmd.AddCommand(dnsRecordsCmd)
+	dnsRecordsCmd.AddCommand(listDNSRecordsCmd)
+
+	createDNSRecordCmd.Flags().StringP("name", "n", "", "Name of the record, can be a wildcard (*.svc.example.com) which is only expanded to the names of the nodes")
+	createDNSRecordCmd.Flags().StringP("type", "t", "", "Type of the record, A, AAAA, CNAME or ALIAS (default A or AAAA derived from the value)")
+	createDNSRecordCmd.Flags().StringP("value", "v", "", "IP address, CNAME target or node (ID or name) of an ALIAS record")
+	if err := createDNSRecordCmd.MarkFlagRequired("name"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := createDNSRecordCmd.MarkFlagRequired("value"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	dnsRecordsCmd.AddCommand(createDNSRecordCmd)
+
+	updateDNSRecordCmd.Flags().Uint64P("identifier", "i", 0, "Record identifier (ID)")
+	updateDNSRecordCmd.Flags().StringP("name", "n", "", "Name of the record, can be a wildcard (*.svc.example.com) which is only expanded to the names of the nodes")
+	updateDNSRecordCmd.Flags().StringP("type", "t", "", "Type of the record, A, AAAA, CNAME or ALIAS (default A or AAAA derived from the value)")
+	updateDNSRecordCmd.Flags().StringP("value", "v", "", "IP address, CNAME target or node (ID or name) of an ALIAS record")
+	if err := updateDNSRecordCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := updateDNSRecordCmd.MarkFlagRequired("name"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := updateDNSRecordCmd.MarkFlagRequired("value"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	dnsRecordsCmd.AddCommand(updateDNSRecordCmd)
+
+	deleteDNSRecordCmd.Flags().Uint64P("identifier", "i", 0, "Record identifier (ID)")
+	if err := deleteDNSRecordCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	dnsRecordsCmd.AddCommand(deleteDNSRecordCmd)
+
+	dnsCmd.AddCommand(nameserversCmd)
+	nameserversCmd.AddCommand(listNameserversCmd)
+
+	createNameserverCmd.Flags().StringP("address", "a", "", "IP address or DNS-over-HTTPS URL of the nameserver")
+	createNameserverCmd.Flags().StringP("domain", "d", "", "Only use the nameserver for this domain (split DNS)")
+	if err := createNameserverCmd.MarkFlagRequired("address"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	nameserversCmd.AddCommand(createNameserverCmd)
+
+	updateNameserverCmd.Flags().Uint64P("identifier", "i", 0, "Nameserver identifier (ID)")
+	updateNameserverCmd.Flags().StringP("address", "a", "", "IP address or DNS-over-HTTPS URL of the nameserver")
+	updateNameserverCmd.Flags().StringP("domain", "d", "", "Only use the nameserver for this domain (split DNS), a global nameserver if empty")
+	if err := updateNameserverCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := updateNameserverCmd.MarkFlagRequired("address"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	nameserversCmd.AddCommand(updateNameserverCmd)
+
+	deleteNameserverCmd.Flags().Uint64P("identifier", "i", 0, "Nameserver identifier (ID)")
+	if err := deleteNameserverCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	nameserversCmd.AddCommand(deleteNameserverCmd)
+
+	dnsCmd.AddCommand(searchDomainsCmd)
+	searchDomainsCmd.AddCommand(listSearchDomainsCmd)
+
+	createSearchDomainCmd.Flags().StringP("domain", "d", "", "Search domain")
+	if err := createSearchDomainCmd.MarkFlagRequired("domain"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	searchDomainsCmd.AddCommand(createSearchDomainCmd)
+
+	updateSearchDomainCmd.Flags().Uint64P("identifier", "i", 0, "Search domain identifier (ID)")
+	updateSearchDomainCmd.Flags().StringP("domain", "d", "", "Search domain")
+	if err := updateSearchDomainCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	if err := updateSearchDomainCmd.MarkFlagRequired("domain"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	searchDomainsCmd.AddCommand(updateSearchDomainCmd)
+
+	deleteSearchDomainCmd.Flags().Uint64P("identifier", "i", 0, "Search domain identifier (ID)")
+	if err := deleteSearchDomainCmd.MarkFlagRequired("identifier"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	searchDomainsCmd.AddCommand(deleteSearchDomainCmd)
+}
+
+var dnsCmd = &cobra.Command{
+	Use:   "dns",
+	Short: "Manage the DNS records, nameservers and search domains of Headscale",
+	Long: `
+Manage the DNS settings stored in the database, they are sent to the nodes
+in addition to the DNS settings of the configuration file.`,
+}
+
+var dnsRecordsCmd = &cobra.Command{
+	Use:     "records",
+	Short:   "Manage the extra DNS records",
+	Aliases: []string{"record"},
+}
+
+var listDNSRecordsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the extra DNS records",
+	Aliases: []string{"ls", "show"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListDNSRecords(ctx, &v1.ListDNSRecordsRequest{})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error getting the list of DNS records: %s", err),
+				output,
+			)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetRecords(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"ID", "Name", "Type", "Value", "Created"},
+		}
+		for _, record := range response.GetRecords() {
+			tableData = append(tableData, []string{
+				strconv.FormatUint(record.GetId(), util.Base10),
+				record.GetName(),
+				record.GetType(),
+				record.GetValue(),
+				record.GetCreatedAt().AsTime().Format(HeadscaleDateTimeFormat),
+			})
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Failed to render pterm table: %s", err),
+				output,
+			)
+		}
+	},
+}
+
+var createDNSRecordCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Create an extra DNS record",
+	Aliases: []string{"c", "new", "add"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		name, _ := cmd.Flags().GetString("name")
+		recordType, _ := cmd.Flags().GetString("type")
+		value, _ := cmd.Flags().GetString("value")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+			Name:  name,
+			Type:  recordType,
+			Value: value,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot create DNS record: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetRecord(), "DNS record created", output)
+	},
+}
+
+var updateDNSRecordCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Replace the name, type and value of an extra DNS record",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+		name, _ := cmd.Flags().GetString("name")
+		recordType, _ := cmd.Flags().GetString("type")
+		value, _ := cmd.Flags().GetString("value")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.UpdateDNSRecord(ctx, &v1.UpdateDNSRecordRequest{
+			Id:    id,
+			Name:  name,
+			Type:  recordType,
+			Value: value,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot update DNS record: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetRecord(), "DNS record updated", output)
+	},
+}
+
+var deleteDNSRecordCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete an extra DNS record",
+	Aliases: []string{"remove", "del", "rm"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.DeleteDNSRecord(ctx, &v1.DeleteDNSRecordRequest{Id: id})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot delete DNS record: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "DNS record deleted", output)
+	},
+}
+
+var nameserversCmd = &cobra.Command{
+	Use:     "nameservers",
+	Short:   "Manage the global and split DNS nameservers",
+	Aliases: []string{"nameserver", "ns"},
+}
+
+var listNameserversCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the nameservers",
+	Aliases: []string{"ls", "show"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListNameservers(ctx, &v1.ListNameserversRequest{})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error getting the list of nameservers: %s", err),
+				output,
+			)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetNameservers(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"ID", "Address", "Domain", "Created"},
+		}
+		for _, ns := range response.GetNameservers() {
+			domain := ns.GetDomain()
+			if domain == "" {
+				domain = "(global)"
+			}
+
+			tableData = append(tableData, []string{
+				strconv.FormatUint(ns.GetId(), util.Base10),
+				ns.GetAddress(),
+				domain,
+				ns.GetCreatedAt().AsTime().Format(HeadscaleDateTimeFormat),
+			})
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Failed to render pterm table: %s", err),
+				output,
+			)
+		}
+	},
+}
+
+var createNameserverCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Add a global nameserver, or a split DNS nameserver with --domain",
+	Aliases: []string{"c", "new", "add"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		address, _ := cmd.Flags().GetString("address")
+		domain, _ := cmd.Flags().GetString("domain")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.CreateNameserver(ctx, &v1.CreateNameserverRequest{
+			Address: address,
+			Domain:  domain,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot create nameserver: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetNameserver(), "Nameserver created", output)
+	},
+}
+
+var updateNameserverCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Replace the address and domain of a nameserver",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+		address, _ := cmd.Flags().GetString("address")
+		domain, _ := cmd.Flags().GetString("domain")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.UpdateNameserver(ctx, &v1.UpdateNameserverRequest{
+			Id:      id,
+			Address: address,
+			Domain:  domain,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot update nameserver: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetNameserver(), "Nameserver updated", output)
+	},
+}
+
+var deleteNameserverCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete a nameserver",
+	Aliases: []string{"remove", "del", "rm"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.DeleteNameserver(ctx, &v1.DeleteNameserverRequest{Id: id})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot delete nameserver: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "Nameserver deleted", output)
+	},
+}
+
+var searchDomainsCmd = &cobra.Command{
+	Use:     "search-domains",
+	Short:   "Manage the search domains",
+	Aliases: []string{"search-domain", "search"},
+}
+
+var listSearchDomainsCmd = &cobra.Command{
+	Use:     "list",
+	Short:   "List the search domains",
+	Aliases: []string{"ls", "show"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListSearchDomains(ctx, &v1.ListSearchDomainsRequest{})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error getting the list of search domains: %s", err),
+				output,
+			)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetSearchDomains(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"ID", "Domain", "Created"},
+		}
+		for _, sd := range response.GetSearchDomains() {
+			tableData = append(tableData, []string{
+				strconv.FormatUint(sd.GetId(), util.Base10),
+				sd.GetDomain(),
+				sd.GetCreatedAt().AsTime().Format(HeadscaleDateTimeFormat),
+			})
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Failed to render pterm table: %s", err),
+				output,
+			)
+		}
+	},
+}
+
+var createSearchDomainCmd = &cobra.Command{
+	Use:     "create",
+	Short:   "Add a search domain",
+	Aliases: []string{"c", "new", "add"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		domain, _ := cmd.Flags().GetString("domain")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.CreateSearchDomain(ctx, &v1.CreateSearchDomainRequest{Domain: domain})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot create search domain: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetSearchDomain(), "Search domain created", output)
+	},
+}
+
+var updateSearchDomainCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Replace a search domain",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+		domain, _ := cmd.Flags().GetString("domain")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.UpdateSearchDomain(ctx, &v1.UpdateSearchDomainRequest{
+			Id:     id,
+			Domain: domain,
+		})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot update search domain: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response.GetSearchDomain(), "Search domain updated", output)
+	},
+}
+
+var deleteSearchDomainCmd = &cobra.Command{
+	Use:     "delete",
+	Short:   "Delete a search domain",
+	Aliases: []string{"remove", "del", "rm"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, _ := cmd.Flags().GetUint64("identifier")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.DeleteSearchDomain(ctx, &v1.DeleteSearchDomainRequest{Id: id})
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot delete search domain: %s", err),
+				output,
+			)
+		}
+
+		SuccessOutput(response, "Search domain deleted", output)
+	},
+}
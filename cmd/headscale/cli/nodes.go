@@ -1,16 +1,24 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"net/netip"
+	"os"
+	"os/signal"
 	"slices"
 	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	survey "github.com/AlecAivazis/survey/v2"
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol"
 	"github.com/juanfont/headscale/hscontrol/util"
 	"github.com/pterm/pterm"
 	"github.com/samber/lo"
@@ -102,7 +110,34 @@ func init() {
 	approveRoutesCmd.Flags().StringSliceP("routes", "r", []string{}, `List of routes that will be approved (comma-separated, e.g. "10.0.0.0/8,192.168.0.0/24" or empty string to remove all approved routes)`)
 	nodeCmd.AddCommand(approveRoutesCmd)
 
+	setRoutePriorityCmd.Flags().Uint64P("identifier", "i", 0, "Node identifier (ID)")
+	setRoutePriorityCmd.MarkFlagRequired("identifier")
+	setRoutePriorityCmd.Flags().StringSliceP("routes", "r", []string{}, `List of routes the priority is set for (comma-separated, e.g. "10.0.0.0/8,192.168.0.0/24"), all approved routes of the node if empty`)
+	setRoutePriorityCmd.Flags().Int32P("priority", "p", 0, "Priority of the node for the routes, the highest is preferred as primary router, 0 removes the priority")
+	nodeCmd.AddCommand(setRoutePriorityCmd)
+
 	nodeCmd.AddCommand(backfillNodeIPsCmd)
+
+	listPendingNodesCmd.Flags().StringP("user", "u", "", "Filter by user")
+	nodeCmd.AddCommand(listPendingNodesCmd)
+
+	approveNodeCmd.Flags().Uint64P("identifier", "i", 0, "Node identifier (ID)")
+	err = approveNodeCmd.MarkFlagRequired("identifier")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	nodeCmd.AddCommand(approveNodeCmd)
+
+	rejectNodeCmd.Flags().Uint64P("identifier", "i", 0, "Node identifier (ID)")
+	err = rejectNodeCmd.MarkFlagRequired("identifier")
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	nodeCmd.AddCommand(rejectNodeCmd)
+
+	watchNodesCmd.Flags().
+		StringSliceP("type", "t", []string{}, "Only show events of these types (added, removed, online, offline, routes_changed, tags_changed, expired)")
+	nodeCmd.AddCommand(watchNodesCmd)
 }
 
 var nodeCmd = &cobra.Command{
@@ -209,6 +244,79 @@ var listNodesCmd = &cobra.Command{
 	},
 }
 
+var watchNodesCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch the events of the nodes",
+	Long: `Watch the events of the nodes until interrupted.
+
+Events are printed as nodes are added, removed, come online or go offline,
+have their routes or tags changed, or expire.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		outputFormat, _ := cmd.Flags().GetString("output")
+		typeNames, err := cmd.Flags().GetStringSlice("type")
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Error getting type flag: %s", err), outputFormat)
+		}
+
+		request := &v1.WatchNodesRequest{}
+		for _, name := range typeNames {
+			eventType, err := hscontrol.ParseNodeEventType(name)
+			if err != nil {
+				ErrorOutput(err, err.Error(), outputFormat)
+			}
+			request.Types = append(request.Types, eventType)
+		}
+
+		// The stream runs until interrupted, the timeout of the CLI
+		// only applies to connecting.
+		_, client, conn, cancel := newHeadscaleCLIWithConfig()
+		cancel()
+		defer conn.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		stream, err := client.WatchNodes(ctx, request)
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot watch nodes: %s", status.Convert(err).Message()),
+				outputFormat,
+			)
+		}
+
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				if ctx.Err() != nil || errors.Is(err, io.EOF) {
+					return
+				}
+
+				ErrorOutput(
+					err,
+					fmt.Sprintf("Error watching nodes: %s", status.Convert(err).Message()),
+					outputFormat,
+				)
+			}
+
+			if outputFormat != "" {
+				fmt.Println(output(event, "", outputFormat))
+
+				continue
+			}
+
+			fmt.Printf(
+				"%s\t%s\t%d\t%s\t%s\n",
+				event.GetTime().AsTime().Format(HeadscaleDateTimeFormat),
+				hscontrol.NodeEventTypeName(event.GetType()),
+				event.GetNodeId(),
+				event.GetNode().GetGivenName(),
+				event.GetNode().GetUser().GetName(),
+			)
+		}
+	},
+}
+
 var listNodeRoutesCmd = &cobra.Command{
 	Use:     "list-routes",
 	Short:   "List routes available on nodes",
@@ -457,6 +565,141 @@ var deleteNodeCmd = &cobra.Command{
 	},
 }
 
+var listPendingNodesCmd = &cobra.Command{
+	Use:   "pending",
+	Short: "List the nodes waiting for approval",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		user, err := cmd.Flags().GetString("user")
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Error getting user: %s", err), output)
+		}
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		request := &v1.ListPendingNodesRequest{
+			User: user,
+		}
+
+		response, err := client.ListPendingNodes(ctx, request)
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Cannot get pending nodes: %s", status.Convert(err).Message()),
+				output,
+			)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetNodes(), "", output)
+		}
+
+		tableData, err := nodesToPtables(user, false, response.GetNodes())
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Error converting to table: %s", err), output)
+		}
+
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Failed to render pterm table: %s", err),
+				output,
+			)
+		}
+	},
+}
+
+var approveNodeCmd = &cobra.Command{
+	Use:   "approve",
+	Short: "Approve a node waiting for approval",
+	Long:  "Approving a node lets it connect to the other nodes of the network.",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		identifier, err := cmd.Flags().GetUint64("identifier")
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error converting ID to integer: %s", err),
+				output,
+			)
+
+			return
+		}
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		request := &v1.ApproveNodeRequest{
+			NodeId: identifier,
+		}
+
+		response, err := client.ApproveNode(ctx, request)
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf(
+					"Cannot approve node: %s\n",
+					status.Convert(err).Message(),
+				),
+				output,
+			)
+
+			return
+		}
+
+		SuccessOutput(response.GetNode(), "Node approved", output)
+	},
+}
+
+var rejectNodeCmd = &cobra.Command{
+	Use:   "reject",
+	Short: "Reject a node waiting for approval",
+	Long:  "Rejecting a node deletes it, the node has to register again.",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		identifier, err := cmd.Flags().GetUint64("identifier")
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error converting ID to integer: %s", err),
+				output,
+			)
+
+			return
+		}
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		request := &v1.RejectNodeRequest{
+			NodeId: identifier,
+		}
+
+		response, err := client.RejectNode(ctx, request)
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf(
+					"Cannot reject node: %s\n",
+					status.Convert(err).Message(),
+				),
+				output,
+			)
+
+			return
+		}
+
+		SuccessOutput(response, "Node rejected", output)
+	},
+}
+
 var moveNodeCmd = &cobra.Command{
 	Use:     "move",
 	Short:   "Move node to another user",
@@ -726,6 +969,73 @@ func nodesToPtables(
 	return tableData, nil
 }
 
+var setRoutePriorityCmd = &cobra.Command{
+	Use:   "set-route-priority",
+	Short: "Set the priority of a node to be the primary router of its routes",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		// retrieve flags from CLI
+		identifier, err := cmd.Flags().GetUint64("identifier")
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error converting ID to integer: %s", err),
+				output,
+			)
+
+			return
+		}
+		routes, err := cmd.Flags().GetStringSlice("routes")
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error retrieving list of routes, %v", err),
+				output,
+			)
+
+			return
+		}
+		priority, err := cmd.Flags().GetInt32("priority")
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error retrieving priority, %v", err),
+				output,
+			)
+
+			return
+		}
+
+		request := &v1.SetRoutePriorityRequest{
+			NodeId:   identifier,
+			Routes:   routes,
+			Priority: priority,
+		}
+		resp, err := client.SetRoutePriority(ctx, request)
+		if err != nil {
+			ErrorOutput(
+				err,
+				fmt.Sprintf("Error while setting route priority: %s", err),
+				output,
+			)
+
+			return
+		}
+
+		if resp != nil {
+			SuccessOutput(
+				resp.GetNode(),
+				"Node updated",
+				output,
+			)
+		}
+	},
+}
+
 func nodeRoutesToPtables(
 	nodes []*v1.Node,
 ) (pterm.TableData, error) {
@@ -735,16 +1045,30 @@ func nodeRoutesToPtables(
 		"Approved",
 		"Available",
 		"Serving (Primary)",
+		"Priority",
+		"Primary reason",
 	}
 	tableData := pterm.TableData{tableHeader}
 
 	for _, node := range nodes {
+		var priorities []string
+		for _, route := range slices.Sorted(maps.Keys(node.GetRoutePriorities())) {
+			priorities = append(priorities, fmt.Sprintf("%s: %d", route, node.GetRoutePriorities()[route]))
+		}
+
+		var reasons []string
+		for _, route := range slices.Sorted(maps.Keys(node.GetPrimaryRouteReasons())) {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", route, node.GetPrimaryRouteReasons()[route]))
+		}
+
 		nodeData := []string{
 			strconv.FormatUint(node.GetId(), util.Base10),
 			node.GetGivenName(),
 			strings.Join(node.GetApprovedRoutes(), ", "),
 			strings.Join(node.GetAvailableRoutes(), ", "),
 			strings.Join(node.GetSubnetRoutes(), ", "),
+			strings.Join(priorities, ", "),
+			strings.Join(reasons, ", "),
 		}
 		tableData = append(
 			tableData,
@@ -1,12 +1,18 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/db"
 	"github.com/juanfont/headscale/hscontrol/policy"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/pterm/pterm"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
@@ -25,7 +31,23 @@ func init() {
 	if err := checkPolicy.MarkFlagRequired("file"); err != nil {
 		log.Fatal().Err(err).Msg("")
 	}
+	checkPolicy.Flags().Bool("with-database", false, "Evaluate the policy tests against the users and nodes in the configured database")
 	policyCmd.AddCommand(checkPolicy)
+
+	policyCmd.AddCommand(listPolicyRevisions)
+
+	diffPolicyRevisions.Flags().Uint64("from", 0, "Revision to diff from")
+	if err := diffPolicyRevisions.MarkFlagRequired("from"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	diffPolicyRevisions.Flags().Uint64("to", 0, "Revision to diff to (default: current revision)")
+	policyCmd.AddCommand(diffPolicyRevisions)
+
+	rollbackPolicy.Flags().Uint64("revision", 0, "Revision to roll back to")
+	if err := rollbackPolicy.MarkFlagRequired("revision"); err != nil {
+		log.Fatal().Err(err).Msg("")
+	}
+	policyCmd.AddCommand(rollbackPolicy)
 }
 
 var policyCmd = &cobra.Command{
@@ -96,9 +118,14 @@ var setPolicy = &cobra.Command{
 var checkPolicy = &cobra.Command{
 	Use:   "check",
 	Short: "Check the Policy file for errors",
+	Long: `
+	Checks the policy file for errors and evaluates the tests in the policy.
+	By default, the tests are evaluated against an empty tailnet, so only tests using IPs and hosts
+	are meaningful. Use --with-database to evaluate them against the users and nodes in the database.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		output, _ := cmd.Flags().GetString("output")
 		policyPath, _ := cmd.Flags().GetString("file")
+		withDatabase, _ := cmd.Flags().GetBool("with-database")
 
 		f, err := os.Open(policyPath)
 		if err != nil {
@@ -111,7 +138,35 @@ var checkPolicy = &cobra.Command{
 			ErrorOutput(err, fmt.Sprintf("Error reading the policy file: %s", err), output)
 		}
 
-		_, err = policy.NewPolicyManager(policyBytes, nil, nil)
+		var users []types.User
+		var nodes types.Nodes
+		if withDatabase {
+			cfg, err := types.LoadServerConfig()
+			if err != nil {
+				ErrorOutput(err, fmt.Sprintf("Error loading the configuration: %s", err), output)
+			}
+
+			hsdb, err := db.NewHeadscaleDatabase(cfg.Database, cfg.BaseDomain, nil)
+			if err != nil {
+				ErrorOutput(err, fmt.Sprintf("Error opening the database: %s", err), output)
+			}
+			defer hsdb.Close()
+
+			users, err = hsdb.ListUsers()
+			if err != nil {
+				ErrorOutput(err, fmt.Sprintf("Error listing users: %s", err), output)
+			}
+
+			nodes, err = hsdb.ListNodes()
+			if err != nil {
+				ErrorOutput(err, fmt.Sprintf("Error listing nodes: %s", err), output)
+			}
+		}
+
+		err = policy.CheckPolicy(policyBytes, users, nodes)
+		if errors.Is(err, policy.ErrPolicyTestsFailed) {
+			ErrorOutput(err, fmt.Sprintf("Error testing the policy file: %s", err), output)
+		}
 		if err != nil {
 			ErrorOutput(err, fmt.Sprintf("Error parsing the policy file: %s", err), output)
 		}
@@ -119,3 +174,95 @@ var checkPolicy = &cobra.Command{
 		SuccessOutput(nil, "Policy is valid", "")
 	},
 }
+
+var listPolicyRevisions = &cobra.Command{
+	Use:     "revisions",
+	Short:   "List the revisions of the ACL Policy",
+	Aliases: []string{"history"},
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		response, err := client.ListPolicyRevisions(ctx, &v1.ListPolicyRevisionsRequest{})
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Error getting the list of policy revisions: %s", err), output)
+		}
+
+		if output != "" {
+			SuccessOutput(response.GetRevisions(), "", output)
+		}
+
+		tableData := pterm.TableData{
+			{"ID", "Author", "Created"},
+		}
+		for _, revision := range response.GetRevisions() {
+			tableData = append(tableData, []string{
+				strconv.FormatUint(revision.GetId(), util.Base10),
+				revision.GetAuthor(),
+				revision.GetCreatedAt().AsTime().Format(HeadscaleDateTimeFormat),
+			})
+		}
+		err = pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Failed to render pterm table: %s", err), output)
+		}
+	},
+}
+
+var diffPolicyRevisions = &cobra.Command{
+	Use:   "diff",
+	Short: "Show the difference between two revisions of the ACL Policy",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		from, _ := cmd.Flags().GetUint64("from")
+		to, _ := cmd.Flags().GetUint64("to")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		request := &v1.DiffPolicyRevisionsRequest{
+			FromId: from,
+			ToId:   to,
+		}
+
+		response, err := client.DiffPolicyRevisions(ctx, request)
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Failed to diff policy revisions: %s", err), output)
+		}
+
+		SuccessOutput("", response.GetDiff(), "")
+	},
+}
+
+var rollbackPolicy = &cobra.Command{
+	Use:   "rollback",
+	Short: "Roll back the ACL Policy to an earlier revision",
+	Long: `
+	Sets the ACL Policy to the policy of an earlier revision, the rollback is stored as a new revision.
+	This command only works when the acl.policy_mode is set to "db".`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		revision, _ := cmd.Flags().GetUint64("revision")
+
+		ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+		defer cancel()
+		defer conn.Close()
+
+		request := &v1.RollbackPolicyRequest{RevisionId: revision}
+
+		response, err := client.RollbackPolicy(ctx, request)
+		if err != nil {
+			ErrorOutput(err, fmt.Sprintf("Failed to roll back ACL Policy: %s", err), output)
+		}
+
+		SuccessOutput(
+			response.GetRevision(),
+			fmt.Sprintf("Policy rolled back to revision %d.", revision),
+			output,
+		)
+	},
+}
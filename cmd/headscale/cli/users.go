@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strconv"
 
 	survey "github.com/AlecAivazis/survey/v2"
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
@@ -53,6 +54,10 @@ func init() {
 	usernameAndIDFlag(renameUserCmd)
 	renameUserCmd.Flags().StringP("new-name", "r", "", "New username")
 	renameNodeCmd.MarkFlagRequired("new-name")
+	userCmd.AddCommand(disableUserCmd)
+	usernameAndIDFlag(disableUserCmd)
+	userCmd.AddCommand(enableUserCmd)
+	usernameAndIDFlag(enableUserCmd)
 }
 
 var errMissingParameter = errors.New("missing parameters")
@@ -242,7 +247,7 @@ var listUsersCmd = &cobra.Command{
 			SuccessOutput(response.GetUsers(), "", output)
 		}
 
-		tableData := pterm.TableData{{"ID", "Name", "Username", "Email", "Created"}}
+		tableData := pterm.TableData{{"ID", "Name", "Username", "Email", "Created", "Disabled"}}
 		for _, user := range response.GetUsers() {
 			tableData = append(
 				tableData,
@@ -252,6 +257,7 @@ var listUsersCmd = &cobra.Command{
 					user.GetName(),
 					user.GetEmail(),
 					user.GetCreatedAt().AsTime().Format("2006-01-02 15:04:05"),
+					strconv.FormatBool(user.GetDisabled()),
 				},
 			)
 		}
@@ -323,3 +329,70 @@ var renameUserCmd = &cobra.Command{
 		SuccessOutput(response.GetUser(), "User renamed", output)
 	},
 }
+
+var disableUserCmd = &cobra.Command{
+	Use:   "disable --identifier ID or --name NAME",
+	Short: "Disables a user, its nodes are no longer authorized and it cannot register new nodes",
+	Run: func(cmd *cobra.Command, args []string) {
+		setUserDisabled(cmd, true)
+	},
+}
+
+var enableUserCmd = &cobra.Command{
+	Use:   "enable --identifier ID or --name NAME",
+	Short: "Enables a disabled user",
+	Run: func(cmd *cobra.Command, args []string) {
+		setUserDisabled(cmd, false)
+	},
+}
+
+func setUserDisabled(cmd *cobra.Command, disabled bool) {
+	output, _ := cmd.Flags().GetString("output")
+
+	ctx, client, conn, cancel := newHeadscaleCLIWithConfig()
+	defer cancel()
+	defer conn.Close()
+
+	id, username := usernameAndIDFromFlag(cmd)
+	users, err := client.ListUsers(ctx, &v1.ListUsersRequest{
+		Name: username,
+		Id:   id,
+	})
+	if err != nil {
+		ErrorOutput(
+			err,
+			fmt.Sprintf("Error: %s", status.Convert(err).Message()),
+			output,
+		)
+	}
+
+	if len(users.GetUsers()) != 1 {
+		err := fmt.Errorf("Unable to determine user, query returned %d users, use ID", len(users.GetUsers()))
+		ErrorOutput(
+			err,
+			fmt.Sprintf("Error: %s", status.Convert(err).Message()),
+			output,
+		)
+	}
+
+	response, err := client.SetUserDisabled(ctx, &v1.SetUserDisabledRequest{
+		Id:       users.GetUsers()[0].GetId(),
+		Disabled: disabled,
+	})
+	if err != nil {
+		ErrorOutput(
+			err,
+			fmt.Sprintf(
+				"Cannot change user: %s",
+				status.Convert(err).Message(),
+			),
+			output,
+		)
+	}
+
+	if disabled {
+		SuccessOutput(response.GetUser(), "User disabled", output)
+	} else {
+		SuccessOutput(response.GetUser(), "User enabled", output)
+	}
+}
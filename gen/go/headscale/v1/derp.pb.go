@@ -0,0 +1,1128 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: headscale/v1/derp.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DERPProbeResult is the last result of probing a DERP server.
+type DERPProbeResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Healthy         bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	Error           string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	DerpLatencyMsec float64                `protobuf:"fixed64,3,opt,name=derp_latency_msec,json=derpLatencyMsec,proto3" json:"derp_latency_msec,omitempty"`
+	StunLatencyMsec float64                `protobuf:"fixed64,4,opt,name=stun_latency_msec,json=stunLatencyMsec,proto3" json:"stun_latency_msec,omitempty"`
+	ProbedAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=probed_at,json=probedAt,proto3" json:"probed_at,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DERPProbeResult) Reset() {
+	*x = DERPProbeResult{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DERPProbeResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DERPProbeResult) ProtoMessage() {}
+
+func (x *DERPProbeResult) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DERPProbeResult.ProtoReflect.Descriptor instead.
+func (*DERPProbeResult) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DERPProbeResult) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *DERPProbeResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DERPProbeResult) GetDerpLatencyMsec() float64 {
+	if x != nil {
+		return x.DerpLatencyMsec
+	}
+	return 0
+}
+
+func (x *DERPProbeResult) GetStunLatencyMsec() float64 {
+	if x != nil {
+		return x.StunLatencyMsec
+	}
+	return 0
+}
+
+func (x *DERPProbeResult) GetProbedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ProbedAt
+	}
+	return nil
+}
+
+type DERPNode struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	RegionId int32                  `protobuf:"varint,2,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	HostName string                 `protobuf:"bytes,3,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	Ipv4     string                 `protobuf:"bytes,4,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6     string                 `protobuf:"bytes,5,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	// stun_port is the STUN port, 0 for the default port 3478 and -1 to
+	// disable STUN.
+	StunPort int32 `protobuf:"varint,6,opt,name=stun_port,json=stunPort,proto3" json:"stun_port,omitempty"`
+	// derp_port is the HTTPS port, 0 for the default port 443.
+	DerpPort int32 `protobuf:"varint,7,opt,name=derp_port,json=derpPort,proto3" json:"derp_port,omitempty"`
+	StunOnly bool  `protobuf:"varint,8,opt,name=stun_only,json=stunOnly,proto3" json:"stun_only,omitempty"`
+	// source is "config" for nodes of the configured DERP map and "api" for
+	// nodes managed through the API.
+	Source        string           `protobuf:"bytes,9,opt,name=source,proto3" json:"source,omitempty"`
+	Disabled      bool             `protobuf:"varint,10,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	Probe         *DERPProbeResult `protobuf:"bytes,11,opt,name=probe,proto3" json:"probe,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DERPNode) Reset() {
+	*x = DERPNode{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DERPNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DERPNode) ProtoMessage() {}
+
+func (x *DERPNode) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DERPNode.ProtoReflect.Descriptor instead.
+func (*DERPNode) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DERPNode) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DERPNode) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *DERPNode) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *DERPNode) GetIpv4() string {
+	if x != nil {
+		return x.Ipv4
+	}
+	return ""
+}
+
+func (x *DERPNode) GetIpv6() string {
+	if x != nil {
+		return x.Ipv6
+	}
+	return ""
+}
+
+func (x *DERPNode) GetStunPort() int32 {
+	if x != nil {
+		return x.StunPort
+	}
+	return 0
+}
+
+func (x *DERPNode) GetDerpPort() int32 {
+	if x != nil {
+		return x.DerpPort
+	}
+	return 0
+}
+
+func (x *DERPNode) GetStunOnly() bool {
+	if x != nil {
+		return x.StunOnly
+	}
+	return false
+}
+
+func (x *DERPNode) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DERPNode) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *DERPNode) GetProbe() *DERPProbeResult {
+	if x != nil {
+		return x.Probe
+	}
+	return nil
+}
+
+type DERPRegion struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	RegionId int32                  `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Code     string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Name     string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Nodes    []*DERPNode            `protobuf:"bytes,4,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	// source is "config" for regions of the configured DERP map and "api"
+	// for regions managed through the API.
+	Source   string `protobuf:"bytes,5,opt,name=source,proto3" json:"source,omitempty"`
+	Disabled bool   `protobuf:"varint,6,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	// avoid is set if no DERP server of the region could be reached by the
+	// prober, the nodes only use the region if they are already connected.
+	Avoid         bool `protobuf:"varint,7,opt,name=avoid,proto3" json:"avoid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DERPRegion) Reset() {
+	*x = DERPRegion{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DERPRegion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DERPRegion) ProtoMessage() {}
+
+func (x *DERPRegion) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DERPRegion.ProtoReflect.Descriptor instead.
+func (*DERPRegion) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *DERPRegion) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *DERPRegion) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *DERPRegion) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DERPRegion) GetNodes() []*DERPNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *DERPRegion) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *DERPRegion) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *DERPRegion) GetAvoid() bool {
+	if x != nil {
+		return x.Avoid
+	}
+	return false
+}
+
+type ListDERPRegionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDERPRegionsRequest) Reset() {
+	*x = ListDERPRegionsRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDERPRegionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDERPRegionsRequest) ProtoMessage() {}
+
+func (x *ListDERPRegionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDERPRegionsRequest.ProtoReflect.Descriptor instead.
+func (*ListDERPRegionsRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{3}
+}
+
+type ListDERPRegionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Regions       []*DERPRegion          `protobuf:"bytes,1,rep,name=regions,proto3" json:"regions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDERPRegionsResponse) Reset() {
+	*x = ListDERPRegionsResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDERPRegionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDERPRegionsResponse) ProtoMessage() {}
+
+func (x *ListDERPRegionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDERPRegionsResponse.ProtoReflect.Descriptor instead.
+func (*ListDERPRegionsResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListDERPRegionsResponse) GetRegions() []*DERPRegion {
+	if x != nil {
+		return x.Regions
+	}
+	return nil
+}
+
+type CreateDERPRegionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RegionId      int32                  `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Code          string                 `protobuf:"bytes,2,opt,name=code,proto3" json:"code,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDERPRegionRequest) Reset() {
+	*x = CreateDERPRegionRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDERPRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDERPRegionRequest) ProtoMessage() {}
+
+func (x *CreateDERPRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDERPRegionRequest.ProtoReflect.Descriptor instead.
+func (*CreateDERPRegionRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateDERPRegionRequest) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *CreateDERPRegionRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *CreateDERPRegionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type CreateDERPRegionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Region        *DERPRegion            `protobuf:"bytes,1,opt,name=region,proto3" json:"region,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDERPRegionResponse) Reset() {
+	*x = CreateDERPRegionResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDERPRegionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDERPRegionResponse) ProtoMessage() {}
+
+func (x *CreateDERPRegionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDERPRegionResponse.ProtoReflect.Descriptor instead.
+func (*CreateDERPRegionResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateDERPRegionResponse) GetRegion() *DERPRegion {
+	if x != nil {
+		return x.Region
+	}
+	return nil
+}
+
+type DeleteDERPRegionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RegionId      int32                  `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDERPRegionRequest) Reset() {
+	*x = DeleteDERPRegionRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDERPRegionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDERPRegionRequest) ProtoMessage() {}
+
+func (x *DeleteDERPRegionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDERPRegionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDERPRegionRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteDERPRegionRequest) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+type DeleteDERPRegionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDERPRegionResponse) Reset() {
+	*x = DeleteDERPRegionResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDERPRegionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDERPRegionResponse) ProtoMessage() {}
+
+func (x *DeleteDERPRegionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDERPRegionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDERPRegionResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{8}
+}
+
+type SetDERPRegionDisabledRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RegionId      int32                  `protobuf:"varint,1,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	Disabled      bool                   `protobuf:"varint,2,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDERPRegionDisabledRequest) Reset() {
+	*x = SetDERPRegionDisabledRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDERPRegionDisabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDERPRegionDisabledRequest) ProtoMessage() {}
+
+func (x *SetDERPRegionDisabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDERPRegionDisabledRequest.ProtoReflect.Descriptor instead.
+func (*SetDERPRegionDisabledRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *SetDERPRegionDisabledRequest) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *SetDERPRegionDisabledRequest) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type SetDERPRegionDisabledResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDERPRegionDisabledResponse) Reset() {
+	*x = SetDERPRegionDisabledResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDERPRegionDisabledResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDERPRegionDisabledResponse) ProtoMessage() {}
+
+func (x *SetDERPRegionDisabledResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDERPRegionDisabledResponse.ProtoReflect.Descriptor instead.
+func (*SetDERPRegionDisabledResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{10}
+}
+
+type CreateDERPNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	RegionId      int32                  `protobuf:"varint,2,opt,name=region_id,json=regionId,proto3" json:"region_id,omitempty"`
+	HostName      string                 `protobuf:"bytes,3,opt,name=host_name,json=hostName,proto3" json:"host_name,omitempty"`
+	Ipv4          string                 `protobuf:"bytes,4,opt,name=ipv4,proto3" json:"ipv4,omitempty"`
+	Ipv6          string                 `protobuf:"bytes,5,opt,name=ipv6,proto3" json:"ipv6,omitempty"`
+	StunPort      int32                  `protobuf:"varint,6,opt,name=stun_port,json=stunPort,proto3" json:"stun_port,omitempty"`
+	DerpPort      int32                  `protobuf:"varint,7,opt,name=derp_port,json=derpPort,proto3" json:"derp_port,omitempty"`
+	StunOnly      bool                   `protobuf:"varint,8,opt,name=stun_only,json=stunOnly,proto3" json:"stun_only,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDERPNodeRequest) Reset() {
+	*x = CreateDERPNodeRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDERPNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDERPNodeRequest) ProtoMessage() {}
+
+func (x *CreateDERPNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDERPNodeRequest.ProtoReflect.Descriptor instead.
+func (*CreateDERPNodeRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *CreateDERPNodeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDERPNodeRequest) GetRegionId() int32 {
+	if x != nil {
+		return x.RegionId
+	}
+	return 0
+}
+
+func (x *CreateDERPNodeRequest) GetHostName() string {
+	if x != nil {
+		return x.HostName
+	}
+	return ""
+}
+
+func (x *CreateDERPNodeRequest) GetIpv4() string {
+	if x != nil {
+		return x.Ipv4
+	}
+	return ""
+}
+
+func (x *CreateDERPNodeRequest) GetIpv6() string {
+	if x != nil {
+		return x.Ipv6
+	}
+	return ""
+}
+
+func (x *CreateDERPNodeRequest) GetStunPort() int32 {
+	if x != nil {
+		return x.StunPort
+	}
+	return 0
+}
+
+func (x *CreateDERPNodeRequest) GetDerpPort() int32 {
+	if x != nil {
+		return x.DerpPort
+	}
+	return 0
+}
+
+func (x *CreateDERPNodeRequest) GetStunOnly() bool {
+	if x != nil {
+		return x.StunOnly
+	}
+	return false
+}
+
+type CreateDERPNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *DERPNode              `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDERPNodeResponse) Reset() {
+	*x = CreateDERPNodeResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDERPNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDERPNodeResponse) ProtoMessage() {}
+
+func (x *CreateDERPNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDERPNodeResponse.ProtoReflect.Descriptor instead.
+func (*CreateDERPNodeResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateDERPNodeResponse) GetNode() *DERPNode {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type DeleteDERPNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDERPNodeRequest) Reset() {
+	*x = DeleteDERPNodeRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDERPNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDERPNodeRequest) ProtoMessage() {}
+
+func (x *DeleteDERPNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDERPNodeRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDERPNodeRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *DeleteDERPNodeRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type DeleteDERPNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDERPNodeResponse) Reset() {
+	*x = DeleteDERPNodeResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDERPNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDERPNodeResponse) ProtoMessage() {}
+
+func (x *DeleteDERPNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDERPNodeResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDERPNodeResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{14}
+}
+
+type SetDERPNodeDisabledRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Disabled      bool                   `protobuf:"varint,2,opt,name=disabled,proto3" json:"disabled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDERPNodeDisabledRequest) Reset() {
+	*x = SetDERPNodeDisabledRequest{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDERPNodeDisabledRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDERPNodeDisabledRequest) ProtoMessage() {}
+
+func (x *SetDERPNodeDisabledRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDERPNodeDisabledRequest.ProtoReflect.Descriptor instead.
+func (*SetDERPNodeDisabledRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SetDERPNodeDisabledRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SetDERPNodeDisabledRequest) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+type SetDERPNodeDisabledResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetDERPNodeDisabledResponse) Reset() {
+	*x = SetDERPNodeDisabledResponse{}
+	mi := &file_headscale_v1_derp_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetDERPNodeDisabledResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetDERPNodeDisabledResponse) ProtoMessage() {}
+
+func (x *SetDERPNodeDisabledResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_derp_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetDERPNodeDisabledResponse.ProtoReflect.Descriptor instead.
+func (*SetDERPNodeDisabledResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_derp_proto_rawDescGZIP(), []int{16}
+}
+
+var File_headscale_v1_derp_proto protoreflect.FileDescriptor
+
+const file_headscale_v1_derp_proto_rawDesc = "" +
+	"\n" +
+	"\x17headscale/v1/derp.proto\x12\fheadscale.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xd2\x01\n" +
+	"\x0fDERPProbeResult\x12\x18\n" +
+	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\x12*\n" +
+	"\x11derp_latency_msec\x18\x03 \x01(\x01R\x0fderpLatencyMsec\x12*\n" +
+	"\x11stun_latency_msec\x18\x04 \x01(\x01R\x0fstunLatencyMsec\x127\n" +
+	"\tprobed_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\bprobedAt\"\xc0\x02\n" +
+	"\bDERPNode\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tregion_id\x18\x02 \x01(\x05R\bregionId\x12\x1b\n" +
+	"\thost_name\x18\x03 \x01(\tR\bhostName\x12\x12\n" +
+	"\x04ipv4\x18\x04 \x01(\tR\x04ipv4\x12\x12\n" +
+	"\x04ipv6\x18\x05 \x01(\tR\x04ipv6\x12\x1b\n" +
+	"\tstun_port\x18\x06 \x01(\x05R\bstunPort\x12\x1b\n" +
+	"\tderp_port\x18\a \x01(\x05R\bderpPort\x12\x1b\n" +
+	"\tstun_only\x18\b \x01(\bR\bstunOnly\x12\x16\n" +
+	"\x06source\x18\t \x01(\tR\x06source\x12\x1a\n" +
+	"\bdisabled\x18\n" +
+	" \x01(\bR\bdisabled\x123\n" +
+	"\x05probe\x18\v \x01(\v2\x1d.headscale.v1.DERPProbeResultR\x05probe\"\xc9\x01\n" +
+	"\n" +
+	"DERPRegion\x12\x1b\n" +
+	"\tregion_id\x18\x01 \x01(\x05R\bregionId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12,\n" +
+	"\x05nodes\x18\x04 \x03(\v2\x16.headscale.v1.DERPNodeR\x05nodes\x12\x16\n" +
+	"\x06source\x18\x05 \x01(\tR\x06source\x12\x1a\n" +
+	"\bdisabled\x18\x06 \x01(\bR\bdisabled\x12\x14\n" +
+	"\x05avoid\x18\a \x01(\bR\x05avoid\"\x18\n" +
+	"\x16ListDERPRegionsRequest\"M\n" +
+	"\x17ListDERPRegionsResponse\x122\n" +
+	"\aregions\x18\x01 \x03(\v2\x18.headscale.v1.DERPRegionR\aregions\"^\n" +
+	"\x17CreateDERPRegionRequest\x12\x1b\n" +
+	"\tregion_id\x18\x01 \x01(\x05R\bregionId\x12\x12\n" +
+	"\x04code\x18\x02 \x01(\tR\x04code\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\"L\n" +
+	"\x18CreateDERPRegionResponse\x120\n" +
+	"\x06region\x18\x01 \x01(\v2\x18.headscale.v1.DERPRegionR\x06region\"6\n" +
+	"\x17DeleteDERPRegionRequest\x12\x1b\n" +
+	"\tregion_id\x18\x01 \x01(\x05R\bregionId\"\x1a\n" +
+	"\x18DeleteDERPRegionResponse\"W\n" +
+	"\x1cSetDERPRegionDisabledRequest\x12\x1b\n" +
+	"\tregion_id\x18\x01 \x01(\x05R\bregionId\x12\x1a\n" +
+	"\bdisabled\x18\x02 \x01(\bR\bdisabled\"\x1f\n" +
+	"\x1dSetDERPRegionDisabledResponse\"\xe4\x01\n" +
+	"\x15CreateDERPNodeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1b\n" +
+	"\tregion_id\x18\x02 \x01(\x05R\bregionId\x12\x1b\n" +
+	"\thost_name\x18\x03 \x01(\tR\bhostName\x12\x12\n" +
+	"\x04ipv4\x18\x04 \x01(\tR\x04ipv4\x12\x12\n" +
+	"\x04ipv6\x18\x05 \x01(\tR\x04ipv6\x12\x1b\n" +
+	"\tstun_port\x18\x06 \x01(\x05R\bstunPort\x12\x1b\n" +
+	"\tderp_port\x18\a \x01(\x05R\bderpPort\x12\x1b\n" +
+	"\tstun_only\x18\b \x01(\bR\bstunOnly\"D\n" +
+	"\x16CreateDERPNodeResponse\x12*\n" +
+	"\x04node\x18\x01 \x01(\v2\x16.headscale.v1.DERPNodeR\x04node\"+\n" +
+	"\x15DeleteDERPNodeRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"\x18\n" +
+	"\x16DeleteDERPNodeResponse\"L\n" +
+	"\x1aSetDERPNodeDisabledRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bdisabled\x18\x02 \x01(\bR\bdisabled\"\x1d\n" +
+	"\x1bSetDERPNodeDisabledResponseB)Z'github.com/juanfont/headscale/gen/go/v1b\x06proto3"
+
+var (
+	file_headscale_v1_derp_proto_rawDescOnce sync.Once
+	file_headscale_v1_derp_proto_rawDescData []byte
+)
+
+func file_headscale_v1_derp_proto_rawDescGZIP() []byte {
+	file_headscale_v1_derp_proto_rawDescOnce.Do(func() {
+		file_headscale_v1_derp_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_headscale_v1_derp_proto_rawDesc), len(file_headscale_v1_derp_proto_rawDesc)))
+	})
+	return file_headscale_v1_derp_proto_rawDescData
+}
+
+var file_headscale_v1_derp_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_headscale_v1_derp_proto_goTypes = []any{
+	(*DERPProbeResult)(nil),               // 0: headscale.v1.DERPProbeResult
+	(*DERPNode)(nil),                      // 1: headscale.v1.DERPNode
+	(*DERPRegion)(nil),                    // 2: headscale.v1.DERPRegion
+	(*ListDERPRegionsRequest)(nil),        // 3: headscale.v1.ListDERPRegionsRequest
+	(*ListDERPRegionsResponse)(nil),       // 4: headscale.v1.ListDERPRegionsResponse
+	(*CreateDERPRegionRequest)(nil),       // 5: headscale.v1.CreateDERPRegionRequest
+	(*CreateDERPRegionResponse)(nil),      // 6: headscale.v1.CreateDERPRegionResponse
+	(*DeleteDERPRegionRequest)(nil),       // 7: headscale.v1.DeleteDERPRegionRequest
+	(*DeleteDERPRegionResponse)(nil),      // 8: headscale.v1.DeleteDERPRegionResponse
+	(*SetDERPRegionDisabledRequest)(nil),  // 9: headscale.v1.SetDERPRegionDisabledRequest
+	(*SetDERPRegionDisabledResponse)(nil), // 10: headscale.v1.SetDERPRegionDisabledResponse
+	(*CreateDERPNodeRequest)(nil),         // 11: headscale.v1.CreateDERPNodeRequest
+	(*CreateDERPNodeResponse)(nil),        // 12: headscale.v1.CreateDERPNodeResponse
+	(*DeleteDERPNodeRequest)(nil),         // 13: headscale.v1.DeleteDERPNodeRequest
+	(*DeleteDERPNodeResponse)(nil),        // 14: headscale.v1.DeleteDERPNodeResponse
+	(*SetDERPNodeDisabledRequest)(nil),    // 15: headscale.v1.SetDERPNodeDisabledRequest
+	(*SetDERPNodeDisabledResponse)(nil),   // 16: headscale.v1.SetDERPNodeDisabledResponse
+	(*timestamppb.Timestamp)(nil),         // 17: google.protobuf.Timestamp
+}
+var file_headscale_v1_derp_proto_depIdxs = []int32{
+	17, // 0: headscale.v1.DERPProbeResult.probed_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: headscale.v1.DERPNode.probe:type_name -> headscale.v1.DERPProbeResult
+	1,  // 2: headscale.v1.DERPRegion.nodes:type_name -> headscale.v1.DERPNode
+	2,  // 3: headscale.v1.ListDERPRegionsResponse.regions:type_name -> headscale.v1.DERPRegion
+	2,  // 4: headscale.v1.CreateDERPRegionResponse.region:type_name -> headscale.v1.DERPRegion
+	1,  // 5: headscale.v1.CreateDERPNodeResponse.node:type_name -> headscale.v1.DERPNode
+	6,  // [6:6] is the sub-list for method output_type
+	6,  // [6:6] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_headscale_v1_derp_proto_init() }
+func file_headscale_v1_derp_proto_init() {
+	if File_headscale_v1_derp_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_headscale_v1_derp_proto_rawDesc), len(file_headscale_v1_derp_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_headscale_v1_derp_proto_goTypes,
+		DependencyIndexes: file_headscale_v1_derp_proto_depIdxs,
+		MessageInfos:      file_headscale_v1_derp_proto_msgTypes,
+	}.Build()
+	File_headscale_v1_derp_proto = out.File
+	file_headscale_v1_derp_proto_goTypes = nil
+	file_headscale_v1_derp_proto_depIdxs = nil
+}
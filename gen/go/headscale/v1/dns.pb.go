@@ -0,0 +1,1489 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: headscale/v1/dns.proto
+
+package v1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DNSRecord is an extra DNS record served to the nodes, in addition to
+// the records of the configuration file. The name can be a wildcard, e.g.
+// *.svc.example.com.
+type DNSRecord struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name  string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	// type is A, AAAA, CNAME or ALIAS. The value of a CNAME record is the
+	// target name, the value of an ALIAS record the ID or name of a node.
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DNSRecord) Reset() {
+	*x = DNSRecord{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DNSRecord) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DNSRecord) ProtoMessage() {}
+
+func (x *DNSRecord) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DNSRecord.ProtoReflect.Descriptor instead.
+func (*DNSRecord) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DNSRecord) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DNSRecord) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *DNSRecord) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListDNSRecordsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDNSRecordsRequest) Reset() {
+	*x = ListDNSRecordsRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDNSRecordsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDNSRecordsRequest) ProtoMessage() {}
+
+func (x *ListDNSRecordsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDNSRecordsRequest.ProtoReflect.Descriptor instead.
+func (*ListDNSRecordsRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{1}
+}
+
+type ListDNSRecordsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Records       []*DNSRecord           `protobuf:"bytes,1,rep,name=records,proto3" json:"records,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListDNSRecordsResponse) Reset() {
+	*x = ListDNSRecordsResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListDNSRecordsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListDNSRecordsResponse) ProtoMessage() {}
+
+func (x *ListDNSRecordsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListDNSRecordsResponse.ProtoReflect.Descriptor instead.
+func (*ListDNSRecordsResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListDNSRecordsResponse) GetRecords() []*DNSRecord {
+	if x != nil {
+		return x.Records
+	}
+	return nil
+}
+
+type CreateDNSRecordRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// type is A, AAAA, CNAME or ALIAS, A or AAAA is derived from the value if
+	// empty.
+	Type          string `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string `protobuf:"bytes,3,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDNSRecordRequest) Reset() {
+	*x = CreateDNSRecordRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDNSRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDNSRecordRequest) ProtoMessage() {}
+
+func (x *CreateDNSRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDNSRecordRequest.ProtoReflect.Descriptor instead.
+func (*CreateDNSRecordRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateDNSRecordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateDNSRecordRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *CreateDNSRecordRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type CreateDNSRecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *DNSRecord             `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDNSRecordResponse) Reset() {
+	*x = CreateDNSRecordResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDNSRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDNSRecordResponse) ProtoMessage() {}
+
+func (x *CreateDNSRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDNSRecordResponse.ProtoReflect.Descriptor instead.
+func (*CreateDNSRecordResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CreateDNSRecordResponse) GetRecord() *DNSRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+// UpdateDNSRecordRequest replaces the name, type and value of a record,
+// the type is derived like for CreateDNSRecordRequest.
+type UpdateDNSRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Value         string                 `protobuf:"bytes,4,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDNSRecordRequest) Reset() {
+	*x = UpdateDNSRecordRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDNSRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDNSRecordRequest) ProtoMessage() {}
+
+func (x *UpdateDNSRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDNSRecordRequest.ProtoReflect.Descriptor instead.
+func (*UpdateDNSRecordRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *UpdateDNSRecordRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateDNSRecordRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *UpdateDNSRecordRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UpdateDNSRecordRequest) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+type UpdateDNSRecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Record        *DNSRecord             `protobuf:"bytes,1,opt,name=record,proto3" json:"record,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDNSRecordResponse) Reset() {
+	*x = UpdateDNSRecordResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDNSRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDNSRecordResponse) ProtoMessage() {}
+
+func (x *UpdateDNSRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDNSRecordResponse.ProtoReflect.Descriptor instead.
+func (*UpdateDNSRecordResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateDNSRecordResponse) GetRecord() *DNSRecord {
+	if x != nil {
+		return x.Record
+	}
+	return nil
+}
+
+type DeleteDNSRecordRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDNSRecordRequest) Reset() {
+	*x = DeleteDNSRecordRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDNSRecordRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDNSRecordRequest) ProtoMessage() {}
+
+func (x *DeleteDNSRecordRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDNSRecordRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDNSRecordRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteDNSRecordRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteDNSRecordResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDNSRecordResponse) Reset() {
+	*x = DeleteDNSRecordResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDNSRecordResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDNSRecordResponse) ProtoMessage() {}
+
+func (x *DeleteDNSRecordResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDNSRecordResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDNSRecordResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{8}
+}
+
+// Nameserver is a DNS resolver of the nodes. Without domain it is a global
+// nameserver, otherwise it resolves the queries for the domain (split DNS).
+type Nameserver struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// address is an IP address or a DNS-over-HTTPS URL.
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Domain        string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Nameserver) Reset() {
+	*x = Nameserver{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Nameserver) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Nameserver) ProtoMessage() {}
+
+func (x *Nameserver) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Nameserver.ProtoReflect.Descriptor instead.
+func (*Nameserver) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Nameserver) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Nameserver) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Nameserver) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *Nameserver) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListNameserversRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNameserversRequest) Reset() {
+	*x = ListNameserversRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNameserversRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNameserversRequest) ProtoMessage() {}
+
+func (x *ListNameserversRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNameserversRequest.ProtoReflect.Descriptor instead.
+func (*ListNameserversRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{10}
+}
+
+type ListNameserversResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nameservers   []*Nameserver          `protobuf:"bytes,1,rep,name=nameservers,proto3" json:"nameservers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListNameserversResponse) Reset() {
+	*x = ListNameserversResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListNameserversResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListNameserversResponse) ProtoMessage() {}
+
+func (x *ListNameserversResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListNameserversResponse.ProtoReflect.Descriptor instead.
+func (*ListNameserversResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ListNameserversResponse) GetNameservers() []*Nameserver {
+	if x != nil {
+		return x.Nameservers
+	}
+	return nil
+}
+
+type CreateNameserverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Address       string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNameserverRequest) Reset() {
+	*x = CreateNameserverRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNameserverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNameserverRequest) ProtoMessage() {}
+
+func (x *CreateNameserverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNameserverRequest.ProtoReflect.Descriptor instead.
+func (*CreateNameserverRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *CreateNameserverRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *CreateNameserverRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type CreateNameserverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nameserver    *Nameserver            `protobuf:"bytes,1,opt,name=nameserver,proto3" json:"nameserver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateNameserverResponse) Reset() {
+	*x = CreateNameserverResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateNameserverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateNameserverResponse) ProtoMessage() {}
+
+func (x *CreateNameserverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateNameserverResponse.ProtoReflect.Descriptor instead.
+func (*CreateNameserverResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CreateNameserverResponse) GetNameserver() *Nameserver {
+	if x != nil {
+		return x.Nameserver
+	}
+	return nil
+}
+
+// UpdateNameserverRequest replaces the address and domain of a
+// nameserver, an empty domain makes it a global nameserver.
+type UpdateNameserverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Address       string                 `protobuf:"bytes,2,opt,name=address,proto3" json:"address,omitempty"`
+	Domain        string                 `protobuf:"bytes,3,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNameserverRequest) Reset() {
+	*x = UpdateNameserverRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNameserverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNameserverRequest) ProtoMessage() {}
+
+func (x *UpdateNameserverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNameserverRequest.ProtoReflect.Descriptor instead.
+func (*UpdateNameserverRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *UpdateNameserverRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateNameserverRequest) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *UpdateNameserverRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type UpdateNameserverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nameserver    *Nameserver            `protobuf:"bytes,1,opt,name=nameserver,proto3" json:"nameserver,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateNameserverResponse) Reset() {
+	*x = UpdateNameserverResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateNameserverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateNameserverResponse) ProtoMessage() {}
+
+func (x *UpdateNameserverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateNameserverResponse.ProtoReflect.Descriptor instead.
+func (*UpdateNameserverResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *UpdateNameserverResponse) GetNameserver() *Nameserver {
+	if x != nil {
+		return x.Nameserver
+	}
+	return nil
+}
+
+type DeleteNameserverRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNameserverRequest) Reset() {
+	*x = DeleteNameserverRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNameserverRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNameserverRequest) ProtoMessage() {}
+
+func (x *DeleteNameserverRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNameserverRequest.ProtoReflect.Descriptor instead.
+func (*DeleteNameserverRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *DeleteNameserverRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteNameserverResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteNameserverResponse) Reset() {
+	*x = DeleteNameserverResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteNameserverResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteNameserverResponse) ProtoMessage() {}
+
+func (x *DeleteNameserverResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteNameserverResponse.ProtoReflect.Descriptor instead.
+func (*DeleteNameserverResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{17}
+}
+
+// SearchDomain is a domain appended to unqualified names by the nodes.
+type SearchDomain struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchDomain) Reset() {
+	*x = SearchDomain{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchDomain) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchDomain) ProtoMessage() {}
+
+func (x *SearchDomain) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchDomain.ProtoReflect.Descriptor instead.
+func (*SearchDomain) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *SearchDomain) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *SearchDomain) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *SearchDomain) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+type ListSearchDomainsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSearchDomainsRequest) Reset() {
+	*x = ListSearchDomainsRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSearchDomainsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSearchDomainsRequest) ProtoMessage() {}
+
+func (x *ListSearchDomainsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSearchDomainsRequest.ProtoReflect.Descriptor instead.
+func (*ListSearchDomainsRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{19}
+}
+
+type ListSearchDomainsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SearchDomains []*SearchDomain        `protobuf:"bytes,1,rep,name=search_domains,json=searchDomains,proto3" json:"search_domains,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSearchDomainsResponse) Reset() {
+	*x = ListSearchDomainsResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSearchDomainsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSearchDomainsResponse) ProtoMessage() {}
+
+func (x *ListSearchDomainsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSearchDomainsResponse.ProtoReflect.Descriptor instead.
+func (*ListSearchDomainsResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *ListSearchDomainsResponse) GetSearchDomains() []*SearchDomain {
+	if x != nil {
+		return x.SearchDomains
+	}
+	return nil
+}
+
+type CreateSearchDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Domain        string                 `protobuf:"bytes,1,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSearchDomainRequest) Reset() {
+	*x = CreateSearchDomainRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSearchDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSearchDomainRequest) ProtoMessage() {}
+
+func (x *CreateSearchDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSearchDomainRequest.ProtoReflect.Descriptor instead.
+func (*CreateSearchDomainRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CreateSearchDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type CreateSearchDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SearchDomain  *SearchDomain          `protobuf:"bytes,1,opt,name=search_domain,json=searchDomain,proto3" json:"search_domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateSearchDomainResponse) Reset() {
+	*x = CreateSearchDomainResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateSearchDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateSearchDomainResponse) ProtoMessage() {}
+
+func (x *CreateSearchDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateSearchDomainResponse.ProtoReflect.Descriptor instead.
+func (*CreateSearchDomainResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CreateSearchDomainResponse) GetSearchDomain() *SearchDomain {
+	if x != nil {
+		return x.SearchDomain
+	}
+	return nil
+}
+
+type UpdateSearchDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Domain        string                 `protobuf:"bytes,2,opt,name=domain,proto3" json:"domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSearchDomainRequest) Reset() {
+	*x = UpdateSearchDomainRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSearchDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSearchDomainRequest) ProtoMessage() {}
+
+func (x *UpdateSearchDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSearchDomainRequest.ProtoReflect.Descriptor instead.
+func (*UpdateSearchDomainRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *UpdateSearchDomainRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateSearchDomainRequest) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+type UpdateSearchDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SearchDomain  *SearchDomain          `protobuf:"bytes,1,opt,name=search_domain,json=searchDomain,proto3" json:"search_domain,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateSearchDomainResponse) Reset() {
+	*x = UpdateSearchDomainResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateSearchDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateSearchDomainResponse) ProtoMessage() {}
+
+func (x *UpdateSearchDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateSearchDomainResponse.ProtoReflect.Descriptor instead.
+func (*UpdateSearchDomainResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UpdateSearchDomainResponse) GetSearchDomain() *SearchDomain {
+	if x != nil {
+		return x.SearchDomain
+	}
+	return nil
+}
+
+type DeleteSearchDomainRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            uint64                 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSearchDomainRequest) Reset() {
+	*x = DeleteSearchDomainRequest{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSearchDomainRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSearchDomainRequest) ProtoMessage() {}
+
+func (x *DeleteSearchDomainRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSearchDomainRequest.ProtoReflect.Descriptor instead.
+func (*DeleteSearchDomainRequest) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *DeleteSearchDomainRequest) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type DeleteSearchDomainResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSearchDomainResponse) Reset() {
+	*x = DeleteSearchDomainResponse{}
+	mi := &file_headscale_v1_dns_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSearchDomainResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSearchDomainResponse) ProtoMessage() {}
+
+func (x *DeleteSearchDomainResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_headscale_v1_dns_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSearchDomainResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSearchDomainResponse) Descriptor() ([]byte, []int) {
+	return file_headscale_v1_dns_proto_rawDescGZIP(), []int{26}
+}
+
+var File_headscale_v1_dns_proto protoreflect.FileDescriptor
+
+const file_headscale_v1_dns_proto_rawDesc = "" +
+	"\n" +
+	"\x16headscale/v1/dns.proto\x12\fheadscale.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\x94\x01\n" +
+	"\tDNSRecord\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x17\n" +
+	"\x15ListDNSRecordsRequest\"K\n" +
+	"\x16ListDNSRecordsResponse\x121\n" +
+	"\arecords\x18\x01 \x03(\v2\x17.headscale.v1.DNSRecordR\arecords\"V\n" +
+	"\x16CreateDNSRecordRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x03 \x01(\tR\x05value\"J\n" +
+	"\x17CreateDNSRecordResponse\x12/\n" +
+	"\x06record\x18\x01 \x01(\v2\x17.headscale.v1.DNSRecordR\x06record\"f\n" +
+	"\x16UpdateDNSRecordRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05value\x18\x04 \x01(\tR\x05value\"J\n" +
+	"\x17UpdateDNSRecordResponse\x12/\n" +
+	"\x06record\x18\x01 \x01(\v2\x17.headscale.v1.DNSRecordR\x06record\"(\n" +
+	"\x16DeleteDNSRecordRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x19\n" +
+	"\x17DeleteDNSRecordResponse\"\x89\x01\n" +
+	"\n" +
+	"Nameserver\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x18\n" +
+	"\x16ListNameserversRequest\"U\n" +
+	"\x17ListNameserversResponse\x12:\n" +
+	"\vnameservers\x18\x01 \x03(\v2\x18.headscale.v1.NameserverR\vnameservers\"K\n" +
+	"\x17CreateNameserverRequest\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"T\n" +
+	"\x18CreateNameserverResponse\x128\n" +
+	"\n" +
+	"nameserver\x18\x01 \x01(\v2\x18.headscale.v1.NameserverR\n" +
+	"nameserver\"[\n" +
+	"\x17UpdateNameserverRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x18\n" +
+	"\aaddress\x18\x02 \x01(\tR\aaddress\x12\x16\n" +
+	"\x06domain\x18\x03 \x01(\tR\x06domain\"T\n" +
+	"\x18UpdateNameserverResponse\x128\n" +
+	"\n" +
+	"nameserver\x18\x01 \x01(\v2\x18.headscale.v1.NameserverR\n" +
+	"nameserver\")\n" +
+	"\x17DeleteNameserverRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x1a\n" +
+	"\x18DeleteNameserverResponse\"q\n" +
+	"\fSearchDomain\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\x129\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\"\x1a\n" +
+	"\x18ListSearchDomainsRequest\"^\n" +
+	"\x19ListSearchDomainsResponse\x12A\n" +
+	"\x0esearch_domains\x18\x01 \x03(\v2\x1a.headscale.v1.SearchDomainR\rsearchDomains\"3\n" +
+	"\x19CreateSearchDomainRequest\x12\x16\n" +
+	"\x06domain\x18\x01 \x01(\tR\x06domain\"]\n" +
+	"\x1aCreateSearchDomainResponse\x12?\n" +
+	"\rsearch_domain\x18\x01 \x01(\v2\x1a.headscale.v1.SearchDomainR\fsearchDomain\"C\n" +
+	"\x19UpdateSearchDomainRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x16\n" +
+	"\x06domain\x18\x02 \x01(\tR\x06domain\"]\n" +
+	"\x1aUpdateSearchDomainResponse\x12?\n" +
+	"\rsearch_domain\x18\x01 \x01(\v2\x1a.headscale.v1.SearchDomainR\fsearchDomain\"+\n" +
+	"\x19DeleteSearchDomainRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\"\x1c\n" +
+	"\x1aDeleteSearchDomainResponseB)Z'github.com/juanfont/headscale/gen/go/v1b\x06proto3"
+
+var (
+	file_headscale_v1_dns_proto_rawDescOnce sync.Once
+	file_headscale_v1_dns_proto_rawDescData []byte
+)
+
+func file_headscale_v1_dns_proto_rawDescGZIP() []byte {
+	file_headscale_v1_dns_proto_rawDescOnce.Do(func() {
+		file_headscale_v1_dns_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_headscale_v1_dns_proto_rawDesc), len(file_headscale_v1_dns_proto_rawDesc)))
+	})
+	return file_headscale_v1_dns_proto_rawDescData
+}
+
+var file_headscale_v1_dns_proto_msgTypes = make([]protoimpl.MessageInfo, 27)
+var file_headscale_v1_dns_proto_goTypes = []any{
+	(*DNSRecord)(nil),                  // 0: headscale.v1.DNSRecord
+	(*ListDNSRecordsRequest)(nil),      // 1: headscale.v1.ListDNSRecordsRequest
+	(*ListDNSRecordsResponse)(nil),     // 2: headscale.v1.ListDNSRecordsResponse
+	(*CreateDNSRecordRequest)(nil),     // 3: headscale.v1.CreateDNSRecordRequest
+	(*CreateDNSRecordResponse)(nil),    // 4: headscale.v1.CreateDNSRecordResponse
+	(*UpdateDNSRecordRequest)(nil),     // 5: headscale.v1.UpdateDNSRecordRequest
+	(*UpdateDNSRecordResponse)(nil),    // 6: headscale.v1.UpdateDNSRecordResponse
+	(*DeleteDNSRecordRequest)(nil),     // 7: headscale.v1.DeleteDNSRecordRequest
+	(*DeleteDNSRecordResponse)(nil),    // 8: headscale.v1.DeleteDNSRecordResponse
+	(*Nameserver)(nil),                 // 9: headscale.v1.Nameserver
+	(*ListNameserversRequest)(nil),     // 10: headscale.v1.ListNameserversRequest
+	(*ListNameserversResponse)(nil),    // 11: headscale.v1.ListNameserversResponse
+	(*CreateNameserverRequest)(nil),    // 12: headscale.v1.CreateNameserverRequest
+	(*CreateNameserverResponse)(nil),   // 13: headscale.v1.CreateNameserverResponse
+	(*UpdateNameserverRequest)(nil),    // 14: headscale.v1.UpdateNameserverRequest
+	(*UpdateNameserverResponse)(nil),   // 15: headscale.v1.UpdateNameserverResponse
+	(*DeleteNameserverRequest)(nil),    // 16: headscale.v1.DeleteNameserverRequest
+	(*DeleteNameserverResponse)(nil),   // 17: headscale.v1.DeleteNameserverResponse
+	(*SearchDomain)(nil),               // 18: headscale.v1.SearchDomain
+	(*ListSearchDomainsRequest)(nil),   // 19: headscale.v1.ListSearchDomainsRequest
+	(*ListSearchDomainsResponse)(nil),  // 20: headscale.v1.ListSearchDomainsResponse
+	(*CreateSearchDomainRequest)(nil),  // 21: headscale.v1.CreateSearchDomainRequest
+	(*CreateSearchDomainResponse)(nil), // 22: headscale.v1.CreateSearchDomainResponse
+	(*UpdateSearchDomainRequest)(nil),  // 23: headscale.v1.UpdateSearchDomainRequest
+	(*UpdateSearchDomainResponse)(nil), // 24: headscale.v1.UpdateSearchDomainResponse
+	(*DeleteSearchDomainRequest)(nil),  // 25: headscale.v1.DeleteSearchDomainRequest
+	(*DeleteSearchDomainResponse)(nil), // 26: headscale.v1.DeleteSearchDomainResponse
+	(*timestamppb.Timestamp)(nil),      // 27: google.protobuf.Timestamp
+}
+var file_headscale_v1_dns_proto_depIdxs = []int32{
+	27, // 0: headscale.v1.DNSRecord.created_at:type_name -> google.protobuf.Timestamp
+	0,  // 1: headscale.v1.ListDNSRecordsResponse.records:type_name -> headscale.v1.DNSRecord
+	0,  // 2: headscale.v1.CreateDNSRecordResponse.record:type_name -> headscale.v1.DNSRecord
+	0,  // 3: headscale.v1.UpdateDNSRecordResponse.record:type_name -> headscale.v1.DNSRecord
+	27, // 4: headscale.v1.Nameserver.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 5: headscale.v1.ListNameserversResponse.nameservers:type_name -> headscale.v1.Nameserver
+	9,  // 6: headscale.v1.CreateNameserverResponse.nameserver:type_name -> headscale.v1.Nameserver
+	9,  // 7: headscale.v1.UpdateNameserverResponse.nameserver:type_name -> headscale.v1.Nameserver
+	27, // 8: headscale.v1.SearchDomain.created_at:type_name -> google.protobuf.Timestamp
+	18, // 9: headscale.v1.ListSearchDomainsResponse.search_domains:type_name -> headscale.v1.SearchDomain
+	18, // 10: headscale.v1.CreateSearchDomainResponse.search_domain:type_name -> headscale.v1.SearchDomain
+	18, // 11: headscale.v1.UpdateSearchDomainResponse.search_domain:type_name -> headscale.v1.SearchDomain
+	12, // [12:12] is the sub-list for method output_type
+	12, // [12:12] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_headscale_v1_dns_proto_init() }
+func file_headscale_v1_dns_proto_init() {
+	if File_headscale_v1_dns_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_headscale_v1_dns_proto_rawDesc), len(file_headscale_v1_dns_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   27,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_headscale_v1_dns_proto_goTypes,
+		DependencyIndexes: file_headscale_v1_dns_proto_depIdxs,
+		MessageInfos:      file_headscale_v1_dns_proto_msgTypes,
+	}.Build()
+	File_headscale_v1_dns_proto = out.File
+	file_headscale_v1_dns_proto_goTypes = nil
+	file_headscale_v1_dns_proto_depIdxs = nil
+}
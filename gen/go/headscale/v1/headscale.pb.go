@@ -25,7 +25,7 @@ var File_headscale_v1_headscale_proto protoreflect.FileDescriptor
 
 const file_headscale_v1_headscale_proto_rawDesc = "" +
 	"\n" +
-	"\x1cheadscale/v1/headscale.proto\x12\fheadscale.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x17headscale/v1/user.proto\x1a\x1dheadscale/v1/preauthkey.proto\x1a\x17headscale/v1/node.proto\x1a\x19headscale/v1/apikey.proto\x1a\x19headscale/v1/policy.proto2\xa3\x16\n" +
+	"\x1cheadscale/v1/headscale.proto\x12\fheadscale.v1\x1a\x1cgoogle/api/annotations.proto\x1a\x17headscale/v1/user.proto\x1a\x1dheadscale/v1/preauthkey.proto\x1a\x17headscale/v1/node.proto\x1a\x19headscale/v1/apikey.proto\x1a\x19headscale/v1/policy.proto\x1a\x18headscale/v1/audit.proto\x1a\x16headscale/v1/dns.proto\x1a\x17headscale/v1/derp.proto2\x835\n" +
 	"\x10HeadscaleService\x12h\n" +
 	"\n" +
 	"CreateUser\x12\x1f.headscale.v1.CreateUserRequest\x1a .headscale.v1.CreateUserResponse\"\x17\x82\xd3\xe4\x93\x02\x11:\x01*\"\f/api/v1/user\x12\x80\x01\n" +
@@ -33,14 +33,16 @@ const file_headscale_v1_headscale_proto_rawDesc = "" +
 	"RenameUser\x12\x1f.headscale.v1.RenameUserRequest\x1a .headscale.v1.RenameUserResponse\"/\x82\xd3\xe4\x93\x02)\"'/api/v1/user/{old_id}/rename/{new_name}\x12j\n" +
 	"\n" +
 	"DeleteUser\x12\x1f.headscale.v1.DeleteUserRequest\x1a .headscale.v1.DeleteUserResponse\"\x19\x82\xd3\xe4\x93\x02\x13*\x11/api/v1/user/{id}\x12b\n" +
-	"\tListUsers\x12\x1e.headscale.v1.ListUsersRequest\x1a\x1f.headscale.v1.ListUsersResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/api/v1/user\x12\x80\x01\n" +
+	"\tListUsers\x12\x1e.headscale.v1.ListUsersRequest\x1a\x1f.headscale.v1.ListUsersResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/api/v1/user\x12\x85\x01\n" +
+	"\x0fSetUserDisabled\x12$.headscale.v1.SetUserDisabledRequest\x1a%.headscale.v1.SetUserDisabledResponse\"%\x82\xd3\xe4\x93\x02\x1f:\x01*\"\x1a/api/v1/user/{id}/disabled\x12\x80\x01\n" +
 	"\x10CreatePreAuthKey\x12%.headscale.v1.CreatePreAuthKeyRequest\x1a&.headscale.v1.CreatePreAuthKeyResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v1/preauthkey\x12\x87\x01\n" +
 	"\x10ExpirePreAuthKey\x12%.headscale.v1.ExpirePreAuthKeyRequest\x1a&.headscale.v1.ExpirePreAuthKeyResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/preauthkey/expire\x12z\n" +
 	"\x0fListPreAuthKeys\x12$.headscale.v1.ListPreAuthKeysRequest\x1a%.headscale.v1.ListPreAuthKeysResponse\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/preauthkey\x12}\n" +
 	"\x0fDebugCreateNode\x12$.headscale.v1.DebugCreateNodeRequest\x1a%.headscale.v1.DebugCreateNodeResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v1/debug/node\x12f\n" +
 	"\aGetNode\x12\x1c.headscale.v1.GetNodeRequest\x1a\x1d.headscale.v1.GetNodeResponse\"\x1e\x82\xd3\xe4\x93\x02\x18\x12\x16/api/v1/node/{node_id}\x12n\n" +
 	"\aSetTags\x12\x1c.headscale.v1.SetTagsRequest\x1a\x1d.headscale.v1.SetTagsResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/api/v1/node/{node_id}/tags\x12\x96\x01\n" +
-	"\x11SetApprovedRoutes\x12&.headscale.v1.SetApprovedRoutesRequest\x1a'.headscale.v1.SetApprovedRoutesResponse\"0\x82\xd3\xe4\x93\x02*:\x01*\"%/api/v1/node/{node_id}/approve_routes\x12t\n" +
+	"\x11SetApprovedRoutes\x12&.headscale.v1.SetApprovedRoutesRequest\x1a'.headscale.v1.SetApprovedRoutesResponse\"0\x82\xd3\xe4\x93\x02*:\x01*\"%/api/v1/node/{node_id}/approve_routes\x12\x93\x01\n" +
+	"\x10SetRoutePriority\x12%.headscale.v1.SetRoutePriorityRequest\x1a&.headscale.v1.SetRoutePriorityResponse\"0\x82\xd3\xe4\x93\x02*:\x01*\"%/api/v1/node/{node_id}/route_priority\x12t\n" +
 	"\fRegisterNode\x12!.headscale.v1.RegisterNodeRequest\x1a\".headscale.v1.RegisterNodeResponse\"\x1d\x82\xd3\xe4\x93\x02\x17\"\x15/api/v1/node/register\x12o\n" +
 	"\n" +
 	"DeleteNode\x12\x1f.headscale.v1.DeleteNodeRequest\x1a .headscale.v1.DeleteNodeResponse\"\x1e\x82\xd3\xe4\x93\x02\x18*\x16/api/v1/node/{node_id}\x12v\n" +
@@ -48,120 +50,265 @@ const file_headscale_v1_headscale_proto_rawDesc = "" +
 	"ExpireNode\x12\x1f.headscale.v1.ExpireNodeRequest\x1a .headscale.v1.ExpireNodeResponse\"%\x82\xd3\xe4\x93\x02\x1f\"\x1d/api/v1/node/{node_id}/expire\x12\x81\x01\n" +
 	"\n" +
 	"RenameNode\x12\x1f.headscale.v1.RenameNodeRequest\x1a .headscale.v1.RenameNodeResponse\"0\x82\xd3\xe4\x93\x02*\"(/api/v1/node/{node_id}/rename/{new_name}\x12b\n" +
-	"\tListNodes\x12\x1e.headscale.v1.ListNodesRequest\x1a\x1f.headscale.v1.ListNodesResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/api/v1/node\x12q\n" +
-	"\bMoveNode\x12\x1d.headscale.v1.MoveNodeRequest\x1a\x1e.headscale.v1.MoveNodeResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/api/v1/node/{node_id}/user\x12\x80\x01\n" +
+	"\tListNodes\x12\x1e.headscale.v1.ListNodesRequest\x1a\x1f.headscale.v1.ListNodesResponse\"\x14\x82\xd3\xe4\x93\x02\x0e\x12\f/api/v1/node\x12d\n" +
+	"\n" +
+	"WatchNodes\x12\x1f.headscale.v1.WatchNodesRequest\x1a\x17.headscale.v1.NodeEvent\"\x1a\x82\xd3\xe4\x93\x02\x14\x12\x12/api/v1/node/watch0\x01\x12q\n" +
+	"\bMoveNode\x12\x1d.headscale.v1.MoveNodeRequest\x1a\x1e.headscale.v1.MoveNodeResponse\"&\x82\xd3\xe4\x93\x02 :\x01*\"\x1b/api/v1/node/{node_id}/user\x12\x7f\n" +
+	"\x10ListPendingNodes\x12%.headscale.v1.ListPendingNodesRequest\x1a&.headscale.v1.ListPendingNodesResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/node/pending\x12z\n" +
+	"\vApproveNode\x12 .headscale.v1.ApproveNodeRequest\x1a!.headscale.v1.ApproveNodeResponse\"&\x82\xd3\xe4\x93\x02 \"\x1e/api/v1/node/{node_id}/approve\x12v\n" +
+	"\n" +
+	"RejectNode\x12\x1f.headscale.v1.RejectNodeRequest\x1a .headscale.v1.RejectNodeResponse\"%\x82\xd3\xe4\x93\x02\x1f\"\x1d/api/v1/node/{node_id}/reject\x12\x80\x01\n" +
 	"\x0fBackfillNodeIPs\x12$.headscale.v1.BackfillNodeIPsRequest\x1a%.headscale.v1.BackfillNodeIPsResponse\" \x82\xd3\xe4\x93\x02\x1a\"\x18/api/v1/node/backfillips\x12p\n" +
 	"\fCreateApiKey\x12!.headscale.v1.CreateApiKeyRequest\x1a\".headscale.v1.CreateApiKeyResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\"\x0e/api/v1/apikey\x12w\n" +
 	"\fExpireApiKey\x12!.headscale.v1.ExpireApiKeyRequest\x1a\".headscale.v1.ExpireApiKeyResponse\" \x82\xd3\xe4\x93\x02\x1a:\x01*\"\x15/api/v1/apikey/expire\x12j\n" +
 	"\vListApiKeys\x12 .headscale.v1.ListApiKeysRequest\x1a!.headscale.v1.ListApiKeysResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/v1/apikey\x12v\n" +
 	"\fDeleteApiKey\x12!.headscale.v1.DeleteApiKeyRequest\x1a\".headscale.v1.DeleteApiKeyResponse\"\x1f\x82\xd3\xe4\x93\x02\x19*\x17/api/v1/apikey/{prefix}\x12d\n" +
 	"\tGetPolicy\x12\x1e.headscale.v1.GetPolicyRequest\x1a\x1f.headscale.v1.GetPolicyResponse\"\x16\x82\xd3\xe4\x93\x02\x10\x12\x0e/api/v1/policy\x12g\n" +
-	"\tSetPolicy\x12\x1e.headscale.v1.SetPolicyRequest\x1a\x1f.headscale.v1.SetPolicyResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\x1a\x0e/api/v1/policyB)Z'github.com/juanfont/headscale/gen/go/v1b\x06proto3"
+	"\tSetPolicy\x12\x1e.headscale.v1.SetPolicyRequest\x1a\x1f.headscale.v1.SetPolicyResponse\"\x19\x82\xd3\xe4\x93\x02\x13:\x01*\x1a\x0e/api/v1/policy\x12\x8c\x01\n" +
+	"\x13ListPolicyRevisions\x12(.headscale.v1.ListPolicyRevisionsRequest\x1a).headscale.v1.ListPolicyRevisionsResponse\" \x82\xd3\xe4\x93\x02\x1a\x12\x18/api/v1/policy/revisions\x12\x91\x01\n" +
+	"\x13DiffPolicyRevisions\x12(.headscale.v1.DiffPolicyRevisionsRequest\x1a).headscale.v1.DiffPolicyRevisionsResponse\"%\x82\xd3\xe4\x93\x02\x1f\x12\x1d/api/v1/policy/revisions/diff\x12\x94\x01\n" +
+	"\x0eRollbackPolicy\x12#.headscale.v1.RollbackPolicyRequest\x1a$.headscale.v1.RollbackPolicyResponse\"7\x82\xd3\xe4\x93\x021\"//api/v1/policy/revisions/{revision_id}/rollback\x12u\n" +
+	"\x0fListAuditEvents\x12$.headscale.v1.ListAuditEventsRequest\x1a%.headscale.v1.ListAuditEventsResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v1/audit\x12x\n" +
+	"\x0eListDNSRecords\x12#.headscale.v1.ListDNSRecordsRequest\x1a$.headscale.v1.ListDNSRecordsResponse\"\x1b\x82\xd3\xe4\x93\x02\x15\x12\x13/api/v1/dns/records\x12~\n" +
+	"\x0fCreateDNSRecord\x12$.headscale.v1.CreateDNSRecordRequest\x1a%.headscale.v1.CreateDNSRecordResponse\"\x1e\x82\xd3\xe4\x93\x02\x18:\x01*\"\x13/api/v1/dns/records\x12\x83\x01\n" +
+	"\x0fUpdateDNSRecord\x12$.headscale.v1.UpdateDNSRecordRequest\x1a%.headscale.v1.UpdateDNSRecordResponse\"#\x82\xd3\xe4\x93\x02\x1d:\x01*\x1a\x18/api/v1/dns/records/{id}\x12\x80\x01\n" +
+	"\x0fDeleteDNSRecord\x12$.headscale.v1.DeleteDNSRecordRequest\x1a%.headscale.v1.DeleteDNSRecordResponse\" \x82\xd3\xe4\x93\x02\x1a*\x18/api/v1/dns/records/{id}\x12\x7f\n" +
+	"\x0fListNameservers\x12$.headscale.v1.ListNameserversRequest\x1a%.headscale.v1.ListNameserversResponse\"\x1f\x82\xd3\xe4\x93\x02\x19\x12\x17/api/v1/dns/nameservers\x12\x85\x01\n" +
+	"\x10CreateNameserver\x12%.headscale.v1.CreateNameserverRequest\x1a&.headscale.v1.CreateNameserverResponse\"\"\x82\xd3\xe4\x93\x02\x1c:\x01*\"\x17/api/v1/dns/nameservers\x12\x8a\x01\n" +
+	"\x10UpdateNameserver\x12%.headscale.v1.UpdateNameserverRequest\x1a&.headscale.v1.UpdateNameserverResponse\"'\x82\xd3\xe4\x93\x02!:\x01*\x1a\x1c/api/v1/dns/nameservers/{id}\x12\x87\x01\n" +
+	"\x10DeleteNameserver\x12%.headscale.v1.DeleteNameserverRequest\x1a&.headscale.v1.DeleteNameserverResponse\"$\x82\xd3\xe4\x93\x02\x1e*\x1c/api/v1/dns/nameservers/{id}\x12\x87\x01\n" +
+	"\x11ListSearchDomains\x12&.headscale.v1.ListSearchDomainsRequest\x1a'.headscale.v1.ListSearchDomainsResponse\"!\x82\xd3\xe4\x93\x02\x1b\x12\x19/api/v1/dns/searchdomains\x12\x8d\x01\n" +
+	"\x12CreateSearchDomain\x12'.headscale.v1.CreateSearchDomainRequest\x1a(.headscale.v1.CreateSearchDomainResponse\"$\x82\xd3\xe4\x93\x02\x1e:\x01*\"\x19/api/v1/dns/searchdomains\x12\x92\x01\n" +
+	"\x12UpdateSearchDomain\x12'.headscale.v1.UpdateSearchDomainRequest\x1a(.headscale.v1.UpdateSearchDomainResponse\")\x82\xd3\xe4\x93\x02#:\x01*\x1a\x1e/api/v1/dns/searchdomains/{id}\x12\x8f\x01\n" +
+	"\x12DeleteSearchDomain\x12'.headscale.v1.DeleteSearchDomainRequest\x1a(.headscale.v1.DeleteSearchDomainResponse\"&\x82\xd3\xe4\x93\x02 *\x1e/api/v1/dns/searchdomains/{id}\x12|\n" +
+	"\x0fListDERPRegions\x12$.headscale.v1.ListDERPRegionsRequest\x1a%.headscale.v1.ListDERPRegionsResponse\"\x1c\x82\xd3\xe4\x93\x02\x16\x12\x14/api/v1/derp/regions\x12\x82\x01\n" +
+	"\x10CreateDERPRegion\x12%.headscale.v1.CreateDERPRegionRequest\x1a&.headscale.v1.CreateDERPRegionResponse\"\x1f\x82\xd3\xe4\x93\x02\x19:\x01*\"\x14/api/v1/derp/regions\x12\x8b\x01\n" +
+	"\x10DeleteDERPRegion\x12%.headscale.v1.DeleteDERPRegionRequest\x1a&.headscale.v1.DeleteDERPRegionResponse\"(\x82\xd3\xe4\x93\x02\"* /api/v1/derp/regions/{region_id}\x12\xa6\x01\n" +
+	"\x15SetDERPRegionDisabled\x12*.headscale.v1.SetDERPRegionDisabledRequest\x1a+.headscale.v1.SetDERPRegionDisabledResponse\"4\x82\xd3\xe4\x93\x02.:\x01*\")/api/v1/derp/regions/{region_id}/disabled\x12z\n" +
+	"\x0eCreateDERPNode\x12#.headscale.v1.CreateDERPNodeRequest\x1a$.headscale.v1.CreateDERPNodeResponse\"\x1d\x82\xd3\xe4\x93\x02\x17:\x01*\"\x12/api/v1/derp/nodes\x12~\n" +
+	"\x0eDeleteDERPNode\x12#.headscale.v1.DeleteDERPNodeRequest\x1a$.headscale.v1.DeleteDERPNodeResponse\"!\x82\xd3\xe4\x93\x02\x1b*\x19/api/v1/derp/nodes/{name}\x12\x99\x01\n" +
+	"\x13SetDERPNodeDisabled\x12(.headscale.v1.SetDERPNodeDisabledRequest\x1a).headscale.v1.SetDERPNodeDisabledResponse\"-\x82\xd3\xe4\x93\x02':\x01*\"\"/api/v1/derp/nodes/{name}/disabledB)Z'github.com/juanfont/headscale/gen/go/v1b\x06proto3"
 
 var file_headscale_v1_headscale_proto_goTypes = []any{
-	(*CreateUserRequest)(nil),         // 0: headscale.v1.CreateUserRequest
-	(*RenameUserRequest)(nil),         // 1: headscale.v1.RenameUserRequest
-	(*DeleteUserRequest)(nil),         // 2: headscale.v1.DeleteUserRequest
-	(*ListUsersRequest)(nil),          // 3: headscale.v1.ListUsersRequest
-	(*CreatePreAuthKeyRequest)(nil),   // 4: headscale.v1.CreatePreAuthKeyRequest
-	(*ExpirePreAuthKeyRequest)(nil),   // 5: headscale.v1.ExpirePreAuthKeyRequest
-	(*ListPreAuthKeysRequest)(nil),    // 6: headscale.v1.ListPreAuthKeysRequest
-	(*DebugCreateNodeRequest)(nil),    // 7: headscale.v1.DebugCreateNodeRequest
-	(*GetNodeRequest)(nil),            // 8: headscale.v1.GetNodeRequest
-	(*SetTagsRequest)(nil),            // 9: headscale.v1.SetTagsRequest
-	(*SetApprovedRoutesRequest)(nil),  // 10: headscale.v1.SetApprovedRoutesRequest
-	(*RegisterNodeRequest)(nil),       // 11: headscale.v1.RegisterNodeRequest
-	(*DeleteNodeRequest)(nil),         // 12: headscale.v1.DeleteNodeRequest
-	(*ExpireNodeRequest)(nil),         // 13: headscale.v1.ExpireNodeRequest
-	(*RenameNodeRequest)(nil),         // 14: headscale.v1.RenameNodeRequest
-	(*ListNodesRequest)(nil),          // 15: headscale.v1.ListNodesRequest
-	(*MoveNodeRequest)(nil),           // 16: headscale.v1.MoveNodeRequest
-	(*BackfillNodeIPsRequest)(nil),    // 17: headscale.v1.BackfillNodeIPsRequest
-	(*CreateApiKeyRequest)(nil),       // 18: headscale.v1.CreateApiKeyRequest
-	(*ExpireApiKeyRequest)(nil),       // 19: headscale.v1.ExpireApiKeyRequest
-	(*ListApiKeysRequest)(nil),        // 20: headscale.v1.ListApiKeysRequest
-	(*DeleteApiKeyRequest)(nil),       // 21: headscale.v1.DeleteApiKeyRequest
-	(*GetPolicyRequest)(nil),          // 22: headscale.v1.GetPolicyRequest
-	(*SetPolicyRequest)(nil),          // 23: headscale.v1.SetPolicyRequest
-	(*CreateUserResponse)(nil),        // 24: headscale.v1.CreateUserResponse
-	(*RenameUserResponse)(nil),        // 25: headscale.v1.RenameUserResponse
-	(*DeleteUserResponse)(nil),        // 26: headscale.v1.DeleteUserResponse
-	(*ListUsersResponse)(nil),         // 27: headscale.v1.ListUsersResponse
-	(*CreatePreAuthKeyResponse)(nil),  // 28: headscale.v1.CreatePreAuthKeyResponse
-	(*ExpirePreAuthKeyResponse)(nil),  // 29: headscale.v1.ExpirePreAuthKeyResponse
-	(*ListPreAuthKeysResponse)(nil),   // 30: headscale.v1.ListPreAuthKeysResponse
-	(*DebugCreateNodeResponse)(nil),   // 31: headscale.v1.DebugCreateNodeResponse
-	(*GetNodeResponse)(nil),           // 32: headscale.v1.GetNodeResponse
-	(*SetTagsResponse)(nil),           // 33: headscale.v1.SetTagsResponse
-	(*SetApprovedRoutesResponse)(nil), // 34: headscale.v1.SetApprovedRoutesResponse
-	(*RegisterNodeResponse)(nil),      // 35: headscale.v1.RegisterNodeResponse
-	(*DeleteNodeResponse)(nil),        // 36: headscale.v1.DeleteNodeResponse
-	(*ExpireNodeResponse)(nil),        // 37: headscale.v1.ExpireNodeResponse
-	(*RenameNodeResponse)(nil),        // 38: headscale.v1.RenameNodeResponse
-	(*ListNodesResponse)(nil),         // 39: headscale.v1.ListNodesResponse
-	(*MoveNodeResponse)(nil),          // 40: headscale.v1.MoveNodeResponse
-	(*BackfillNodeIPsResponse)(nil),   // 41: headscale.v1.BackfillNodeIPsResponse
-	(*CreateApiKeyResponse)(nil),      // 42: headscale.v1.CreateApiKeyResponse
-	(*ExpireApiKeyResponse)(nil),      // 43: headscale.v1.ExpireApiKeyResponse
-	(*ListApiKeysResponse)(nil),       // 44: headscale.v1.ListApiKeysResponse
-	(*DeleteApiKeyResponse)(nil),      // 45: headscale.v1.DeleteApiKeyResponse
-	(*GetPolicyResponse)(nil),         // 46: headscale.v1.GetPolicyResponse
-	(*SetPolicyResponse)(nil),         // 47: headscale.v1.SetPolicyResponse
+	(*CreateUserRequest)(nil),             // 0: headscale.v1.CreateUserRequest
+	(*RenameUserRequest)(nil),             // 1: headscale.v1.RenameUserRequest
+	(*DeleteUserRequest)(nil),             // 2: headscale.v1.DeleteUserRequest
+	(*ListUsersRequest)(nil),              // 3: headscale.v1.ListUsersRequest
+	(*SetUserDisabledRequest)(nil),        // 4: headscale.v1.SetUserDisabledRequest
+	(*CreatePreAuthKeyRequest)(nil),       // 5: headscale.v1.CreatePreAuthKeyRequest
+	(*ExpirePreAuthKeyRequest)(nil),       // 6: headscale.v1.ExpirePreAuthKeyRequest
+	(*ListPreAuthKeysRequest)(nil),        // 7: headscale.v1.ListPreAuthKeysRequest
+	(*DebugCreateNodeRequest)(nil),        // 8: headscale.v1.DebugCreateNodeRequest
+	(*GetNodeRequest)(nil),                // 9: headscale.v1.GetNodeRequest
+	(*SetTagsRequest)(nil),                // 10: headscale.v1.SetTagsRequest
+	(*SetApprovedRoutesRequest)(nil),      // 11: headscale.v1.SetApprovedRoutesRequest
+	(*SetRoutePriorityRequest)(nil),       // 12: headscale.v1.SetRoutePriorityRequest
+	(*RegisterNodeRequest)(nil),           // 13: headscale.v1.RegisterNodeRequest
+	(*DeleteNodeRequest)(nil),             // 14: headscale.v1.DeleteNodeRequest
+	(*ExpireNodeRequest)(nil),             // 15: headscale.v1.ExpireNodeRequest
+	(*RenameNodeRequest)(nil),             // 16: headscale.v1.RenameNodeRequest
+	(*ListNodesRequest)(nil),              // 17: headscale.v1.ListNodesRequest
+	(*WatchNodesRequest)(nil),             // 18: headscale.v1.WatchNodesRequest
+	(*MoveNodeRequest)(nil),               // 19: headscale.v1.MoveNodeRequest
+	(*ListPendingNodesRequest)(nil),       // 20: headscale.v1.ListPendingNodesRequest
+	(*ApproveNodeRequest)(nil),            // 21: headscale.v1.ApproveNodeRequest
+	(*RejectNodeRequest)(nil),             // 22: headscale.v1.RejectNodeRequest
+	(*BackfillNodeIPsRequest)(nil),        // 23: headscale.v1.BackfillNodeIPsRequest
+	(*CreateApiKeyRequest)(nil),           // 24: headscale.v1.CreateApiKeyRequest
+	(*ExpireApiKeyRequest)(nil),           // 25: headscale.v1.ExpireApiKeyRequest
+	(*ListApiKeysRequest)(nil),            // 26: headscale.v1.ListApiKeysRequest
+	(*DeleteApiKeyRequest)(nil),           // 27: headscale.v1.DeleteApiKeyRequest
+	(*GetPolicyRequest)(nil),              // 28: headscale.v1.GetPolicyRequest
+	(*SetPolicyRequest)(nil),              // 29: headscale.v1.SetPolicyRequest
+	(*ListPolicyRevisionsRequest)(nil),    // 30: headscale.v1.ListPolicyRevisionsRequest
+	(*DiffPolicyRevisionsRequest)(nil),    // 31: headscale.v1.DiffPolicyRevisionsRequest
+	(*RollbackPolicyRequest)(nil),         // 32: headscale.v1.RollbackPolicyRequest
+	(*ListAuditEventsRequest)(nil),        // 33: headscale.v1.ListAuditEventsRequest
+	(*ListDNSRecordsRequest)(nil),         // 34: headscale.v1.ListDNSRecordsRequest
+	(*CreateDNSRecordRequest)(nil),        // 35: headscale.v1.CreateDNSRecordRequest
+	(*UpdateDNSRecordRequest)(nil),        // 36: headscale.v1.UpdateDNSRecordRequest
+	(*DeleteDNSRecordRequest)(nil),        // 37: headscale.v1.DeleteDNSRecordRequest
+	(*ListNameserversRequest)(nil),        // 38: headscale.v1.ListNameserversRequest
+	(*CreateNameserverRequest)(nil),       // 39: headscale.v1.CreateNameserverRequest
+	(*UpdateNameserverRequest)(nil),       // 40: headscale.v1.UpdateNameserverRequest
+	(*DeleteNameserverRequest)(nil),       // 41: headscale.v1.DeleteNameserverRequest
+	(*ListSearchDomainsRequest)(nil),      // 42: headscale.v1.ListSearchDomainsRequest
+	(*CreateSearchDomainRequest)(nil),     // 43: headscale.v1.CreateSearchDomainRequest
+	(*UpdateSearchDomainRequest)(nil),     // 44: headscale.v1.UpdateSearchDomainRequest
+	(*DeleteSearchDomainRequest)(nil),     // 45: headscale.v1.DeleteSearchDomainRequest
+	(*ListDERPRegionsRequest)(nil),        // 46: headscale.v1.ListDERPRegionsRequest
+	(*CreateDERPRegionRequest)(nil),       // 47: headscale.v1.CreateDERPRegionRequest
+	(*DeleteDERPRegionRequest)(nil),       // 48: headscale.v1.DeleteDERPRegionRequest
+	(*SetDERPRegionDisabledRequest)(nil),  // 49: headscale.v1.SetDERPRegionDisabledRequest
+	(*CreateDERPNodeRequest)(nil),         // 50: headscale.v1.CreateDERPNodeRequest
+	(*DeleteDERPNodeRequest)(nil),         // 51: headscale.v1.DeleteDERPNodeRequest
+	(*SetDERPNodeDisabledRequest)(nil),    // 52: headscale.v1.SetDERPNodeDisabledRequest
+	(*CreateUserResponse)(nil),            // 53: headscale.v1.CreateUserResponse
+	(*RenameUserResponse)(nil),            // 54: headscale.v1.RenameUserResponse
+	(*DeleteUserResponse)(nil),            // 55: headscale.v1.DeleteUserResponse
+	(*ListUsersResponse)(nil),             // 56: headscale.v1.ListUsersResponse
+	(*SetUserDisabledResponse)(nil),       // 57: headscale.v1.SetUserDisabledResponse
+	(*CreatePreAuthKeyResponse)(nil),      // 58: headscale.v1.CreatePreAuthKeyResponse
+	(*ExpirePreAuthKeyResponse)(nil),      // 59: headscale.v1.ExpirePreAuthKeyResponse
+	(*ListPreAuthKeysResponse)(nil),       // 60: headscale.v1.ListPreAuthKeysResponse
+	(*DebugCreateNodeResponse)(nil),       // 61: headscale.v1.DebugCreateNodeResponse
+	(*GetNodeResponse)(nil),               // 62: headscale.v1.GetNodeResponse
+	(*SetTagsResponse)(nil),               // 63: headscale.v1.SetTagsResponse
+	(*SetApprovedRoutesResponse)(nil),     // 64: headscale.v1.SetApprovedRoutesResponse
+	(*SetRoutePriorityResponse)(nil),      // 65: headscale.v1.SetRoutePriorityResponse
+	(*RegisterNodeResponse)(nil),          // 66: headscale.v1.RegisterNodeResponse
+	(*DeleteNodeResponse)(nil),            // 67: headscale.v1.DeleteNodeResponse
+	(*ExpireNodeResponse)(nil),            // 68: headscale.v1.ExpireNodeResponse
+	(*RenameNodeResponse)(nil),            // 69: headscale.v1.RenameNodeResponse
+	(*ListNodesResponse)(nil),             // 70: headscale.v1.ListNodesResponse
+	(*NodeEvent)(nil),                     // 71: headscale.v1.NodeEvent
+	(*MoveNodeResponse)(nil),              // 72: headscale.v1.MoveNodeResponse
+	(*ListPendingNodesResponse)(nil),      // 73: headscale.v1.ListPendingNodesResponse
+	(*ApproveNodeResponse)(nil),           // 74: headscale.v1.ApproveNodeResponse
+	(*RejectNodeResponse)(nil),            // 75: headscale.v1.RejectNodeResponse
+	(*BackfillNodeIPsResponse)(nil),       // 76: headscale.v1.BackfillNodeIPsResponse
+	(*CreateApiKeyResponse)(nil),          // 77: headscale.v1.CreateApiKeyResponse
+	(*ExpireApiKeyResponse)(nil),          // 78: headscale.v1.ExpireApiKeyResponse
+	(*ListApiKeysResponse)(nil),           // 79: headscale.v1.ListApiKeysResponse
+	(*DeleteApiKeyResponse)(nil),          // 80: headscale.v1.DeleteApiKeyResponse
+	(*GetPolicyResponse)(nil),             // 81: headscale.v1.GetPolicyResponse
+	(*SetPolicyResponse)(nil),             // 82: headscale.v1.SetPolicyResponse
+	(*ListPolicyRevisionsResponse)(nil),   // 83: headscale.v1.ListPolicyRevisionsResponse
+	(*DiffPolicyRevisionsResponse)(nil),   // 84: headscale.v1.DiffPolicyRevisionsResponse
+	(*RollbackPolicyResponse)(nil),        // 85: headscale.v1.RollbackPolicyResponse
+	(*ListAuditEventsResponse)(nil),       // 86: headscale.v1.ListAuditEventsResponse
+	(*ListDNSRecordsResponse)(nil),        // 87: headscale.v1.ListDNSRecordsResponse
+	(*CreateDNSRecordResponse)(nil),       // 88: headscale.v1.CreateDNSRecordResponse
+	(*UpdateDNSRecordResponse)(nil),       // 89: headscale.v1.UpdateDNSRecordResponse
+	(*DeleteDNSRecordResponse)(nil),       // 90: headscale.v1.DeleteDNSRecordResponse
+	(*ListNameserversResponse)(nil),       // 91: headscale.v1.ListNameserversResponse
+	(*CreateNameserverResponse)(nil),      // 92: headscale.v1.CreateNameserverResponse
+	(*UpdateNameserverResponse)(nil),      // 93: headscale.v1.UpdateNameserverResponse
+	(*DeleteNameserverResponse)(nil),      // 94: headscale.v1.DeleteNameserverResponse
+	(*ListSearchDomainsResponse)(nil),     // 95: headscale.v1.ListSearchDomainsResponse
+	(*CreateSearchDomainResponse)(nil),    // 96: headscale.v1.CreateSearchDomainResponse
+	(*UpdateSearchDomainResponse)(nil),    // 97: headscale.v1.UpdateSearchDomainResponse
+	(*DeleteSearchDomainResponse)(nil),    // 98: headscale.v1.DeleteSearchDomainResponse
+	(*ListDERPRegionsResponse)(nil),       // 99: headscale.v1.ListDERPRegionsResponse
+	(*CreateDERPRegionResponse)(nil),      // 100: headscale.v1.CreateDERPRegionResponse
+	(*DeleteDERPRegionResponse)(nil),      // 101: headscale.v1.DeleteDERPRegionResponse
+	(*SetDERPRegionDisabledResponse)(nil), // 102: headscale.v1.SetDERPRegionDisabledResponse
+	(*CreateDERPNodeResponse)(nil),        // 103: headscale.v1.CreateDERPNodeResponse
+	(*DeleteDERPNodeResponse)(nil),        // 104: headscale.v1.DeleteDERPNodeResponse
+	(*SetDERPNodeDisabledResponse)(nil),   // 105: headscale.v1.SetDERPNodeDisabledResponse
 }
 var file_headscale_v1_headscale_proto_depIdxs = []int32{
-	0,  // 0: headscale.v1.HeadscaleService.CreateUser:input_type -> headscale.v1.CreateUserRequest
-	1,  // 1: headscale.v1.HeadscaleService.RenameUser:input_type -> headscale.v1.RenameUserRequest
-	2,  // 2: headscale.v1.HeadscaleService.DeleteUser:input_type -> headscale.v1.DeleteUserRequest
-	3,  // 3: headscale.v1.HeadscaleService.ListUsers:input_type -> headscale.v1.ListUsersRequest
-	4,  // 4: headscale.v1.HeadscaleService.CreatePreAuthKey:input_type -> headscale.v1.CreatePreAuthKeyRequest
-	5,  // 5: headscale.v1.HeadscaleService.ExpirePreAuthKey:input_type -> headscale.v1.ExpirePreAuthKeyRequest
-	6,  // 6: headscale.v1.HeadscaleService.ListPreAuthKeys:input_type -> headscale.v1.ListPreAuthKeysRequest
-	7,  // 7: headscale.v1.HeadscaleService.DebugCreateNode:input_type -> headscale.v1.DebugCreateNodeRequest
-	8,  // 8: headscale.v1.HeadscaleService.GetNode:input_type -> headscale.v1.GetNodeRequest
-	9,  // 9: headscale.v1.HeadscaleService.SetTags:input_type -> headscale.v1.SetTagsRequest
-	10, // 10: headscale.v1.HeadscaleService.SetApprovedRoutes:input_type -> headscale.v1.SetApprovedRoutesRequest
-	11, // 11: headscale.v1.HeadscaleService.RegisterNode:input_type -> headscale.v1.RegisterNodeRequest
-	12, // 12: headscale.v1.HeadscaleService.DeleteNode:input_type -> headscale.v1.DeleteNodeRequest
-	13, // 13: headscale.v1.HeadscaleService.ExpireNode:input_type -> headscale.v1.ExpireNodeRequest
-	14, // 14: headscale.v1.HeadscaleService.RenameNode:input_type -> headscale.v1.RenameNodeRequest
-	15, // 15: headscale.v1.HeadscaleService.ListNodes:input_type -> headscale.v1.ListNodesRequest
-	16, // 16: headscale.v1.HeadscaleService.MoveNode:input_type -> headscale.v1.MoveNodeRequest
-	17, // 17: headscale.v1.HeadscaleService.BackfillNodeIPs:input_type -> headscale.v1.BackfillNodeIPsRequest
-	18, // 18: headscale.v1.HeadscaleService.CreateApiKey:input_type -> headscale.v1.CreateApiKeyRequest
-	19, // 19: headscale.v1.HeadscaleService.ExpireApiKey:input_type -> headscale.v1.ExpireApiKeyRequest
-	20, // 20: headscale.v1.HeadscaleService.ListApiKeys:input_type -> headscale.v1.ListApiKeysRequest
-	21, // 21: headscale.v1.HeadscaleService.DeleteApiKey:input_type -> headscale.v1.DeleteApiKeyRequest
-	22, // 22: headscale.v1.HeadscaleService.GetPolicy:input_type -> headscale.v1.GetPolicyRequest
-	23, // 23: headscale.v1.HeadscaleService.SetPolicy:input_type -> headscale.v1.SetPolicyRequest
-	24, // 24: headscale.v1.HeadscaleService.CreateUser:output_type -> headscale.v1.CreateUserResponse
-	25, // 25: headscale.v1.HeadscaleService.RenameUser:output_type -> headscale.v1.RenameUserResponse
-	26, // 26: headscale.v1.HeadscaleService.DeleteUser:output_type -> headscale.v1.DeleteUserResponse
-	27, // 27: headscale.v1.HeadscaleService.ListUsers:output_type -> headscale.v1.ListUsersResponse
-	28, // 28: headscale.v1.HeadscaleService.CreatePreAuthKey:output_type -> headscale.v1.CreatePreAuthKeyResponse
-	29, // 29: headscale.v1.HeadscaleService.ExpirePreAuthKey:output_type -> headscale.v1.ExpirePreAuthKeyResponse
-	30, // 30: headscale.v1.HeadscaleService.ListPreAuthKeys:output_type -> headscale.v1.ListPreAuthKeysResponse
-	31, // 31: headscale.v1.HeadscaleService.DebugCreateNode:output_type -> headscale.v1.DebugCreateNodeResponse
-	32, // 32: headscale.v1.HeadscaleService.GetNode:output_type -> headscale.v1.GetNodeResponse
-	33, // 33: headscale.v1.HeadscaleService.SetTags:output_type -> headscale.v1.SetTagsResponse
-	34, // 34: headscale.v1.HeadscaleService.SetApprovedRoutes:output_type -> headscale.v1.SetApprovedRoutesResponse
-	35, // 35: headscale.v1.HeadscaleService.RegisterNode:output_type -> headscale.v1.RegisterNodeResponse
-	36, // 36: headscale.v1.HeadscaleService.DeleteNode:output_type -> headscale.v1.DeleteNodeResponse
-	37, // 37: headscale.v1.HeadscaleService.ExpireNode:output_type -> headscale.v1.ExpireNodeResponse
-	38, // 38: headscale.v1.HeadscaleService.RenameNode:output_type -> headscale.v1.RenameNodeResponse
-	39, // 39: headscale.v1.HeadscaleService.ListNodes:output_type -> headscale.v1.ListNodesResponse
-	40, // 40: headscale.v1.HeadscaleService.MoveNode:output_type -> headscale.v1.MoveNodeResponse
-	41, // 41: headscale.v1.HeadscaleService.BackfillNodeIPs:output_type -> headscale.v1.BackfillNodeIPsResponse
-	42, // 42: headscale.v1.HeadscaleService.CreateApiKey:output_type -> headscale.v1.CreateApiKeyResponse
-	43, // 43: headscale.v1.HeadscaleService.ExpireApiKey:output_type -> headscale.v1.ExpireApiKeyResponse
-	44, // 44: headscale.v1.HeadscaleService.ListApiKeys:output_type -> headscale.v1.ListApiKeysResponse
-	45, // 45: headscale.v1.HeadscaleService.DeleteApiKey:output_type -> headscale.v1.DeleteApiKeyResponse
-	46, // 46: headscale.v1.HeadscaleService.GetPolicy:output_type -> headscale.v1.GetPolicyResponse
-	47, // 47: headscale.v1.HeadscaleService.SetPolicy:output_type -> headscale.v1.SetPolicyResponse
-	24, // [24:48] is the sub-list for method output_type
-	0,  // [0:24] is the sub-list for method input_type
-	0,  // [0:0] is the sub-list for extension type_name
-	0,  // [0:0] is the sub-list for extension extendee
-	0,  // [0:0] is the sub-list for field type_name
+	0,   // 0: headscale.v1.HeadscaleService.CreateUser:input_type -> headscale.v1.CreateUserRequest
+	1,   // 1: headscale.v1.HeadscaleService.RenameUser:input_type -> headscale.v1.RenameUserRequest
+	2,   // 2: headscale.v1.HeadscaleService.DeleteUser:input_type -> headscale.v1.DeleteUserRequest
+	3,   // 3: headscale.v1.HeadscaleService.ListUsers:input_type -> headscale.v1.ListUsersRequest
+	4,   // 4: headscale.v1.HeadscaleService.SetUserDisabled:input_type -> headscale.v1.SetUserDisabledRequest
+	5,   // 5: headscale.v1.HeadscaleService.CreatePreAuthKey:input_type -> headscale.v1.CreatePreAuthKeyRequest
+	6,   // 6: headscale.v1.HeadscaleService.ExpirePreAuthKey:input_type -> headscale.v1.ExpirePreAuthKeyRequest
+	7,   // 7: headscale.v1.HeadscaleService.ListPreAuthKeys:input_type -> headscale.v1.ListPreAuthKeysRequest
+	8,   // 8: headscale.v1.HeadscaleService.DebugCreateNode:input_type -> headscale.v1.DebugCreateNodeRequest
+	9,   // 9: headscale.v1.HeadscaleService.GetNode:input_type -> headscale.v1.GetNodeRequest
+	10,  // 10: headscale.v1.HeadscaleService.SetTags:input_type -> headscale.v1.SetTagsRequest
+	11,  // 11: headscale.v1.HeadscaleService.SetApprovedRoutes:input_type -> headscale.v1.SetApprovedRoutesRequest
+	12,  // 12: headscale.v1.HeadscaleService.SetRoutePriority:input_type -> headscale.v1.SetRoutePriorityRequest
+	13,  // 13: headscale.v1.HeadscaleService.RegisterNode:input_type -> headscale.v1.RegisterNodeRequest
+	14,  // 14: headscale.v1.HeadscaleService.DeleteNode:input_type -> headscale.v1.DeleteNodeRequest
+	15,  // 15: headscale.v1.HeadscaleService.ExpireNode:input_type -> headscale.v1.ExpireNodeRequest
+	16,  // 16: headscale.v1.HeadscaleService.RenameNode:input_type -> headscale.v1.RenameNodeRequest
+	17,  // 17: headscale.v1.HeadscaleService.ListNodes:input_type -> headscale.v1.ListNodesRequest
+	18,  // 18: headscale.v1.HeadscaleService.WatchNodes:input_type -> headscale.v1.WatchNodesRequest
+	19,  // 19: headscale.v1.HeadscaleService.MoveNode:input_type -> headscale.v1.MoveNodeRequest
+	20,  // 20: headscale.v1.HeadscaleService.ListPendingNodes:input_type -> headscale.v1.ListPendingNodesRequest
+	21,  // 21: headscale.v1.HeadscaleService.ApproveNode:input_type -> headscale.v1.ApproveNodeRequest
+	22,  // 22: headscale.v1.HeadscaleService.RejectNode:input_type -> headscale.v1.RejectNodeRequest
+	23,  // 23: headscale.v1.HeadscaleService.BackfillNodeIPs:input_type -> headscale.v1.BackfillNodeIPsRequest
+	24,  // 24: headscale.v1.HeadscaleService.CreateApiKey:input_type -> headscale.v1.CreateApiKeyRequest
+	25,  // 25: headscale.v1.HeadscaleService.ExpireApiKey:input_type -> headscale.v1.ExpireApiKeyRequest
+	26,  // 26: headscale.v1.HeadscaleService.ListApiKeys:input_type -> headscale.v1.ListApiKeysRequest
+	27,  // 27: headscale.v1.HeadscaleService.DeleteApiKey:input_type -> headscale.v1.DeleteApiKeyRequest
+	28,  // 28: headscale.v1.HeadscaleService.GetPolicy:input_type -> headscale.v1.GetPolicyRequest
+	29,  // 29: headscale.v1.HeadscaleService.SetPolicy:input_type -> headscale.v1.SetPolicyRequest
+	30,  // 30: headscale.v1.HeadscaleService.ListPolicyRevisions:input_type -> headscale.v1.ListPolicyRevisionsRequest
+	31,  // 31: headscale.v1.HeadscaleService.DiffPolicyRevisions:input_type -> headscale.v1.DiffPolicyRevisionsRequest
+	32,  // 32: headscale.v1.HeadscaleService.RollbackPolicy:input_type -> headscale.v1.RollbackPolicyRequest
+	33,  // 33: headscale.v1.HeadscaleService.ListAuditEvents:input_type -> headscale.v1.ListAuditEventsRequest
+	34,  // 34: headscale.v1.HeadscaleService.ListDNSRecords:input_type -> headscale.v1.ListDNSRecordsRequest
+	35,  // 35: headscale.v1.HeadscaleService.CreateDNSRecord:input_type -> headscale.v1.CreateDNSRecordRequest
+	36,  // 36: headscale.v1.HeadscaleService.UpdateDNSRecord:input_type -> headscale.v1.UpdateDNSRecordRequest
+	37,  // 37: headscale.v1.HeadscaleService.DeleteDNSRecord:input_type -> headscale.v1.DeleteDNSRecordRequest
+	38,  // 38: headscale.v1.HeadscaleService.ListNameservers:input_type -> headscale.v1.ListNameserversRequest
+	39,  // 39: headscale.v1.HeadscaleService.CreateNameserver:input_type -> headscale.v1.CreateNameserverRequest
+	40,  // 40: headscale.v1.HeadscaleService.UpdateNameserver:input_type -> headscale.v1.UpdateNameserverRequest
+	41,  // 41: headscale.v1.HeadscaleService.DeleteNameserver:input_type -> headscale.v1.DeleteNameserverRequest
+	42,  // 42: headscale.v1.HeadscaleService.ListSearchDomains:input_type -> headscale.v1.ListSearchDomainsRequest
+	43,  // 43: headscale.v1.HeadscaleService.CreateSearchDomain:input_type -> headscale.v1.CreateSearchDomainRequest
+	44,  // 44: headscale.v1.HeadscaleService.UpdateSearchDomain:input_type -> headscale.v1.UpdateSearchDomainRequest
+	45,  // 45: headscale.v1.HeadscaleService.DeleteSearchDomain:input_type -> headscale.v1.DeleteSearchDomainRequest
+	46,  // 46: headscale.v1.HeadscaleService.ListDERPRegions:input_type -> headscale.v1.ListDERPRegionsRequest
+	47,  // 47: headscale.v1.HeadscaleService.CreateDERPRegion:input_type -> headscale.v1.CreateDERPRegionRequest
+	48,  // 48: headscale.v1.HeadscaleService.DeleteDERPRegion:input_type -> headscale.v1.DeleteDERPRegionRequest
+	49,  // 49: headscale.v1.HeadscaleService.SetDERPRegionDisabled:input_type -> headscale.v1.SetDERPRegionDisabledRequest
+	50,  // 50: headscale.v1.HeadscaleService.CreateDERPNode:input_type -> headscale.v1.CreateDERPNodeRequest
+	51,  // 51: headscale.v1.HeadscaleService.DeleteDERPNode:input_type -> headscale.v1.DeleteDERPNodeRequest
+	52,  // 52: headscale.v1.HeadscaleService.SetDERPNodeDisabled:input_type -> headscale.v1.SetDERPNodeDisabledRequest
+	53,  // 53: headscale.v1.HeadscaleService.CreateUser:output_type -> headscale.v1.CreateUserResponse
+	54,  // 54: headscale.v1.HeadscaleService.RenameUser:output_type -> headscale.v1.RenameUserResponse
+	55,  // 55: headscale.v1.HeadscaleService.DeleteUser:output_type -> headscale.v1.DeleteUserResponse
+	56,  // 56: headscale.v1.HeadscaleService.ListUsers:output_type -> headscale.v1.ListUsersResponse
+	57,  // 57: headscale.v1.HeadscaleService.SetUserDisabled:output_type -> headscale.v1.SetUserDisabledResponse
+	58,  // 58: headscale.v1.HeadscaleService.CreatePreAuthKey:output_type -> headscale.v1.CreatePreAuthKeyResponse
+	59,  // 59: headscale.v1.HeadscaleService.ExpirePreAuthKey:output_type -> headscale.v1.ExpirePreAuthKeyResponse
+	60,  // 60: headscale.v1.HeadscaleService.ListPreAuthKeys:output_type -> headscale.v1.ListPreAuthKeysResponse
+	61,  // 61: headscale.v1.HeadscaleService.DebugCreateNode:output_type -> headscale.v1.DebugCreateNodeResponse
+	62,  // 62: headscale.v1.HeadscaleService.GetNode:output_type -> headscale.v1.GetNodeResponse
+	63,  // 63: headscale.v1.HeadscaleService.SetTags:output_type -> headscale.v1.SetTagsResponse
+	64,  // 64: headscale.v1.HeadscaleService.SetApprovedRoutes:output_type -> headscale.v1.SetApprovedRoutesResponse
+	65,  // 65: headscale.v1.HeadscaleService.SetRoutePriority:output_type -> headscale.v1.SetRoutePriorityResponse
+	66,  // 66: headscale.v1.HeadscaleService.RegisterNode:output_type -> headscale.v1.RegisterNodeResponse
+	67,  // 67: headscale.v1.HeadscaleService.DeleteNode:output_type -> headscale.v1.DeleteNodeResponse
+	68,  // 68: headscale.v1.HeadscaleService.ExpireNode:output_type -> headscale.v1.ExpireNodeResponse
+	69,  // 69: headscale.v1.HeadscaleService.RenameNode:output_type -> headscale.v1.RenameNodeResponse
+	70,  // 70: headscale.v1.HeadscaleService.ListNodes:output_type -> headscale.v1.ListNodesResponse
+	71,  // 71: headscale.v1.HeadscaleService.WatchNodes:output_type -> headscale.v1.NodeEvent
+	72,  // 72: headscale.v1.HeadscaleService.MoveNode:output_type -> headscale.v1.MoveNodeResponse
+	73,  // 73: headscale.v1.HeadscaleService.ListPendingNodes:output_type -> headscale.v1.ListPendingNodesResponse
+	74,  // 74: headscale.v1.HeadscaleService.ApproveNode:output_type -> headscale.v1.ApproveNodeResponse
+	75,  // 75: headscale.v1.HeadscaleService.RejectNode:output_type -> headscale.v1.RejectNodeResponse
+	76,  // 76: headscale.v1.HeadscaleService.BackfillNodeIPs:output_type -> headscale.v1.BackfillNodeIPsResponse
+	77,  // 77: headscale.v1.HeadscaleService.CreateApiKey:output_type -> headscale.v1.CreateApiKeyResponse
+	78,  // 78: headscale.v1.HeadscaleService.ExpireApiKey:output_type -> headscale.v1.ExpireApiKeyResponse
+	79,  // 79: headscale.v1.HeadscaleService.ListApiKeys:output_type -> headscale.v1.ListApiKeysResponse
+	80,  // 80: headscale.v1.HeadscaleService.DeleteApiKey:output_type -> headscale.v1.DeleteApiKeyResponse
+	81,  // 81: headscale.v1.HeadscaleService.GetPolicy:output_type -> headscale.v1.GetPolicyResponse
+	82,  // 82: headscale.v1.HeadscaleService.SetPolicy:output_type -> headscale.v1.SetPolicyResponse
+	83,  // 83: headscale.v1.HeadscaleService.ListPolicyRevisions:output_type -> headscale.v1.ListPolicyRevisionsResponse
+	84,  // 84: headscale.v1.HeadscaleService.DiffPolicyRevisions:output_type -> headscale.v1.DiffPolicyRevisionsResponse
+	85,  // 85: headscale.v1.HeadscaleService.RollbackPolicy:output_type -> headscale.v1.RollbackPolicyResponse
+	86,  // 86: headscale.v1.HeadscaleService.ListAuditEvents:output_type -> headscale.v1.ListAuditEventsResponse
+	87,  // 87: headscale.v1.HeadscaleService.ListDNSRecords:output_type -> headscale.v1.ListDNSRecordsResponse
+	88,  // 88: headscale.v1.HeadscaleService.CreateDNSRecord:output_type -> headscale.v1.CreateDNSRecordResponse
+	89,  // 89: headscale.v1.HeadscaleService.UpdateDNSRecord:output_type -> headscale.v1.UpdateDNSRecordResponse
+	90,  // 90: headscale.v1.HeadscaleService.DeleteDNSRecord:output_type -> headscale.v1.DeleteDNSRecordResponse
+	91,  // 91: headscale.v1.HeadscaleService.ListNameservers:output_type -> headscale.v1.ListNameserversResponse
+	92,  // 92: headscale.v1.HeadscaleService.CreateNameserver:output_type -> headscale.v1.CreateNameserverResponse
+	93,  // 93: headscale.v1.HeadscaleService.UpdateNameserver:output_type -> headscale.v1.UpdateNameserverResponse
+	94,  // 94: headscale.v1.HeadscaleService.DeleteNameserver:output_type -> headscale.v1.DeleteNameserverResponse
+	95,  // 95: headscale.v1.HeadscaleService.ListSearchDomains:output_type -> headscale.v1.ListSearchDomainsResponse
+	96,  // 96: headscale.v1.HeadscaleService.CreateSearchDomain:output_type -> headscale.v1.CreateSearchDomainResponse
+	97,  // 97: headscale.v1.HeadscaleService.UpdateSearchDomain:output_type -> headscale.v1.UpdateSearchDomainResponse
+	98,  // 98: headscale.v1.HeadscaleService.DeleteSearchDomain:output_type -> headscale.v1.DeleteSearchDomainResponse
+	99,  // 99: headscale.v1.HeadscaleService.ListDERPRegions:output_type -> headscale.v1.ListDERPRegionsResponse
+	100, // 100: headscale.v1.HeadscaleService.CreateDERPRegion:output_type -> headscale.v1.CreateDERPRegionResponse
+	101, // 101: headscale.v1.HeadscaleService.DeleteDERPRegion:output_type -> headscale.v1.DeleteDERPRegionResponse
+	102, // 102: headscale.v1.HeadscaleService.SetDERPRegionDisabled:output_type -> headscale.v1.SetDERPRegionDisabledResponse
+	103, // 103: headscale.v1.HeadscaleService.CreateDERPNode:output_type -> headscale.v1.CreateDERPNodeResponse
+	104, // 104: headscale.v1.HeadscaleService.DeleteDERPNode:output_type -> headscale.v1.DeleteDERPNodeResponse
+	105, // 105: headscale.v1.HeadscaleService.SetDERPNodeDisabled:output_type -> headscale.v1.SetDERPNodeDisabledResponse
+	53,  // [53:106] is the sub-list for method output_type
+	0,   // [0:53] is the sub-list for method input_type
+	0,   // [0:0] is the sub-list for extension type_name
+	0,   // [0:0] is the sub-list for extension extendee
+	0,   // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_headscale_v1_headscale_proto_init() }
@@ -174,6 +321,9 @@ func file_headscale_v1_headscale_proto_init() {
 	file_headscale_v1_node_proto_init()
 	file_headscale_v1_apikey_proto_init()
 	file_headscale_v1_policy_proto_init()
+	file_headscale_v1_audit_proto_init()
+	file_headscale_v1_dns_proto_init()
+	file_headscale_v1_derp_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -19,30 +19,59 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	HeadscaleService_CreateUser_FullMethodName        = "/headscale.v1.HeadscaleService/CreateUser"
-	HeadscaleService_RenameUser_FullMethodName        = "/headscale.v1.HeadscaleService/RenameUser"
-	HeadscaleService_DeleteUser_FullMethodName        = "/headscale.v1.HeadscaleService/DeleteUser"
-	HeadscaleService_ListUsers_FullMethodName         = "/headscale.v1.HeadscaleService/ListUsers"
-	HeadscaleService_CreatePreAuthKey_FullMethodName  = "/headscale.v1.HeadscaleService/CreatePreAuthKey"
-	HeadscaleService_ExpirePreAuthKey_FullMethodName  = "/headscale.v1.HeadscaleService/ExpirePreAuthKey"
-	HeadscaleService_ListPreAuthKeys_FullMethodName   = "/headscale.v1.HeadscaleService/ListPreAuthKeys"
-	HeadscaleService_DebugCreateNode_FullMethodName   = "/headscale.v1.HeadscaleService/DebugCreateNode"
-	HeadscaleService_GetNode_FullMethodName           = "/headscale.v1.HeadscaleService/GetNode"
-	HeadscaleService_SetTags_FullMethodName           = "/headscale.v1.HeadscaleService/SetTags"
-	HeadscaleService_SetApprovedRoutes_FullMethodName = "/headscale.v1.HeadscaleService/SetApprovedRoutes"
-	HeadscaleService_RegisterNode_FullMethodName      = "/headscale.v1.HeadscaleService/RegisterNode"
-	HeadscaleService_DeleteNode_FullMethodName        = "/headscale.v1.HeadscaleService/DeleteNode"
-	HeadscaleService_ExpireNode_FullMethodName        = "/headscale.v1.HeadscaleService/ExpireNode"
-	HeadscaleService_RenameNode_FullMethodName        = "/headscale.v1.HeadscaleService/RenameNode"
-	HeadscaleService_ListNodes_FullMethodName         = "/headscale.v1.HeadscaleService/ListNodes"
-	HeadscaleService_MoveNode_FullMethodName          = "/headscale.v1.HeadscaleService/MoveNode"
-	HeadscaleService_BackfillNodeIPs_FullMethodName   = "/headscale.v1.HeadscaleService/BackfillNodeIPs"
-	HeadscaleService_CreateApiKey_FullMethodName      = "/headscale.v1.HeadscaleService/CreateApiKey"
-	HeadscaleService_ExpireApiKey_FullMethodName      = "/headscale.v1.HeadscaleService/ExpireApiKey"
-	HeadscaleService_ListApiKeys_FullMethodName       = "/headscale.v1.HeadscaleService/ListApiKeys"
-	HeadscaleService_DeleteApiKey_FullMethodName      = "/headscale.v1.HeadscaleService/DeleteApiKey"
-	HeadscaleService_GetPolicy_FullMethodName         = "/headscale.v1.HeadscaleService/GetPolicy"
-	HeadscaleService_SetPolicy_FullMethodName         = "/headscale.v1.HeadscaleService/SetPolicy"
+	HeadscaleService_CreateUser_FullMethodName            = "/headscale.v1.HeadscaleService/CreateUser"
+	HeadscaleService_RenameUser_FullMethodName            = "/headscale.v1.HeadscaleService/RenameUser"
+	HeadscaleService_DeleteUser_FullMethodName            = "/headscale.v1.HeadscaleService/DeleteUser"
+	HeadscaleService_ListUsers_FullMethodName             = "/headscale.v1.HeadscaleService/ListUsers"
+	HeadscaleService_SetUserDisabled_FullMethodName       = "/headscale.v1.HeadscaleService/SetUserDisabled"
+	HeadscaleService_CreatePreAuthKey_FullMethodName      = "/headscale.v1.HeadscaleService/CreatePreAuthKey"
+	HeadscaleService_ExpirePreAuthKey_FullMethodName      = "/headscale.v1.HeadscaleService/ExpirePreAuthKey"
+	HeadscaleService_ListPreAuthKeys_FullMethodName       = "/headscale.v1.HeadscaleService/ListPreAuthKeys"
+	HeadscaleService_DebugCreateNode_FullMethodName       = "/headscale.v1.HeadscaleService/DebugCreateNode"
+	HeadscaleService_GetNode_FullMethodName               = "/headscale.v1.HeadscaleService/GetNode"
+	HeadscaleService_SetTags_FullMethodName               = "/headscale.v1.HeadscaleService/SetTags"
+	HeadscaleService_SetApprovedRoutes_FullMethodName     = "/headscale.v1.HeadscaleService/SetApprovedRoutes"
+	HeadscaleService_SetRoutePriority_FullMethodName      = "/headscale.v1.HeadscaleService/SetRoutePriority"
+	HeadscaleService_RegisterNode_FullMethodName          = "/headscale.v1.HeadscaleService/RegisterNode"
+	HeadscaleService_DeleteNode_FullMethodName            = "/headscale.v1.HeadscaleService/DeleteNode"
+	HeadscaleService_ExpireNode_FullMethodName            = "/headscale.v1.HeadscaleService/ExpireNode"
+	HeadscaleService_RenameNode_FullMethodName            = "/headscale.v1.HeadscaleService/RenameNode"
+	HeadscaleService_ListNodes_FullMethodName             = "/headscale.v1.HeadscaleService/ListNodes"
+	HeadscaleService_WatchNodes_FullMethodName            = "/headscale.v1.HeadscaleService/WatchNodes"
+	HeadscaleService_MoveNode_FullMethodName              = "/headscale.v1.HeadscaleService/MoveNode"
+	HeadscaleService_ListPendingNodes_FullMethodName      = "/headscale.v1.HeadscaleService/ListPendingNodes"
+	HeadscaleService_ApproveNode_FullMethodName           = "/headscale.v1.HeadscaleService/ApproveNode"
+	HeadscaleService_RejectNode_FullMethodName            = "/headscale.v1.HeadscaleService/RejectNode"
+	HeadscaleService_BackfillNodeIPs_FullMethodName       = "/headscale.v1.HeadscaleService/BackfillNodeIPs"
+	HeadscaleService_CreateApiKey_FullMethodName          = "/headscale.v1.HeadscaleService/CreateApiKey"
+	HeadscaleService_ExpireApiKey_FullMethodName          = "/headscale.v1.HeadscaleService/ExpireApiKey"
+	HeadscaleService_ListApiKeys_FullMethodName           = "/headscale.v1.HeadscaleService/ListApiKeys"
+	HeadscaleService_DeleteApiKey_FullMethodName          = "/headscale.v1.HeadscaleService/DeleteApiKey"
+	HeadscaleService_GetPolicy_FullMethodName             = "/headscale.v1.HeadscaleService/GetPolicy"
+	HeadscaleService_SetPolicy_FullMethodName             = "/headscale.v1.HeadscaleService/SetPolicy"
+	HeadscaleService_ListPolicyRevisions_FullMethodName   = "/headscale.v1.HeadscaleService/ListPolicyRevisions"
+	HeadscaleService_DiffPolicyRevisions_FullMethodName   = "/headscale.v1.HeadscaleService/DiffPolicyRevisions"
+	HeadscaleService_RollbackPolicy_FullMethodName        = "/headscale.v1.HeadscaleService/RollbackPolicy"
+	HeadscaleService_ListAuditEvents_FullMethodName       = "/headscale.v1.HeadscaleService/ListAuditEvents"
+	HeadscaleService_ListDNSRecords_FullMethodName        = "/headscale.v1.HeadscaleService/ListDNSRecords"
+	HeadscaleService_CreateDNSRecord_FullMethodName       = "/headscale.v1.HeadscaleService/CreateDNSRecord"
+	HeadscaleService_UpdateDNSRecord_FullMethodName       = "/headscale.v1.HeadscaleService/UpdateDNSRecord"
+	HeadscaleService_DeleteDNSRecord_FullMethodName       = "/headscale.v1.HeadscaleService/DeleteDNSRecord"
+	HeadscaleService_ListNameservers_FullMethodName       = "/headscale.v1.HeadscaleService/ListNameservers"
+	HeadscaleService_CreateNameserver_FullMethodName      = "/headscale.v1.HeadscaleService/CreateNameserver"
+	HeadscaleService_UpdateNameserver_FullMethodName      = "/headscale.v1.HeadscaleService/UpdateNameserver"
+	HeadscaleService_DeleteNameserver_FullMethodName      = "/headscale.v1.HeadscaleService/DeleteNameserver"
+	HeadscaleService_ListSearchDomains_FullMethodName     = "/headscale.v1.HeadscaleService/ListSearchDomains"
+	HeadscaleService_CreateSearchDomain_FullMethodName    = "/headscale.v1.HeadscaleService/CreateSearchDomain"
+	HeadscaleService_UpdateSearchDomain_FullMethodName    = "/headscale.v1.HeadscaleService/UpdateSearchDomain"
+	HeadscaleService_DeleteSearchDomain_FullMethodName    = "/headscale.v1.HeadscaleService/DeleteSearchDomain"
+	HeadscaleService_ListDERPRegions_FullMethodName       = "/headscale.v1.HeadscaleService/ListDERPRegions"
+	HeadscaleService_CreateDERPRegion_FullMethodName      = "/headscale.v1.HeadscaleService/CreateDERPRegion"
+	HeadscaleService_DeleteDERPRegion_FullMethodName      = "/headscale.v1.HeadscaleService/DeleteDERPRegion"
+	HeadscaleService_SetDERPRegionDisabled_FullMethodName = "/headscale.v1.HeadscaleService/SetDERPRegionDisabled"
+	HeadscaleService_CreateDERPNode_FullMethodName        = "/headscale.v1.HeadscaleService/CreateDERPNode"
+	HeadscaleService_DeleteDERPNode_FullMethodName        = "/headscale.v1.HeadscaleService/DeleteDERPNode"
+	HeadscaleService_SetDERPNodeDisabled_FullMethodName   = "/headscale.v1.HeadscaleService/SetDERPNodeDisabled"
 )
 
 // HeadscaleServiceClient is the client API for HeadscaleService service.
@@ -54,6 +83,7 @@ type HeadscaleServiceClient interface {
 	RenameUser(ctx context.Context, in *RenameUserRequest, opts ...grpc.CallOption) (*RenameUserResponse, error)
 	DeleteUser(ctx context.Context, in *DeleteUserRequest, opts ...grpc.CallOption) (*DeleteUserResponse, error)
 	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListUsersResponse, error)
+	SetUserDisabled(ctx context.Context, in *SetUserDisabledRequest, opts ...grpc.CallOption) (*SetUserDisabledResponse, error)
 	// --- PreAuthKeys start ---
 	CreatePreAuthKey(ctx context.Context, in *CreatePreAuthKeyRequest, opts ...grpc.CallOption) (*CreatePreAuthKeyResponse, error)
 	ExpirePreAuthKey(ctx context.Context, in *ExpirePreAuthKeyRequest, opts ...grpc.CallOption) (*ExpirePreAuthKeyResponse, error)
@@ -63,12 +93,17 @@ type HeadscaleServiceClient interface {
 	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeResponse, error)
 	SetTags(ctx context.Context, in *SetTagsRequest, opts ...grpc.CallOption) (*SetTagsResponse, error)
 	SetApprovedRoutes(ctx context.Context, in *SetApprovedRoutesRequest, opts ...grpc.CallOption) (*SetApprovedRoutesResponse, error)
+	SetRoutePriority(ctx context.Context, in *SetRoutePriorityRequest, opts ...grpc.CallOption) (*SetRoutePriorityResponse, error)
 	RegisterNode(ctx context.Context, in *RegisterNodeRequest, opts ...grpc.CallOption) (*RegisterNodeResponse, error)
 	DeleteNode(ctx context.Context, in *DeleteNodeRequest, opts ...grpc.CallOption) (*DeleteNodeResponse, error)
 	ExpireNode(ctx context.Context, in *ExpireNodeRequest, opts ...grpc.CallOption) (*ExpireNodeResponse, error)
 	RenameNode(ctx context.Context, in *RenameNodeRequest, opts ...grpc.CallOption) (*RenameNodeResponse, error)
 	ListNodes(ctx context.Context, in *ListNodesRequest, opts ...grpc.CallOption) (*ListNodesResponse, error)
+	WatchNodes(ctx context.Context, in *WatchNodesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeEvent], error)
 	MoveNode(ctx context.Context, in *MoveNodeRequest, opts ...grpc.CallOption) (*MoveNodeResponse, error)
+	ListPendingNodes(ctx context.Context, in *ListPendingNodesRequest, opts ...grpc.CallOption) (*ListPendingNodesResponse, error)
+	ApproveNode(ctx context.Context, in *ApproveNodeRequest, opts ...grpc.CallOption) (*ApproveNodeResponse, error)
+	RejectNode(ctx context.Context, in *RejectNodeRequest, opts ...grpc.CallOption) (*RejectNodeResponse, error)
 	BackfillNodeIPs(ctx context.Context, in *BackfillNodeIPsRequest, opts ...grpc.CallOption) (*BackfillNodeIPsResponse, error)
 	// --- ApiKeys start ---
 	CreateApiKey(ctx context.Context, in *CreateApiKeyRequest, opts ...grpc.CallOption) (*CreateApiKeyResponse, error)
@@ -78,6 +113,32 @@ type HeadscaleServiceClient interface {
 	// --- Policy start ---
 	GetPolicy(ctx context.Context, in *GetPolicyRequest, opts ...grpc.CallOption) (*GetPolicyResponse, error)
 	SetPolicy(ctx context.Context, in *SetPolicyRequest, opts ...grpc.CallOption) (*SetPolicyResponse, error)
+	ListPolicyRevisions(ctx context.Context, in *ListPolicyRevisionsRequest, opts ...grpc.CallOption) (*ListPolicyRevisionsResponse, error)
+	DiffPolicyRevisions(ctx context.Context, in *DiffPolicyRevisionsRequest, opts ...grpc.CallOption) (*DiffPolicyRevisionsResponse, error)
+	RollbackPolicy(ctx context.Context, in *RollbackPolicyRequest, opts ...grpc.CallOption) (*RollbackPolicyResponse, error)
+	// --- Audit start ---
+	ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error)
+	// --- DNS start ---
+	ListDNSRecords(ctx context.Context, in *ListDNSRecordsRequest, opts ...grpc.CallOption) (*ListDNSRecordsResponse, error)
+	CreateDNSRecord(ctx context.Context, in *CreateDNSRecordRequest, opts ...grpc.CallOption) (*CreateDNSRecordResponse, error)
+	UpdateDNSRecord(ctx context.Context, in *UpdateDNSRecordRequest, opts ...grpc.CallOption) (*UpdateDNSRecordResponse, error)
+	DeleteDNSRecord(ctx context.Context, in *DeleteDNSRecordRequest, opts ...grpc.CallOption) (*DeleteDNSRecordResponse, error)
+	ListNameservers(ctx context.Context, in *ListNameserversRequest, opts ...grpc.CallOption) (*ListNameserversResponse, error)
+	CreateNameserver(ctx context.Context, in *CreateNameserverRequest, opts ...grpc.CallOption) (*CreateNameserverResponse, error)
+	UpdateNameserver(ctx context.Context, in *UpdateNameserverRequest, opts ...grpc.CallOption) (*UpdateNameserverResponse, error)
+	DeleteNameserver(ctx context.Context, in *DeleteNameserverRequest, opts ...grpc.CallOption) (*DeleteNameserverResponse, error)
+	ListSearchDomains(ctx context.Context, in *ListSearchDomainsRequest, opts ...grpc.CallOption) (*ListSearchDomainsResponse, error)
+	CreateSearchDomain(ctx context.Context, in *CreateSearchDomainRequest, opts ...grpc.CallOption) (*CreateSearchDomainResponse, error)
+	UpdateSearchDomain(ctx context.Context, in *UpdateSearchDomainRequest, opts ...grpc.CallOption) (*UpdateSearchDomainResponse, error)
+	DeleteSearchDomain(ctx context.Context, in *DeleteSearchDomainRequest, opts ...grpc.CallOption) (*DeleteSearchDomainResponse, error)
+	// --- DERP start ---
+	ListDERPRegions(ctx context.Context, in *ListDERPRegionsRequest, opts ...grpc.CallOption) (*ListDERPRegionsResponse, error)
+	CreateDERPRegion(ctx context.Context, in *CreateDERPRegionRequest, opts ...grpc.CallOption) (*CreateDERPRegionResponse, error)
+	DeleteDERPRegion(ctx context.Context, in *DeleteDERPRegionRequest, opts ...grpc.CallOption) (*DeleteDERPRegionResponse, error)
+	SetDERPRegionDisabled(ctx context.Context, in *SetDERPRegionDisabledRequest, opts ...grpc.CallOption) (*SetDERPRegionDisabledResponse, error)
+	CreateDERPNode(ctx context.Context, in *CreateDERPNodeRequest, opts ...grpc.CallOption) (*CreateDERPNodeResponse, error)
+	DeleteDERPNode(ctx context.Context, in *DeleteDERPNodeRequest, opts ...grpc.CallOption) (*DeleteDERPNodeResponse, error)
+	SetDERPNodeDisabled(ctx context.Context, in *SetDERPNodeDisabledRequest, opts ...grpc.CallOption) (*SetDERPNodeDisabledResponse, error)
 }
 
 type headscaleServiceClient struct {
@@ -128,6 +189,16 @@ func (c *headscaleServiceClient) ListUsers(ctx context.Context, in *ListUsersReq
 	return out, nil
 }
 
+func (c *headscaleServiceClient) SetUserDisabled(ctx context.Context, in *SetUserDisabledRequest, opts ...grpc.CallOption) (*SetUserDisabledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetUserDisabledResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_SetUserDisabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *headscaleServiceClient) CreatePreAuthKey(ctx context.Context, in *CreatePreAuthKeyRequest, opts ...grpc.CallOption) (*CreatePreAuthKeyResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CreatePreAuthKeyResponse)
@@ -198,6 +269,16 @@ func (c *headscaleServiceClient) SetApprovedRoutes(ctx context.Context, in *SetA
 	return out, nil
 }
 
+func (c *headscaleServiceClient) SetRoutePriority(ctx context.Context, in *SetRoutePriorityRequest, opts ...grpc.CallOption) (*SetRoutePriorityResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetRoutePriorityResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_SetRoutePriority_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *headscaleServiceClient) RegisterNode(ctx context.Context, in *RegisterNodeRequest, opts ...grpc.CallOption) (*RegisterNodeResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RegisterNodeResponse)
@@ -248,6 +329,25 @@ func (c *headscaleServiceClient) ListNodes(ctx context.Context, in *ListNodesReq
 	return out, nil
 }
 
+func (c *headscaleServiceClient) WatchNodes(ctx context.Context, in *WatchNodesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[NodeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &HeadscaleService_ServiceDesc.Streams[0], HeadscaleService_WatchNodes_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchNodesRequest, NodeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HeadscaleService_WatchNodesClient = grpc.ServerStreamingClient[NodeEvent]
+
 func (c *headscaleServiceClient) MoveNode(ctx context.Context, in *MoveNodeRequest, opts ...grpc.CallOption) (*MoveNodeResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(MoveNodeResponse)
@@ -258,6 +358,36 @@ func (c *headscaleServiceClient) MoveNode(ctx context.Context, in *MoveNodeReque
 	return out, nil
 }
 
+func (c *headscaleServiceClient) ListPendingNodes(ctx context.Context, in *ListPendingNodesRequest, opts ...grpc.CallOption) (*ListPendingNodesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPendingNodesResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListPendingNodes_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ApproveNode(ctx context.Context, in *ApproveNodeRequest, opts ...grpc.CallOption) (*ApproveNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApproveNodeResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ApproveNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) RejectNode(ctx context.Context, in *RejectNodeRequest, opts ...grpc.CallOption) (*RejectNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RejectNodeResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_RejectNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *headscaleServiceClient) BackfillNodeIPs(ctx context.Context, in *BackfillNodeIPsRequest, opts ...grpc.CallOption) (*BackfillNodeIPsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(BackfillNodeIPsResponse)
@@ -328,6 +458,236 @@ func (c *headscaleServiceClient) SetPolicy(ctx context.Context, in *SetPolicyReq
 	return out, nil
 }
 
+func (c *headscaleServiceClient) ListPolicyRevisions(ctx context.Context, in *ListPolicyRevisionsRequest, opts ...grpc.CallOption) (*ListPolicyRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPolicyRevisionsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListPolicyRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DiffPolicyRevisions(ctx context.Context, in *DiffPolicyRevisionsRequest, opts ...grpc.CallOption) (*DiffPolicyRevisionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DiffPolicyRevisionsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DiffPolicyRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) RollbackPolicy(ctx context.Context, in *RollbackPolicyRequest, opts ...grpc.CallOption) (*RollbackPolicyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RollbackPolicyResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_RollbackPolicy_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ListAuditEvents(ctx context.Context, in *ListAuditEventsRequest, opts ...grpc.CallOption) (*ListAuditEventsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAuditEventsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListAuditEvents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ListDNSRecords(ctx context.Context, in *ListDNSRecordsRequest, opts ...grpc.CallOption) (*ListDNSRecordsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDNSRecordsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListDNSRecords_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) CreateDNSRecord(ctx context.Context, in *CreateDNSRecordRequest, opts ...grpc.CallOption) (*CreateDNSRecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDNSRecordResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_CreateDNSRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) UpdateDNSRecord(ctx context.Context, in *UpdateDNSRecordRequest, opts ...grpc.CallOption) (*UpdateDNSRecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateDNSRecordResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_UpdateDNSRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DeleteDNSRecord(ctx context.Context, in *DeleteDNSRecordRequest, opts ...grpc.CallOption) (*DeleteDNSRecordResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDNSRecordResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DeleteDNSRecord_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ListNameservers(ctx context.Context, in *ListNameserversRequest, opts ...grpc.CallOption) (*ListNameserversResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListNameserversResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListNameservers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) CreateNameserver(ctx context.Context, in *CreateNameserverRequest, opts ...grpc.CallOption) (*CreateNameserverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateNameserverResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_CreateNameserver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) UpdateNameserver(ctx context.Context, in *UpdateNameserverRequest, opts ...grpc.CallOption) (*UpdateNameserverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateNameserverResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_UpdateNameserver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DeleteNameserver(ctx context.Context, in *DeleteNameserverRequest, opts ...grpc.CallOption) (*DeleteNameserverResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteNameserverResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DeleteNameserver_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ListSearchDomains(ctx context.Context, in *ListSearchDomainsRequest, opts ...grpc.CallOption) (*ListSearchDomainsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSearchDomainsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListSearchDomains_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) CreateSearchDomain(ctx context.Context, in *CreateSearchDomainRequest, opts ...grpc.CallOption) (*CreateSearchDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateSearchDomainResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_CreateSearchDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) UpdateSearchDomain(ctx context.Context, in *UpdateSearchDomainRequest, opts ...grpc.CallOption) (*UpdateSearchDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateSearchDomainResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_UpdateSearchDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DeleteSearchDomain(ctx context.Context, in *DeleteSearchDomainRequest, opts ...grpc.CallOption) (*DeleteSearchDomainResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSearchDomainResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DeleteSearchDomain_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) ListDERPRegions(ctx context.Context, in *ListDERPRegionsRequest, opts ...grpc.CallOption) (*ListDERPRegionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListDERPRegionsResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_ListDERPRegions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) CreateDERPRegion(ctx context.Context, in *CreateDERPRegionRequest, opts ...grpc.CallOption) (*CreateDERPRegionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDERPRegionResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_CreateDERPRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DeleteDERPRegion(ctx context.Context, in *DeleteDERPRegionRequest, opts ...grpc.CallOption) (*DeleteDERPRegionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDERPRegionResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DeleteDERPRegion_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) SetDERPRegionDisabled(ctx context.Context, in *SetDERPRegionDisabledRequest, opts ...grpc.CallOption) (*SetDERPRegionDisabledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDERPRegionDisabledResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_SetDERPRegionDisabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) CreateDERPNode(ctx context.Context, in *CreateDERPNodeRequest, opts ...grpc.CallOption) (*CreateDERPNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDERPNodeResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_CreateDERPNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) DeleteDERPNode(ctx context.Context, in *DeleteDERPNodeRequest, opts ...grpc.CallOption) (*DeleteDERPNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDERPNodeResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_DeleteDERPNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *headscaleServiceClient) SetDERPNodeDisabled(ctx context.Context, in *SetDERPNodeDisabledRequest, opts ...grpc.CallOption) (*SetDERPNodeDisabledResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetDERPNodeDisabledResponse)
+	err := c.cc.Invoke(ctx, HeadscaleService_SetDERPNodeDisabled_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // HeadscaleServiceServer is the server API for HeadscaleService service.
 // All implementations must embed UnimplementedHeadscaleServiceServer
 // for forward compatibility.
@@ -337,6 +697,7 @@ type HeadscaleServiceServer interface {
 	RenameUser(context.Context, *RenameUserRequest) (*RenameUserResponse, error)
 	DeleteUser(context.Context, *DeleteUserRequest) (*DeleteUserResponse, error)
 	ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error)
+	SetUserDisabled(context.Context, *SetUserDisabledRequest) (*SetUserDisabledResponse, error)
 	// --- PreAuthKeys start ---
 	CreatePreAuthKey(context.Context, *CreatePreAuthKeyRequest) (*CreatePreAuthKeyResponse, error)
 	ExpirePreAuthKey(context.Context, *ExpirePreAuthKeyRequest) (*ExpirePreAuthKeyResponse, error)
@@ -346,12 +707,17 @@ type HeadscaleServiceServer interface {
 	GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error)
 	SetTags(context.Context, *SetTagsRequest) (*SetTagsResponse, error)
 	SetApprovedRoutes(context.Context, *SetApprovedRoutesRequest) (*SetApprovedRoutesResponse, error)
+	SetRoutePriority(context.Context, *SetRoutePriorityRequest) (*SetRoutePriorityResponse, error)
 	RegisterNode(context.Context, *RegisterNodeRequest) (*RegisterNodeResponse, error)
 	DeleteNode(context.Context, *DeleteNodeRequest) (*DeleteNodeResponse, error)
 	ExpireNode(context.Context, *ExpireNodeRequest) (*ExpireNodeResponse, error)
 	RenameNode(context.Context, *RenameNodeRequest) (*RenameNodeResponse, error)
 	ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error)
+	WatchNodes(*WatchNodesRequest, grpc.ServerStreamingServer[NodeEvent]) error
 	MoveNode(context.Context, *MoveNodeRequest) (*MoveNodeResponse, error)
+	ListPendingNodes(context.Context, *ListPendingNodesRequest) (*ListPendingNodesResponse, error)
+	ApproveNode(context.Context, *ApproveNodeRequest) (*ApproveNodeResponse, error)
+	RejectNode(context.Context, *RejectNodeRequest) (*RejectNodeResponse, error)
 	BackfillNodeIPs(context.Context, *BackfillNodeIPsRequest) (*BackfillNodeIPsResponse, error)
 	// --- ApiKeys start ---
 	CreateApiKey(context.Context, *CreateApiKeyRequest) (*CreateApiKeyResponse, error)
@@ -361,6 +727,32 @@ type HeadscaleServiceServer interface {
 	// --- Policy start ---
 	GetPolicy(context.Context, *GetPolicyRequest) (*GetPolicyResponse, error)
 	SetPolicy(context.Context, *SetPolicyRequest) (*SetPolicyResponse, error)
+	ListPolicyRevisions(context.Context, *ListPolicyRevisionsRequest) (*ListPolicyRevisionsResponse, error)
+	DiffPolicyRevisions(context.Context, *DiffPolicyRevisionsRequest) (*DiffPolicyRevisionsResponse, error)
+	RollbackPolicy(context.Context, *RollbackPolicyRequest) (*RollbackPolicyResponse, error)
+	// --- Audit start ---
+	ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error)
+	// --- DNS start ---
+	ListDNSRecords(context.Context, *ListDNSRecordsRequest) (*ListDNSRecordsResponse, error)
+	CreateDNSRecord(context.Context, *CreateDNSRecordRequest) (*CreateDNSRecordResponse, error)
+	UpdateDNSRecord(context.Context, *UpdateDNSRecordRequest) (*UpdateDNSRecordResponse, error)
+	DeleteDNSRecord(context.Context, *DeleteDNSRecordRequest) (*DeleteDNSRecordResponse, error)
+	ListNameservers(context.Context, *ListNameserversRequest) (*ListNameserversResponse, error)
+	CreateNameserver(context.Context, *CreateNameserverRequest) (*CreateNameserverResponse, error)
+	UpdateNameserver(context.Context, *UpdateNameserverRequest) (*UpdateNameserverResponse, error)
+	DeleteNameserver(context.Context, *DeleteNameserverRequest) (*DeleteNameserverResponse, error)
+	ListSearchDomains(context.Context, *ListSearchDomainsRequest) (*ListSearchDomainsResponse, error)
+	CreateSearchDomain(context.Context, *CreateSearchDomainRequest) (*CreateSearchDomainResponse, error)
+	UpdateSearchDomain(context.Context, *UpdateSearchDomainRequest) (*UpdateSearchDomainResponse, error)
+	DeleteSearchDomain(context.Context, *DeleteSearchDomainRequest) (*DeleteSearchDomainResponse, error)
+	// --- DERP start ---
+	ListDERPRegions(context.Context, *ListDERPRegionsRequest) (*ListDERPRegionsResponse, error)
+	CreateDERPRegion(context.Context, *CreateDERPRegionRequest) (*CreateDERPRegionResponse, error)
+	DeleteDERPRegion(context.Context, *DeleteDERPRegionRequest) (*DeleteDERPRegionResponse, error)
+	SetDERPRegionDisabled(context.Context, *SetDERPRegionDisabledRequest) (*SetDERPRegionDisabledResponse, error)
+	CreateDERPNode(context.Context, *CreateDERPNodeRequest) (*CreateDERPNodeResponse, error)
+	DeleteDERPNode(context.Context, *DeleteDERPNodeRequest) (*DeleteDERPNodeResponse, error)
+	SetDERPNodeDisabled(context.Context, *SetDERPNodeDisabledRequest) (*SetDERPNodeDisabledResponse, error)
 	mustEmbedUnimplementedHeadscaleServiceServer()
 }
 
@@ -383,6 +775,9 @@ func (UnimplementedHeadscaleServiceServer) DeleteUser(context.Context, *DeleteUs
 func (UnimplementedHeadscaleServiceServer) ListUsers(context.Context, *ListUsersRequest) (*ListUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
 }
+func (UnimplementedHeadscaleServiceServer) SetUserDisabled(context.Context, *SetUserDisabledRequest) (*SetUserDisabledResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetUserDisabled not implemented")
+}
 func (UnimplementedHeadscaleServiceServer) CreatePreAuthKey(context.Context, *CreatePreAuthKeyRequest) (*CreatePreAuthKeyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreatePreAuthKey not implemented")
 }
@@ -404,6 +799,9 @@ func (UnimplementedHeadscaleServiceServer) SetTags(context.Context, *SetTagsRequ
 func (UnimplementedHeadscaleServiceServer) SetApprovedRoutes(context.Context, *SetApprovedRoutesRequest) (*SetApprovedRoutesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetApprovedRoutes not implemented")
 }
+func (UnimplementedHeadscaleServiceServer) SetRoutePriority(context.Context, *SetRoutePriorityRequest) (*SetRoutePriorityResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetRoutePriority not implemented")
+}
 func (UnimplementedHeadscaleServiceServer) RegisterNode(context.Context, *RegisterNodeRequest) (*RegisterNodeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RegisterNode not implemented")
 }
@@ -419,9 +817,21 @@ func (UnimplementedHeadscaleServiceServer) RenameNode(context.Context, *RenameNo
 func (UnimplementedHeadscaleServiceServer) ListNodes(context.Context, *ListNodesRequest) (*ListNodesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListNodes not implemented")
 }
+func (UnimplementedHeadscaleServiceServer) WatchNodes(*WatchNodesRequest, grpc.ServerStreamingServer[NodeEvent]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchNodes not implemented")
+}
 func (UnimplementedHeadscaleServiceServer) MoveNode(context.Context, *MoveNodeRequest) (*MoveNodeResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MoveNode not implemented")
 }
+func (UnimplementedHeadscaleServiceServer) ListPendingNodes(context.Context, *ListPendingNodesRequest) (*ListPendingNodesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPendingNodes not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ApproveNode(context.Context, *ApproveNodeRequest) (*ApproveNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ApproveNode not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) RejectNode(context.Context, *RejectNodeRequest) (*RejectNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RejectNode not implemented")
+}
 func (UnimplementedHeadscaleServiceServer) BackfillNodeIPs(context.Context, *BackfillNodeIPsRequest) (*BackfillNodeIPsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method BackfillNodeIPs not implemented")
 }
@@ -443,6 +853,75 @@ func (UnimplementedHeadscaleServiceServer) GetPolicy(context.Context, *GetPolicy
 func (UnimplementedHeadscaleServiceServer) SetPolicy(context.Context, *SetPolicyRequest) (*SetPolicyResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetPolicy not implemented")
 }
+func (UnimplementedHeadscaleServiceServer) ListPolicyRevisions(context.Context, *ListPolicyRevisionsRequest) (*ListPolicyRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPolicyRevisions not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DiffPolicyRevisions(context.Context, *DiffPolicyRevisionsRequest) (*DiffPolicyRevisionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffPolicyRevisions not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) RollbackPolicy(context.Context, *RollbackPolicyRequest) (*RollbackPolicyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RollbackPolicy not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ListAuditEvents(context.Context, *ListAuditEventsRequest) (*ListAuditEventsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAuditEvents not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ListDNSRecords(context.Context, *ListDNSRecordsRequest) (*ListDNSRecordsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDNSRecords not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) CreateDNSRecord(context.Context, *CreateDNSRecordRequest) (*CreateDNSRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDNSRecord not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) UpdateDNSRecord(context.Context, *UpdateDNSRecordRequest) (*UpdateDNSRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateDNSRecord not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DeleteDNSRecord(context.Context, *DeleteDNSRecordRequest) (*DeleteDNSRecordResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDNSRecord not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ListNameservers(context.Context, *ListNameserversRequest) (*ListNameserversResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListNameservers not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) CreateNameserver(context.Context, *CreateNameserverRequest) (*CreateNameserverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateNameserver not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) UpdateNameserver(context.Context, *UpdateNameserverRequest) (*UpdateNameserverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateNameserver not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DeleteNameserver(context.Context, *DeleteNameserverRequest) (*DeleteNameserverResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteNameserver not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ListSearchDomains(context.Context, *ListSearchDomainsRequest) (*ListSearchDomainsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSearchDomains not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) CreateSearchDomain(context.Context, *CreateSearchDomainRequest) (*CreateSearchDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateSearchDomain not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) UpdateSearchDomain(context.Context, *UpdateSearchDomainRequest) (*UpdateSearchDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateSearchDomain not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DeleteSearchDomain(context.Context, *DeleteSearchDomainRequest) (*DeleteSearchDomainResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSearchDomain not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) ListDERPRegions(context.Context, *ListDERPRegionsRequest) (*ListDERPRegionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListDERPRegions not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) CreateDERPRegion(context.Context, *CreateDERPRegionRequest) (*CreateDERPRegionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDERPRegion not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DeleteDERPRegion(context.Context, *DeleteDERPRegionRequest) (*DeleteDERPRegionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDERPRegion not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) SetDERPRegionDisabled(context.Context, *SetDERPRegionDisabledRequest) (*SetDERPRegionDisabledResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDERPRegionDisabled not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) CreateDERPNode(context.Context, *CreateDERPNodeRequest) (*CreateDERPNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDERPNode not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) DeleteDERPNode(context.Context, *DeleteDERPNodeRequest) (*DeleteDERPNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDERPNode not implemented")
+}
+func (UnimplementedHeadscaleServiceServer) SetDERPNodeDisabled(context.Context, *SetDERPNodeDisabledRequest) (*SetDERPNodeDisabledResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDERPNodeDisabled not implemented")
+}
 func (UnimplementedHeadscaleServiceServer) mustEmbedUnimplementedHeadscaleServiceServer() {}
 func (UnimplementedHeadscaleServiceServer) testEmbeddedByValue()                          {}
 
@@ -536,6 +1015,24 @@ func _HeadscaleService_ListUsers_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HeadscaleService_SetUserDisabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetUserDisabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).SetUserDisabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_SetUserDisabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).SetUserDisabled(ctx, req.(*SetUserDisabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _HeadscaleService_CreatePreAuthKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CreatePreAuthKeyRequest)
 	if err := dec(in); err != nil {
@@ -662,26 +1159,44 @@ func _HeadscaleService_SetApprovedRoutes_Handler(srv interface{}, ctx context.Co
 	return interceptor(ctx, in, info, handler)
 }
 
-func _HeadscaleService_RegisterNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(RegisterNodeRequest)
+func _HeadscaleService_SetRoutePriority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetRoutePriorityRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(HeadscaleServiceServer).RegisterNode(ctx, in)
+		return srv.(HeadscaleServiceServer).SetRoutePriority(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: HeadscaleService_RegisterNode_FullMethodName,
+		FullMethod: HeadscaleService_SetRoutePriority_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(HeadscaleServiceServer).RegisterNode(ctx, req.(*RegisterNodeRequest))
+		return srv.(HeadscaleServiceServer).SetRoutePriority(ctx, req.(*SetRoutePriorityRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _HeadscaleService_DeleteNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteNodeRequest)
+func _HeadscaleService_RegisterNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).RegisterNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_RegisterNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).RegisterNode(ctx, req.(*RegisterNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNodeRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
@@ -752,6 +1267,17 @@ func _HeadscaleService_ListNodes_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HeadscaleService_WatchNodes_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchNodesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(HeadscaleServiceServer).WatchNodes(m, &grpc.GenericServerStream[WatchNodesRequest, NodeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type HeadscaleService_WatchNodesServer = grpc.ServerStreamingServer[NodeEvent]
+
 func _HeadscaleService_MoveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(MoveNodeRequest)
 	if err := dec(in); err != nil {
@@ -770,6 +1296,60 @@ func _HeadscaleService_MoveNode_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HeadscaleService_ListPendingNodes_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPendingNodesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListPendingNodes(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListPendingNodes_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListPendingNodes(ctx, req.(*ListPendingNodesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ApproveNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApproveNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ApproveNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ApproveNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ApproveNode(ctx, req.(*ApproveNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_RejectNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RejectNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).RejectNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_RejectNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).RejectNode(ctx, req.(*RejectNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _HeadscaleService_BackfillNodeIPs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(BackfillNodeIPsRequest)
 	if err := dec(in); err != nil {
@@ -896,6 +1476,420 @@ func _HeadscaleService_SetPolicy_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _HeadscaleService_ListPolicyRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPolicyRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListPolicyRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListPolicyRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListPolicyRevisions(ctx, req.(*ListPolicyRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DiffPolicyRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DiffPolicyRevisionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DiffPolicyRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DiffPolicyRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DiffPolicyRevisions(ctx, req.(*DiffPolicyRevisionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_RollbackPolicy_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RollbackPolicyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).RollbackPolicy(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_RollbackPolicy_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).RollbackPolicy(ctx, req.(*RollbackPolicyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ListAuditEvents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAuditEventsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListAuditEvents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListAuditEvents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListAuditEvents(ctx, req.(*ListAuditEventsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ListDNSRecords_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDNSRecordsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListDNSRecords(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListDNSRecords_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListDNSRecords(ctx, req.(*ListDNSRecordsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_CreateDNSRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDNSRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).CreateDNSRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_CreateDNSRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).CreateDNSRecord(ctx, req.(*CreateDNSRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_UpdateDNSRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateDNSRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).UpdateDNSRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_UpdateDNSRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).UpdateDNSRecord(ctx, req.(*UpdateDNSRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteDNSRecord_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDNSRecordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DeleteDNSRecord(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DeleteDNSRecord_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DeleteDNSRecord(ctx, req.(*DeleteDNSRecordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ListNameservers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListNameserversRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListNameservers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListNameservers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListNameservers(ctx, req.(*ListNameserversRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_CreateNameserver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateNameserverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).CreateNameserver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_CreateNameserver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).CreateNameserver(ctx, req.(*CreateNameserverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_UpdateNameserver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateNameserverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).UpdateNameserver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_UpdateNameserver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).UpdateNameserver(ctx, req.(*UpdateNameserverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteNameserver_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteNameserverRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DeleteNameserver(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DeleteNameserver_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DeleteNameserver(ctx, req.(*DeleteNameserverRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ListSearchDomains_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSearchDomainsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListSearchDomains(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListSearchDomains_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListSearchDomains(ctx, req.(*ListSearchDomainsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_CreateSearchDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateSearchDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).CreateSearchDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_CreateSearchDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).CreateSearchDomain(ctx, req.(*CreateSearchDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_UpdateSearchDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateSearchDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).UpdateSearchDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_UpdateSearchDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).UpdateSearchDomain(ctx, req.(*UpdateSearchDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteSearchDomain_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteSearchDomainRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DeleteSearchDomain(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DeleteSearchDomain_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DeleteSearchDomain(ctx, req.(*DeleteSearchDomainRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_ListDERPRegions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListDERPRegionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).ListDERPRegions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_ListDERPRegions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).ListDERPRegions(ctx, req.(*ListDERPRegionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_CreateDERPRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDERPRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).CreateDERPRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_CreateDERPRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).CreateDERPRegion(ctx, req.(*CreateDERPRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteDERPRegion_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDERPRegionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DeleteDERPRegion(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DeleteDERPRegion_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DeleteDERPRegion(ctx, req.(*DeleteDERPRegionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_SetDERPRegionDisabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDERPRegionDisabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).SetDERPRegionDisabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_SetDERPRegionDisabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).SetDERPRegionDisabled(ctx, req.(*SetDERPRegionDisabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_CreateDERPNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDERPNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).CreateDERPNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_CreateDERPNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).CreateDERPNode(ctx, req.(*CreateDERPNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_DeleteDERPNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDERPNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).DeleteDERPNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_DeleteDERPNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).DeleteDERPNode(ctx, req.(*DeleteDERPNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _HeadscaleService_SetDERPNodeDisabled_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDERPNodeDisabledRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(HeadscaleServiceServer).SetDERPNodeDisabled(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: HeadscaleService_SetDERPNodeDisabled_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(HeadscaleServiceServer).SetDERPNodeDisabled(ctx, req.(*SetDERPNodeDisabledRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // HeadscaleService_ServiceDesc is the grpc.ServiceDesc for HeadscaleService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -919,6 +1913,10 @@ var HeadscaleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListUsers",
 			Handler:    _HeadscaleService_ListUsers_Handler,
 		},
+		{
+			MethodName: "SetUserDisabled",
+			Handler:    _HeadscaleService_SetUserDisabled_Handler,
+		},
 		{
 			MethodName: "CreatePreAuthKey",
 			Handler:    _HeadscaleService_CreatePreAuthKey_Handler,
@@ -947,6 +1945,10 @@ var HeadscaleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetApprovedRoutes",
 			Handler:    _HeadscaleService_SetApprovedRoutes_Handler,
 		},
+		{
+			MethodName: "SetRoutePriority",
+			Handler:    _HeadscaleService_SetRoutePriority_Handler,
+		},
 		{
 			MethodName: "RegisterNode",
 			Handler:    _HeadscaleService_RegisterNode_Handler,
@@ -971,6 +1973,18 @@ var HeadscaleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MoveNode",
 			Handler:    _HeadscaleService_MoveNode_Handler,
 		},
+		{
+			MethodName: "ListPendingNodes",
+			Handler:    _HeadscaleService_ListPendingNodes_Handler,
+		},
+		{
+			MethodName: "ApproveNode",
+			Handler:    _HeadscaleService_ApproveNode_Handler,
+		},
+		{
+			MethodName: "RejectNode",
+			Handler:    _HeadscaleService_RejectNode_Handler,
+		},
 		{
 			MethodName: "BackfillNodeIPs",
 			Handler:    _HeadscaleService_BackfillNodeIPs_Handler,
@@ -999,7 +2013,105 @@ var HeadscaleService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SetPolicy",
 			Handler:    _HeadscaleService_SetPolicy_Handler,
 		},
+		{
+			MethodName: "ListPolicyRevisions",
+			Handler:    _HeadscaleService_ListPolicyRevisions_Handler,
+		},
+		{
+			MethodName: "DiffPolicyRevisions",
+			Handler:    _HeadscaleService_DiffPolicyRevisions_Handler,
+		},
+		{
+			MethodName: "RollbackPolicy",
+			Handler:    _HeadscaleService_RollbackPolicy_Handler,
+		},
+		{
+			MethodName: "ListAuditEvents",
+			Handler:    _HeadscaleService_ListAuditEvents_Handler,
+		},
+		{
+			MethodName: "ListDNSRecords",
+			Handler:    _HeadscaleService_ListDNSRecords_Handler,
+		},
+		{
+			MethodName: "CreateDNSRecord",
+			Handler:    _HeadscaleService_CreateDNSRecord_Handler,
+		},
+		{
+			MethodName: "UpdateDNSRecord",
+			Handler:    _HeadscaleService_UpdateDNSRecord_Handler,
+		},
+		{
+			MethodName: "DeleteDNSRecord",
+			Handler:    _HeadscaleService_DeleteDNSRecord_Handler,
+		},
+		{
+			MethodName: "ListNameservers",
+			Handler:    _HeadscaleService_ListNameservers_Handler,
+		},
+		{
+			MethodName: "CreateNameserver",
+			Handler:    _HeadscaleService_CreateNameserver_Handler,
+		},
+		{
+			MethodName: "UpdateNameserver",
+			Handler:    _HeadscaleService_UpdateNameserver_Handler,
+		},
+		{
+			MethodName: "DeleteNameserver",
+			Handler:    _HeadscaleService_DeleteNameserver_Handler,
+		},
+		{
+			MethodName: "ListSearchDomains",
+			Handler:    _HeadscaleService_ListSearchDomains_Handler,
+		},
+		{
+			MethodName: "CreateSearchDomain",
+			Handler:    _HeadscaleService_CreateSearchDomain_Handler,
+		},
+		{
+			MethodName: "UpdateSearchDomain",
+			Handler:    _HeadscaleService_UpdateSearchDomain_Handler,
+		},
+		{
+			MethodName: "DeleteSearchDomain",
+			Handler:    _HeadscaleService_DeleteSearchDomain_Handler,
+		},
+		{
+			MethodName: "ListDERPRegions",
+			Handler:    _HeadscaleService_ListDERPRegions_Handler,
+		},
+		{
+			MethodName: "CreateDERPRegion",
+			Handler:    _HeadscaleService_CreateDERPRegion_Handler,
+		},
+		{
+			MethodName: "DeleteDERPRegion",
+			Handler:    _HeadscaleService_DeleteDERPRegion_Handler,
+		},
+		{
+			MethodName: "SetDERPRegionDisabled",
+			Handler:    _HeadscaleService_SetDERPRegionDisabled_Handler,
+		},
+		{
+			MethodName: "CreateDERPNode",
+			Handler:    _HeadscaleService_CreateDERPNode_Handler,
+		},
+		{
+			MethodName: "DeleteDERPNode",
+			Handler:    _HeadscaleService_DeleteDERPNode_Handler,
+		},
+		{
+			MethodName: "SetDERPNodeDisabled",
+			Handler:    _HeadscaleService_SetDERPNodeDisabled_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchNodes",
+			Handler:       _HeadscaleService_WatchNodes_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "headscale/v1/headscale.proto",
 }
@@ -0,0 +1,246 @@
+package hscontrol
+
+import (
+	"context"
+	"path"
+	"slices"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var readOnlyMethods = []string{
+	v1.HeadscaleService_ListUsers_FullMethodName,
+	v1.HeadscaleService_ListPreAuthKeys_FullMethodName,
+	v1.HeadscaleService_GetNode_FullMethodName,
+	v1.HeadscaleService_ListNodes_FullMethodName,
+	v1.HeadscaleService_ListPendingNodes_FullMethodName,
+	v1.HeadscaleService_GetPolicy_FullMethodName,
+	v1.HeadscaleService_ListPolicyRevisions_FullMethodName,
+	v1.HeadscaleService_DiffPolicyRevisions_FullMethodName,
+	v1.HeadscaleService_ListAuditEvents_FullMethodName,
+	v1.HeadscaleService_WatchNodes_FullMethodName,
+	v1.HeadscaleService_ListDNSRecords_FullMethodName,
+	v1.HeadscaleService_ListNameservers_FullMethodName,
+	v1.HeadscaleService_ListSearchDomains_FullMethodName,
+	v1.HeadscaleService_ListDERPRegions_FullMethodName,
+}
+
+// apiKeyScopeMethods lists the API calls allowed for each scope, admin
+// keys can make all calls.
+var apiKeyScopeMethods = map[types.APIKeyScope][]string{
+	types.APIKeyScopeReadOnly: readOnlyMethods,
+	types.APIKeyScopeNodeOperator: append(slices.Clone(readOnlyMethods),
+		v1.HeadscaleService_RegisterNode_FullMethodName,
+		v1.HeadscaleService_SetTags_FullMethodName,
+		v1.HeadscaleService_SetApprovedRoutes_FullMethodName,
+		v1.HeadscaleService_SetRoutePriority_FullMethodName,
+		v1.HeadscaleService_DeleteNode_FullMethodName,
+		v1.HeadscaleService_ExpireNode_FullMethodName,
+		v1.HeadscaleService_RenameNode_FullMethodName,
+		v1.HeadscaleService_MoveNode_FullMethodName,
+		v1.HeadscaleService_BackfillNodeIPs_FullMethodName,
+		v1.HeadscaleService_ApproveNode_FullMethodName,
+		v1.HeadscaleService_RejectNode_FullMethodName,
+	),
+	types.APIKeyScopeKeyIssuer: {
+		v1.HeadscaleService_ListUsers_FullMethodName,
+		v1.HeadscaleService_CreatePreAuthKey_FullMethodName,
+		v1.HeadscaleService_ExpirePreAuthKey_FullMethodName,
+		v1.HeadscaleService_ListPreAuthKeys_FullMethodName,
+	},
+	types.APIKeyScopePolicyAdmin: append(slices.Clone(readOnlyMethods),
+		v1.HeadscaleService_SetPolicy_FullMethodName,
+		v1.HeadscaleService_RollbackPolicy_FullMethodName,
+	),
+}
+
+// apiKeyScopeAllows reports whether a key with the given scope can call
+// the given method.
+func apiKeyScopeAllows(scope types.APIKeyScope, method string) bool {
+	if scope == types.APIKeyScopeAdmin {
+		return true
+	}
+
+	return slices.Contains(apiKeyScopeMethods[scope], method)
+}
+
+// grpcAPIKeyScopeInterceptor enforces the scope and the user restriction
+// of the API key used for the request. Requests without an API key come
+// from the local socket and have full access.
+func (h *Headscale) grpcAPIKeyScopeInterceptor(ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	prefix, ok := requestAPIKeyPrefix(ctx)
+	if !ok {
+		return handler(ctx, req)
+	}
+
+	key, err := h.db.GetAPIKey(prefix)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	method := path.Base(info.FullMethod)
+	if !apiKeyScopeAllows(key.EffectiveScope(), info.FullMethod) {
+		return nil, status.Errorf(
+			codes.PermissionDenied,
+			"API key with scope %q cannot call %s",
+			key.EffectiveScope(),
+			method,
+		)
+	}
+
+	if !key.IsUserRestricted() {
+		return handler(ctx, req)
+	}
+
+	if err := h.checkAPIKeyUsers(key, method, req); err != nil {
+		return nil, err
+	}
+
+	resp, err := handler(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return filterAPIKeyResponse(key, resp), nil
+}
+
+// grpcStreamAPIKeyScopeInterceptor is the streaming counterpart of
+// grpcAPIKeyScopeInterceptor.
+func (h *Headscale) grpcStreamAPIKeyScopeInterceptor(srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	prefix, ok := requestAPIKeyPrefix(stream.Context())
+	if !ok {
+		return handler(srv, stream)
+	}
+
+	key, err := h.db.GetAPIKey(prefix)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	if !apiKeyScopeAllows(key.EffectiveScope(), info.FullMethod) {
+		return status.Errorf(
+			codes.PermissionDenied,
+			"API key with scope %q cannot call %s",
+			key.EffectiveScope(),
+			path.Base(info.FullMethod),
+		)
+	}
+
+	if !key.IsUserRestricted() {
+		return handler(srv, stream)
+	}
+
+	return handler(srv, &apiKeyFilteredStream{ServerStream: stream, key: key})
+}
+
+// apiKeyFilteredStream drops the messages about users a key restricted
+// to a set of users cannot see.
+type apiKeyFilteredStream struct {
+	grpc.ServerStream
+	key *types.APIKey
+}
+
+func (s *apiKeyFilteredStream) SendMsg(m interface{}) error {
+	if event, ok := m.(*v1.NodeEvent); ok && !s.key.CanAccessUser(event.GetNode().GetUser().GetId()) {
+		return nil
+	}
+
+	return s.ServerStream.SendMsg(m)
+}
+
+// checkAPIKeyUsers checks that a key restricted to a set of users only
+// acts on these users.
+func (h *Headscale) checkAPIKeyUsers(key *types.APIKey, method string, req interface{}) error {
+	denied := status.Errorf(
+		codes.PermissionDenied,
+		"API key is not allowed to call %s for this user",
+		method,
+	)
+
+	nodeUser := func(id uint64) (uint64, bool) {
+		node, err := h.db.GetNodeByID(types.NodeID(id))
+		if err != nil {
+			return 0, false
+		}
+
+		return uint64(node.UserID), true
+	}
+
+	var userIDs []uint64
+	switch r := req.(type) {
+	case *v1.ListUsersRequest, *v1.ListNodesRequest, *v1.ListPendingNodesRequest:
+		// The response is filtered.
+		return nil
+	case *v1.CreatePreAuthKeyRequest:
+		userIDs = append(userIDs, r.GetUser())
+	case *v1.ExpirePreAuthKeyRequest:
+		userIDs = append(userIDs, r.GetUser())
+	case *v1.ListPreAuthKeysRequest:
+		userIDs = append(userIDs, r.GetUser())
+	case *v1.RegisterNodeRequest:
+		user, err := h.db.GetUserByName(r.GetUser())
+		if err != nil {
+			return denied
+		}
+		userIDs = append(userIDs, uint64(user.ID))
+	case *v1.MoveNodeRequest:
+		id, ok := nodeUser(r.GetNodeId())
+		if !ok {
+			return denied
+		}
+		userIDs = append(userIDs, id, r.GetUser())
+	case interface{ GetNodeId() uint64 }:
+		id, ok := nodeUser(r.GetNodeId())
+		if !ok {
+			return denied
+		}
+		userIDs = append(userIDs, id)
+	default:
+		return status.Errorf(
+			codes.PermissionDenied,
+			"API key restricted to users cannot call %s",
+			method,
+		)
+	}
+
+	for _, id := range userIDs {
+		if !key.CanAccessUser(id) {
+			return denied
+		}
+	}
+
+	return nil
+}
+
+// filterAPIKeyResponse removes the users and nodes a key restricted to a
+// set of users cannot see from list responses.
+func filterAPIKeyResponse(key *types.APIKey, resp interface{}) interface{} {
+	switch r := resp.(type) {
+	case *v1.ListUsersResponse:
+		r.Users = slices.DeleteFunc(r.Users, func(user *v1.User) bool {
+			return !key.CanAccessUser(user.GetId())
+		})
+	case *v1.ListNodesResponse:
+		r.Nodes = slices.DeleteFunc(r.Nodes, func(node *v1.Node) bool {
+			return !key.CanAccessUser(node.GetUser().GetId())
+		})
+	case *v1.ListPendingNodesResponse:
+		r.Nodes = slices.DeleteFunc(r.Nodes, func(node *v1.Node) bool {
+			return !key.CanAccessUser(node.GetUser().GetId())
+		})
+	}
+
+	return resp
+}
@@ -0,0 +1,95 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCAPIKeyScopeInterceptor(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+
+	user1, err := h.db.CreateUser(types.User{Name: "user1"})
+	require.NoError(t, err)
+	user2, err := h.db.CreateUser(types.User{Name: "user2"})
+	require.NoError(t, err)
+
+	keyCtx := func(scope types.APIKeyScope, userIDs ...uint64) context.Context {
+		keyStr, _, err := h.db.CreateScopedAPIKey(nil, scope, userIDs)
+		require.NoError(t, err)
+
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+			"authorization", AuthPrefix+keyStr,
+		))
+	}
+
+	// call runs the request through the interceptor into the API server.
+	call := func(ctx context.Context, method string, req interface{}) (interface{}, error) {
+		return h.grpcAPIKeyScopeInterceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method},
+			func(ctx context.Context, req interface{}) (interface{}, error) {
+				switch r := req.(type) {
+				case *v1.CreatePreAuthKeyRequest:
+					return api.CreatePreAuthKey(ctx, r)
+				case *v1.ListUsersRequest:
+					return api.ListUsers(ctx, r)
+				case *v1.DeleteUserRequest:
+					return api.DeleteUser(ctx, r)
+				case *v1.GetPolicyRequest:
+					return nil, nil
+				}
+
+				t.Fatalf("unexpected request %T", req)
+
+				return nil, nil
+			})
+	}
+
+	createKeyReq := func(user uint) *v1.CreatePreAuthKeyRequest {
+		return &v1.CreatePreAuthKeyRequest{User: uint64(user)}
+	}
+
+	keyIssuer := keyCtx(types.APIKeyScopeKeyIssuer)
+	_, err = call(keyIssuer, v1.HeadscaleService_CreatePreAuthKey_FullMethodName, createKeyReq(user1.ID))
+	require.NoError(t, err)
+
+	_, err = call(keyIssuer, v1.HeadscaleService_DeleteUser_FullMethodName, &v1.DeleteUserRequest{Id: uint64(user2.ID)})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	readOnly := keyCtx(types.APIKeyScopeReadOnly)
+	_, err = call(readOnly, v1.HeadscaleService_GetPolicy_FullMethodName, &v1.GetPolicyRequest{})
+	require.NoError(t, err)
+
+	_, err = call(readOnly, v1.HeadscaleService_CreatePreAuthKey_FullMethodName, createKeyReq(user1.ID))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// A key restricted to user1 only acts on and sees user1.
+	restricted := keyCtx(types.APIKeyScopeKeyIssuer, uint64(user1.ID))
+	_, err = call(restricted, v1.HeadscaleService_CreatePreAuthKey_FullMethodName, createKeyReq(user1.ID))
+	require.NoError(t, err)
+
+	_, err = call(restricted, v1.HeadscaleService_CreatePreAuthKey_FullMethodName, createKeyReq(user2.ID))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	resp, err := call(restricted, v1.HeadscaleService_ListUsers_FullMethodName, &v1.ListUsersRequest{})
+	require.NoError(t, err)
+	users := resp.(*v1.ListUsersResponse).GetUsers()
+	require.Len(t, users, 1)
+	assert.Equal(t, "user1", users[0].GetName())
+
+	restrictedAdmin := keyCtx(types.APIKeyScopeAdmin, uint64(user1.ID))
+	_, err = call(restrictedAdmin, v1.HeadscaleService_GetPolicy_FullMethodName, &v1.GetPolicyRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Requests without an API key come from the local socket.
+	_, err = call(context.Background(), v1.HeadscaleService_DeleteUser_FullMethodName, &v1.DeleteUserRequest{Id: uint64(user2.ID)})
+	require.NoError(t, err)
+}
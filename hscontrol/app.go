@@ -6,13 +6,16 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net"
 	"net/http"
 	_ "net/http/pprof" // nolint
+	"net/netip"
 	"os"
 	"os/signal"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
@@ -24,6 +27,7 @@ import (
 	grpcRuntime "github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/juanfont/headscale"
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/audit"
 	"github.com/juanfont/headscale/hscontrol/capver"
 	"github.com/juanfont/headscale/hscontrol/db"
 	"github.com/juanfont/headscale/hscontrol/derp"
@@ -35,6 +39,7 @@ import (
 	"github.com/juanfont/headscale/hscontrol/routes"
 	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/juanfont/headscale/hscontrol/webhook"
 	zerolog "github.com/philip-bui/grpc-zerolog"
 	"github.com/pkg/profile"
 	zl "github.com/rs/zerolog"
@@ -90,11 +95,27 @@ type Headscale struct {
 	DERPMap    *tailcfg.DERPMap
 	DERPServer *derpServer.DERPServer
 
+	// baseDERPMap is the DERP map of the configuration, the DERP regions
+	// and nodes of the database and the probe results are applied to it
+	// to build DERPMap.
+	baseDERPMap *tailcfg.DERPMap
+	derpMu      sync.Mutex
+
+	// derpProber probes the servers of the DERP map, it is nil if probing
+	// is disabled.
+	derpProber *derp.Prober
+
 	polManOnce     sync.Once
 	polMan         policy.PolicyManager
 	extraRecordMan *dns.ExtraRecordsMan
 	primaryRoutes  *routes.PrimaryRoutes
 
+	// baseDNSConfig is the DNS configuration of the configuration file,
+	// the extra records file and the DNS settings of the database are
+	// added to it to build cfg.TailcfgDNS.
+	baseDNSConfig *tailcfg.DNSConfig
+	dnsMu         sync.Mutex
+
 	mapper       *mapper.Mapper
 	nodeNotifier *notifier.Notifier
 
@@ -102,6 +123,27 @@ type Headscale struct {
 
 	authProvider AuthProvider
 
+	// auditLog records the changes made by administrators and node
+	// registrations.
+	auditLog *audit.Logger
+
+	// webhooks delivers the tailnet events to the configured endpoints,
+	// it is nil if there are none.
+	webhooks *webhook.Dispatcher
+
+	// nodeWatcher emits the node events for the WatchNodes API.
+	nodeWatcher *nodeWatcher
+
+	// derpAdmission decides which nodes may connect to the DERP servers
+	// verifying their clients with headscale.
+	derpAdmission *derpAdmission
+
+	// tkaMu serialises the changes to the tailnet lock state.
+	tkaMu sync.Mutex
+	// tkaState is the tailnet lock state, guarded by tkaMu. It is kept in
+	// memory to not read it from the database for every map response.
+	tkaState *types.TKAState
+
 	pollNetMapStreamWG sync.WaitGroup
 }
 
@@ -136,7 +178,7 @@ func NewHeadscale(cfg *types.Config) (*Headscale, error) {
 		registrationCache:  registrationCache,
 		pollNetMapStreamWG: sync.WaitGroup{},
 		nodeNotifier:       notifier.NewNotifier(cfg),
-		primaryRoutes:      routes.New(),
+		primaryRoutes:      routes.NewWithConfig(cfg.Routes.Failover),
 	}
 
 	app.db, err = db.NewHeadscaleDatabase(
@@ -148,6 +190,37 @@ func NewHeadscale(cfg *types.Config) (*Headscale, error) {
 		return nil, fmt.Errorf("new database: %w", err)
 	}
 
+	app.tkaState, err = app.db.GetTKAState()
+	if err != nil {
+		return nil, fmt.Errorf("loading tailnet lock state: %w", err)
+	}
+
+	auditSinks := []audit.Sink{app.db}
+	if cfg.Audit.FilePath != "" {
+		fileSink, err := audit.NewFileSink(cfg.Audit.FilePath)
+		if err != nil {
+			return nil, err
+		}
+		auditSinks = append(auditSinks, fileSink)
+	}
+	if len(cfg.Webhooks.Endpoints) > 0 {
+		app.webhooks = webhook.NewDispatcher(app.db, cfg.Webhooks)
+		auditSinks = append(auditSinks, app.webhooks)
+	}
+	app.auditLog = audit.NewLogger(auditSinks...)
+
+	app.primaryRoutes.ObserveFailovers(func(event routes.FailoverEvent) {
+		log.Warn().
+			Str("route", event.Prefix.String()).
+			Uint64("from.node.id", event.From.Uint64()).
+			Uint64("to.node.id", event.To.Uint64()).
+			Str("reason", string(event.Reason)).
+			Msg("primary route failed over")
+	})
+
+	app.nodeWatcher = newNodeWatcher(&app)
+	app.nodeNotifier.Observe(app.nodeWatcher.observe)
+
 	app.ipAlloc, err = db.NewIPAllocator(app.db, cfg.PrefixV4, cfg.PrefixV6, cfg.IPAllocation)
 	if err != nil {
 		return nil, err
@@ -165,26 +238,40 @@ func NewHeadscale(cfg *types.Config) (*Headscale, error) {
 
 	var authProvider AuthProvider
 	authProvider = NewAuthProviderWeb(cfg.ServerURL)
-	if cfg.OIDC.Issuer != "" {
+	if len(cfg.OIDCProviders) > 0 {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		oidcProvider, err := NewAuthProviderOIDC(
-			ctx,
-			cfg.ServerURL,
-			&cfg.OIDC,
-			app.db,
-			app.nodeNotifier,
-			app.ipAlloc,
-			app.polMan,
-		)
-		if err != nil {
-			if cfg.OIDC.OnlyStartIfOIDCIsAvailable {
-				return nil, err
-			} else {
-				log.Warn().Err(err).Msg("failed to set up OIDC provider, falling back to CLI based authentication")
+
+		var oidcProviders []*AuthProviderOIDC
+		for i := range cfg.OIDCProviders {
+			oidcProvider, err := NewAuthProviderOIDC(
+				ctx,
+				cfg.ServerURL,
+				&cfg.OIDCProviders[i],
+				cfg.NodeApproval.Enabled,
+				app.db,
+				app.nodeNotifier,
+				app.ipAlloc,
+				app.polMan,
+				app.auditLog,
+			)
+			if err != nil {
+				if cfg.OIDC.OnlyStartIfOIDCIsAvailable {
+					return nil, err
+				}
+
+				log.Warn().Err(err).Str("provider", cfg.OIDCProviders[i].Name).Msg("failed to set up OIDC provider")
+
+				continue
 			}
+
+			oidcProviders = append(oidcProviders, oidcProvider)
+		}
+
+		if len(oidcProviders) > 0 {
+			authProvider = NewAuthProviderOIDCSet(cfg.ServerURL, oidcProviders)
 		} else {
-			authProvider = oidcProvider
+			log.Warn().Msg("no OIDC provider could be set up, falling back to CLI based authentication")
 		}
 	}
 	app.authProvider = authProvider
@@ -212,6 +299,17 @@ func NewHeadscale(cfg *types.Config) (*Headscale, error) {
 			app.cfg.TailcfgDNSConfig.Routes[d.WithoutTrailingDot()] = nil
 		}
 	}
+	app.baseDNSConfig = app.cfg.TailcfgDNSConfig.Clone()
+
+	if cfg.DERP.Probe.Enabled {
+		app.derpProber = derp.NewProber(cfg.DERP.Probe.Timeout)
+	}
+
+	app.derpAdmission = newDERPAdmission(&app)
+	if app.derpProber != nil {
+		app.derpAdmission.allowKeys[app.derpProber.PublicKey()] = true
+	}
+	app.nodeNotifier.Observe(app.derpAdmission.observe)
 
 	if cfg.DERP.ServerEnabled {
 		derpServerKey, err := readOrCreatePrivateKey(cfg.DERP.ServerPrivateKeyPath)
@@ -234,6 +332,13 @@ func NewHeadscale(cfg *types.Config) (*Headscale, error) {
 		if err != nil {
 			return nil, err
 		}
+		if cfg.DERP.ServerVerifyClients {
+			verifyURL, err := derpVerifyURL(cfg.ServerURL, cfg.DERP.ServerRegionID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to verify the clients of the embedded DERP server: %w", err)
+			}
+			embeddedDERPServer.VerifyClients(verifyURL)
+		}
 		app.DERPServer = embeddedDERPServer
 	}
 
@@ -252,6 +357,20 @@ func (h *Headscale) scheduledTasks(ctx context.Context) {
 
 	lastExpiryCheck := time.Unix(0, 0)
 
+	keyExpiryTickerChan := make(<-chan time.Time)
+	if h.webhooks != nil {
+		keyExpiryTicker := time.NewTicker(keyExpiryCheckInterval)
+		defer keyExpiryTicker.Stop()
+		keyExpiryTickerChan = keyExpiryTicker.C
+	}
+
+	webhookPruneTickerChan := make(<-chan time.Time)
+	if h.cfg.Webhooks.Retention > 0 {
+		webhookPruneTicker := time.NewTicker(webhookPruneInterval)
+		defer webhookPruneTicker.Stop()
+		webhookPruneTickerChan = webhookPruneTicker.C
+	}
+
 	derpTickerChan := make(<-chan time.Time)
 	if h.cfg.DERP.AutoUpdate && h.cfg.DERP.UpdateFrequency != 0 {
 		derpTicker := time.NewTicker(h.cfg.DERP.UpdateFrequency)
@@ -292,25 +411,43 @@ func (h *Headscale) scheduledTasks(ctx context.Context) {
 				h.nodeNotifier.NotifyAll(ctx, update)
 			}
 
+			// Fail over the routes of primaries that have not been
+			// seen for too long.
+			if h.primaryRoutes.CheckHealth() {
+				ctx := types.NotifyCtx(context.Background(), "poll-primary-change", "na")
+				h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+			}
+
+		case <-keyExpiryTickerChan:
+			h.notifyExpiringNodes()
+
+		case <-webhookPruneTickerChan:
+			h.pruneWebhookDeliveries()
+
 		case <-derpTickerChan:
 			log.Info().Msg("Fetching DERPMap updates")
-			h.DERPMap = derp.GetDERPMap(h.cfg.DERP)
+			derpMap := derp.GetDERPMap(h.cfg.DERP)
 			if h.cfg.DERP.ServerEnabled && h.cfg.DERP.AutomaticallyAddEmbeddedDerpRegion {
 				region, _ := h.DERPServer.GenerateRegion()
-				h.DERPMap.Regions[region.RegionID] = &region
+				derpMap.Regions[region.RegionID] = &region
 			}
 
-			ctx := types.NotifyCtx(context.Background(), "derpmap-update", "na")
-			h.nodeNotifier.NotifyAll(ctx, types.StateUpdate{
-				Type:    types.StateDERPUpdated,
-				DERPMap: h.DERPMap,
-			})
+			h.derpMu.Lock()
+			h.baseDERPMap = derpMap
+			h.derpMu.Unlock()
+
+			if err := h.notifyDERPChange(context.Background()); err != nil {
+				log.Error().Err(err).Msg("failed to update DERP map")
+			}
 
-		case records, ok := <-extraRecordsUpdate:
+		case _, ok := <-extraRecordsUpdate:
 			if !ok {
 				continue
 			}
-			h.cfg.TailcfgDNSConfig.ExtraRecords = records
+			if err := h.updateDNSConfig(); err != nil {
+				log.Error().Err(err).Msg("failed to update DNS configuration")
+				continue
+			}
 
 			ctx := types.NotifyCtx(context.Background(), "dns-extrarecord", "all")
 			// TODO(kradalby): We can probably do better than sending a full update here,
@@ -325,6 +462,27 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 	info *grpc.UnaryServerInfo,
 	handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	if err := h.grpcAuthenticate(ctx); err != nil {
+		return nil, err
+	}
+
+	return handler(ctx, req)
+}
+
+func (h *Headscale) grpcStreamAuthenticationInterceptor(srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	if err := h.grpcAuthenticate(stream.Context()); err != nil {
+		return err
+	}
+
+	return handler(srv, stream)
+}
+
+// grpcAuthenticate validates the API key of a gRPC request.
+func (h *Headscale) grpcAuthenticate(ctx context.Context) error {
 	// Check if the request is coming from the on-server client.
 	// This is not secure, but it is to maintain maintainability
 	// with the "legacy" database-based client
@@ -339,7 +497,7 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 
 	meta, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
-		return ctx, status.Errorf(
+		return status.Errorf(
 			codes.InvalidArgument,
 			"Retrieving metadata is failed",
 		)
@@ -347,7 +505,7 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 
 	authHeader, ok := meta["authorization"]
 	if !ok {
-		return ctx, status.Errorf(
+		return status.Errorf(
 			codes.Unauthenticated,
 			"Authorization token is not supplied",
 		)
@@ -356,7 +514,7 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 	token := authHeader[0]
 
 	if !strings.HasPrefix(token, AuthPrefix) {
-		return ctx, status.Error(
+		return status.Error(
 			codes.Unauthenticated,
 			`missing "Bearer " prefix in "Authorization" header`,
 		)
@@ -364,7 +522,7 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 
 	valid, err := h.db.ValidateAPIKey(strings.TrimPrefix(token, AuthPrefix))
 	if err != nil {
-		return ctx, status.Error(codes.Internal, "failed to validate token")
+		return status.Error(codes.Internal, "failed to validate token")
 	}
 
 	if !valid {
@@ -372,10 +530,10 @@ func (h *Headscale) grpcAuthenticationInterceptor(ctx context.Context,
 			Str("client_address", client.Addr.String()).
 			Msg("invalid token")
 
-		return ctx, status.Error(codes.Unauthenticated, "invalid token")
+		return status.Error(codes.Unauthenticated, "invalid token")
 	}
 
-	return handler(ctx, req)
+	return nil
 }
 
 func (h *Headscale) httpAuthenticationMiddleware(next http.Handler) http.Handler {
@@ -471,8 +629,8 @@ func (h *Headscale) createRouter(grpcMux *grpcRuntime.ServeMux) *mux.Router {
 	router.HandleFunc("/register/{registration_id}", h.authProvider.RegisterHandler).
 		Methods(http.MethodGet)
 
-	if provider, ok := h.authProvider.(*AuthProviderOIDC); ok {
-		router.HandleFunc("/oidc/callback", provider.OIDCCallbackHandler).Methods(http.MethodGet)
+	if providers, ok := h.authProvider.(*AuthProviderOIDCSet); ok {
+		router.HandleFunc("/oidc/callback", providers.OIDCCallbackHandler).Methods(http.MethodGet)
 	}
 	router.HandleFunc("/apple", h.AppleConfigMessage).Methods(http.MethodGet)
 	router.HandleFunc("/apple/{platform}", h.ApplePlatformConfig).
@@ -495,8 +653,12 @@ func (h *Headscale) createRouter(grpcMux *grpcRuntime.ServeMux) *mux.Router {
 
 	apiRouter := router.PathPrefix("/api").Subrouter()
 	apiRouter.Use(h.httpAuthenticationMiddleware)
+	apiRouter.HandleFunc("/v1/node/events", h.NodeEventsHandler).
+		Methods(http.MethodGet)
 	apiRouter.PathPrefix("/v1/").HandlerFunc(grpcMux.ServeHTTP)
 
+	h.registerSCIMRoutes(router)
+
 	router.PathPrefix("/").HandlerFunc(notFoundHandler)
 
 	return router
@@ -581,8 +743,7 @@ func (h *Headscale) Serve() error {
 		Msg("Clients with a lower minimum version will be rejected")
 
 	// Fetch an initial DERP Map before we start serving
-	h.DERPMap = derp.GetDERPMap(h.cfg.DERP)
-	h.mapper = mapper.NewMapper(h.db, h.cfg, h.DERPMap, h.nodeNotifier, h.polMan, h.primaryRoutes)
+	h.baseDERPMap = derp.GetDERPMap(h.cfg.DERP)
 
 	if h.cfg.DERP.ServerEnabled {
 		// When embedded DERP is enabled we always need a STUN server
@@ -596,16 +757,25 @@ func (h *Headscale) Serve() error {
 		}
 
 		if h.cfg.DERP.AutomaticallyAddEmbeddedDerpRegion {
-			h.DERPMap.Regions[region.RegionID] = &region
+			h.baseDERPMap.Regions[region.RegionID] = &region
 		}
 
 		go h.DERPServer.ServeSTUN()
 	}
 
-	if len(h.DERPMap.Regions) == 0 {
+	if len(h.baseDERPMap.Regions) == 0 {
 		return errEmptyInitialDERPMap
 	}
 
+	derpMap, err := h.updateDERPMap()
+	if err != nil {
+		return err
+	}
+	h.mapper = mapper.NewMapper(h.db, h.cfg, derpMap, h.nodeNotifier, h.polMan, h.primaryRoutes)
+	h.tkaMu.Lock()
+	h.mapper.SetTKAInfo(h.tkaState.TKAInfo())
+	h.tkaMu.Unlock()
+
 	// Start ephemeral node garbage collector and schedule all nodes
 	// that are already in the database and ephemeral. If they are still
 	// around between restarts, they will reconnect and the GC will
@@ -624,17 +794,40 @@ func (h *Headscale) Serve() error {
 		if err != nil {
 			return fmt.Errorf("setting up extrarecord manager: %w", err)
 		}
-		h.cfg.TailcfgDNSConfig.ExtraRecords = h.extraRecordMan.Records()
 		go h.extraRecordMan.Run()
 		defer h.extraRecordMan.Close()
 	}
 
+	if err := h.updateDNSConfig(); err != nil {
+		return fmt.Errorf("loading DNS configuration: %w", err)
+	}
+
 	// Start all scheduled tasks, e.g. expiring nodes, derp updates and
 	// records updates
 	scheduleCtx, scheduleCancel := context.WithCancel(context.Background())
 	defer scheduleCancel()
 	go h.scheduledTasks(scheduleCtx)
 
+	if h.webhooks != nil {
+		go h.webhooks.Run(scheduleCtx)
+	}
+
+	if h.derpProber != nil {
+		go h.probeDERP(scheduleCtx)
+	}
+
+	if providers, ok := h.authProvider.(*AuthProviderOIDCSet); ok {
+		for _, provider := range providers.Providers() {
+			if provider.tokens != nil {
+				go provider.revalidateUsers(scheduleCtx)
+			}
+		}
+	}
+
+	if h.DERPServer != nil {
+		h.DERPServer.ServeMesh(scheduleCtx)
+	}
+
 	if zl.GlobalLevel() == zl.TraceLevel {
 		zerolog.RespLog = true
 	} else {
@@ -696,9 +889,17 @@ func (h *Headscale) Serve() error {
 	}
 
 	// Start the local gRPC server without TLS and without authentication
+	// API keys are validated by the HTTP middleware before the requests
+	// reach the socket through the gateway, their scope is enforced here.
 	grpcSocket := grpc.NewServer(
-	// Uncomment to debug grpc communication.
-	// zerolog.UnaryInterceptor(),
+		grpc.UnaryInterceptor(
+			grpcMiddleware.ChainUnaryServer(
+				h.grpcAPIKeyScopeInterceptor,
+				// Uncomment to debug grpc communication.
+				// zerolog.UnaryInterceptor(),
+			),
+		),
+		grpc.StreamInterceptor(h.grpcStreamAPIKeyScopeInterceptor),
 	)
 
 	v1.RegisterHeadscaleServiceServer(grpcSocket, newHeadscaleV1APIServer(h))
@@ -737,10 +938,17 @@ func (h *Headscale) Serve() error {
 			grpc.UnaryInterceptor(
 				grpcMiddleware.ChainUnaryServer(
 					h.grpcAuthenticationInterceptor,
+					h.grpcAPIKeyScopeInterceptor,
 					// Uncomment to debug grpc communication.
 					// zerolog.NewUnaryServerInterceptor(),
 				),
 			),
+			grpc.StreamInterceptor(
+				grpcMiddleware.ChainStreamServer(
+					h.grpcStreamAuthenticationInterceptor,
+					h.grpcStreamAPIKeyScopeInterceptor,
+				),
+			),
 		}
 
 		if tlsConfig != nil {
@@ -1169,9 +1377,10 @@ func (h *Headscale) loadPolicyManager() error {
 	return errOut
 }
 
-// autoApproveNodes mass approves routes on all nodes. It is _only_ intended for
-// use when the policy is replaced. It is not sending or reporting any changes
-// or updates as we send full updates after replacing the policy.
+// autoApproveNodes mass approves routes on all nodes and updates their route
+// priorities and regions. It is _only_ intended for use when the policy is replaced. It is
+// not sending or reporting any changes or updates as we send full updates after
+// replacing the policy.
 // TODO(kradalby): This is kind of messy, maybe this is another +1
 // for an event bus. See example comments here.
 func (h *Headscale) autoApproveNodes() error {
@@ -1182,6 +1391,9 @@ func (h *Headscale) autoApproveNodes() error {
 		}
 
 		for _, node := range nodes {
+			h.primaryRoutes.SetRegion(node.ID, h.nodeRegion(node))
+			h.primaryRoutes.SetPriorities(node.ID, h.routePriorities(node))
+
 			changed := policy.AutoApproveRoutes(h.polMan, node)
 			if changed {
 				err = tx.Save(node).Error
@@ -1189,6 +1401,7 @@ func (h *Headscale) autoApproveNodes() error {
 					return err
 				}
 
+				h.primaryRoutes.SetPriorities(node.ID, h.routePriorities(node))
 				h.primaryRoutes.SetRoutes(node.ID, node.SubnetRoutes()...)
 			}
 		}
@@ -1201,3 +1414,262 @@ func (h *Headscale) autoApproveNodes() error {
 
 	return nil
 }
+
+// routePriorities returns the priorities of the node to be the primary router
+// of its subnet routes, set by an operator and given by the policy.
+func (h *Headscale) routePriorities(node *types.Node) map[netip.Prefix]routes.Priority {
+	var priorities map[netip.Prefix]routes.Priority
+	for _, prefix := range node.SubnetRoutes() {
+		priority := routes.Priority{
+			Operator: node.RoutePriorities[prefix],
+			Policy:   h.polMan.RoutePriority(node, prefix),
+		}
+		if priority == (routes.Priority{}) {
+			continue
+		}
+
+		if priorities == nil {
+			priorities = make(map[netip.Prefix]routes.Priority)
+		}
+		priorities[prefix] = priority
+	}
+
+	return priorities
+}
+
+// nodeRegion returns the region of the node used to steer it to the subnet
+// routers of its region: the region given by the policy, else the region of
+// its preferred DERP server if enabled.
+func (h *Headscale) nodeRegion(node *types.Node) string {
+	if region := h.polMan.NodeRegion(node); region != "" {
+		return region
+	}
+
+	if h.cfg.Routes.Steering.DERPRegion && node.Hostinfo != nil &&
+		node.Hostinfo.NetInfo != nil && node.Hostinfo.NetInfo.PreferredDERP != 0 {
+		return fmt.Sprintf("derp:%d", node.Hostinfo.NetInfo.PreferredDERP)
+	}
+
+	return ""
+}
+
+// updateDNSConfig builds the DNS configuration sent to the nodes from the
+// configuration file, the extra records file and the DNS settings managed
+// through the API.
+func (h *Headscale) updateDNSConfig() error {
+	if h.baseDNSConfig == nil {
+		return nil
+	}
+
+	h.dnsMu.Lock()
+	defer h.dnsMu.Unlock()
+
+	settings, err := h.db.GetDNSSettings()
+	if err != nil {
+		return fmt.Errorf("loading DNS settings: %w", err)
+	}
+
+	dnsConfig := h.baseDNSConfig.Clone()
+	if h.extraRecordMan != nil {
+		dnsConfig.ExtraRecords = h.extraRecordMan.Records()
+	}
+	settings.Apply(dnsConfig, h.cfg.DNSConfig.OverrideLocalDNS)
+
+	h.cfg.SetTailcfgDNS(dnsConfig)
+
+	return nil
+}
+
+// updateDERPMap builds the DERP map sent to the nodes from the DERP map of
+// the configuration, the DERP regions and nodes of the database and the
+// results of the prober, and returns it.
+func (h *Headscale) updateDERPMap() (*tailcfg.DERPMap, error) {
+	regions, nodes, err := h.db.GetDERPOverrides()
+	if err != nil {
+		return nil, fmt.Errorf("loading DERP regions and nodes: %w", err)
+	}
+
+	h.derpMu.Lock()
+	defer h.derpMu.Unlock()
+
+	derpMap := derp.ApplyOverrides(h.baseDERPMap, regions, nodes)
+	if h.derpProber != nil {
+		h.derpProber.Avoid(derpMap)
+	}
+
+	h.DERPMap = derpMap
+
+	return derpMap, nil
+}
+
+// currentDERPMap returns the DERP map sent to the nodes.
+func (h *Headscale) currentDERPMap() *tailcfg.DERPMap {
+	h.derpMu.Lock()
+	defer h.derpMu.Unlock()
+
+	return h.DERPMap
+}
+
+// notifyDERPChange rebuilds the DERP map and sends it to all nodes.
+func (h *Headscale) notifyDERPChange(ctx context.Context) error {
+	derpMap, err := h.updateDERPMap()
+	if err != nil {
+		return err
+	}
+
+	ctx = types.NotifyCtx(ctx, "derpmap-update", "na")
+	h.nodeNotifier.NotifyAll(ctx, types.StateUpdate{
+		Type:    types.StateDERPUpdated,
+		DERPMap: derpMap,
+	})
+
+	return nil
+}
+
+// derpRegionExists reports if the region is in the DERP map of the
+// configuration or has been created via the API.
+func (h *Headscale) derpRegionExists(regionID int) (bool, error) {
+	h.derpMu.Lock()
+	_, ok := h.baseDERPMap.Regions[regionID]
+	h.derpMu.Unlock()
+	if ok {
+		return true, nil
+	}
+
+	regions, _, err := h.db.GetDERPOverrides()
+	if err != nil {
+		return false, err
+	}
+
+	return slices.ContainsFunc(regions, func(region types.DERPRegion) bool {
+		return region.RegionID == regionID && region.Code != ""
+	}), nil
+}
+
+// derpRegions returns all DERP regions and nodes for the API, including
+// the disabled ones, with the results of the prober.
+func (h *Headscale) derpRegions() ([]*v1.DERPRegion, error) {
+	regions, nodes, err := h.db.GetDERPOverrides()
+	if err != nil {
+		return nil, err
+	}
+
+	h.derpMu.Lock()
+	base, served := h.baseDERPMap, h.DERPMap
+	h.derpMu.Unlock()
+
+	var results map[string]types.DERPProbeResult
+	if h.derpProber != nil {
+		results = h.derpProber.Results()
+	}
+
+	apiRegions := make(map[int]bool)
+	disabledRegions := make(map[int]bool)
+	enabledRegions := make([]types.DERPRegion, len(regions))
+	for i, region := range regions {
+		apiRegions[region.RegionID] = region.Code != ""
+		disabledRegions[region.RegionID] = region.Disabled
+		enabledRegions[i] = region
+		enabledRegions[i].Disabled = false
+	}
+
+	apiNodes := make(map[string]bool)
+	disabledNodes := make(map[string]bool)
+	enabledNodes := make([]types.DERPNode, len(nodes))
+	for i, node := range nodes {
+		apiNodes[node.Name] = node.HostName != ""
+		disabledNodes[node.Name] = node.Disabled
+		enabledNodes[i] = node
+		enabledNodes[i].Disabled = false
+	}
+
+	all := derp.ApplyOverrides(base, enabledRegions, enabledNodes)
+
+	// Regions created via the API without nodes are not in the DERP map.
+	for _, region := range regions {
+		if _, ok := all.Regions[region.RegionID]; !ok && region.Code != "" {
+			all.Regions[region.RegionID] = &tailcfg.DERPRegion{
+				RegionID:   region.RegionID,
+				RegionCode: region.Code,
+				RegionName: region.Name,
+			}
+		}
+	}
+
+	ret := make([]*v1.DERPRegion, 0, len(all.Regions))
+	for _, id := range slices.Sorted(maps.Keys(all.Regions)) {
+		region := all.Regions[id]
+		protoRegion := &v1.DERPRegion{
+			RegionId: int32(id),
+			Code:     region.RegionCode,
+			Name:     region.RegionName,
+			Source:   types.DERPSourceConfig,
+			Disabled: disabledRegions[id],
+			Avoid:    served != nil && served.Regions[id] != nil && served.Regions[id].Avoid,
+		}
+		if apiRegions[id] {
+			protoRegion.Source = types.DERPSourceAPI
+		}
+
+		for _, node := range region.Nodes {
+			protoNode := &v1.DERPNode{
+				Name:     node.Name,
+				RegionId: int32(node.RegionID),
+				HostName: node.HostName,
+				Ipv4:     node.IPv4,
+				Ipv6:     node.IPv6,
+				StunPort: int32(node.STUNPort),
+				DerpPort: int32(node.DERPPort),
+				StunOnly: node.STUNOnly,
+				Source:   types.DERPSourceConfig,
+				Disabled: disabledNodes[node.Name],
+			}
+			if apiNodes[node.Name] {
+				protoNode.Source = types.DERPSourceAPI
+			}
+			if result, ok := results[node.Name]; ok {
+				protoNode.Probe = result.Proto()
+			}
+
+			protoRegion.Nodes = append(protoRegion.Nodes, protoNode)
+		}
+
+		ret = append(ret, protoRegion)
+	}
+
+	return ret, nil
+}
+
+// probeDERP probes the servers of the DERP map periodically and sends the
+// DERP map to the nodes when the regions to avoid change.
+func (h *Headscale) probeDERP(ctx context.Context) {
+	ticker := time.NewTicker(h.cfg.DERP.Probe.Interval)
+	defer ticker.Stop()
+
+	for {
+		if h.derpProber.Probe(ctx, h.currentDERPMap()) {
+			if err := h.notifyDERPChange(ctx); err != nil {
+				log.Error().Err(err).Msg("failed to update DERP map")
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// notifyDNSChange rebuilds the DNS configuration after a change of the DNS
+// settings and sends it to all nodes.
+func (h *Headscale) notifyDNSChange(ctx context.Context) error {
+	if err := h.updateDNSConfig(); err != nil {
+		return err
+	}
+
+	ctx = types.NotifyCtx(ctx, "dns-settings", "all")
+	h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+
+	return nil
+}
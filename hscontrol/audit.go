@@ -0,0 +1,84 @@
+package hscontrol
+
+import (
+	"fmt"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"gorm.io/gorm"
+)
+
+func userTarget(id uint) string {
+	return fmt.Sprintf("user:%d", id)
+}
+
+func userGroupTarget(id uint64) string {
+	return fmt.Sprintf("user-group:%d", id)
+}
+
+func nodeTarget(id types.NodeID) string {
+	return fmt.Sprintf("node:%d", id)
+}
+
+func preAuthKeyTarget(id uint64) string {
+	return fmt.Sprintf("preauthkey:%d", id)
+}
+
+func apiKeyTarget(prefix string) string {
+	return "apikey:" + prefix
+}
+
+func dnsRecordTarget(id uint64) string {
+	return fmt.Sprintf("dns-record:%d", id)
+}
+
+func nameserverTarget(id uint64) string {
+	return fmt.Sprintf("nameserver:%d", id)
+}
+
+func searchDomainTarget(id uint64) string {
+	return fmt.Sprintf("search-domain:%d", id)
+}
+
+func derpRegionTarget(regionID int) string {
+	return fmt.Sprintf("derp-region:%d", regionID)
+}
+
+func derpNodeTarget(name string) string {
+	return "derp-node:" + name
+}
+
+// auditNode returns the state of the node for the audit log, nil if the
+// node does not exist.
+func auditNode(tx *gorm.DB, id types.NodeID) *v1.Node {
+	node, err := db.GetNodeByID(tx, id)
+	if err != nil {
+		return nil
+	}
+
+	return node.Proto()
+}
+
+// auditPreAuthKey returns the state of the pre auth key for the audit log,
+// without the secret key.
+func auditPreAuthKey(pak *types.PreAuthKey) *v1.PreAuthKey {
+	proto := pak.Proto()
+	proto.Key = ""
+
+	return proto
+}
+
+// auditPolicy returns the policy for the audit log, nil if there is no
+// policy.
+func auditPolicy(p *types.Policy) *v1.GetPolicyResponse {
+	if p == nil {
+		return nil
+	}
+
+	return &v1.GetPolicyResponse{
+		Policy:    p.Data,
+		UpdatedAt: timestamppb.New(p.UpdatedAt),
+	}
+}
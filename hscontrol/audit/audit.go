@@ -0,0 +1,72 @@
+// Package audit records the changes made to the tailnet by administrators
+// and by node registrations.
+package audit
+
+import (
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Sink receives the recorded audit events.
+type Sink interface {
+	RecordAuditEvent(event *types.AuditEvent) error
+}
+
+// Logger records audit events to all its sinks.
+type Logger struct {
+	sinks []Sink
+}
+
+// NewLogger returns a Logger recording to the given sinks.
+func NewLogger(sinks ...Sink) *Logger {
+	return &Logger{sinks: sinks}
+}
+
+// Record records that the actor performed the action on the target. The
+// before and after states are stored as JSON, nil means that the target
+// did not exist.
+// Failing to record an event is logged, it does not undo the change.
+func (l *Logger) Record(actor, action, target string, before, after proto.Message) {
+	if l == nil {
+		return
+	}
+
+	event := &types.AuditEvent{
+		CreatedAt: time.Now(),
+		Actor:     actor,
+		Action:    action,
+		Target:    target,
+		Before:    marshal(before),
+		After:     marshal(after),
+	}
+
+	for _, sink := range l.sinks {
+		if err := sink.RecordAuditEvent(event); err != nil {
+			log.Error().
+				Err(err).
+				Str("actor", actor).
+				Str("action", action).
+				Str("target", target).
+				Msg("failed to record audit event")
+		}
+	}
+}
+
+func marshal(msg proto.Message) string {
+	if msg == nil || !msg.ProtoReflect().IsValid() {
+		return ""
+	}
+
+	b, err := protojson.Marshal(msg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal audit event state")
+
+		return ""
+	}
+
+	return string(b)
+}
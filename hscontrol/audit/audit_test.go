@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memSink struct {
+	events []*types.AuditEvent
+	err    error
+}
+
+func (s *memSink) RecordAuditEvent(event *types.AuditEvent) error {
+	s.events = append(s.events, event)
+
+	return s.err
+}
+
+func TestLoggerRecord(t *testing.T) {
+	failing := &memSink{err: errors.New("sink unavailable")}
+	sink := &memSink{}
+	logger := NewLogger(failing, sink)
+
+	var nilNode *v1.Node
+	logger.Record("unix-socket", "user.create", "user:1", nilNode, &v1.User{Id: 1, Name: "alice"})
+
+	// A failing sink does not stop the other sinks.
+	require.Len(t, failing.events, 1)
+	require.Len(t, sink.events, 1)
+
+	event := sink.events[0]
+	assert.Equal(t, "unix-socket", event.Actor)
+	assert.Equal(t, "user.create", event.Action)
+	assert.Equal(t, "user:1", event.Target)
+	assert.Empty(t, event.Before)
+	assert.JSONEq(t, `{"id":"1","name":"alice"}`, event.After)
+	assert.False(t, event.CreatedAt.IsZero())
+
+	// A nil logger records nothing.
+	var nilLogger *Logger
+	nilLogger.Record("unix-socket", "user.delete", "user:1", nil, nil)
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	logger := NewLogger(sink)
+	logger.Record("api-key:abc", "node.delete", "node:1", &v1.Node{Id: 1}, nil)
+	logger.Record("api-key:abc", "node.delete", "node:2", &v1.Node{Id: 2}, nil)
+	require.NoError(t, sink.Close())
+
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 2)
+
+	var event types.AuditEvent
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &event))
+	assert.Equal(t, "api-key:abc", event.Actor)
+	assert.Equal(t, "node:2", event.Target)
+	assert.JSONEq(t, `{"id":"2"}`, event.Before)
+	assert.Empty(t, event.After)
+
+	// The file is appended to when it is opened again.
+	sink, err = NewFileSink(path)
+	require.NoError(t, err)
+	NewLogger(sink).Record("unix-socket", "user.create", "user:1", nil, &v1.User{Id: 1})
+	require.NoError(t, sink.Close())
+
+	b, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(b)), "\n"), 3)
+}
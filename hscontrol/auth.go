@@ -117,10 +117,9 @@ func nodeToRegisterResponse(node *types.Node) *tailcfg.RegisterResponse {
 		Login:          *node.User.TailscaleLogin(),
 		NodeKeyExpired: node.IsExpired(),
 
-		// Headscale does not implement the concept of machine authorization
-		// so we always return true here.
-		// Revisit this if #2176 gets implemented.
-		MachineAuthorized: true,
+		// Nodes waiting for an admin to approve them are not authorized,
+		// the client waits until it is authorized by a map response.
+		MachineAuthorized: !node.PendingApproval,
 	}
 }
 
@@ -161,6 +160,9 @@ func canUsePreAuthKey(pak *types.PreAuthKey) error {
 	if pak.Expiration != nil && pak.Expiration.Before(time.Now()) {
 		return NewHTTPError(http.StatusUnauthorized, "authkey expired", nil)
 	}
+	if pak.User.Disabled {
+		return NewHTTPError(http.StatusForbidden, "user is disabled", nil)
+	}
 
 	// we don't need to check if has been used before
 	if pak.Reusable {
@@ -197,6 +199,8 @@ func (h *Headscale) handleRegisterWithAuthKey(
 		User:           pak.User,
 		MachineKey:     machineKey,
 		NodeKey:        regReq.NodeKey,
+		NLKey:          regReq.NLKey,
+		KeySignature:   regReq.NodeKeySignature,
 		Hostinfo:       regReq.Hostinfo,
 		LastSeen:       ptr.To(time.Now()),
 		RegisterMethod: util.RegisterMethodAuthKey,
@@ -240,6 +244,8 @@ func (h *Headscale) handleRegisterWithAuthKey(
 		return nil, err
 	}
 
+	h.auditLog.Record(preAuthKeyTarget(pak.ID), "node.register", nodeTarget(node.ID), nil, node.Proto())
+
 	updateSent, err := nodesChangedHook(h.db, h.polMan, h.nodeNotifier)
 	if err != nil {
 		return nil, fmt.Errorf("nodes changed hook: %w", err)
@@ -285,11 +291,13 @@ func (h *Headscale) handleRegisterInteractive(
 
 	nodeToRegister := types.RegisterNode{
 		Node: types.Node{
-			Hostname:   regReq.Hostinfo.Hostname,
-			MachineKey: machineKey,
-			NodeKey:    regReq.NodeKey,
-			Hostinfo:   regReq.Hostinfo,
-			LastSeen:   ptr.To(time.Now()),
+			Hostname:     regReq.Hostinfo.Hostname,
+			MachineKey:   machineKey,
+			NodeKey:      regReq.NodeKey,
+			NLKey:        regReq.NLKey,
+			KeySignature: regReq.NodeKeySignature,
+			Hostinfo:     regReq.Hostinfo,
+			LastSeen:     ptr.To(time.Now()),
 		},
 		Registered: make(chan *types.Node),
 	}
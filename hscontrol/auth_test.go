@@ -76,6 +76,16 @@ func TestCanUsePreAuthKey(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "key of disabled user",
+			pak: &types.PreAuthKey{
+				Reusable:   true,
+				Expiration: &future,
+				User:       types.User{Disabled: true},
+			},
+			wantErr: true,
+			err:     NewHTTPError(http.StatusForbidden, "user is disabled", nil),
+		},
 		{
 			name:    "nil preauth key",
 			pak:     nil,
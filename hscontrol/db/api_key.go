@@ -18,9 +18,19 @@ const (
 
 var ErrAPIKeyFailedToParse = errors.New("failed to parse ApiKey")
 
-// CreateAPIKey creates a new ApiKey in a user, and returns it.
+// CreateAPIKey creates a new admin ApiKey, and returns it.
 func (hsdb *HSDatabase) CreateAPIKey(
 	expiration *time.Time,
+) (string, *types.APIKey, error) {
+	return hsdb.CreateScopedAPIKey(expiration, types.APIKeyScopeAdmin, nil)
+}
+
+// CreateScopedAPIKey creates a new ApiKey limited to the given scope and,
+// if any are given, to the given users, and returns it.
+func (hsdb *HSDatabase) CreateScopedAPIKey(
+	expiration *time.Time,
+	scope types.APIKeyScope,
+	userIDs []uint64,
 ) (string, *types.APIKey, error) {
 	prefix, err := util.GenerateRandomStringURLSafe(apiPrefixLength)
 	if err != nil {
@@ -43,6 +53,8 @@ func (hsdb *HSDatabase) CreateAPIKey(
 	key := types.APIKey{
 		Prefix:     prefix,
 		Hash:       hash,
+		Scope:      scope,
+		UserIDs:    userIDs,
 		Expiration: expiration,
 	}
 
@@ -3,6 +3,7 @@ package db
 import (
 	"time"
 
+	"github.com/juanfont/headscale/hscontrol/types"
 	"gopkg.in/check.v1"
 )
 
@@ -24,6 +25,18 @@ func (*Suite) TestCreateAPIKey(c *check.C) {
 	c.Assert(len(keys), check.Equals, 1)
 }
 
+func (*Suite) TestCreateScopedAPIKey(c *check.C) {
+	_, apiKey, err := db.CreateScopedAPIKey(nil, types.APIKeyScopeKeyIssuer, []uint64{1, 3})
+	c.Assert(err, check.IsNil)
+
+	key, err := db.GetAPIKey(apiKey.Prefix)
+	c.Assert(err, check.IsNil)
+	c.Assert(key.EffectiveScope(), check.Equals, types.APIKeyScopeKeyIssuer)
+	c.Assert(key.UserIDs, check.DeepEquals, []uint64{1, 3})
+	c.Assert(key.CanAccessUser(1), check.Equals, true)
+	c.Assert(key.CanAccessUser(2), check.Equals, false)
+}
+
 func (*Suite) TestAPIKeyDoesNotExist(c *check.C) {
 	key, err := db.GetAPIKey("does-not-exist")
 	c.Assert(err, check.NotNil)
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+)
+
+// RecordAuditEvent stores the audit event in the database.
+func (hsdb *HSDatabase) RecordAuditEvent(event *types.AuditEvent) error {
+	return hsdb.DB.Create(event).Error
+}
+
+// ListAuditEvents returns the audit events matching the filter, newest
+// first.
+func (hsdb *HSDatabase) ListAuditEvents(filter types.AuditEventFilter) ([]types.AuditEvent, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) ([]types.AuditEvent, error) {
+		return ListAuditEvents(rx, filter)
+	})
+}
+
+// ListAuditEvents returns the audit events matching the filter, newest
+// first.
+func ListAuditEvents(tx *gorm.DB, filter types.AuditEventFilter) ([]types.AuditEvent, error) {
+	query := tx.Model(&types.AuditEvent{})
+
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.Target != "" {
+		query = query.Where("target = ?", filter.Target)
+	}
+	if !filter.Since.IsZero() {
+		query = query.Where("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query = query.Where("created_at < ?", filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []types.AuditEvent
+	if err := query.Order("id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -708,7 +708,247 @@ AND auth_key_id NOT IN (
 					for _, user := range users {
 						user.ProviderIdentifier.String = types.CleanIdentifier(user.ProviderIdentifier.String)
 
-						err := tx.Save(user).Error
+						// Only update the column this migration owns, the
+						// user model may have columns that later
+						// migrations add.
+						err := tx.Model(&types.User{}).
+							Where("id = ?", user.ID).
+							UpdateColumn("provider_identifier", user.ProviderIdentifier).Error
+						if err != nil {
+							return fmt.Errorf("saving user: %w", err)
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the tables and node columns needed for Tailnet Lock.
+			{
+				ID: "202610171100",
+				Migrate: func(tx *gorm.DB) error {
+					if err := tx.AutoMigrate(&types.TKAState{}, &types.TKAAUM{}); err != nil {
+						return err
+					}
+
+					if !tx.Migrator().HasColumn(&types.Node{}, "nl_key") {
+						if err := tx.Migrator().AddColumn(&types.Node{}, "NLKey"); err != nil {
+							return err
+						}
+					}
+
+					if !tx.Migrator().HasColumn(&types.Node{}, "key_signature") {
+						if err := tx.Migrator().AddColumn(&types.Node{}, "KeySignature"); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Record who stored each revision of the policy.
+			{
+				ID: "202610171200",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.Policy{}, "author") {
+						if err := tx.Migrator().AddColumn(&types.Policy{}, "Author"); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the audit log.
+			{
+				ID: "202610171300",
+				Migrate: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&types.AuditEvent{})
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add scopes to API keys, existing keys keep full access.
+			{
+				ID: "202610171400",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.APIKey{}, "scope") {
+						if err := tx.Migrator().AddColumn(&types.APIKey{}, "Scope"); err != nil {
+							return err
+						}
+					}
+
+					if !tx.Migrator().HasColumn(&types.APIKey{}, "user_ids") {
+						if err := tx.Migrator().AddColumn(&types.APIKey{}, "UserIDs"); err != nil {
+							return err
+						}
+					}
+
+					return tx.Model(&types.APIKey{}).
+						Where("scope IS NULL OR scope = ''").
+						Update("scope", types.APIKeyScopeAdmin).Error
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the queue of outbound webhook deliveries.
+			{
+				ID: "202610171500",
+				Migrate: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&types.WebhookDelivery{})
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the operator set priorities of the routes of nodes.
+			{
+				ID: "202610171600",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.Node{}, "route_priorities") {
+						if err := tx.Migrator().AddColumn(&types.Node{}, "RoutePriorities"); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the DNS records, nameservers and search domains managed
+			// through the API.
+			{
+				ID: "202610171700",
+				Migrate: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&types.DNSRecord{}, &types.Nameserver{}, &types.SearchDomain{})
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the DERP regions and nodes managed through the API.
+			{
+				ID: "202610171800",
+				Migrate: func(tx *gorm.DB) error {
+					return tx.AutoMigrate(&types.DERPRegion{}, &types.DERPNode{})
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the disabled flag of users.
+			{
+				ID: "202610171900",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "disabled") {
+						if err := tx.Migrator().AddColumn(&types.User{}, "Disabled"); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the groups of the users at the OIDC provider.
+			{
+				ID: "202610172000",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "groups") {
+						if err := tx.Migrator().AddColumn(&types.User{}, "Groups"); err != nil {
+							return err
+						}
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the external ID of users and the groups provisioned via SCIM.
+			{
+				ID: "202610172100",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "external_id") {
+						if err := tx.Migrator().AddColumn(&types.User{}, "ExternalID"); err != nil {
+							return err
+						}
+					}
+
+					return tx.AutoMigrate(&types.UserGroup{})
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the encrypted OIDC refresh token of users.
+			{
+				ID: "202610172200",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "oidc_refresh_token") {
+						return tx.Migrator().AddColumn(&types.User{}, "OIDCRefreshToken")
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the OIDC provider of the last login of users.
+			{
+				ID: "202610172300",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "oidc_provider") {
+						return tx.Migrator().AddColumn(&types.User{}, "OIDCProvider")
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Add the approval state of nodes, the existing nodes are
+			// approved.
+			{
+				ID: "202610172400",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.Node{}, "pending_approval") {
+						return tx.Migrator().AddColumn(&types.Node{}, "PendingApproval")
+					}
+
+					return nil
+				},
+				Rollback: func(db *gorm.DB) error { return nil },
+			},
+			// Keep the groups provisioned via SCIM apart from the groups
+			// claim of OIDC logins, which replaces the groups. The SCIM
+			// groups are moved out of the groups of their members.
+			{
+				ID: "202610172500",
+				Migrate: func(tx *gorm.DB) error {
+					if !tx.Migrator().HasColumn(&types.User{}, "scim_groups") {
+						if err := tx.Migrator().AddColumn(&types.User{}, "SCIMGroups"); err != nil {
+							return err
+						}
+					}
+
+					var scimGroups []string
+					if err := tx.Model(&types.UserGroup{}).Pluck("name", &scimGroups).Error; err != nil {
+						return fmt.Errorf("listing user groups: %w", err)
+					}
+
+					var users []types.User
+					if err := tx.Select("id", "groups").Find(&users).Error; err != nil {
+						return fmt.Errorf("listing users: %w", err)
+					}
+
+					for _, user := range users {
+						var groups, moved []string
+						for _, name := range user.Groups {
+							if slices.Contains(scimGroups, name) {
+								moved = append(moved, name)
+							} else {
+								groups = append(groups, name)
+							}
+						}
+
+						if len(moved) == 0 {
+							continue
+						}
+
+						err := tx.Model(&types.User{}).
+							Where("id = ?", user.ID).
+							Select("groups", "scim_groups").
+							UpdateColumns(&types.User{Groups: groups, SCIMGroups: moved}).Error
 						if err != nil {
 							return fmt.Errorf("saving user: %w", err)
 						}
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDERPRegionExists = errors.New("DERP region already exists")
+	ErrDERPNodeExists   = errors.New("DERP node already exists")
+)
+
+// GetDERPOverrides returns the DERP regions and nodes managed through the
+// API.
+func (hsdb *HSDatabase) GetDERPOverrides() ([]types.DERPRegion, []types.DERPNode, error) {
+	var (
+		regions []types.DERPRegion
+		nodes   []types.DERPNode
+	)
+
+	err := hsdb.DB.Transaction(func(rx *gorm.DB) error {
+		if err := rx.Order("region_id").Find(&regions).Error; err != nil {
+			return err
+		}
+
+		return rx.Order("id").Find(&nodes).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return regions, nodes, nil
+}
+
+// CreateDERPRegion stores a validated DERP region. It fails if the region
+// has been created or disabled before.
+func (hsdb *HSDatabase) CreateDERPRegion(region *types.DERPRegion) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.DERPRegion{}).
+			Where("region_id = ?", region.RegionID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDERPRegionExists
+		}
+
+		return tx.Create(region).Error
+	})
+}
+
+// DeleteDERPRegion deletes the DERP region with the given region ID and
+// the nodes of the region managed through the API.
+func (hsdb *HSDatabase) DeleteDERPRegion(regionID int) (*types.DERPRegion, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.DERPRegion, error) {
+		var region types.DERPRegion
+		if err := tx.Where("region_id = ?", regionID).First(&region).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Where("region_id = ?", regionID).Delete(&types.DERPNode{}).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&region).Error; err != nil {
+			return nil, err
+		}
+
+		return &region, nil
+	})
+}
+
+// SetDERPRegionDisabled disables or enables the DERP region with the given
+// region ID, creating an entry for regions of the configuration.
+func (hsdb *HSDatabase) SetDERPRegionDisabled(regionID int, disabled bool) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		region := types.DERPRegion{RegionID: regionID}
+		if err := tx.Where("region_id = ?", regionID).FirstOrCreate(&region).Error; err != nil {
+			return err
+		}
+
+		// Entries only disabling a region of the configuration are
+		// removed when it is enabled again.
+		if !disabled && region.Code == "" {
+			return tx.Delete(&region).Error
+		}
+
+		return tx.Model(&region).Update("disabled", disabled).Error
+	})
+}
+
+// CreateDERPNode stores a validated DERP node. It fails if a node with the
+// same name has been created before.
+func (hsdb *HSDatabase) CreateDERPNode(node *types.DERPNode) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.DERPNode{}).
+			Where("name = ? AND host_name != ''", node.Name).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDERPNodeExists
+		}
+
+		// Replace an entry disabling a node of the configuration.
+		if err := tx.Where("name = ?", node.Name).Delete(&types.DERPNode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(node).Error
+	})
+}
+
+// DeleteDERPNode deletes the DERP node with the given name.
+func (hsdb *HSDatabase) DeleteDERPNode(name string) (*types.DERPNode, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.DERPNode, error) {
+		var node types.DERPNode
+		if err := tx.Where("name = ?", name).First(&node).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&node).Error; err != nil {
+			return nil, err
+		}
+
+		return &node, nil
+	})
+}
+
+// SetDERPNodeDisabled disables or enables the DERP node with the given
+// name in the given region, creating an entry for nodes of the
+// configuration.
+func (hsdb *HSDatabase) SetDERPNodeDisabled(name string, regionID int, disabled bool) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		node := types.DERPNode{Name: name, RegionID: regionID}
+		if err := tx.Where("name = ?", name).FirstOrCreate(&node).Error; err != nil {
+			return err
+		}
+
+		if !disabled && node.HostName == "" {
+			return tx.Delete(&node).Error
+		}
+
+		return tx.Model(&node).Update("disabled", disabled).Error
+	})
+}
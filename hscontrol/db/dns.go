@@ -0,0 +1,221 @@
+package db
+
+import (
+	"errors"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDNSRecordExists    = errors.New("DNS record already exists")
+	ErrNameserverExists   = errors.New("nameserver already exists")
+	ErrSearchDomainExists = errors.New("search domain already exists")
+)
+
+// GetDNSSettings returns the DNS records, nameservers and search domains
+// managed through the API.
+func (hsdb *HSDatabase) GetDNSSettings() (types.DNSSettings, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) (types.DNSSettings, error) {
+		var settings types.DNSSettings
+
+		if err := rx.Order("id").Find(&settings.Records).Error; err != nil {
+			return types.DNSSettings{}, err
+		}
+
+		if err := rx.Order("id").Find(&settings.Nameservers).Error; err != nil {
+			return types.DNSSettings{}, err
+		}
+
+		if err := rx.Order("id").Find(&settings.SearchDomains).Error; err != nil {
+			return types.DNSSettings{}, err
+		}
+
+		return settings, nil
+	})
+}
+
+// CreateDNSRecord stores a validated DNS record.
+func (hsdb *HSDatabase) CreateDNSRecord(record *types.DNSRecord) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.DNSRecord{}).
+			Where("name = ? AND type = ? AND value = ?", record.Name, record.Type, record.Value).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrDNSRecordExists
+		}
+
+		return tx.Create(record).Error
+	})
+}
+
+// UpdateDNSRecord replaces the DNS record with the ID of the given,
+// validated, record and returns the record before the update.
+func (hsdb *HSDatabase) UpdateDNSRecord(record *types.DNSRecord) (*types.DNSRecord, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.DNSRecord, error) {
+		var old types.DNSRecord
+		if err := tx.First(&old, record.ID).Error; err != nil {
+			return nil, err
+		}
+
+		var count int64
+		if err := tx.Model(&types.DNSRecord{}).
+			Where("id <> ? AND name = ? AND type = ? AND value = ?", record.ID, record.Name, record.Type, record.Value).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrDNSRecordExists
+		}
+
+		record.CreatedAt = old.CreatedAt
+		if err := tx.Save(record).Error; err != nil {
+			return nil, err
+		}
+
+		return &old, nil
+	})
+}
+
+// DeleteDNSRecord deletes the DNS record with the given ID and returns it.
+func (hsdb *HSDatabase) DeleteDNSRecord(id uint64) (*types.DNSRecord, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.DNSRecord, error) {
+		var record types.DNSRecord
+		if err := tx.First(&record, id).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&record).Error; err != nil {
+			return nil, err
+		}
+
+		return &record, nil
+	})
+}
+
+// CreateNameserver stores a validated nameserver.
+func (hsdb *HSDatabase) CreateNameserver(ns *types.Nameserver) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.Nameserver{}).
+			Where("address = ? AND domain = ?", ns.Address, ns.Domain).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrNameserverExists
+		}
+
+		return tx.Create(ns).Error
+	})
+}
+
+// UpdateNameserver replaces the nameserver with the ID of the given,
+// validated, nameserver and returns the nameserver before the update.
+func (hsdb *HSDatabase) UpdateNameserver(ns *types.Nameserver) (*types.Nameserver, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.Nameserver, error) {
+		var old types.Nameserver
+		if err := tx.First(&old, ns.ID).Error; err != nil {
+			return nil, err
+		}
+
+		var count int64
+		if err := tx.Model(&types.Nameserver{}).
+			Where("id <> ? AND address = ? AND domain = ?", ns.ID, ns.Address, ns.Domain).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrNameserverExists
+		}
+
+		ns.CreatedAt = old.CreatedAt
+		if err := tx.Save(ns).Error; err != nil {
+			return nil, err
+		}
+
+		return &old, nil
+	})
+}
+
+// DeleteNameserver deletes the nameserver with the given ID and returns it.
+func (hsdb *HSDatabase) DeleteNameserver(id uint64) (*types.Nameserver, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.Nameserver, error) {
+		var ns types.Nameserver
+		if err := tx.First(&ns, id).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&ns).Error; err != nil {
+			return nil, err
+		}
+
+		return &ns, nil
+	})
+}
+
+// CreateSearchDomain stores a validated search domain.
+func (hsdb *HSDatabase) CreateSearchDomain(sd *types.SearchDomain) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&types.SearchDomain{}).
+			Where("domain = ?", sd.Domain).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrSearchDomainExists
+		}
+
+		return tx.Create(sd).Error
+	})
+}
+
+// UpdateSearchDomain replaces the search domain with the ID of the given,
+// validated, search domain and returns the search domain before the
+// update.
+func (hsdb *HSDatabase) UpdateSearchDomain(sd *types.SearchDomain) (*types.SearchDomain, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.SearchDomain, error) {
+		var old types.SearchDomain
+		if err := tx.First(&old, sd.ID).Error; err != nil {
+			return nil, err
+		}
+
+		var count int64
+		if err := tx.Model(&types.SearchDomain{}).
+			Where("id <> ? AND domain = ?", sd.ID, sd.Domain).
+			Count(&count).Error; err != nil {
+			return nil, err
+		}
+		if count > 0 {
+			return nil, ErrSearchDomainExists
+		}
+
+		sd.CreatedAt = old.CreatedAt
+		if err := tx.Save(sd).Error; err != nil {
+			return nil, err
+		}
+
+		return &old, nil
+	})
+}
+
+// DeleteSearchDomain deletes the search domain with the given ID and
+// returns it.
+func (hsdb *HSDatabase) DeleteSearchDomain(id uint64) (*types.SearchDomain, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.SearchDomain, error) {
+		var sd types.SearchDomain
+		if err := tx.First(&sd, id).Error; err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(&sd).Error; err != nil {
+			return nil, err
+		}
+
+		return &sd, nil
+	})
+}
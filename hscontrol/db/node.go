@@ -33,6 +33,7 @@ var (
 	ErrDifferentRegisteredUser      = errors.New(
 		"node was previously registered with a different user",
 	)
+	ErrNodeNotPendingApproval = errors.New("node is not waiting for approval")
 )
 
 // ListPeers returns peers of node, regardless of any Policy or if the node is expired.
@@ -251,6 +252,28 @@ func SetApprovedRoutes(
 	return nil
 }
 
+// SetRoutePriorities sets the priorities of the routes of a node.
+func SetRoutePriorities(
+	tx *gorm.DB,
+	nodeID types.NodeID,
+	priorities map[netip.Prefix]int,
+) error {
+	if priorities == nil {
+		priorities = map[netip.Prefix]int{}
+	}
+
+	b, err := json.Marshal(priorities)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Model(&types.Node{}).Where("id = ?", nodeID).Update("route_priorities", string(b)).Error; err != nil {
+		return fmt.Errorf("updating route priorities: %w", err)
+	}
+
+	return nil
+}
+
 // SetLastSeen sets a node's last seen field indicating that we
 // have recently communicating with this node.
 func (hsdb *HSDatabase) SetLastSeen(nodeID types.NodeID, lastSeen time.Time) error {
@@ -306,6 +329,43 @@ func NodeSetExpiry(tx *gorm.DB,
 	return tx.Model(&types.Node{}).Where("id = ?", nodeID).Update("expiry", expiry).Error
 }
 
+// ListPendingNodes returns the nodes waiting for an admin to approve them.
+func (hsdb *HSDatabase) ListPendingNodes() (types.Nodes, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) (types.Nodes, error) {
+		return ListPendingNodes(rx)
+	})
+}
+
+// ListPendingNodes returns the nodes waiting for an admin to approve them.
+func ListPendingNodes(tx *gorm.DB) (types.Nodes, error) {
+	nodes := types.Nodes{}
+	if err := tx.
+		Preload("AuthKey").
+		Preload("AuthKey.User").
+		Preload("User").
+		Where("pending_approval = ?", true).
+		Order("id").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
+// ApproveNode approves a node waiting for approval.
+func ApproveNode(tx *gorm.DB, nodeID types.NodeID) error {
+	node, err := GetNodeByID(tx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	if !node.PendingApproval {
+		return ErrNodeNotPendingApproval
+	}
+
+	return tx.Model(&types.Node{}).Where("id = ?", nodeID).Update("pending_approval", false).Error
+}
+
 func (hsdb *HSDatabase) DeleteNode(node *types.Node) error {
 	return hsdb.Write(func(tx *gorm.DB) error {
 		return DeleteNode(tx, node)
@@ -344,12 +404,14 @@ func (hsdb *HSDatabase) DeleteEphemeralNode(
 // If the node found in the registration cache is not already registered,
 // it will be registered with the user and the node will be removed from the cache.
 // If the node is already registered, the expiry will be updated.
+// A new node waits for an admin to approve it if pendingApproval is set.
 // The node, and a boolean indicating if it was a new node or not, will be returned.
 func (hsdb *HSDatabase) HandleNodeFromAuthPath(
 	registrationID types.RegistrationID,
 	userID types.UserID,
 	nodeExpiry *time.Time,
 	registrationMethod string,
+	pendingApproval bool,
 	ipv4 *netip.Addr,
 	ipv6 *netip.Addr,
 ) (*types.Node, bool, error) {
@@ -383,6 +445,7 @@ func (hsdb *HSDatabase) HandleNodeFromAuthPath(
 				reg.Node.UserID = user.ID
 				reg.Node.User = *user
 				reg.Node.RegisterMethod = registrationMethod
+				reg.Node.PendingApproval = pendingApproval
 
 				if nodeExpiry != nil {
 					reg.Node.Expiry = nodeExpiry
@@ -439,13 +502,14 @@ func RegisterNode(tx *gorm.DB, node types.Node, ipv4 *netip.Addr, ipv6 *netip.Ad
 		Msg("Registering node")
 
 	// If the a new node is registered with the same machine key, to the same user,
-	// update the existing node.
+	// update the existing node. An approved node stays approved.
 	// If the same node is registered again, but to a new user, then that is considered
 	// a new node.
 	oldNode, _ := GetNodeByMachineKey(tx, node.MachineKey)
 	if oldNode != nil && oldNode.UserID == node.UserID {
 		node.ID = oldNode.ID
 		node.GivenName = oldNode.GivenName
+		node.PendingApproval = node.PendingApproval && oldNode.PendingApproval
 		ipv4 = oldNode.IPv4
 		ipv6 = oldNode.IPv6
 	}
@@ -587,6 +651,30 @@ func ensureUniqueGivenName(
 	return givenName, nil
 }
 
+// ListNodesExpiringBetween returns the nodes whose key expires after from
+// and no later than until.
+func (hsdb *HSDatabase) ListNodesExpiringBetween(from, until time.Time) (types.Nodes, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) (types.Nodes, error) {
+		return ListNodesExpiringBetween(rx, from, until)
+	})
+}
+
+// ListNodesExpiringBetween returns the nodes whose key expires after from
+// and no later than until.
+func ListNodesExpiringBetween(tx *gorm.DB, from, until time.Time) (types.Nodes, error) {
+	nodes := types.Nodes{}
+	if err := tx.
+		Preload("AuthKey").
+		Preload("AuthKey.User").
+		Preload("User").
+		Where("expiry > ? AND expiry <= ?", from, until).
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+
+	return nodes, nil
+}
+
 func ExpireExpiredNodes(tx *gorm.DB,
 	lastCheck time.Time,
 ) (time.Time, types.StateUpdate, bool) {
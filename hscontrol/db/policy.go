@@ -8,11 +8,13 @@ import (
 	"gorm.io/gorm/clause"
 )
 
-// SetPolicy sets the policy in the database.
-func (hsdb *HSDatabase) SetPolicy(policy string) (*types.Policy, error) {
+// SetPolicy sets the policy in the database, the previous policies are
+// kept as revisions.
+func (hsdb *HSDatabase) SetPolicy(policy string, author string) (*types.Policy, error) {
 	// Create a new policy.
 	p := types.Policy{
-		Data: policy,
+		Data:   policy,
+		Author: author,
 	}
 
 	if err := hsdb.DB.Clauses(clause.Returning{}).Create(&p).Error; err != nil {
@@ -41,3 +43,28 @@ func (hsdb *HSDatabase) GetPolicy() (*types.Policy, error) {
 
 	return &p, nil
 }
+
+// GetPolicyByID returns the policy revision with the given ID.
+func (hsdb *HSDatabase) GetPolicyByID(id uint64) (*types.Policy, error) {
+	var p types.Policy
+	if err := hsdb.DB.First(&p, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, types.ErrPolicyNotFound
+		}
+
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// ListPolicies returns all the policy revisions in the database, newest
+// first.
+func (hsdb *HSDatabase) ListPolicies() ([]types.Policy, error) {
+	var policies []types.Policy
+	if err := hsdb.DB.Order("id DESC").Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
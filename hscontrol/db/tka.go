@@ -0,0 +1,176 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"tailscale.com/tka"
+	"tailscale.com/types/tkatype"
+)
+
+// tkaStateID is the ID of the only row in the TKA state table.
+const tkaStateID = 1
+
+// GetTKAState returns the tailnet lock state, a zero state is returned if
+// tailnet lock has never been initialised.
+func (hsdb *HSDatabase) GetTKAState() (*types.TKAState, error) {
+	return GetTKAState(hsdb.DB)
+}
+
+// GetTKAState returns the tailnet lock state, a zero state is returned if
+// tailnet lock has never been initialised.
+func GetTKAState(tx *gorm.DB) (*types.TKAState, error) {
+	var state types.TKAState
+	if err := tx.First(&state, tkaStateID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &types.TKAState{ID: tkaStateID}, nil
+		}
+
+		return nil, err
+	}
+
+	return &state, nil
+}
+
+// SaveTKAState stores the tailnet lock state.
+func SaveTKAState(tx *gorm.DB, state *types.TKAState) error {
+	state.ID = tkaStateID
+
+	return tx.Save(state).Error
+}
+
+// ResetTKA removes all the AUMs and node key signatures of the tailnet
+// lock, it is used when tailnet lock is disabled.
+func ResetTKA(tx *gorm.DB) error {
+	if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&types.TKAAUM{}).Error; err != nil {
+		return fmt.Errorf("deleting AUMs: %w", err)
+	}
+
+	if err := tx.Model(&types.Node{}).
+		Where("key_signature IS NOT NULL").
+		Update("key_signature", nil).Error; err != nil {
+		return fmt.Errorf("clearing node key signatures: %w", err)
+	}
+
+	return nil
+}
+
+// NodeSetKeySignature stores the tailnet lock signature of the node key.
+func NodeSetKeySignature(tx *gorm.DB, nodeID types.NodeID, sig tkatype.MarshaledSignature) error {
+	return tx.Model(&types.Node{}).
+		Where("id = ?", nodeID).
+		Update("key_signature", sig).Error
+}
+
+// TKAChonk returns a tka.Chonk storing the AUMs in the database.
+func TKAChonk(tx *gorm.DB) tka.Chonk {
+	return &tkaChonk{tx: tx}
+}
+
+// tkaChonk implements tka.Chonk on top of the database.
+type tkaChonk struct {
+	tx *gorm.DB
+}
+
+func unserializeAUMs(rows []types.TKAAUM) ([]tka.AUM, error) {
+	aums := make([]tka.AUM, len(rows))
+	for i, row := range rows {
+		if err := aums[i].Unserialize(row.AUM); err != nil {
+			return nil, fmt.Errorf("unserializing AUM %s: %w", row.Hash, err)
+		}
+	}
+
+	return aums, nil
+}
+
+func (c *tkaChonk) AUM(hash tka.AUMHash) (tka.AUM, error) {
+	var row types.TKAAUM
+	if err := c.tx.First(&row, "hash = ?", hash.String()).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tka.AUM{}, os.ErrNotExist
+		}
+
+		return tka.AUM{}, err
+	}
+
+	var aum tka.AUM
+	if err := aum.Unserialize(row.AUM); err != nil {
+		return tka.AUM{}, fmt.Errorf("unserializing AUM %s: %w", row.Hash, err)
+	}
+
+	return aum, nil
+}
+
+func (c *tkaChonk) ChildAUMs(prevAUMHash tka.AUMHash) ([]tka.AUM, error) {
+	var rows []types.TKAAUM
+	if err := c.tx.Where("prev_hash = ?", prevAUMHash.String()).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return unserializeAUMs(rows)
+}
+
+func (c *tkaChonk) CommitVerifiedAUMs(updates []tka.AUM) error {
+	rows := make([]types.TKAAUM, 0, len(updates))
+	for _, aum := range updates {
+		row := types.TKAAUM{
+			Hash: aum.Hash().String(),
+			AUM:  aum.Serialize(),
+		}
+		if parent, ok := aum.Parent(); ok {
+			row.PrevHash = parent.String()
+		}
+
+		rows = append(rows, row)
+	}
+
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return c.tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&rows).Error
+}
+
+func (c *tkaChonk) Heads() ([]tka.AUM, error) {
+	var rows []types.TKAAUM
+	if err := c.tx.
+		Where("hash NOT IN (?)", c.tx.Model(&types.TKAAUM{}).Select("prev_hash")).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return unserializeAUMs(rows)
+}
+
+func (c *tkaChonk) SetLastActiveAncestor(hash tka.AUMHash) error {
+	state, err := GetTKAState(c.tx)
+	if err != nil {
+		return err
+	}
+
+	state.LastActiveAncestor = hash.String()
+
+	return SaveTKAState(c.tx, state)
+}
+
+func (c *tkaChonk) LastActiveAncestor() (*tka.AUMHash, error) {
+	state, err := GetTKAState(c.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.LastActiveAncestor == "" {
+		return nil, nil
+	}
+
+	var hash tka.AUMHash
+	if err := hash.UnmarshalText([]byte(state.LastActiveAncestor)); err != nil {
+		return nil, fmt.Errorf("parsing last active ancestor: %w", err)
+	}
+
+	return &hash, nil
+}
@@ -0,0 +1,146 @@
+package db
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserGroupExists   = errors.New("user group already exists")
+	ErrUserGroupNotFound = errors.New("user group not found")
+)
+
+// ListUserGroups returns the groups of users provisioned via SCIM.
+func (hsdb *HSDatabase) ListUserGroups() ([]types.UserGroup, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) ([]types.UserGroup, error) {
+		groups := []types.UserGroup{}
+		if err := rx.Order("id").Find(&groups).Error; err != nil {
+			return nil, err
+		}
+
+		return groups, nil
+	})
+}
+
+func (hsdb *HSDatabase) GetUserGroup(id uint64) (*types.UserGroup, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) (*types.UserGroup, error) {
+		return GetUserGroup(rx, id)
+	})
+}
+
+// GetUserGroup returns the group of users with the given ID.
+func GetUserGroup(tx *gorm.DB, id uint64) (*types.UserGroup, error) {
+	var group types.UserGroup
+	if err := tx.First(&group, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserGroupNotFound
+		}
+
+		return nil, err
+	}
+
+	return &group, nil
+}
+
+// CreateUserGroup stores a new group with the given members. It fails if
+// a group with the same name exists.
+func (hsdb *HSDatabase) CreateUserGroup(group *types.UserGroup, members []types.UserID) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		if err := checkUserGroupName(tx, group); err != nil {
+			return err
+		}
+
+		if err := tx.Create(group).Error; err != nil {
+			return err
+		}
+
+		return setUserGroupMembers(tx, "", group.Name, members)
+	})
+}
+
+// UpdateUserGroup renames the group and replaces its members.
+func (hsdb *HSDatabase) UpdateUserGroup(group *types.UserGroup, members []types.UserID) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		old, err := GetUserGroup(tx, group.ID)
+		if err != nil {
+			return err
+		}
+
+		if err := checkUserGroupName(tx, group); err != nil {
+			return err
+		}
+
+		if err := tx.Save(group).Error; err != nil {
+			return err
+		}
+
+		return setUserGroupMembers(tx, old.Name, group.Name, members)
+	})
+}
+
+// DeleteUserGroup deletes the group and removes it from its members.
+func (hsdb *HSDatabase) DeleteUserGroup(id uint64) (*types.UserGroup, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (*types.UserGroup, error) {
+		group, err := GetUserGroup(tx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := setUserGroupMembers(tx, group.Name, "", nil); err != nil {
+			return nil, err
+		}
+
+		if err := tx.Delete(group).Error; err != nil {
+			return nil, err
+		}
+
+		return group, nil
+	})
+}
+
+func checkUserGroupName(tx *gorm.DB, group *types.UserGroup) error {
+	var count int64
+	if err := tx.Model(&types.UserGroup{}).
+		Where("name = ? AND id != ?", group.Name, group.ID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUserGroupExists
+	}
+
+	return nil
+}
+
+// setUserGroupMembers removes the group with the old name from all users
+// and adds the group with the new name to the given members.
+func setUserGroupMembers(tx *gorm.DB, oldName, newName string, members []types.UserID) error {
+	users, err := ListUsers(tx)
+	if err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		groups := slices.DeleteFunc(slices.Clone(user.SCIMGroups), func(name string) bool {
+			return name == oldName || name == newName
+		})
+		if newName != "" && slices.Contains(members, types.UserID(user.ID)) {
+			groups = append(groups, newName)
+			slices.Sort(groups)
+		}
+
+		if slices.Equal(groups, user.SCIMGroups) {
+			continue
+		}
+
+		user.SCIMGroups = groups
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package db
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/juanfont/headscale/hscontrol/util"
@@ -109,6 +110,75 @@ func RenameUser(tx *gorm.DB, uid types.UserID, newName string) error {
 	return nil
 }
 
+func (hsdb *HSDatabase) SetUserDisabled(uid types.UserID, disabled bool) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		return SetUserDisabled(tx, uid, disabled)
+	})
+}
+
+// SetUserDisabled disables or enables a User. Returns error if the User
+// does not exist.
+func SetUserDisabled(tx *gorm.DB, uid types.UserID, disabled bool) error {
+	user, err := GetUserByID(tx, uid)
+	if err != nil {
+		return err
+	}
+
+	return tx.Model(user).Update("disabled", disabled).Error
+}
+
+// SetOIDCRefreshToken stores the encrypted OIDC refresh token of a User
+// together with the name of the OIDC provider it is from, nil removes it.
+func (hsdb *HSDatabase) SetOIDCRefreshToken(uid types.UserID, provider string, token []byte) error {
+	return hsdb.Write(func(tx *gorm.DB) error {
+		return tx.Model(&types.User{}).Where("id = ?", uid).Updates(map[string]any{
+			"oidc_provider":      provider,
+			"oidc_refresh_token": token,
+		}).Error
+	})
+}
+
+// ExpireUserNodes expires the nodes of the User which have not expired
+// yet, and returns them.
+func ExpireUserNodes(tx *gorm.DB, uid types.UserID, expiry time.Time) (types.Nodes, error) {
+	nodes, err := ListNodesByUser(tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	var expired types.Nodes
+	for _, node := range nodes {
+		if node.IsExpired() {
+			continue
+		}
+
+		if err := NodeSetExpiry(tx, node.ID, expiry); err != nil {
+			return nil, err
+		}
+		node.Expiry = &expiry
+		expired = append(expired, node)
+	}
+
+	return expired, nil
+}
+
+// DestroyUserAndNodes destroys a User together with its nodes, and returns
+// the deleted nodes.
+func DestroyUserAndNodes(tx *gorm.DB, uid types.UserID) (types.Nodes, error) {
+	nodes, err := ListNodesByUser(tx, uid)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, node := range nodes {
+		if err := DeleteNode(tx, node); err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, DestroyUser(tx, uid)
+}
+
 func (hsdb *HSDatabase) GetUserByID(uid types.UserID) (*types.User, error) {
 	return Read(hsdb.DB, func(rx *gorm.DB) (*types.User, error) {
 		return GetUserByID(rx, uid)
@@ -170,6 +240,32 @@ func ListUsers(tx *gorm.DB, where ...*types.User) ([]types.User, error) {
 	return users, nil
 }
 
+// GetUnlinkedSCIMUser returns the user provisioned via SCIM which has not
+// logged in with OIDC yet and has the given external ID or verified email.
+// Empty values never match.
+func (hsdb *HSDatabase) GetUnlinkedSCIMUser(externalID, email string) (*types.User, error) {
+	if externalID == "" && email == "" {
+		return nil, ErrUserNotFound
+	}
+
+	return Read(hsdb.DB, func(rx *gorm.DB) (*types.User, error) {
+		var user types.User
+		err := rx.Where("provider = ? AND provider_identifier IS NULL", util.ProviderSCIM).
+			Where(rx.Where("external_id != '' AND external_id = ?", externalID).
+				Or("email != '' AND email = ?", email)).
+			First(&user).Error
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrUserNotFound
+			}
+
+			return nil, err
+		}
+
+		return &user, nil
+	})
+}
+
 // GetUserByName returns a user if the provided username is
 // unique, and otherwise an error.
 func (hsdb *HSDatabase) GetUserByName(name string) (*types.User, error) {
@@ -131,3 +131,36 @@ func (s *Suite) TestSetMachineUser(c *check.C) {
 	c.Assert(node.UserID, check.Equals, newUser.ID)
 	c.Assert(node.User.Name, check.Equals, newUser.Name)
 }
+
+func (s *Suite) TestGetUnlinkedSCIMUser(c *check.C) {
+	user, err := db.CreateUser(types.User{
+		Name:       "alice",
+		Email:      "alice@example.com",
+		ExternalID: "00u1",
+		Provider:   util.ProviderSCIM,
+	})
+	c.Assert(err, check.IsNil)
+
+	found, err := db.GetUnlinkedSCIMUser("00u1", "")
+	c.Assert(err, check.IsNil)
+	c.Assert(found.ID, check.Equals, user.ID)
+
+	found, err = db.GetUnlinkedSCIMUser("", "alice@example.com")
+	c.Assert(err, check.IsNil)
+	c.Assert(found.ID, check.Equals, user.ID)
+
+	// The username never links a user.
+	_, err = db.GetUnlinkedSCIMUser("alice", "")
+	c.Assert(err, check.Equals, ErrUserNotFound)
+
+	_, err = db.GetUnlinkedSCIMUser("", "")
+	c.Assert(err, check.Equals, ErrUserNotFound)
+
+	// Users which have logged in are linked already.
+	user.ProviderIdentifier.String = "https://idp.example.com/00u1"
+	user.ProviderIdentifier.Valid = true
+	c.Assert(db.DB.Save(user).Error, check.IsNil)
+
+	_, err = db.GetUnlinkedSCIMUser("00u1", "alice@example.com")
+	c.Assert(err, check.Equals, ErrUserNotFound)
+}
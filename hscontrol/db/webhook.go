@@ -0,0 +1,75 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"gorm.io/gorm"
+)
+
+// QueueWebhookDelivery stores a webhook delivery. A delivery with a dedup
+// key is not stored if one with the same key was already queued for the
+// endpoint, in which case false is returned.
+func (hsdb *HSDatabase) QueueWebhookDelivery(delivery *types.WebhookDelivery) (bool, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (bool, error) {
+		if delivery.DedupKey != "" {
+			var existing types.WebhookDelivery
+			err := tx.
+				Where("endpoint = ? AND dedup_key = ?", delivery.Endpoint, delivery.DedupKey).
+				First(&existing).Error
+			if err == nil {
+				return false, nil
+			}
+			if !errors.Is(err, gorm.ErrRecordNotFound) {
+				return false, err
+			}
+		}
+
+		if err := tx.Create(delivery).Error; err != nil {
+			return false, err
+		}
+
+		return true, nil
+	})
+}
+
+// ListPendingWebhookDeliveries returns the deliveries to the endpoint that
+// are neither delivered nor given up and are due at the given time, oldest
+// first.
+func (hsdb *HSDatabase) ListPendingWebhookDeliveries(endpoint string, now time.Time, limit int) ([]types.WebhookDelivery, error) {
+	return Read(hsdb.DB, func(rx *gorm.DB) ([]types.WebhookDelivery, error) {
+		var deliveries []types.WebhookDelivery
+		err := rx.
+			Where("endpoint = ? AND delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?", endpoint, now).
+			Order("id").
+			Limit(limit).
+			Find(&deliveries).Error
+
+		return deliveries, err
+	})
+}
+
+// SaveWebhookDelivery stores the state of a delivery attempt.
+func (hsdb *HSDatabase) SaveWebhookDelivery(delivery *types.WebhookDelivery) error {
+	return hsdb.DB.Save(delivery).Error
+}
+
+// DeleteWebhookDeliveries deletes the deliveries created before the given
+// time that are delivered, given up, or for an endpoint that is not in the
+// given list anymore. It returns the number of deleted deliveries.
+func (hsdb *HSDatabase) DeleteWebhookDeliveries(before time.Time, endpoints []string) (int64, error) {
+	return Write(hsdb.DB, func(tx *gorm.DB) (int64, error) {
+		query := tx.Where("created_at < ?", before)
+		if len(endpoints) > 0 {
+			query = query.Where(
+				"delivered_at IS NOT NULL OR failed_at IS NOT NULL OR endpoint NOT IN ?",
+				endpoints,
+			)
+		}
+
+		res := query.Delete(&types.WebhookDelivery{})
+
+		return res.RowsAffected, res.Error
+	})
+}
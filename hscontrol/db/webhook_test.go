@@ -0,0 +1,114 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliveries(t *testing.T) {
+	db := dbForTest(t)
+	now := time.Now()
+
+	queue := func(endpoint, key string, due time.Time) bool {
+		t.Helper()
+
+		queued, err := db.QueueWebhookDelivery(&types.WebhookDelivery{
+			Endpoint:      endpoint,
+			Event:         types.WebhookEventNodeKeyExpiring,
+			DedupKey:      key,
+			Payload:       "{}",
+			NextAttemptAt: due,
+		})
+		require.NoError(t, err)
+
+		return queued
+	}
+
+	assert.True(t, queue("https://a.example.com", "node:1", now))
+	assert.False(t, queue("https://a.example.com", "node:1", now))
+	assert.True(t, queue("https://b.example.com", "node:1", now))
+	assert.True(t, queue("https://a.example.com", "", now.Add(time.Hour)))
+	assert.True(t, queue("https://a.example.com", "", now.Add(-time.Minute)))
+
+	pending, err := db.ListPendingWebhookDeliveries("https://a.example.com", now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, "node:1", pending[0].DedupKey)
+
+	delivered := pending[0]
+	delivered.DeliveredAt = &now
+	require.NoError(t, db.SaveWebhookDelivery(&delivered))
+
+	pending, err = db.ListPendingWebhookDeliveries("https://b.example.com", now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	failed := pending[0]
+	failed.FailedAt = &now
+	require.NoError(t, db.SaveWebhookDelivery(&failed))
+
+	pending, err = db.ListPendingWebhookDeliveries("https://a.example.com", now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	pending, err = db.ListPendingWebhookDeliveries("https://b.example.com", now, 10)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	pending, err = db.ListPendingWebhookDeliveries("https://a.example.com", now.Add(2*time.Hour), 1)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+}
+
+func TestDeleteWebhookDeliveries(t *testing.T) {
+	db := dbForTest(t)
+	now := time.Now()
+
+	queue := func(endpoint string, delivered, failed bool) {
+		t.Helper()
+
+		delivery := &types.WebhookDelivery{
+			Endpoint:      endpoint,
+			Event:         types.WebhookEventNodeRegistered,
+			Payload:       "{}",
+			NextAttemptAt: now,
+		}
+		if delivered {
+			delivery.DeliveredAt = &now
+		}
+		if failed {
+			delivery.FailedAt = &now
+		}
+
+		_, err := db.QueueWebhookDelivery(delivery)
+		require.NoError(t, err)
+	}
+
+	queue("https://a.example.com", true, false)
+	queue("https://a.example.com", false, true)
+	queue("https://a.example.com", false, false)
+	queue("https://removed.example.com", false, false)
+
+	// Nothing is old enough yet.
+	deleted, err := db.DeleteWebhookDeliveries(now.Add(-time.Hour), []string{"https://a.example.com"})
+	require.NoError(t, err)
+	assert.Zero(t, deleted)
+
+	// The pending delivery to a configured endpoint is kept.
+	deleted, err = db.DeleteWebhookDeliveries(now.Add(time.Hour), []string{"https://a.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+
+	pending, err := db.ListPendingWebhookDeliveries("https://a.example.com", now, 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+
+	// Without endpoints, all old deliveries are deleted.
+	deleted, err = db.DeleteWebhookDeliveries(now.Add(time.Hour), nil)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
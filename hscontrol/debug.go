@@ -79,7 +79,7 @@ func (h *Headscale) debugHTTPServer() *http.Server {
 		w.Write(sshJSON)
 	}))
 	debug.Handle("derpmap", "Current DERPMap", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		dm := h.DERPMap
+		dm := h.currentDERPMap()
 
 		dmJSON, err := json.MarshalIndent(dm, "", "  ")
 		if err != nil {
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 
 	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/rs/zerolog/log"
@@ -130,3 +131,72 @@ func GetDERPMap(cfg types.DERPConfig) *tailcfg.DERPMap {
 
 	return derpMap
 }
+
+// ApplyOverrides returns a copy of the DERP map with the regions and nodes
+// managed through the API added, changed or removed. Disabled regions and
+// nodes, and regions left without nodes, are removed.
+func ApplyOverrides(derpMap *tailcfg.DERPMap, regions []types.DERPRegion, nodes []types.DERPNode) *tailcfg.DERPMap {
+	ret := &tailcfg.DERPMap{
+		OmitDefaultRegions: derpMap.OmitDefaultRegions,
+		Regions:            make(map[int]*tailcfg.DERPRegion, len(derpMap.Regions)),
+	}
+	for id, region := range derpMap.Regions {
+		ret.Regions[id] = region.Clone()
+	}
+
+	for _, r := range regions {
+		region, ok := ret.Regions[r.RegionID]
+		if !ok {
+			// Only disables a region which is no longer configured.
+			if r.Code == "" {
+				continue
+			}
+
+			region = &tailcfg.DERPRegion{RegionID: r.RegionID}
+			ret.Regions[r.RegionID] = region
+		}
+
+		if r.Code != "" {
+			region.RegionCode = r.Code
+			region.RegionName = r.Name
+		}
+	}
+
+	for _, n := range nodes {
+		region, ok := ret.Regions[n.RegionID]
+		if !ok {
+			continue
+		}
+
+		i := slices.IndexFunc(region.Nodes, func(node *tailcfg.DERPNode) bool {
+			return node.Name == n.Name
+		})
+
+		switch {
+		case n.Disabled:
+			if i >= 0 {
+				region.Nodes = slices.Delete(region.Nodes, i, i+1)
+			}
+		case n.HostName == "":
+			// Only enables a node of the configuration.
+		case i >= 0:
+			region.Nodes[i] = n.Tailcfg()
+		default:
+			region.Nodes = append(region.Nodes, n.Tailcfg())
+		}
+	}
+
+	for _, r := range regions {
+		if r.Disabled {
+			delete(ret.Regions, r.RegionID)
+		}
+	}
+
+	for id, region := range ret.Regions {
+		if len(region.Nodes) == 0 {
+			delete(ret.Regions, id)
+		}
+	}
+
+	return ret
+}
@@ -0,0 +1,76 @@
+package derp
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"tailscale.com/tailcfg"
+)
+
+func TestApplyOverrides(t *testing.T) {
+	base := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID:   1,
+				RegionCode: "nyc",
+				RegionName: "New York",
+				Nodes: []*tailcfg.DERPNode{
+					{Name: "1a", RegionID: 1, HostName: "derp1a.example.com"},
+					{Name: "1b", RegionID: 1, HostName: "derp1b.example.com"},
+				},
+			},
+			2: {
+				RegionID:   2,
+				RegionCode: "sfo",
+				RegionName: "San Francisco",
+				Nodes: []*tailcfg.DERPNode{
+					{Name: "2a", RegionID: 2, HostName: "derp2a.example.com"},
+				},
+			},
+		},
+	}
+
+	got := ApplyOverrides(base,
+		[]types.DERPRegion{
+			{RegionID: 2, Disabled: true},
+			{RegionID: 900, Code: "office", Name: "Office"},
+			{RegionID: 901, Code: "empty", Name: "Empty"},
+		},
+		[]types.DERPNode{
+			{Name: "1a", RegionID: 1, Disabled: true},
+			{Name: "1b", RegionID: 1, HostName: "derp1b.example.net", STUNPort: -1},
+			{Name: "900a", RegionID: 900, HostName: "derp.office.example.com", IPv4: "192.0.2.1"},
+			{Name: "gone", RegionID: 42, HostName: "derp.gone.example.com"},
+		},
+	)
+
+	want := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID:   1,
+				RegionCode: "nyc",
+				RegionName: "New York",
+				Nodes: []*tailcfg.DERPNode{
+					{Name: "1b", RegionID: 1, HostName: "derp1b.example.net", STUNPort: -1},
+				},
+			},
+			900: {
+				RegionID:   900,
+				RegionCode: "office",
+				RegionName: "Office",
+				Nodes: []*tailcfg.DERPNode{
+					{Name: "900a", RegionID: 900, HostName: "derp.office.example.com", IPv4: "192.0.2.1"},
+				},
+			},
+		},
+	}
+
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("ApplyOverrides() unexpected result (-want +got):\n%s", diff)
+	}
+
+	if len(base.Regions[1].Nodes) != 2 || base.Regions[1].Nodes[1].HostName != "derp1b.example.com" {
+		t.Errorf("ApplyOverrides() modified the base DERP map")
+	}
+}
@@ -0,0 +1,238 @@
+package derp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/rs/zerolog/log"
+	"tailscale.com/derp/derphttp"
+	"tailscale.com/net/netmon"
+	"tailscale.com/net/stun"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+const defaultSTUNPort = 3478
+
+var errSTUNResponse = errors.New("unexpected STUN response")
+
+// Prober probes the DERP and STUN servers of a DERP map. It connects to
+// the DERP servers with the DERP client of the nodes and sends a STUN
+// binding request to the STUN servers.
+type Prober struct {
+	timeout time.Duration
+	key     key.NodePrivate
+	netMon  *netmon.Monitor
+
+	mu        sync.Mutex
+	results   map[string]types.DERPProbeResult
+	unhealthy map[int]bool
+}
+
+func NewProber(timeout time.Duration) *Prober {
+	return &Prober{
+		timeout:   timeout,
+		key:       key.NewNode(),
+		netMon:    netmon.NewStatic(),
+		results:   make(map[string]types.DERPProbeResult),
+		unhealthy: make(map[int]bool),
+	}
+}
+
+// PublicKey returns the node key the prober connects to the DERP servers
+// with.
+func (p *Prober) PublicKey() key.NodePublic {
+	return p.key.Public()
+}
+
+// Probe probes all servers of the DERP map concurrently and reports if
+// the set of regions to avoid changed.
+func (p *Prober) Probe(ctx context.Context, derpMap *tailcfg.DERPMap) bool {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]types.DERPProbeResult)
+	)
+
+	for _, region := range derpMap.Regions {
+		for _, node := range region.Nodes {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				result := p.probeNode(ctx, node)
+				if !result.Healthy() {
+					log.Debug().
+						Int("region", node.RegionID).
+						Str("node", node.Name).
+						AnErr("derp", result.DERPErr).
+						AnErr("stun", result.STUNErr).
+						Msg("DERP server failed probe")
+				}
+
+				mu.Lock()
+				results[node.Name] = result
+				mu.Unlock()
+			}()
+		}
+	}
+	wg.Wait()
+
+	unhealthy := unhealthyRegions(derpMap, results)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.results = results
+	changed := !maps.Equal(p.unhealthy, unhealthy)
+	p.unhealthy = unhealthy
+
+	if changed {
+		log.Info().Interface("regions", unhealthy).Msg("DERP regions to avoid changed")
+	}
+
+	return changed
+}
+
+// Results returns the last probe results by DERP node name.
+func (p *Prober) Results() map[string]types.DERPProbeResult {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return maps.Clone(p.results)
+}
+
+// Avoid marks the regions of the DERP map where no server could be reached
+// by the last probe to be avoided by the nodes.
+func (p *Prober) Avoid(derpMap *tailcfg.DERPMap) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id, region := range derpMap.Regions {
+		if p.unhealthy[id] {
+			region.Avoid = true
+		}
+	}
+}
+
+// unhealthyRegions returns the regions where no server passed the probe.
+// If all regions fail, the problem is most likely the network of
+// headscale and no region is returned.
+func unhealthyRegions(derpMap *tailcfg.DERPMap, results map[string]types.DERPProbeResult) map[int]bool {
+	unhealthy := make(map[int]bool)
+	for id, region := range derpMap.Regions {
+		healthy := false
+		for _, node := range region.Nodes {
+			if results[node.Name].Healthy() {
+				healthy = true
+				break
+			}
+		}
+
+		if !healthy {
+			unhealthy[id] = true
+		}
+	}
+
+	if len(unhealthy) == len(derpMap.Regions) {
+		return map[int]bool{}
+	}
+
+	return unhealthy
+}
+
+func (p *Prober) probeNode(ctx context.Context, node *tailcfg.DERPNode) types.DERPProbeResult {
+	result := types.DERPProbeResult{ProbedAt: time.Now()}
+
+	if !node.STUNOnly {
+		result.DERPLatency, result.DERPErr = p.probeDERP(ctx, node)
+	}
+
+	if node.STUNPort >= 0 {
+		result.STUNLatency, result.STUNErr = p.probeSTUN(ctx, node)
+	}
+
+	return result
+}
+
+// probeDERP returns how long it took to connect to the DERP server.
+func (p *Prober) probeDERP(ctx context.Context, node *tailcfg.DERPNode) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	client := derphttp.NewRegionClient(p.key, util.TSLogfWrapper(), p.netMon, func() *tailcfg.DERPRegion {
+		return &tailcfg.DERPRegion{
+			RegionID: node.RegionID,
+			Nodes:    []*tailcfg.DERPNode{node},
+		}
+	})
+	defer client.Close()
+
+	start := time.Now()
+	if err := client.Connect(ctx); err != nil {
+		return 0, err
+	}
+
+	return time.Since(start), nil
+}
+
+// probeSTUN returns how long it took to get a response to a STUN binding
+// request.
+func (p *Prober) probeSTUN(ctx context.Context, node *tailcfg.DERPNode) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	host := node.HostName
+	if _, err := netip.ParseAddr(node.IPv4); err == nil {
+		host = node.IPv4
+	}
+
+	port := node.STUNPort
+	if port == 0 {
+		port = defaultSTUNPort
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return 0, err
+		}
+	}
+
+	txID := stun.NewTxID()
+	start := time.Now()
+	if _, err := conn.Write(stun.Request(txID)); err != nil {
+		return 0, err
+	}
+
+	buf := make([]byte, 1024)
+	n, err := conn.Read(buf)
+	if err != nil {
+		return 0, err
+	}
+
+	respID, _, err := stun.ParseResponse(buf[:n])
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", errSTUNResponse, err)
+	}
+	if respID != txID {
+		return 0, fmt.Errorf("%w: transaction ID mismatch", errSTUNResponse)
+	}
+
+	return time.Since(start), nil
+}
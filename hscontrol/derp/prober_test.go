@@ -0,0 +1,109 @@
+package derp
+
+import (
+	"context"
+	"net"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tsderp "tailscale.com/derp"
+	"tailscale.com/derp/derphttp"
+	"tailscale.com/net/stun"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+	"tailscale.com/types/logger"
+)
+
+func TestProber(t *testing.T) {
+	derpServer := tsderp.NewServer(key.NewNode(), logger.Discard)
+	defer derpServer.Close()
+	srv := httptest.NewTLSServer(derphttp.Handler(derpServer))
+	defer srv.Close()
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	derpPort, err := strconv.Atoi(srvURL.Port())
+	require.NoError(t, err)
+
+	stunConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer stunConn.Close()
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, addr, err := stunConn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			txID, err := stun.ParseBindingRequest(buf[:n])
+			if err != nil {
+				continue
+			}
+			udpAddr := addr.(*net.UDPAddr)
+			stunConn.WriteTo(stun.Response(txID, netip.AddrPortFrom(netip.MustParseAddr("127.0.0.1"), uint16(udpAddr.Port))), addr)
+		}
+	}()
+	stunPort := stunConn.LocalAddr().(*net.UDPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	derpMap := &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID: 1,
+				Nodes: []*tailcfg.DERPNode{{
+					Name:             "1a",
+					RegionID:         1,
+					HostName:         "127.0.0.1",
+					IPv4:             "127.0.0.1",
+					DERPPort:         derpPort,
+					STUNPort:         stunPort,
+					InsecureForTests: true,
+				}},
+			},
+			2: {
+				RegionID: 2,
+				Nodes: []*tailcfg.DERPNode{{
+					Name:     "2a",
+					RegionID: 2,
+					HostName: "127.0.0.1",
+					IPv4:     "127.0.0.1",
+					DERPPort: closedPort,
+					STUNPort: -1,
+				}},
+			},
+		},
+	}
+
+	prober := NewProber(5 * time.Second)
+	assert.True(t, prober.Probe(context.Background(), derpMap))
+
+	results := prober.Results()
+	require.NoError(t, results["1a"].DERPErr)
+	require.NoError(t, results["1a"].STUNErr)
+	assert.True(t, results["1a"].Healthy())
+	assert.Positive(t, results["1a"].DERPLatency)
+	assert.Positive(t, results["1a"].STUNLatency)
+	assert.False(t, results["2a"].Healthy())
+
+	prober.Avoid(derpMap)
+	assert.False(t, derpMap.Regions[1].Avoid)
+	assert.True(t, derpMap.Regions[2].Avoid)
+
+	assert.False(t, prober.Probe(context.Background(), derpMap), "regions to avoid did not change")
+
+	// If all regions fail, none is avoided.
+	delete(derpMap.Regions, 1)
+	derpMap.Regions[2].Avoid = false
+	assert.True(t, prober.Probe(context.Background(), derpMap))
+	prober.Avoid(derpMap)
+	assert.False(t, derpMap.Regions[2].Avoid)
+}
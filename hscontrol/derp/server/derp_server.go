@@ -18,6 +18,8 @@ import (
 	"github.com/juanfont/headscale/hscontrol/util"
 	"github.com/rs/zerolog/log"
 	"tailscale.com/derp"
+	"tailscale.com/derp/derphttp"
+	"tailscale.com/net/netmon"
 	"tailscale.com/net/stun"
 	"tailscale.com/net/wsconn"
 	"tailscale.com/tailcfg"
@@ -44,6 +46,9 @@ func NewDERPServer(
 ) (*DERPServer, error) {
 	log.Trace().Caller().Msg("Creating new embedded DERP server")
 	server := derp.NewServer(derpKey, util.TSLogfWrapper()) // nolint // zerolinter complains
+	if cfg.MeshKey != "" {
+		server.SetMeshKey(cfg.MeshKey)
+	}
 
 	return &DERPServer{
 		serverURL:     serverURL,
@@ -53,6 +58,14 @@ func NewDERPServer(
 	}, nil
 }
 
+// VerifyClients makes the server only admit the clients the admission
+// controller at verifyURL allows. Clients are refused if the admission
+// controller cannot be reached.
+func (d *DERPServer) VerifyClients(verifyURL string) {
+	d.tailscaleDERP.SetVerifyClientURL(verifyURL)
+	d.tailscaleDERP.SetVerifyClientURLFailOpen(false)
+}
+
 func (d *DERPServer) GenerateRegion() (tailcfg.DERPRegion, error) {
 	serverURL, err := url.Parse(d.serverURL)
 	if err != nil {
@@ -103,12 +116,63 @@ func (d *DERPServer) GenerateRegion() (tailcfg.DERPRegion, error) {
 	}
 	localDERPregion.Nodes[0].STUNPort = portSTUN
 
+	// The servers of the mesh are published in the same region, the nodes
+	// can connect to any of them.
+	for i, peer := range d.cfg.MeshPeers {
+		localDERPregion.Nodes = append(localDERPregion.Nodes, meshPeerNode(d.cfg.ServerRegionID, i, peer))
+	}
+
 	log.Info().Caller().Msgf("DERP region: %+v", localDERPregion)
 	log.Info().Caller().Msgf("DERP Nodes[0]: %+v", localDERPregion.Nodes[0])
 
 	return localDERPregion, nil
 }
 
+func meshPeerNode(regionID int, index int, peer types.DERPMeshPeer) *tailcfg.DERPNode {
+	return &tailcfg.DERPNode{
+		// The embedded server is the first node, named by the region ID,
+		// the peers follow as <region ID>b, <region ID>c and so on.
+		Name:     fmt.Sprintf("%d%c", regionID, 'b'+index),
+		RegionID: regionID,
+		HostName: peer.HostName,
+		IPv4:     peer.IPv4,
+		IPv6:     peer.IPv6,
+		DERPPort: peer.DERPPort,
+		STUNPort: peer.STUNPort,
+	}
+}
+
+// ServeMesh connects to the DERP servers of the mesh and forwards the
+// packets for the clients connected to them, until the context is done.
+func (d *DERPServer) ServeMesh(ctx context.Context) {
+	for i, peer := range d.cfg.MeshPeers {
+		node := meshPeerNode(d.cfg.ServerRegionID, i, peer)
+		logf := func(format string, args ...any) {
+			log.Debug().Str("peer", peer.HostName).Msgf("DERP mesh: "+format, args...)
+		}
+
+		client := derphttp.NewRegionClient(d.key, logf, netmon.NewStatic(), func() *tailcfg.DERPRegion {
+			return &tailcfg.DERPRegion{
+				RegionID: node.RegionID,
+				Nodes:    []*tailcfg.DERPNode{node},
+			}
+		})
+		client.MeshKey = d.cfg.MeshKey
+		client.WatchConnectionChanges = true
+
+		add := func(m derp.PeerPresentMessage) { d.tailscaleDERP.AddPacketForwarder(m.Key, client) }
+		remove := func(m derp.PeerGoneMessage) { d.tailscaleDERP.RemovePacketForwarder(m.Peer, client) }
+
+		log.Info().Str("peer", peer.HostName).Msg("Meshing embedded DERP server")
+		go client.RunWatchConnectionLoop(ctx, d.tailscaleDERP.PublicKey(), logf, add, remove)
+		go func() {
+			// The loop only returns once the client is closed.
+			<-ctx.Done()
+			client.Close()
+		}()
+	}
+}
+
 func (d *DERPServer) DERPHandler(
 	writer http.ResponseWriter,
 	req *http.Request,
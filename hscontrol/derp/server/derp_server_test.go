@@ -0,0 +1,40 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestGenerateRegionMesh(t *testing.T) {
+	server, err := NewDERPServer("https://headscale.example.com", key.NewNode(), &types.DERPConfig{
+		ServerRegionID:   999,
+		ServerRegionCode: "headscale",
+		ServerRegionName: "Headscale Embedded DERP",
+		STUNAddr:         "0.0.0.0:3478",
+		IPv4:             "192.0.2.1",
+		MeshKey:          "0000000000000000000000000000000000000000000000000000000000000000",
+		MeshPeers: []types.DERPMeshPeer{
+			{HostName: "derp2.example.com", IPv4: "192.0.2.2"},
+			{HostName: "derp3.example.com", DERPPort: 8443, STUNPort: -1},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, server.tailscaleDERP.HasMeshKey())
+
+	region, err := server.GenerateRegion()
+	require.NoError(t, err)
+
+	want := []*tailcfg.DERPNode{
+		{Name: "999", RegionID: 999, HostName: "headscale.example.com", IPv4: "192.0.2.1", DERPPort: 443, STUNPort: 3478},
+		{Name: "999b", RegionID: 999, HostName: "derp2.example.com", IPv4: "192.0.2.2"},
+		{Name: "999c", RegionID: 999, HostName: "derp3.example.com", DERPPort: 8443, STUNPort: -1},
+	}
+	if diff := cmp.Diff(want, region.Nodes); diff != "" {
+		t.Errorf("GenerateRegion() unexpected nodes (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,176 @@
+package hscontrol
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+// derpAdmissionRebuildInterval is the minimum time between rebuilds of the
+// admission index caused by unknown node keys, so clients with unknown
+// keys cannot cause a database scan per connection.
+const derpAdmissionRebuildInterval = 10 * time.Second
+
+var errInvalidDERPRegion = errors.New("invalid DERP region")
+
+// derpAdmission decides which clients may connect to the DERP servers
+// verifying their clients with headscale.
+// It keeps an index of the nodes by node key, which is rebuilt when the
+// notifier sends an update changing the nodes, instead of loading all
+// nodes for every connection.
+type derpAdmission struct {
+	h *Headscale
+
+	// allowKeys are always admitted, like the key of the DERP prober.
+	allowKeys map[key.NodePublic]bool
+
+	mu      sync.Mutex
+	nodes   map[key.NodePublic]*types.Node
+	builtAt time.Time
+	stale   bool
+}
+
+func newDERPAdmission(h *Headscale) *derpAdmission {
+	return &derpAdmission{
+		h:         h,
+		allowKeys: make(map[key.NodePublic]bool),
+		stale:     true,
+	}
+}
+
+// observe is registered with the notifier and marks the index as stale
+// when an update changes the nodes, their keys or their expiry.
+func (a *derpAdmission) observe(update types.StateUpdate) {
+	switch update.Type {
+	case types.StatePeerChangedPatch:
+		changed := false
+		for _, patch := range update.ChangePatches {
+			if patch.KeyExpiry != nil || patch.Key != nil {
+				changed = true
+				break
+			}
+		}
+		if !changed {
+			return
+		}
+	case types.StateDERPUpdated:
+		return
+	}
+
+	a.mu.Lock()
+	a.stale = true
+	a.mu.Unlock()
+}
+
+// allow reports if the node with the given key may connect to the DERP
+// region. A regionID of zero means the region is not known and only the
+// node is checked.
+// Expired nodes, nodes waiting for approval, nodes of disabled users and
+// nodes not allowed in the region by the policy are refused.
+func (a *derpAdmission) allow(nodeKey key.NodePublic, regionID int) (bool, error) {
+	if a.allowKeys[nodeKey] {
+		return true, nil
+	}
+
+	node, err := a.lookup(nodeKey)
+	if err != nil {
+		return false, err
+	}
+
+	if node == nil || node.IsExpired() || node.PendingApproval || node.User.Disabled {
+		return false, nil
+	}
+
+	if regionID != 0 && !a.h.polMan.NodeCanUseDERPRegion(node, regionID) {
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// lookup returns the node with the given key, or nil. The index is
+// rebuilt if it is stale, or if the key is unknown and the index was not
+// rebuilt recently, to pick up nodes registered since.
+func (a *derpAdmission) lookup(nodeKey key.NodePublic) (*types.Node, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.stale {
+		if node, ok := a.nodes[nodeKey]; ok {
+			return node, nil
+		}
+
+		if time.Since(a.builtAt) < derpAdmissionRebuildInterval {
+			return nil, nil
+		}
+	}
+
+	if err := a.rebuildLocked(); err != nil {
+		return nil, err
+	}
+
+	return a.nodes[nodeKey], nil
+}
+
+func (a *derpAdmission) rebuildLocked() error {
+	nodes, err := a.h.db.ListNodes()
+	if err != nil {
+		return fmt.Errorf("cannot list nodes: %w", err)
+	}
+
+	a.nodes = make(map[key.NodePublic]*types.Node, len(nodes))
+	for _, node := range nodes {
+		a.nodes[node.NodeKey] = node
+	}
+	a.builtAt = time.Now()
+	a.stale = false
+
+	return nil
+}
+
+// verify answers a DERPAdmitClientRequest of a DERP server. The region of
+// the DERP server is read from the optional "region" query parameter of
+// the request, e.g. derper --verify-client-url=https://headscale/verify?region=900.
+func (a *derpAdmission) verify(req *http.Request) (bool, error) {
+	var regionID int
+	if region := req.URL.Query().Get("region"); region != "" {
+		var err error
+		regionID, err = strconv.Atoi(region)
+		if err != nil || regionID <= 0 {
+			return false, fmt.Errorf("%w: %q", errInvalidDERPRegion, region)
+		}
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return false, fmt.Errorf("cannot read request body: %w", err)
+	}
+
+	var derpAdmitClientRequest tailcfg.DERPAdmitClientRequest
+	if err := json.Unmarshal(body, &derpAdmitClientRequest); err != nil {
+		return false, fmt.Errorf("cannot parse derpAdmitClientRequest: %w", err)
+	}
+
+	return a.allow(derpAdmitClientRequest.NodePublic, regionID)
+}
+
+// derpVerifyURL returns the URL of the /verify endpoint of headscale, at
+// the given server URL, for the DERP server of the given region.
+func derpVerifyURL(serverURL string, regionID int) (string, error) {
+	verifyURL, err := url.JoinPath(serverURL, "verify")
+	if err != nil {
+		return "", fmt.Errorf("invalid server URL %q: %w", serverURL, err)
+	}
+
+	return verifyURL + "?region=" + strconv.Itoa(regionID), nil
+}
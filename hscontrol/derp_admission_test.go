@@ -0,0 +1,122 @@
+package hscontrol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestDERPAdmission(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	user, err := h.db.CreateUser(types.User{Name: "ops"})
+	require.NoError(t, err)
+
+	newNode := func(hostname, ip string) *types.Node {
+		addr := netip.MustParseAddr(ip)
+		node := &types.Node{
+			MachineKey:     key.NewMachine().Public(),
+			NodeKey:        key.NewNode().Public(),
+			Hostname:       hostname,
+			UserID:         user.ID,
+			IPv4:           &addr,
+			RegisterMethod: util.RegisterMethodAuthKey,
+			Hostinfo:       &tailcfg.Hostinfo{},
+		}
+		require.NoError(t, h.db.DB.Save(node).Error)
+
+		return node
+	}
+
+	server := newNode("server", "100.64.0.1")
+	server.ForcedTags = []string{"tag:infra"}
+	require.NoError(t, h.db.DB.Save(server).Error)
+	laptop := newNode("laptop", "100.64.0.2")
+	expired := newNode("expired", "100.64.0.3")
+	expiry := time.Now().Add(-time.Hour)
+	expired.Expiry = &expiry
+	require.NoError(t, h.db.DB.Save(expired).Error)
+
+	nodes, err := h.db.ListNodes()
+	require.NoError(t, err)
+	_, err = h.polMan.SetNodes(nodes)
+	require.NoError(t, err)
+
+	_, err = api.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: `{
+	"tagOwners": {"tag:infra": ["ops@"]},
+	"derpRegions": {"999": ["tag:infra"]},
+	"acls": [{"action": "accept", "src": ["*"], "dst": ["*:*"]}],
+}`})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		nodeKey  key.NodePublic
+		regionID int
+		want     bool
+	}{
+		{"tagged node in restricted region", server.NodeKey, 999, true},
+		{"untagged node in restricted region", laptop.NodeKey, 999, false},
+		{"untagged node in open region", laptop.NodeKey, 1, true},
+		{"unknown region", laptop.NodeKey, 0, true},
+		{"expired node", expired.NodeKey, 1, false},
+		{"unknown node", key.NewNode().Public(), 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := h.derpAdmission.allow(tt.nodeKey, tt.regionID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = api.SetUserDisabled(ctx, &v1.SetUserDisabledRequest{Id: uint64(user.ID), Disabled: true})
+	require.NoError(t, err)
+
+	allowed, err := h.derpAdmission.allow(laptop.NodeKey, 1)
+	require.NoError(t, err)
+	assert.False(t, allowed, "nodes of disabled users are refused")
+
+	_, err = api.SetUserDisabled(ctx, &v1.SetUserDisabledRequest{Id: uint64(user.ID), Disabled: false})
+	require.NoError(t, err)
+
+	// The embedded DERP server verifies its clients with the /verify
+	// endpoint, for its region.
+	srv := httptest.NewServer(http.HandlerFunc(h.VerifyHandler))
+	defer srv.Close()
+
+	verifyURL, err := derpVerifyURL(srv.URL+"/", 999)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL+"/verify?region=999", verifyURL)
+
+	verify := func(nodeKey key.NodePublic) bool {
+		body, err := json.Marshal(tailcfg.DERPAdmitClientRequest{NodePublic: nodeKey})
+		require.NoError(t, err)
+
+		res, err := http.Post(verifyURL, "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close()
+
+		var resp tailcfg.DERPAdmitClientResponse
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+
+		return resp.Allow
+	}
+	assert.True(t, verify(server.NodeKey))
+	assert.False(t, verify(laptop.NodeKey))
+}
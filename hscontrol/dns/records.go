@@ -0,0 +1,196 @@
+package dns
+
+import (
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/rs/zerolog/log"
+	"tailscale.com/tailcfg"
+)
+
+// maxCNAMEDepth limits how many CNAME records are followed to resolve
+// a name, protecting against loops.
+const maxCNAMEDepth = 8
+
+// NeedsResolution reports if any of the records is a CNAME, ALIAS or
+// wildcard record, which must be resolved by headscale with
+// ResolveRecords.
+func NeedsResolution(records []tailcfg.DNSRecord) bool {
+	return slices.ContainsFunc(records, func(record tailcfg.DNSRecord) bool {
+		return record.Type == types.DNSRecordTypeCNAME ||
+			record.Type == types.DNSRecordTypeAlias ||
+			types.IsWildcardDNSName(record.Name)
+	})
+}
+
+// ResolveRecords resolves the CNAME, ALIAS and wildcard records to A and
+// AAAA records, the only records the Tailscale clients serve. The other
+// records are returned as is.
+//
+// A CNAME record resolves to the addresses of its target, which is
+// another extra record or the MagicDNS name of a node. An ALIAS record
+// resolves to the addresses of the node given by ID or name, following
+// the node when its addresses change. As the clients only answer queries
+// for exact names, a wildcard record (*.example.com) is expanded to the
+// names of all nodes under the domain (node.example.com), unless they are
+// defined by another record. Nodes waiting for approval are left out.
+func ResolveRecords(records []tailcfg.DNSRecord, nodes types.Nodes, baseDomain string) []tailcfg.DNSRecord {
+	nodes = slices.DeleteFunc(slices.Clone(nodes), func(node *types.Node) bool {
+		return node.PendingApproval
+	})
+
+	r := resolver{
+		addrs:  make(map[string][]netip.Addr),
+		cnames: make(map[string]string),
+		nodes:  make(map[string][]netip.Addr),
+	}
+
+	for _, node := range nodes {
+		if fqdn, err := node.GetFQDN(baseDomain); err == nil {
+			r.nodes[normaliseName(fqdn)] = node.IPs()
+		}
+	}
+
+	var ret, aliases, cnames, wildcards []tailcfg.DNSRecord
+	for _, record := range records {
+		switch {
+		case types.IsWildcardDNSName(record.Name):
+			wildcards = append(wildcards, record)
+		case record.Type == types.DNSRecordTypeAlias:
+			aliases = append(aliases, record)
+		case record.Type == types.DNSRecordTypeCNAME:
+			cnames = append(cnames, record)
+			r.cnames[normaliseName(record.Name)] = normaliseName(record.Value)
+		default:
+			ret = append(ret, record)
+			if addr, err := netip.ParseAddr(record.Value); err == nil {
+				name := normaliseName(record.Name)
+				r.addrs[name] = append(r.addrs[name], addr)
+			}
+		}
+	}
+
+	// Aliases are resolved first, CNAME records can point to them.
+	for _, record := range aliases {
+		addrs := nodeAddrs(nodes, record.Value)
+		if len(addrs) == 0 {
+			log.Debug().Str("name", record.Name).Str("node", record.Value).Msg("DNS alias does not resolve to a node, ignoring")
+			continue
+		}
+
+		name := normaliseName(record.Name)
+		r.addrs[name] = append(r.addrs[name], addrs...)
+		ret = append(ret, addrRecords(record.Name, addrs)...)
+	}
+
+	for _, record := range cnames {
+		addrs := r.resolve(normaliseName(record.Value), 0)
+		if len(addrs) == 0 {
+			log.Debug().Str("name", record.Name).Str("target", record.Value).Msg("DNS CNAME record does not resolve, ignoring")
+			continue
+		}
+
+		ret = append(ret, addrRecords(record.Name, addrs)...)
+	}
+
+	for _, record := range wildcards {
+		var addrs []netip.Addr
+		switch record.Type {
+		case types.DNSRecordTypeAlias:
+			addrs = nodeAddrs(nodes, record.Value)
+		case types.DNSRecordTypeCNAME:
+			addrs = r.resolve(normaliseName(record.Value), 0)
+		default:
+			if addr, err := netip.ParseAddr(record.Value); err == nil {
+				addrs = []netip.Addr{addr}
+			}
+		}
+		if len(addrs) == 0 {
+			log.Debug().Str("name", record.Name).Str("value", record.Value).Msg("DNS wildcard record does not resolve, ignoring")
+			continue
+		}
+
+		suffix := strings.TrimPrefix(record.Name, "*")
+		for _, node := range nodes {
+			name := node.GivenName + suffix
+			if r.defines(normaliseName(name)) {
+				continue
+			}
+
+			ret = append(ret, addrRecords(name, addrs)...)
+		}
+	}
+
+	return ret
+}
+
+type resolver struct {
+	addrs  map[string][]netip.Addr
+	cnames map[string]string
+	nodes  map[string][]netip.Addr
+}
+
+// resolve returns the addresses of the name, from the extra records,
+// following CNAME records, or from the MagicDNS names of the nodes.
+func (r *resolver) resolve(name string, depth int) []netip.Addr {
+	if depth > maxCNAMEDepth {
+		return nil
+	}
+
+	if addrs, ok := r.addrs[name]; ok {
+		return addrs
+	}
+
+	if target, ok := r.cnames[name]; ok {
+		return r.resolve(target, depth+1)
+	}
+
+	return r.nodes[name]
+}
+
+// defines reports if the name is defined by an extra record or is the
+// MagicDNS name of a node.
+func (r *resolver) defines(name string) bool {
+	_, isRecord := r.addrs[name]
+	_, isCNAME := r.cnames[name]
+	_, isNode := r.nodes[name]
+
+	return isRecord || isCNAME || isNode
+}
+
+// nodeAddrs returns the addresses of the node with the given ID or name.
+func nodeAddrs(nodes types.Nodes, node string) []netip.Addr {
+	id, err := strconv.ParseUint(node, 10, 64)
+	for _, n := range nodes {
+		if (err == nil && n.ID == types.NodeID(id)) || strings.EqualFold(n.GivenName, node) {
+			return n.IPs()
+		}
+	}
+
+	return nil
+}
+
+func addrRecords(name string, addrs []netip.Addr) []tailcfg.DNSRecord {
+	records := make([]tailcfg.DNSRecord, 0, len(addrs))
+	for _, addr := range addrs {
+		recordType := "A"
+		if addr.Is6() {
+			recordType = "AAAA"
+		}
+
+		records = append(records, tailcfg.DNSRecord{
+			Name:  name,
+			Type:  recordType,
+			Value: addr.String(),
+		})
+	}
+
+	return records
+}
+
+func normaliseName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, "."))
+}
@@ -0,0 +1,96 @@
+package dns
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"tailscale.com/tailcfg"
+)
+
+func TestResolveRecords(t *testing.T) {
+	ap := func(s string) *netip.Addr {
+		addr := netip.MustParseAddr(s)
+		return &addr
+	}
+
+	nodes := types.Nodes{
+		{ID: 1, GivenName: "db", IPv4: ap("100.64.0.1"), IPv6: ap("fd7a:115c:a1e0::1")},
+		{ID: 2, GivenName: "web", IPv4: ap("100.64.0.2")},
+		{ID: 3, GivenName: "pending", IPv4: ap("100.64.0.4"), PendingApproval: true},
+	}
+
+	tests := []struct {
+		name    string
+		records []tailcfg.DNSRecord
+		want    []tailcfg.DNSRecord
+	}{
+		{
+			name: "address-records-unchanged",
+			records: []tailcfg.DNSRecord{
+				{Name: "grafana.example.com", Type: "A", Value: "100.64.0.3"},
+			},
+			want: []tailcfg.DNSRecord{
+				{Name: "grafana.example.com", Type: "A", Value: "100.64.0.3"},
+			},
+		},
+		{
+			name: "alias-by-id-and-name",
+			records: []tailcfg.DNSRecord{
+				{Name: "db.internal", Type: "ALIAS", Value: "1"},
+				{Name: "www.internal", Type: "ALIAS", Value: "web"},
+				{Name: "gone.internal", Type: "ALIAS", Value: "42"},
+			},
+			want: []tailcfg.DNSRecord{
+				{Name: "db.internal", Type: "A", Value: "100.64.0.1"},
+				{Name: "db.internal", Type: "AAAA", Value: "fd7a:115c:a1e0::1"},
+				{Name: "www.internal", Type: "A", Value: "100.64.0.2"},
+			},
+		},
+		{
+			name: "cname-to-record-node-and-chain",
+			records: []tailcfg.DNSRecord{
+				{Name: "grafana.example.com", Type: "A", Value: "100.64.0.3"},
+				{Name: "dashboards.example.com", Type: "CNAME", Value: "grafana.example.com"},
+				{Name: "metrics.example.com", Type: "CNAME", Value: "dashboards.example.com."},
+				{Name: "app.example.com", Type: "CNAME", Value: "web.ts.example.com"},
+				{Name: "loop.example.com", Type: "CNAME", Value: "loop.example.com"},
+			},
+			want: []tailcfg.DNSRecord{
+				{Name: "grafana.example.com", Type: "A", Value: "100.64.0.3"},
+				{Name: "dashboards.example.com", Type: "A", Value: "100.64.0.3"},
+				{Name: "metrics.example.com", Type: "A", Value: "100.64.0.3"},
+				{Name: "app.example.com", Type: "A", Value: "100.64.0.2"},
+			},
+		},
+		{
+			name: "wildcard-expanded-for-nodes",
+			records: []tailcfg.DNSRecord{
+				{Name: "*.svc.example.com", Type: "A", Value: "100.64.0.9"},
+				{Name: "db.svc.example.com", Type: "A", Value: "100.64.0.1"},
+			},
+			want: []tailcfg.DNSRecord{
+				{Name: "db.svc.example.com", Type: "A", Value: "100.64.0.1"},
+				{Name: "web.svc.example.com", Type: "A", Value: "100.64.0.9"},
+			},
+		},
+		{
+			name: "pending-nodes-not-resolved",
+			records: []tailcfg.DNSRecord{
+				{Name: "new.internal", Type: "ALIAS", Value: "pending"},
+				{Name: "app.example.com", Type: "CNAME", Value: "pending.ts.example.com"},
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ResolveRecords(tt.records, nodes, "ts.example.com")
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("ResolveRecords() unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
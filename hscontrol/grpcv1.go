@@ -13,10 +13,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pmezard/go-difflib/difflib"
 	"github.com/puzpuzpuz/xsync/v3"
 	"github.com/rs/zerolog/log"
 	"github.com/samber/lo"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
@@ -58,6 +61,8 @@ func (api headscaleV1APIServer) CreateUser(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "user.create", userTarget(user.ID), nil, user.Proto())
+
 	err = usersChangedHook(api.h.db, api.h.polMan, api.h.nodeNotifier)
 	if err != nil {
 		return nil, fmt.Errorf("updating resources using user: %w", err)
@@ -85,9 +90,43 @@ func (api headscaleV1APIServer) RenameUser(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "user.rename", userTarget(oldUser.ID), oldUser.Proto(), newUser.Proto())
+
 	return &v1.RenameUserResponse{User: newUser.Proto()}, nil
 }
 
+func (api headscaleV1APIServer) SetUserDisabled(
+	ctx context.Context,
+	request *v1.SetUserDisabledRequest,
+) (*v1.SetUserDisabledResponse, error) {
+	oldUser, err := api.h.db.GetUserByID(types.UserID(request.GetId()))
+	if err != nil {
+		return nil, err
+	}
+
+	err = api.h.db.SetUserDisabled(types.UserID(oldUser.ID), request.GetDisabled())
+	if err != nil {
+		return nil, err
+	}
+
+	newUser, err := api.h.db.GetUserByID(types.UserID(oldUser.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	action := "user.enable"
+	if request.GetDisabled() {
+		action = "user.disable"
+	}
+	api.h.auditLog.Record(requestActor(ctx), action, userTarget(oldUser.ID), oldUser.Proto(), newUser.Proto())
+
+	// The nodes of the user are shown as (not) authorized to their peers.
+	ctx = types.NotifyCtx(ctx, "user-disabled", newUser.Name)
+	api.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+
+	return &v1.SetUserDisabledResponse{User: newUser.Proto()}, nil
+}
+
 func (api headscaleV1APIServer) DeleteUser(
 	ctx context.Context,
 	request *v1.DeleteUserRequest,
@@ -102,6 +141,8 @@ func (api headscaleV1APIServer) DeleteUser(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "user.delete", userTarget(user.ID), user.Proto(), nil)
+
 	err = usersChangedHook(api.h.db, api.h.polMan, api.h.nodeNotifier)
 	if err != nil {
 		return nil, fmt.Errorf("updating resources using user: %w", err)
@@ -177,6 +218,8 @@ func (api headscaleV1APIServer) CreatePreAuthKey(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "preauthkey.create", preAuthKeyTarget(preAuthKey.ID), nil, auditPreAuthKey(preAuthKey))
+
 	return &v1.CreatePreAuthKeyResponse{PreAuthKey: preAuthKey.Proto()}, nil
 }
 
@@ -184,6 +227,8 @@ func (api headscaleV1APIServer) ExpirePreAuthKey(
 	ctx context.Context,
 	request *v1.ExpirePreAuthKeyRequest,
 ) (*v1.ExpirePreAuthKeyResponse, error) {
+	var before, after *v1.PreAuthKey
+	var preAuthKeyID uint64
 	err := api.h.db.Write(func(tx *gorm.DB) error {
 		preAuthKey, err := db.GetPreAuthKey(tx, request.Key)
 		if err != nil {
@@ -194,12 +239,21 @@ func (api headscaleV1APIServer) ExpirePreAuthKey(
 			return fmt.Errorf("preauth key does not belong to user")
 		}
 
-		return db.ExpirePreAuthKey(tx, preAuthKey)
+		preAuthKeyID = preAuthKey.ID
+		before = auditPreAuthKey(preAuthKey)
+		if err := db.ExpirePreAuthKey(tx, preAuthKey); err != nil {
+			return err
+		}
+		after = auditPreAuthKey(preAuthKey)
+
+		return nil
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "preauthkey.expire", preAuthKeyTarget(preAuthKeyID), before, after)
+
 	return &v1.ExpirePreAuthKeyResponse{}, nil
 }
 
@@ -258,12 +312,15 @@ func (api headscaleV1APIServer) RegisterNode(
 		types.UserID(user.ID),
 		nil,
 		util.RegisterMethodCLI,
+		false,
 		ipv4, ipv6,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.register", nodeTarget(node.ID), nil, node.Proto())
+
 	updateSent, err := nodesChangedHook(api.h.db, api.h.polMan, api.h.nodeNotifier)
 	if err != nil {
 		return nil, fmt.Errorf("updating resources using node: %w", err)
@@ -322,7 +379,10 @@ func (api headscaleV1APIServer) SetTags(
 		}
 	}
 
+	var before *v1.Node
 	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
 		err := db.SetTags(tx, types.NodeID(request.GetNodeId()), request.GetTags())
 		if err != nil {
 			return nil, err
@@ -336,6 +396,8 @@ func (api headscaleV1APIServer) SetTags(
 		}, status.Error(codes.InvalidArgument, err.Error())
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.tags", nodeTarget(node.ID), before, node.Proto())
+
 	ctx = types.NotifyCtx(ctx, "cli-settags", node.Hostname)
 	api.h.nodeNotifier.NotifyWithIgnore(ctx, types.UpdatePeerChanged(node.ID), node.ID)
 
@@ -369,7 +431,10 @@ func (api headscaleV1APIServer) SetApprovedRoutes(
 	tsaddr.SortPrefixes(routes)
 	routes = slices.Compact(routes)
 
+	var before *v1.Node
 	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
 		err := db.SetApprovedRoutes(tx, types.NodeID(request.GetNodeId()), routes)
 		if err != nil {
 			return nil, err
@@ -381,7 +446,10 @@ func (api headscaleV1APIServer) SetApprovedRoutes(
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	if api.h.primaryRoutes.SetRoutes(node.ID, node.SubnetRoutes()...) {
+	api.h.auditLog.Record(requestActor(ctx), "node.routes", nodeTarget(node.ID), before, node.Proto())
+
+	prioritiesChanged := api.h.primaryRoutes.SetPriorities(node.ID, api.h.routePriorities(node))
+	if api.h.primaryRoutes.SetRoutes(node.ID, node.SubnetRoutes()...) || prioritiesChanged {
 		ctx := types.NotifyCtx(ctx, "poll-primary-change", node.Hostname)
 		api.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
 	} else {
@@ -391,10 +459,98 @@ func (api headscaleV1APIServer) SetApprovedRoutes(
 
 	proto := node.Proto()
 	proto.SubnetRoutes = util.PrefixesToString(api.h.primaryRoutes.PrimaryRoutes(node.ID))
+	proto.PrimaryRouteReasons = primaryRouteReasons(api.h.primaryRoutes, node.ID)
 
 	return &v1.SetApprovedRoutesResponse{Node: proto}, nil
 }
 
+func (api headscaleV1APIServer) SetRoutePriority(
+	ctx context.Context,
+	request *v1.SetRoutePriorityRequest,
+) (*v1.SetRoutePriorityResponse, error) {
+	var routes []netip.Prefix
+	for _, route := range request.GetRoutes() {
+		prefix, err := netip.ParsePrefix(route)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "parsing route: %s", err)
+		}
+
+		if tsaddr.IsExitRoute(prefix) {
+			return nil, status.Errorf(codes.InvalidArgument, "route %s is an exit route, exit nodes have no primary", prefix)
+		}
+
+		routes = append(routes, prefix)
+	}
+
+	var before *v1.Node
+	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
+		node, err := db.GetNodeByID(tx, types.NodeID(request.GetNodeId()))
+		if err != nil {
+			return nil, err
+		}
+
+		if len(routes) == 0 {
+			routes = slices.DeleteFunc(slices.Clone(node.ApprovedRoutes), tsaddr.IsExitRoute)
+		}
+
+		priorities := make(map[netip.Prefix]int, len(node.RoutePriorities))
+		for prefix, priority := range node.RoutePriorities {
+			priorities[prefix] = priority
+		}
+		for _, route := range routes {
+			if request.GetPriority() == 0 {
+				delete(priorities, route)
+			} else {
+				priorities[route] = int(request.GetPriority())
+			}
+		}
+
+		err = db.SetRoutePriorities(tx, node.ID, priorities)
+		if err != nil {
+			return nil, err
+		}
+
+		return db.GetNodeByID(tx, node.ID)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "node %d not found", request.GetNodeId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "node.route_priority", nodeTarget(node.ID), before, node.Proto())
+
+	if api.h.primaryRoutes.SetPriorities(node.ID, api.h.routePriorities(node)) {
+		ctx := types.NotifyCtx(ctx, "poll-primary-change", node.Hostname)
+		api.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+	}
+
+	proto := node.Proto()
+	proto.SubnetRoutes = util.PrefixesToString(api.h.primaryRoutes.PrimaryRoutes(node.ID))
+	proto.PrimaryRouteReasons = primaryRouteReasons(api.h.primaryRoutes, node.ID)
+
+	return &v1.SetRoutePriorityResponse{Node: proto}, nil
+}
+
+// primaryRouteReasons returns why the node is the primary router of each of
+// its primary routes, keyed by the route.
+func primaryRouteReasons(pr *routes.PrimaryRoutes, id types.NodeID) map[string]string {
+	reasons := pr.PrimaryReasons(id)
+	if len(reasons) == 0 {
+		return nil
+	}
+
+	ret := make(map[string]string, len(reasons))
+	for prefix, reason := range reasons {
+		ret[prefix.String()] = reason
+	}
+
+	return ret
+}
+
 func validateTag(tag string) error {
 	if strings.Index(tag, "tag:") != 0 {
 		return errors.New("tag must start with the string 'tag:'")
@@ -422,6 +578,8 @@ func (api headscaleV1APIServer) DeleteNode(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.delete", nodeTarget(node.ID), node.Proto(), nil)
+
 	ctx = types.NotifyCtx(ctx, "cli-deletenode", node.Hostname)
 	api.h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerRemoved(node.ID))
 
@@ -434,7 +592,10 @@ func (api headscaleV1APIServer) ExpireNode(
 ) (*v1.ExpireNodeResponse, error) {
 	now := time.Now()
 
+	var before *v1.Node
 	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
 		db.NodeSetExpiry(
 			tx,
 			types.NodeID(request.GetNodeId()),
@@ -447,6 +608,8 @@ func (api headscaleV1APIServer) ExpireNode(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.expire", nodeTarget(node.ID), before, node.Proto())
+
 	ctx = types.NotifyCtx(ctx, "cli-expirenode-self", node.Hostname)
 	api.h.nodeNotifier.NotifyByNodeID(
 		ctx,
@@ -468,7 +631,10 @@ func (api headscaleV1APIServer) RenameNode(
 	ctx context.Context,
 	request *v1.RenameNodeRequest,
 ) (*v1.RenameNodeResponse, error) {
+	var before *v1.Node
 	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
 		err := db.RenameNode(
 			tx,
 			types.NodeID(request.GetNodeId()),
@@ -484,6 +650,8 @@ func (api headscaleV1APIServer) RenameNode(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.rename", nodeTarget(node.ID), before, node.Proto())
+
 	ctx = types.NotifyCtx(ctx, "cli-renamenode", node.Hostname)
 	api.h.nodeNotifier.NotifyWithIgnore(ctx, types.UpdatePeerChanged(node.ID), node.ID)
 
@@ -535,6 +703,13 @@ func (api headscaleV1APIServer) ListNodes(
 	return &v1.ListNodesResponse{Nodes: response}, nil
 }
 
+func (api headscaleV1APIServer) WatchNodes(
+	request *v1.WatchNodesRequest,
+	stream grpc.ServerStreamingServer[v1.NodeEvent],
+) error {
+	return api.h.nodeWatcher.watch(stream.Context(), request.GetTypes(), stream.Send)
+}
+
 func nodesToProto(polMan policy.PolicyManager, isLikelyConnected *xsync.MapOf[types.NodeID, bool], pr *routes.PrimaryRoutes, nodes types.Nodes) []*v1.Node {
 	response := make([]*v1.Node, len(nodes))
 	for index, node := range nodes {
@@ -554,6 +729,7 @@ func nodesToProto(polMan policy.PolicyManager, isLikelyConnected *xsync.MapOf[ty
 		}
 		resp.ValidTags = lo.Uniq(append(tags, node.ForcedTags...))
 		resp.SubnetRoutes = util.PrefixesToString(append(pr.PrimaryRoutes(node.ID), node.ExitRoutes()...))
+		resp.PrimaryRouteReasons = primaryRouteReasons(pr, node.ID)
 		response[index] = resp
 	}
 
@@ -564,11 +740,13 @@ func (api headscaleV1APIServer) MoveNode(
 	ctx context.Context,
 	request *v1.MoveNodeRequest,
 ) (*v1.MoveNodeResponse, error) {
+	var before *v1.Node
 	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
 		node, err := db.GetNodeByID(tx, types.NodeID(request.GetNodeId()))
 		if err != nil {
 			return nil, err
 		}
+		before = node.Proto()
 
 		err = db.AssignNodeToUser(tx, node, types.UserID(request.GetUser()))
 		if err != nil {
@@ -581,6 +759,8 @@ func (api headscaleV1APIServer) MoveNode(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.move", nodeTarget(node.ID), before, node.Proto())
+
 	ctx = types.NotifyCtx(ctx, "cli-movenode-self", node.Hostname)
 	api.h.nodeNotifier.NotifyByNodeID(
 		ctx,
@@ -607,9 +787,118 @@ func (api headscaleV1APIServer) BackfillNodeIPs(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "node.backfill_ips", "", nil, &v1.BackfillNodeIPsResponse{Changes: changes})
+
 	return &v1.BackfillNodeIPsResponse{Changes: changes}, nil
 }
 
+func (api headscaleV1APIServer) ListPendingNodes(
+	ctx context.Context,
+	request *v1.ListPendingNodesRequest,
+) (*v1.ListPendingNodesResponse, error) {
+	nodes, err := api.h.db.ListPendingNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if request.GetUser() != "" {
+		nodes = slices.DeleteFunc(nodes, func(node *types.Node) bool {
+			return node.User.Name != request.GetUser()
+		})
+	}
+
+	isLikelyConnected := api.h.nodeNotifier.LikelyConnectedMap()
+	response := nodesToProto(api.h.polMan, isLikelyConnected, api.h.primaryRoutes, nodes)
+
+	return &v1.ListPendingNodesResponse{Nodes: response}, nil
+}
+
+func (api headscaleV1APIServer) ApproveNode(
+	ctx context.Context,
+	request *v1.ApproveNodeRequest,
+) (*v1.ApproveNodeResponse, error) {
+	var before *v1.Node
+	node, err := db.Write(api.h.db.DB, func(tx *gorm.DB) (*types.Node, error) {
+		before = auditNode(tx, types.NodeID(request.GetNodeId()))
+
+		err := db.ApproveNode(tx, types.NodeID(request.GetNodeId()))
+		if err != nil {
+			return nil, err
+		}
+
+		node, err := db.GetNodeByID(tx, types.NodeID(request.GetNodeId()))
+		if err != nil {
+			return nil, err
+		}
+
+		// The routes of the node were not auto approved while it was
+		// waiting for approval.
+		if policy.AutoApproveRoutes(api.h.polMan, node) {
+			if err := tx.Save(node).Error; err != nil {
+				return nil, err
+			}
+		}
+
+		return node, nil
+	})
+	if errors.Is(err, db.ErrNodeNotPendingApproval) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "node.approve", nodeTarget(node.ID), before, node.Proto())
+
+	api.h.notifyPendingRoutes(node)
+
+	// The node gets its peers, and becomes a peer of the other nodes.
+	// Its routes are registered now, which can change the primary routes.
+	prioritiesChanged := api.h.primaryRoutes.SetPriorities(node.ID, api.h.routePriorities(node))
+	if api.h.primaryRoutes.SetRoutes(node.ID, node.SubnetRoutes()...) || prioritiesChanged {
+		ctx = types.NotifyCtx(ctx, "cli-approvenode-primary-change", node.Hostname)
+		api.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+	} else {
+		ctx = types.NotifyCtx(ctx, "cli-approvenode-self", node.Hostname)
+		api.h.nodeNotifier.NotifyByNodeID(ctx, types.UpdateFull(), node.ID)
+
+		ctx = types.NotifyCtx(ctx, "cli-approvenode-peers", node.Hostname)
+		api.h.nodeNotifier.NotifyWithIgnore(ctx, types.UpdatePeerChanged(node.ID), node.ID)
+	}
+
+	log.Trace().
+		Str("node", node.Hostname).
+		Msg("node approved")
+
+	return &v1.ApproveNodeResponse{Node: node.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) RejectNode(
+	ctx context.Context,
+	request *v1.RejectNodeRequest,
+) (*v1.RejectNodeResponse, error) {
+	node, err := api.h.db.GetNodeByID(types.NodeID(request.GetNodeId()))
+	if err != nil {
+		return nil, err
+	}
+
+	if !node.PendingApproval {
+		return nil, status.Error(codes.FailedPrecondition, db.ErrNodeNotPendingApproval.Error())
+	}
+
+	err = api.h.db.DeleteNode(node)
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "node.reject", nodeTarget(node.ID), node.Proto(), nil)
+
+	ctx = types.NotifyCtx(ctx, "cli-rejectnode", node.Hostname)
+	api.h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerRemoved(node.ID))
+
+	return &v1.RejectNodeResponse{}, nil
+}
+
 func (api headscaleV1APIServer) CreateApiKey(
 	ctx context.Context,
 	request *v1.CreateApiKeyRequest,
@@ -619,13 +908,28 @@ func (api headscaleV1APIServer) CreateApiKey(
 		expiration = request.GetExpiration().AsTime()
 	}
 
-	apiKey, _, err := api.h.db.CreateAPIKey(
+	scope, err := types.ParseAPIKeyScope(request.GetScope())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	for _, id := range request.GetUserIds() {
+		if _, err := api.h.db.GetUserByID(types.UserID(id)); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "looking up user %d: %s", id, err)
+		}
+	}
+
+	apiKey, key, err := api.h.db.CreateScopedAPIKey(
 		&expiration,
+		scope,
+		request.GetUserIds(),
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "apikey.create", apiKeyTarget(key.Prefix), nil, key.Proto())
+
 	return &v1.CreateApiKeyResponse{ApiKey: apiKey}, nil
 }
 
@@ -641,11 +945,14 @@ func (api headscaleV1APIServer) ExpireApiKey(
 		return nil, err
 	}
 
+	before := apiKey.Proto()
 	err = api.h.db.ExpireAPIKey(apiKey)
 	if err != nil {
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "apikey.expire", apiKeyTarget(apiKey.Prefix), before, apiKey.Proto())
+
 	return &v1.ExpireApiKeyResponse{}, nil
 }
 
@@ -688,6 +995,8 @@ func (api headscaleV1APIServer) DeleteApiKey(
 		return nil, err
 	}
 
+	api.h.auditLog.Record(requestActor(ctx), "apikey.delete", apiKeyTarget(apiKey.Prefix), apiKey.Proto(), nil)
+
 	return &v1.DeleteApiKeyResponse{}, nil
 }
 
@@ -728,14 +1037,35 @@ func (api headscaleV1APIServer) GetPolicy(
 }
 
 func (api headscaleV1APIServer) SetPolicy(
-	_ context.Context,
+	ctx context.Context,
 	request *v1.SetPolicyRequest,
 ) (*v1.SetPolicyResponse, error) {
 	if api.h.cfg.Policy.Mode != types.PolicyModeDB {
 		return nil, types.ErrPolicyUpdateIsDisabled
 	}
 
-	p := request.GetPolicy()
+	updated, err := api.applyPolicy(ctx, "policy.set", request.GetPolicy())
+	if err != nil {
+		return nil, err
+	}
+
+	response := &v1.SetPolicyResponse{
+		Policy:    updated.Data,
+		UpdatedAt: timestamppb.New(updated.UpdatedAt),
+	}
+
+	return response, nil
+}
+
+// applyPolicy sets the policy in the policy manager, stores it as a new
+// revision in the database and notifies the nodes if the filter changed.
+func (api headscaleV1APIServer) applyPolicy(ctx context.Context, action string, p string) (*types.Policy, error) {
+	actor := requestActor(ctx)
+
+	before, err := api.h.db.GetPolicy()
+	if err != nil && !errors.Is(err, types.ErrPolicyNotFound) {
+		return nil, fmt.Errorf("loading current policy: %w", err)
+	}
 
 	// Validate and reject configuration that would error when applied
 	// when creating a map response. This requires nodes, so there is still
@@ -747,6 +1077,9 @@ func (api headscaleV1APIServer) SetPolicy(
 		return nil, fmt.Errorf("loading nodes from database to validate policy: %w", err)
 	}
 	changed, err := api.h.polMan.SetPolicy([]byte(p))
+	if errors.Is(err, policy.ErrPolicyTestsFailed) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
 	if err != nil {
 		return nil, fmt.Errorf("setting policy: %w", err)
 	}
@@ -758,11 +1091,13 @@ func (api headscaleV1APIServer) SetPolicy(
 		}
 	}
 
-	updated, err := api.h.db.SetPolicy(p)
+	updated, err := api.h.db.SetPolicy(p, actor)
 	if err != nil {
 		return nil, err
 	}
 
+	api.h.auditLog.Record(actor, action, "policy", auditPolicy(before), auditPolicy(updated))
+
 	// Only send update if the packet filter has changed.
 	if changed {
 		err = api.h.autoApproveNodes()
@@ -774,12 +1109,663 @@ func (api headscaleV1APIServer) SetPolicy(
 		api.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
 	}
 
-	response := &v1.SetPolicyResponse{
-		Policy:    updated.Data,
-		UpdatedAt: timestamppb.New(updated.UpdatedAt),
+	return updated, nil
+}
+
+// requestAPIKeyPrefix returns the prefix of the API key the request was
+// made with, if any.
+func requestAPIKeyPrefix(ctx context.Context) (string, bool) {
+	meta, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
 	}
 
-	return response, nil
+	authHeader := meta.Get("authorization")
+	if len(authHeader) == 0 {
+		return "", false
+	}
+
+	token := strings.TrimPrefix(authHeader[0], AuthPrefix)
+	prefix, _, found := strings.Cut(token, ".")
+
+	return prefix, found
+}
+
+// requestActor describes who made the request, the prefix of the API key
+// if one was supplied, otherwise the request came over the local socket.
+func requestActor(ctx context.Context) string {
+	if prefix, ok := requestAPIKeyPrefix(ctx); ok {
+		return "api-key:" + prefix
+	}
+
+	return "unix-socket"
+}
+
+func (api headscaleV1APIServer) ListPolicyRevisions(
+	_ context.Context,
+	_ *v1.ListPolicyRevisionsRequest,
+) (*v1.ListPolicyRevisionsResponse, error) {
+	if api.h.cfg.Policy.Mode != types.PolicyModeDB {
+		return nil, types.ErrPolicyUpdateIsDisabled
+	}
+
+	policies, err := api.h.db.ListPolicies()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*v1.PolicyRevision, len(policies))
+	for index, p := range policies {
+		response[index] = p.Proto()
+	}
+
+	return &v1.ListPolicyRevisionsResponse{Revisions: response}, nil
+}
+
+func (api headscaleV1APIServer) getPolicyRevision(id uint64) (*types.Policy, error) {
+	var p *types.Policy
+	var err error
+	if id == 0 {
+		p, err = api.h.db.GetPolicy()
+	} else {
+		p, err = api.h.db.GetPolicyByID(id)
+	}
+	if errors.Is(err, types.ErrPolicyNotFound) {
+		return nil, status.Errorf(codes.NotFound, "policy revision %d not found", id)
+	}
+
+	return p, err
+}
+
+func (api headscaleV1APIServer) DiffPolicyRevisions(
+	_ context.Context,
+	request *v1.DiffPolicyRevisionsRequest,
+) (*v1.DiffPolicyRevisionsResponse, error) {
+	if api.h.cfg.Policy.Mode != types.PolicyModeDB {
+		return nil, types.ErrPolicyUpdateIsDisabled
+	}
+
+	if request.GetFromId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "from_id must be set")
+	}
+
+	from, err := api.getPolicyRevision(request.GetFromId())
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := api.getPolicyRevision(request.GetToId())
+	if err != nil {
+		return nil, err
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(from.Data),
+		B:        difflib.SplitLines(to.Data),
+		FromFile: fmt.Sprintf("revision %d", from.ID),
+		ToFile:   fmt.Sprintf("revision %d", to.ID),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("diffing policy revisions: %w", err)
+	}
+
+	return &v1.DiffPolicyRevisionsResponse{Diff: diff}, nil
+}
+
+func (api headscaleV1APIServer) RollbackPolicy(
+	ctx context.Context,
+	request *v1.RollbackPolicyRequest,
+) (*v1.RollbackPolicyResponse, error) {
+	if api.h.cfg.Policy.Mode != types.PolicyModeDB {
+		return nil, types.ErrPolicyUpdateIsDisabled
+	}
+
+	if request.GetRevisionId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "revision_id must be set")
+	}
+
+	revision, err := api.getPolicyRevision(request.GetRevisionId())
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := api.applyPolicy(ctx, "policy.rollback", revision.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info().
+		Uint64("revision", request.GetRevisionId()).
+		Uint("new_revision", updated.ID).
+		Msg("Policy rolled back")
+
+	return &v1.RollbackPolicyResponse{
+		Policy:    updated.Data,
+		UpdatedAt: timestamppb.New(updated.UpdatedAt),
+		Revision:  updated.Proto(),
+	}, nil
+}
+
+func (api headscaleV1APIServer) ListAuditEvents(
+	_ context.Context,
+	request *v1.ListAuditEventsRequest,
+) (*v1.ListAuditEventsResponse, error) {
+	filter := types.AuditEventFilter{
+		Actor:  request.GetActor(),
+		Action: request.GetAction(),
+		Target: request.GetTarget(),
+		Limit:  int(request.GetLimit()),
+	}
+	if request.GetSince() != nil {
+		filter.Since = request.GetSince().AsTime()
+	}
+	if request.GetUntil() != nil {
+		filter.Until = request.GetUntil().AsTime()
+	}
+
+	events, err := api.h.db.ListAuditEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*v1.AuditEvent, len(events))
+	for index, event := range events {
+		response[index] = event.Proto()
+	}
+
+	return &v1.ListAuditEventsResponse{Events: response}, nil
+}
+
+func (api headscaleV1APIServer) ListDNSRecords(
+	_ context.Context,
+	_ *v1.ListDNSRecordsRequest,
+) (*v1.ListDNSRecordsResponse, error) {
+	settings, err := api.h.db.GetDNSSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*v1.DNSRecord, len(settings.Records))
+	for index, record := range settings.Records {
+		response[index] = record.Proto()
+	}
+
+	return &v1.ListDNSRecordsResponse{Records: response}, nil
+}
+
+func (api headscaleV1APIServer) CreateDNSRecord(
+	ctx context.Context,
+	request *v1.CreateDNSRecordRequest,
+) (*v1.CreateDNSRecordResponse, error) {
+	record := types.DNSRecord{
+		Name:  request.GetName(),
+		Type:  request.GetType(),
+		Value: request.GetValue(),
+	}
+	if err := record.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := api.h.db.CreateDNSRecord(&record)
+	if errors.Is(err, db.ErrDNSRecordExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.record.create", dnsRecordTarget(record.ID), nil, record.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateDNSRecordResponse{Record: record.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) UpdateDNSRecord(
+	ctx context.Context,
+	request *v1.UpdateDNSRecordRequest,
+) (*v1.UpdateDNSRecordResponse, error) {
+	record := types.DNSRecord{
+		ID:    request.GetId(),
+		Name:  request.GetName(),
+		Type:  request.GetType(),
+		Value: request.GetValue(),
+	}
+	if err := record.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	old, err := api.h.db.UpdateDNSRecord(&record)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "DNS record %d not found", request.GetId())
+	}
+	if errors.Is(err, db.ErrDNSRecordExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.record.update", dnsRecordTarget(record.ID), old.Proto(), record.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.UpdateDNSRecordResponse{Record: record.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) DeleteDNSRecord(
+	ctx context.Context,
+	request *v1.DeleteDNSRecordRequest,
+) (*v1.DeleteDNSRecordResponse, error) {
+	record, err := api.h.db.DeleteDNSRecord(request.GetId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "DNS record %d not found", request.GetId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.record.delete", dnsRecordTarget(record.ID), record.Proto(), nil)
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteDNSRecordResponse{}, nil
+}
+
+func (api headscaleV1APIServer) ListNameservers(
+	_ context.Context,
+	_ *v1.ListNameserversRequest,
+) (*v1.ListNameserversResponse, error) {
+	settings, err := api.h.db.GetDNSSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*v1.Nameserver, len(settings.Nameservers))
+	for index, ns := range settings.Nameservers {
+		response[index] = ns.Proto()
+	}
+
+	return &v1.ListNameserversResponse{Nameservers: response}, nil
+}
+
+func (api headscaleV1APIServer) CreateNameserver(
+	ctx context.Context,
+	request *v1.CreateNameserverRequest,
+) (*v1.CreateNameserverResponse, error) {
+	ns := types.Nameserver{
+		Address: request.GetAddress(),
+		Domain:  request.GetDomain(),
+	}
+	if err := ns.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := api.h.db.CreateNameserver(&ns)
+	if errors.Is(err, db.ErrNameserverExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.nameserver.create", nameserverTarget(ns.ID), nil, ns.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateNameserverResponse{Nameserver: ns.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) UpdateNameserver(
+	ctx context.Context,
+	request *v1.UpdateNameserverRequest,
+) (*v1.UpdateNameserverResponse, error) {
+	ns := types.Nameserver{
+		ID:      request.GetId(),
+		Address: request.GetAddress(),
+		Domain:  request.GetDomain(),
+	}
+	if err := ns.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	old, err := api.h.db.UpdateNameserver(&ns)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "nameserver %d not found", request.GetId())
+	}
+	if errors.Is(err, db.ErrNameserverExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.nameserver.update", nameserverTarget(ns.ID), old.Proto(), ns.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.UpdateNameserverResponse{Nameserver: ns.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) DeleteNameserver(
+	ctx context.Context,
+	request *v1.DeleteNameserverRequest,
+) (*v1.DeleteNameserverResponse, error) {
+	ns, err := api.h.db.DeleteNameserver(request.GetId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "nameserver %d not found", request.GetId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.nameserver.delete", nameserverTarget(ns.ID), ns.Proto(), nil)
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteNameserverResponse{}, nil
+}
+
+func (api headscaleV1APIServer) ListSearchDomains(
+	_ context.Context,
+	_ *v1.ListSearchDomainsRequest,
+) (*v1.ListSearchDomainsResponse, error) {
+	settings, err := api.h.db.GetDNSSettings()
+	if err != nil {
+		return nil, err
+	}
+
+	response := make([]*v1.SearchDomain, len(settings.SearchDomains))
+	for index, sd := range settings.SearchDomains {
+		response[index] = sd.Proto()
+	}
+
+	return &v1.ListSearchDomainsResponse{SearchDomains: response}, nil
+}
+
+func (api headscaleV1APIServer) CreateSearchDomain(
+	ctx context.Context,
+	request *v1.CreateSearchDomainRequest,
+) (*v1.CreateSearchDomainResponse, error) {
+	sd := types.SearchDomain{
+		Domain: request.GetDomain(),
+	}
+	if err := sd.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := api.h.db.CreateSearchDomain(&sd)
+	if errors.Is(err, db.ErrSearchDomainExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.search_domain.create", searchDomainTarget(sd.ID), nil, sd.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateSearchDomainResponse{SearchDomain: sd.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) UpdateSearchDomain(
+	ctx context.Context,
+	request *v1.UpdateSearchDomainRequest,
+) (*v1.UpdateSearchDomainResponse, error) {
+	sd := types.SearchDomain{
+		ID:     request.GetId(),
+		Domain: request.GetDomain(),
+	}
+	if err := sd.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	old, err := api.h.db.UpdateSearchDomain(&sd)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "search domain %d not found", request.GetId())
+	}
+	if errors.Is(err, db.ErrSearchDomainExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.search_domain.update", searchDomainTarget(sd.ID), old.Proto(), sd.Proto())
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.UpdateSearchDomainResponse{SearchDomain: sd.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) DeleteSearchDomain(
+	ctx context.Context,
+	request *v1.DeleteSearchDomainRequest,
+) (*v1.DeleteSearchDomainResponse, error) {
+	sd, err := api.h.db.DeleteSearchDomain(request.GetId())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "search domain %d not found", request.GetId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "dns.search_domain.delete", searchDomainTarget(sd.ID), sd.Proto(), nil)
+
+	if err := api.h.notifyDNSChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteSearchDomainResponse{}, nil
+}
+
+func (api headscaleV1APIServer) ListDERPRegions(
+	_ context.Context,
+	_ *v1.ListDERPRegionsRequest,
+) (*v1.ListDERPRegionsResponse, error) {
+	regions, err := api.h.derpRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ListDERPRegionsResponse{Regions: regions}, nil
+}
+
+func (api headscaleV1APIServer) CreateDERPRegion(
+	ctx context.Context,
+	request *v1.CreateDERPRegionRequest,
+) (*v1.CreateDERPRegionResponse, error) {
+	region := types.DERPRegion{
+		RegionID: int(request.GetRegionId()),
+		Code:     request.GetCode(),
+		Name:     request.GetName(),
+	}
+	if err := region.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err := api.h.db.CreateDERPRegion(&region)
+	if errors.Is(err, db.ErrDERPRegionExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "derp.region.create", derpRegionTarget(region.RegionID), nil, region.Proto())
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateDERPRegionResponse{Region: region.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) DeleteDERPRegion(
+	ctx context.Context,
+	request *v1.DeleteDERPRegionRequest,
+) (*v1.DeleteDERPRegionResponse, error) {
+	region, err := api.h.db.DeleteDERPRegion(int(request.GetRegionId()))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "DERP region %d is not managed via the API", request.GetRegionId())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "derp.region.delete", derpRegionTarget(region.RegionID), region.Proto(), nil)
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteDERPRegionResponse{}, nil
+}
+
+func (api headscaleV1APIServer) SetDERPRegionDisabled(
+	ctx context.Context,
+	request *v1.SetDERPRegionDisabledRequest,
+) (*v1.SetDERPRegionDisabledResponse, error) {
+	regionID := int(request.GetRegionId())
+	if ok, err := api.h.derpRegionExists(regionID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, status.Errorf(codes.NotFound, "DERP region %d not found", regionID)
+	}
+
+	if err := api.h.db.SetDERPRegionDisabled(regionID, request.GetDisabled()); err != nil {
+		return nil, err
+	}
+
+	action := "derp.region.enable"
+	if request.GetDisabled() {
+		action = "derp.region.disable"
+	}
+	api.h.auditLog.Record(requestActor(ctx), action, derpRegionTarget(regionID), nil, nil)
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.SetDERPRegionDisabledResponse{}, nil
+}
+
+func (api headscaleV1APIServer) CreateDERPNode(
+	ctx context.Context,
+	request *v1.CreateDERPNodeRequest,
+) (*v1.CreateDERPNodeResponse, error) {
+	node := types.DERPNode{
+		Name:     request.GetName(),
+		RegionID: int(request.GetRegionId()),
+		HostName: request.GetHostName(),
+		IPv4:     request.GetIpv4(),
+		IPv6:     request.GetIpv6(),
+		STUNPort: int(request.GetStunPort()),
+		DERPPort: int(request.GetDerpPort()),
+		STUNOnly: request.GetStunOnly(),
+	}
+	if err := node.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if ok, err := api.h.derpRegionExists(node.RegionID); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "DERP region %d not found", node.RegionID)
+	}
+
+	err := api.h.db.CreateDERPNode(&node)
+	if errors.Is(err, db.ErrDERPNodeExists) {
+		return nil, status.Error(codes.AlreadyExists, err.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "derp.node.create", derpNodeTarget(node.Name), nil, node.Proto())
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.CreateDERPNodeResponse{Node: node.Proto()}, nil
+}
+
+func (api headscaleV1APIServer) DeleteDERPNode(
+	ctx context.Context,
+	request *v1.DeleteDERPNodeRequest,
+) (*v1.DeleteDERPNodeResponse, error) {
+	node, err := api.h.db.DeleteDERPNode(request.GetName())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, status.Errorf(codes.NotFound, "DERP node %q is not managed via the API", request.GetName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	api.h.auditLog.Record(requestActor(ctx), "derp.node.delete", derpNodeTarget(node.Name), node.Proto(), nil)
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.DeleteDERPNodeResponse{}, nil
+}
+
+func (api headscaleV1APIServer) SetDERPNodeDisabled(
+	ctx context.Context,
+	request *v1.SetDERPNodeDisabledRequest,
+) (*v1.SetDERPNodeDisabledResponse, error) {
+	regions, err := api.h.derpRegions()
+	if err != nil {
+		return nil, err
+	}
+
+	regionID := 0
+	for _, region := range regions {
+		for _, node := range region.GetNodes() {
+			if node.GetName() == request.GetName() {
+				regionID = int(region.GetRegionId())
+			}
+		}
+	}
+	if regionID == 0 {
+		return nil, status.Errorf(codes.NotFound, "DERP node %q not found", request.GetName())
+	}
+
+	if err := api.h.db.SetDERPNodeDisabled(request.GetName(), regionID, request.GetDisabled()); err != nil {
+		return nil, err
+	}
+
+	action := "derp.node.enable"
+	if request.GetDisabled() {
+		action = "derp.node.disable"
+	}
+	api.h.auditLog.Record(requestActor(ctx), action, derpNodeTarget(request.GetName()), nil, nil)
+
+	if err := api.h.notifyDERPChange(ctx); err != nil {
+		return nil, err
+	}
+
+	return &v1.SetDERPNodeDisabledResponse{}, nil
 }
 
 // The following service calls are for testing and debugging
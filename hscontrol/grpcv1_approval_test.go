@@ -0,0 +1,133 @@
+package hscontrol
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestNodeApproval(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	alice, err := h.db.CreateUser(types.User{Name: "alice"})
+	require.NoError(t, err)
+	bob, err := h.db.CreateUser(types.User{Name: "bob"})
+	require.NoError(t, err)
+
+	route := netip.MustParsePrefix("10.0.0.0/24")
+
+	// register registers a node of the machine like an OIDC login with
+	// node approval enabled.
+	register := func(machineKey key.MachinePrivate, hostname string, user *types.User) *types.Node {
+		registrationID, err := types.NewRegistrationID()
+		require.NoError(t, err)
+
+		h.registrationCache.Set(registrationID, types.RegisterNode{
+			Node: types.Node{
+				MachineKey: machineKey.Public(),
+				NodeKey:    key.NewNode().Public(),
+				Hostname:   hostname,
+				Hostinfo: &tailcfg.Hostinfo{
+					RoutableIPs: []netip.Prefix{route},
+				},
+			},
+			Registered: make(chan *types.Node, 1),
+		})
+
+		ipv4, ipv6, err := h.ipAlloc.Next()
+		require.NoError(t, err)
+
+		node, _, err := h.db.HandleNodeFromAuthPath(
+			registrationID,
+			types.UserID(user.ID),
+			nil,
+			util.RegisterMethodOIDC,
+			true,
+			ipv4, ipv6,
+		)
+		require.NoError(t, err)
+
+		return node
+	}
+
+	laptopKey := key.NewMachine()
+	laptop := register(laptopKey, "laptop", alice)
+	phone := register(key.NewMachine(), "phone", bob)
+
+	assert.True(t, laptop.PendingApproval)
+	assert.False(t, nodeToRegisterResponse(laptop).MachineAuthorized)
+
+	allowed, err := h.derpAdmission.allow(laptop.NodeKey, 0)
+	require.NoError(t, err)
+	assert.False(t, allowed, "nodes waiting for approval are refused")
+
+	pending, err := api.ListPendingNodes(ctx, &v1.ListPendingNodesRequest{})
+	require.NoError(t, err)
+	require.Len(t, pending.GetNodes(), 2)
+	assert.True(t, pending.GetNodes()[0].GetPendingApproval())
+
+	pending, err = api.ListPendingNodes(ctx, &v1.ListPendingNodesRequest{User: "bob"})
+	require.NoError(t, err)
+	require.Len(t, pending.GetNodes(), 1)
+	assert.Equal(t, "phone", pending.GetNodes()[0].GetName())
+
+	// Nodes waiting for approval don't route, even if their routes are
+	// approved.
+	_, err = api.SetApprovedRoutes(ctx, &v1.SetApprovedRoutesRequest{
+		NodeId: uint64(laptop.ID),
+		Routes: []string{route.String()},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, h.primaryRoutes.PrimaryRoutes(laptop.ID))
+
+	approved, err := api.ApproveNode(ctx, &v1.ApproveNodeRequest{NodeId: uint64(laptop.ID)})
+	require.NoError(t, err)
+	assert.False(t, approved.GetNode().GetPendingApproval())
+	assert.Equal(t, []netip.Prefix{route}, h.primaryRoutes.PrimaryRoutes(laptop.ID))
+
+	allowed, err = h.derpAdmission.allow(laptop.NodeKey, 0)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	_, err = api.ApproveNode(ctx, &v1.ApproveNodeRequest{NodeId: uint64(laptop.ID)})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = api.RejectNode(ctx, &v1.RejectNodeRequest{NodeId: uint64(laptop.ID)})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err), "approved nodes are not rejected")
+
+	_, err = api.RejectNode(ctx, &v1.RejectNodeRequest{NodeId: uint64(phone.ID)})
+	require.NoError(t, err)
+
+	_, err = h.db.GetNodeByID(phone.ID)
+	assert.Error(t, err, "rejected nodes are deleted")
+
+	pending, err = api.ListPendingNodes(ctx, &v1.ListPendingNodesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, pending.GetNodes())
+
+	// An approved machine logging in again stays approved.
+	laptop = register(laptopKey, "laptop", alice)
+	assert.False(t, laptop.PendingApproval)
+
+	events, err := h.db.ListAuditEvents(types.AuditEventFilter{})
+	require.NoError(t, err)
+
+	var actions []string
+	for _, event := range events {
+		actions = append(actions, event.Action)
+	}
+	assert.Contains(t, actions, "node.approve")
+	assert.Contains(t, actions, "node.reject")
+}
@@ -0,0 +1,77 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuditEvents(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+
+	keyCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", AuthPrefix+"abcdefg.secret",
+	))
+
+	created, err := api.CreateUser(keyCtx, &v1.CreateUserRequest{Name: "alice"})
+	require.NoError(t, err)
+
+	_, err = api.RenameUser(context.Background(), &v1.RenameUserRequest{
+		OldId:   created.GetUser().GetId(),
+		NewName: "bob",
+	})
+	require.NoError(t, err)
+
+	pak, err := api.CreatePreAuthKey(keyCtx, &v1.CreatePreAuthKeyRequest{
+		User: created.GetUser().GetId(),
+	})
+	require.NoError(t, err)
+
+	_, err = api.ExpirePreAuthKey(keyCtx, &v1.ExpirePreAuthKeyRequest{
+		User: created.GetUser().GetId(),
+		Key:  pak.GetPreAuthKey().GetKey(),
+	})
+	require.NoError(t, err)
+
+	list, err := api.ListAuditEvents(context.Background(), &v1.ListAuditEventsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetEvents(), 4)
+
+	events := list.GetEvents()
+	assert.Equal(t, "preauthkey.expire", events[0].GetAction())
+	assert.Equal(t, "preauthkey.create", events[1].GetAction())
+	assert.NotContains(t, events[1].GetAfter(), pak.GetPreAuthKey().GetKey())
+	assert.NotEqual(t, events[0].GetBefore(), events[0].GetAfter())
+
+	rename := events[2]
+	assert.Equal(t, "user.rename", rename.GetAction())
+	assert.Equal(t, "unix-socket", rename.GetActor())
+	assert.Equal(t, "user:1", rename.GetTarget())
+	assert.Contains(t, rename.GetBefore(), `"name":"alice"`)
+	assert.Contains(t, rename.GetAfter(), `"name":"bob"`)
+
+	create := events[3]
+	assert.Equal(t, "user.create", create.GetAction())
+	assert.Equal(t, "api-key:abcdefg", create.GetActor())
+	assert.Empty(t, create.GetBefore())
+
+	filtered, err := api.ListAuditEvents(context.Background(), &v1.ListAuditEventsRequest{
+		Actor: "api-key:abcdefg",
+		Limit: 2,
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered.GetEvents(), 2)
+	assert.Equal(t, "preauthkey.expire", filtered.GetEvents()[0].GetAction())
+	assert.Equal(t, "preauthkey.create", filtered.GetEvents()[1].GetAction())
+
+	filtered, err = api.ListAuditEvents(context.Background(), &v1.ListAuditEventsRequest{
+		Target: "user:1",
+	})
+	require.NoError(t, err)
+	require.Len(t, filtered.GetEvents(), 2)
+}
@@ -0,0 +1,86 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tailscale.com/tailcfg"
+)
+
+func TestDERPRegions(t *testing.T) {
+	h := newTestHeadscale(t)
+	h.baseDERPMap = &tailcfg.DERPMap{
+		Regions: map[int]*tailcfg.DERPRegion{
+			1: {
+				RegionID:   1,
+				RegionCode: "nyc",
+				RegionName: "New York",
+				Nodes: []*tailcfg.DERPNode{
+					{Name: "1a", RegionID: 1, HostName: "derp1a.example.com"},
+					{Name: "1b", RegionID: 1, HostName: "derp1b.example.com"},
+				},
+			},
+		},
+	}
+	_, err := h.updateDERPMap()
+	require.NoError(t, err)
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	_, err = api.CreateDERPRegion(ctx, &v1.CreateDERPRegionRequest{RegionId: 900, Code: "office"})
+	require.NoError(t, err)
+
+	_, err = api.CreateDERPRegion(ctx, &v1.CreateDERPRegionRequest{RegionId: 900, Code: "office"})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = api.CreateDERPNode(ctx, &v1.CreateDERPNodeRequest{Name: "900a", RegionId: 900, HostName: "derp.office.example.com"})
+	require.NoError(t, err)
+
+	_, err = api.CreateDERPNode(ctx, &v1.CreateDERPNodeRequest{Name: "42a", RegionId: 42, HostName: "derp.example.com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = api.SetDERPNodeDisabled(ctx, &v1.SetDERPNodeDisabledRequest{Name: "1a", Disabled: true})
+	require.NoError(t, err)
+
+	_, err = api.SetDERPNodeDisabled(ctx, &v1.SetDERPNodeDisabledRequest{Name: "unknown", Disabled: true})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	require.Len(t, h.currentDERPMap().Regions, 2)
+	require.Len(t, h.currentDERPMap().Regions[1].Nodes, 1)
+	assert.Equal(t, "1b", h.currentDERPMap().Regions[1].Nodes[0].Name)
+	assert.Equal(t, "derp.office.example.com", h.currentDERPMap().Regions[900].Nodes[0].HostName)
+
+	_, err = api.SetDERPRegionDisabled(ctx, &v1.SetDERPRegionDisabledRequest{RegionId: 1, Disabled: true})
+	require.NoError(t, err)
+	assert.NotContains(t, h.currentDERPMap().Regions, 1)
+
+	list, err := api.ListDERPRegions(ctx, &v1.ListDERPRegionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetRegions(), 2)
+	assert.Equal(t, "config", list.GetRegions()[0].GetSource())
+	assert.True(t, list.GetRegions()[0].GetDisabled())
+	require.Len(t, list.GetRegions()[0].GetNodes(), 2)
+	assert.True(t, list.GetRegions()[0].GetNodes()[0].GetDisabled())
+	assert.Equal(t, "api", list.GetRegions()[1].GetSource())
+
+	_, err = api.SetDERPRegionDisabled(ctx, &v1.SetDERPRegionDisabledRequest{RegionId: 1, Disabled: false})
+	require.NoError(t, err)
+	_, err = api.SetDERPNodeDisabled(ctx, &v1.SetDERPNodeDisabledRequest{Name: "1a", Disabled: false})
+	require.NoError(t, err)
+	require.Len(t, h.currentDERPMap().Regions[1].Nodes, 2)
+
+	_, err = api.DeleteDERPRegion(ctx, &v1.DeleteDERPRegionRequest{RegionId: 900})
+	require.NoError(t, err)
+	assert.NotContains(t, h.currentDERPMap().Regions, 900)
+
+	_, err = api.DeleteDERPNode(ctx, &v1.DeleteDERPNodeRequest{Name: "900a"})
+	assert.Equal(t, codes.NotFound, status.Code(err), "nodes are deleted with their region")
+
+	_, err = api.DeleteDERPRegion(ctx, &v1.DeleteDERPRegionRequest{RegionId: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,169 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/dnstype"
+)
+
+func TestDNSSettings(t *testing.T) {
+	h := newTestHeadscale(t)
+	h.baseDNSConfig = &tailcfg.DNSConfig{
+		Domains:      []string{"example.ts.net"},
+		ExtraRecords: []tailcfg.DNSRecord{{Name: "config.example.ts.net", Type: "A", Value: "100.64.0.1"}},
+	}
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	record, err := api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+		Name:  "grafana.example.ts.net.",
+		Value: "100.64.0.10",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "grafana.example.ts.net", record.GetRecord().GetName())
+	assert.Equal(t, "A", record.GetRecord().GetType())
+
+	_, err = api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+		Name:  "grafana.example.ts.net",
+		Value: "100.64.0.10",
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+		Name:  "grafana.example.ts.net",
+		Type:  "AAAA",
+		Value: "100.64.0.10",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+		Name:  "example.ts.net",
+		Type:  "TXT",
+		Value: "v=spf1 -all",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	wildcard, err := api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{
+		Name:  "*.svc.example.ts.net",
+		Type:  "cname",
+		Value: "grafana.example.ts.net.",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "CNAME", wildcard.GetRecord().GetType())
+	assert.Equal(t, "grafana.example.ts.net", wildcard.GetRecord().GetValue())
+	_, err = api.DeleteDNSRecord(ctx, &v1.DeleteDNSRecordRequest{Id: wildcard.GetRecord().GetId()})
+	require.NoError(t, err)
+
+	_, err = api.CreateNameserver(ctx, &v1.CreateNameserverRequest{Address: "1.1.1.1"})
+	require.NoError(t, err)
+	split, err := api.CreateNameserver(ctx, &v1.CreateNameserverRequest{Address: "10.0.0.53", Domain: "corp.example.com"})
+	require.NoError(t, err)
+
+	_, err = api.CreateNameserver(ctx, &v1.CreateNameserverRequest{Address: "not-a-nameserver"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = api.CreateSearchDomain(ctx, &v1.CreateSearchDomainRequest{Domain: "corp.example.com"})
+	require.NoError(t, err)
+
+	assert.Equal(t, &tailcfg.DNSConfig{
+		Domains:           []string{"example.ts.net", "corp.example.com"},
+		FallbackResolvers: []*dnstype.Resolver{{Addr: "1.1.1.1"}},
+		Routes: map[string][]*dnstype.Resolver{
+			"corp.example.com": {{Addr: "10.0.0.53"}},
+		},
+		ExtraRecords: []tailcfg.DNSRecord{
+			{Name: "config.example.ts.net", Type: "A", Value: "100.64.0.1"},
+			{Name: "grafana.example.ts.net", Type: "A", Value: "100.64.0.10"},
+		},
+	}, h.cfg.TailcfgDNS())
+
+	records, err := api.ListDNSRecords(ctx, &v1.ListDNSRecordsRequest{})
+	require.NoError(t, err)
+	require.Len(t, records.GetRecords(), 1)
+
+	_, err = api.DeleteDNSRecord(ctx, &v1.DeleteDNSRecordRequest{Id: record.GetRecord().GetId()})
+	require.NoError(t, err)
+	_, err = api.DeleteNameserver(ctx, &v1.DeleteNameserverRequest{Id: split.GetNameserver().GetId()})
+	require.NoError(t, err)
+
+	_, err = api.DeleteNameserver(ctx, &v1.DeleteNameserverRequest{Id: split.GetNameserver().GetId()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	assert.Equal(t, []tailcfg.DNSRecord{
+		{Name: "config.example.ts.net", Type: "A", Value: "100.64.0.1"},
+	}, h.cfg.TailcfgDNS().ExtraRecords)
+	assert.Empty(t, h.cfg.TailcfgDNS().Routes)
+}
+
+func TestDNSSettingsUpdate(t *testing.T) {
+	h := newTestHeadscale(t)
+	h.baseDNSConfig = &tailcfg.DNSConfig{}
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	record, err := api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{Name: "grafana.example.ts.net", Value: "100.64.0.10"})
+	require.NoError(t, err)
+	other, err := api.CreateDNSRecord(ctx, &v1.CreateDNSRecordRequest{Name: "prometheus.example.ts.net", Value: "100.64.0.11"})
+	require.NoError(t, err)
+	ns, err := api.CreateNameserver(ctx, &v1.CreateNameserverRequest{Address: "1.1.1.1"})
+	require.NoError(t, err)
+	sd, err := api.CreateSearchDomain(ctx, &v1.CreateSearchDomainRequest{Domain: "corp.example.com"})
+	require.NoError(t, err)
+
+	updated, err := api.UpdateDNSRecord(ctx, &v1.UpdateDNSRecordRequest{
+		Id:    record.GetRecord().GetId(),
+		Name:  "grafana.example.ts.net",
+		Value: "fd7a:115c:a1e0::10",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "AAAA", updated.GetRecord().GetType())
+	assert.Equal(t, record.GetRecord().GetCreatedAt().AsTime(), updated.GetRecord().GetCreatedAt().AsTime())
+
+	_, err = api.UpdateDNSRecord(ctx, &v1.UpdateDNSRecordRequest{
+		Id:    other.GetRecord().GetId(),
+		Name:  "grafana.example.ts.net",
+		Value: "fd7a:115c:a1e0::10",
+	})
+	assert.Equal(t, codes.AlreadyExists, status.Code(err))
+
+	_, err = api.UpdateDNSRecord(ctx, &v1.UpdateDNSRecordRequest{Id: 100, Name: "grafana.example.ts.net", Value: "100.64.0.10"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = api.UpdateDNSRecord(ctx, &v1.UpdateDNSRecordRequest{Id: record.GetRecord().GetId(), Name: "grafana.example.ts.net"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// A global nameserver becomes a split route.
+	_, err = api.UpdateNameserver(ctx, &v1.UpdateNameserverRequest{
+		Id:      ns.GetNameserver().GetId(),
+		Address: "10.0.0.53",
+		Domain:  "corp.example.com",
+	})
+	require.NoError(t, err)
+
+	_, err = api.UpdateNameserver(ctx, &v1.UpdateNameserverRequest{Id: 100, Address: "1.1.1.1"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = api.UpdateSearchDomain(ctx, &v1.UpdateSearchDomainRequest{
+		Id:     sd.GetSearchDomain().GetId(),
+		Domain: "internal.example.com",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &tailcfg.DNSConfig{
+		Domains: []string{"internal.example.com"},
+		Routes: map[string][]*dnstype.Resolver{
+			"corp.example.com": {{Addr: "10.0.0.53"}},
+		},
+		ExtraRecords: []tailcfg.DNSRecord{
+			{Name: "grafana.example.ts.net", Type: "AAAA", Value: "fd7a:115c:a1e0::10"},
+			{Name: "prometheus.example.ts.net", Type: "A", Value: "100.64.0.11"},
+		},
+	}, h.cfg.TailcfgDNS())
+}
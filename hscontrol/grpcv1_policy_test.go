@@ -0,0 +1,68 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"tailscale.com/tailcfg"
+)
+
+func TestPolicyRevisions(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+
+	policies := []string{
+		`{"acls": [{"action": "accept", "src": ["*"], "dst": ["*:*"]}]}`,
+		`{"acls": [{"action": "accept", "src": ["*"], "dst": ["*:22"]}]}`,
+	}
+
+	keyCtx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", AuthPrefix+"abcdefg.secret",
+	))
+
+	_, err := api.SetPolicy(context.Background(), &v1.SetPolicyRequest{Policy: policies[0]})
+	require.NoError(t, err)
+	_, err = api.SetPolicy(keyCtx, &v1.SetPolicyRequest{Policy: policies[1]})
+	require.NoError(t, err)
+
+	list, err := api.ListPolicyRevisions(context.Background(), &v1.ListPolicyRevisionsRequest{})
+	require.NoError(t, err)
+	require.Len(t, list.GetRevisions(), 2)
+	assert.Equal(t, "api-key:abcdefg", list.GetRevisions()[0].GetAuthor())
+	assert.Equal(t, "unix-socket", list.GetRevisions()[1].GetAuthor())
+
+	first := list.GetRevisions()[1].GetId()
+
+	diff, err := api.DiffPolicyRevisions(context.Background(), &v1.DiffPolicyRevisionsRequest{
+		FromId: first,
+	})
+	require.NoError(t, err)
+	assert.Contains(t, diff.GetDiff(), `-{"acls": [{"action": "accept", "src": ["*"], "dst": ["*:*"]}]}`)
+	assert.Contains(t, diff.GetDiff(), `+{"acls": [{"action": "accept", "src": ["*"], "dst": ["*:22"]}]}`)
+
+	_, err = api.DiffPolicyRevisions(context.Background(), &v1.DiffPolicyRevisionsRequest{
+		FromId: 1000,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	rollback, err := api.RollbackPolicy(context.Background(), &v1.RollbackPolicyRequest{
+		RevisionId: first,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, policies[0], rollback.GetPolicy())
+	assert.Greater(t, rollback.GetRevision().GetId(), list.GetRevisions()[0].GetId())
+
+	current, err := api.GetPolicy(context.Background(), &v1.GetPolicyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, policies[0], current.GetPolicy())
+
+	rules, _ := h.polMan.Filter()
+	require.Len(t, rules, 1)
+	assert.Equal(t, tailcfg.PortRangeAny, rules[0].DstPorts[0].Ports)
+}
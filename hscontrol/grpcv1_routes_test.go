@@ -0,0 +1,87 @@
+package hscontrol
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestSetRoutePriority(t *testing.T) {
+	h := newTestHeadscale(t)
+	api := newHeadscaleV1APIServer(h)
+	ctx := context.Background()
+
+	user, err := h.db.CreateUser(types.User{Name: "routers"})
+	require.NoError(t, err)
+
+	route := netip.MustParsePrefix("10.0.0.0/24")
+	var nodes []*types.Node
+	for _, hostname := range []string{"router1", "router2"} {
+		node := &types.Node{
+			MachineKey:     key.NewMachine().Public(),
+			NodeKey:        key.NewNode().Public(),
+			Hostname:       hostname,
+			UserID:         user.ID,
+			RegisterMethod: util.RegisterMethodAuthKey,
+			Hostinfo: &tailcfg.Hostinfo{
+				RoutableIPs: []netip.Prefix{route},
+			},
+		}
+		require.NoError(t, h.db.DB.Save(node).Error)
+		nodes = append(nodes, node)
+
+		_, err := api.SetApprovedRoutes(ctx, &v1.SetApprovedRoutesRequest{
+			NodeId: uint64(node.ID),
+			Routes: []string{route.String()},
+		})
+		require.NoError(t, err)
+	}
+
+	resp, err := api.SetRoutePriority(ctx, &v1.SetRoutePriorityRequest{
+		NodeId:   uint64(nodes[1].ID),
+		Priority: 10,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int32{"10.0.0.0/24": 10}, resp.GetNode().GetRoutePriorities())
+	assert.Equal(t, []string{"10.0.0.0/24"}, resp.GetNode().GetSubnetRoutes())
+	assert.Equal(t, map[string]string{"10.0.0.0/24": "highest priority (10)"}, resp.GetNode().GetPrimaryRouteReasons())
+
+	node, err := h.db.GetNodeByID(nodes[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[netip.Prefix]int{route: 10}, node.RoutePriorities)
+
+	list, err := api.ListNodes(ctx, &v1.ListNodesRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, list.GetNodes()[0].GetSubnetRoutes())
+
+	resp, err = api.SetRoutePriority(ctx, &v1.SetRoutePriorityRequest{
+		NodeId: uint64(nodes[1].ID),
+		Routes: []string{route.String()},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, resp.GetNode().GetRoutePriorities())
+
+	_, err = api.SetRoutePriority(ctx, &v1.SetRoutePriorityRequest{
+		NodeId:   uint64(nodes[1].ID),
+		Routes:   []string{"0.0.0.0/0"},
+		Priority: 10,
+	})
+	require.ErrorContains(t, err, "exit route")
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = api.SetRoutePriority(ctx, &v1.SetRoutePriorityRequest{
+		NodeId:   9999,
+		Priority: 10,
+	})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
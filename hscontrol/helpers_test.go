@@ -0,0 +1,44 @@
+package hscontrol
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHeadscale(t *testing.T) *Headscale {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+	cfg := types.Config{
+		NoisePrivateKeyPath: tmpDir + "/noise_private.key",
+		Database: types.DatabaseConfig{
+			Type: "sqlite3",
+			Sqlite: types.SqliteConfig{
+				Path: tmpDir + "/headscale_test.db",
+			},
+		},
+		Policy: types.PolicyConfig{
+			Mode: types.PolicyModeDB,
+		},
+		Tuning: types.Tuning{
+			BatchChangeDelay: time.Second,
+		},
+	}
+
+	h, err := NewHeadscale(&cfg)
+	require.NoError(t, err)
+
+	return h
+}
+
+func requireHTTPErrorCode(t *testing.T, err error, code int) {
+	t.Helper()
+
+	var httpErr HTTPError
+	require.True(t, errors.As(err, &httpErr), "expected HTTPError, got %v", err)
+	require.Equal(t, code, httpErr.Code)
+}
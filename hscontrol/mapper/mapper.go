@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"maps"
 	"net/netip"
 	"net/url"
 	"os"
@@ -17,6 +18,7 @@ import (
 	"time"
 
 	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/dns"
 	"github.com/juanfont/headscale/hscontrol/notifier"
 	"github.com/juanfont/headscale/hscontrol/policy"
 	"github.com/juanfont/headscale/hscontrol/routes"
@@ -60,6 +62,16 @@ type Mapper struct {
 	polMan  policy.PolicyManager
 	primary *routes.PrimaryRoutes
 
+	// dnsNodes are all nodes, used to resolve the DNS records pointing to
+	// nodes. They are listed once per change rather than for every node
+	// receiving it, and reset when an update is sent to all nodes.
+	dnsMu    sync.Mutex
+	dnsNodes types.Nodes
+
+	// tkaInfo is the tailnet lock info sent to the nodes, set by
+	// SetTKAInfo when the tailnet lock state changes.
+	tkaInfo atomic.Pointer[tailcfg.TKAInfo]
+
 	uid     string
 	created time.Time
 	seq     uint64
@@ -80,7 +92,7 @@ func NewMapper(
 ) *Mapper {
 	uid, _ := util.GenerateRandomStringDNSSafe(mapperIDLength)
 
-	return &Mapper{
+	m := &Mapper{
 		db:      db,
 		cfg:     cfg,
 		derpMap: derpMap,
@@ -92,6 +104,49 @@ func NewMapper(
 		created: time.Now(),
 		seq:     0,
 	}
+
+	if notif != nil {
+		notif.Observe(m.observe)
+	}
+
+	return m
+}
+
+// SetTKAInfo sets the tailnet lock info sent to the nodes, nil if tailnet
+// lock has never been enabled.
+func (m *Mapper) SetTKAInfo(info *tailcfg.TKAInfo) {
+	m.tkaInfo.Store(info)
+}
+
+// observe resets the nodes the DNS records are resolved with when nodes
+// change. Patches only change the endpoints and online state of nodes.
+func (m *Mapper) observe(update types.StateUpdate) {
+	if update.Type == types.StateDERPUpdated || update.Type == types.StatePeerChangedPatch {
+		return
+	}
+
+	m.dnsMu.Lock()
+	defer m.dnsMu.Unlock()
+
+	m.dnsNodes = nil
+}
+
+// listDNSNodes returns all nodes to resolve the DNS records with, they
+// are listed again after a change of nodes.
+func (m *Mapper) listDNSNodes() (types.Nodes, error) {
+	m.dnsMu.Lock()
+	defer m.dnsMu.Unlock()
+
+	if m.dnsNodes == nil {
+		nodes, err := m.db.ListNodes()
+		if err != nil {
+			return nil, err
+		}
+
+		m.dnsNodes = nodes
+	}
+
+	return m.dnsNodes, nil
 }
 
 func (m *Mapper) String() string {
@@ -123,15 +178,31 @@ func generateUserProfiles(
 	return profiles
 }
 
+// generateDNSConfig returns the DNS configuration of the node, with the
+// DNS profile of the policy applied. nodes are all the nodes of the
+// tailnet, used to resolve the extra records pointing to nodes.
 func generateDNSConfig(
 	cfg *types.Config,
+	polMan policy.PolicyManager,
 	node *types.Node,
+	nodes types.Nodes,
 ) *tailcfg.DNSConfig {
-	if cfg.TailcfgDNSConfig == nil {
+	baseDNSConfig := cfg.TailcfgDNS()
+	if baseDNSConfig == nil {
 		return nil
 	}
 
-	dnsConfig := cfg.TailcfgDNSConfig.Clone()
+	dnsConfig := baseDNSConfig.Clone()
+
+	if polMan != nil {
+		if profile := polMan.DNSProfile(node); profile != nil {
+			profile.Apply(dnsConfig, cfg.DNSConfig.OverrideLocalDNS)
+		}
+	}
+
+	if dns.NeedsResolution(dnsConfig.ExtraRecords) {
+		dnsConfig.ExtraRecords = dns.ResolveRecords(dnsConfig.ExtraRecords, nodes, cfg.BaseDomain)
+	}
 
 	addNextDNSMetadata(dnsConfig.Resolvers, node)
 
@@ -182,6 +253,7 @@ func (m *Mapper) fullMapResponse(
 		node,
 		capVer,
 		peers,
+		slices.Concat(types.Nodes{node}, peers),
 		m.cfg,
 	)
 	if err != nil {
@@ -244,11 +316,43 @@ func (m *Mapper) DERPMapResponse(
 	m.derpMap = derpMap
 
 	resp := m.baseMapResponse()
-	resp.DERPMap = derpMap
+	resp.DERPMap = nodeDERPMap(derpMap, node, m.polMan)
 
 	return m.marshalMapResponse(mapRequest, &resp, node, mapRequest.Compress)
 }
 
+// nodeDERPMap returns the DERP map without the regions the policy does not
+// allow the node to use, so the node does not pick a home region where it
+// is refused.
+func nodeDERPMap(
+	derpMap *tailcfg.DERPMap,
+	node *types.Node,
+	polMan policy.PolicyManager,
+) *tailcfg.DERPMap {
+	if derpMap == nil || polMan == nil {
+		return derpMap
+	}
+
+	var refused []int
+	for id := range derpMap.Regions {
+		if !polMan.NodeCanUseDERPRegion(node, id) {
+			refused = append(refused, id)
+		}
+	}
+
+	if len(refused) == 0 {
+		return derpMap
+	}
+
+	ret := *derpMap
+	ret.Regions = maps.Clone(derpMap.Regions)
+	for _, id := range refused {
+		delete(ret.Regions, id)
+	}
+
+	return &ret
+}
+
 func (m *Mapper) PeerChangedResponse(
 	mapRequest tailcfg.MapRequest,
 	node *types.Node,
@@ -278,6 +382,16 @@ func (m *Mapper) PeerChangedResponse(
 		}
 	}
 
+	// The DNS records pointing to nodes are resolved with all the nodes,
+	// not just the changed ones.
+	var nodes types.Nodes
+	if dnsConfig := m.cfg.TailcfgDNS(); dnsConfig != nil && dns.NeedsResolution(dnsConfig.ExtraRecords) {
+		nodes, err = m.listDNSNodes()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	err = appendPeerChanges(
 		&resp,
 		false, // partial change
@@ -286,6 +400,7 @@ func (m *Mapper) PeerChangedResponse(
 		node,
 		mapRequest.Version,
 		changedNodes,
+		nodes,
 		m.cfg,
 	)
 	if err != nil {
@@ -309,13 +424,17 @@ func (m *Mapper) PeerChangedResponse(
 		resp.PeersChangedPatch = patches
 	}
 
-	_, matchers := m.polMan.Filter()
+	_, matchers, err := m.polMan.FilterForNode(node)
+	if err != nil {
+		return nil, err
+	}
+
 	// Add the node itself, it might have changed, and particularly
 	// if there are no patches or changes, this is a self update.
 	tailnode, err := tailNode(
 		node, mapRequest.Version, m.polMan,
 		func(id types.NodeID) []netip.Prefix {
-			return policy.ReduceRoutes(node, m.primary.PrimaryRoutes(id), matchers)
+			return policy.ReduceRoutes(node, m.primary.PrimaryRoutesFor(node.ID, id), matchers)
 		},
 		m.cfg)
 	if err != nil {
@@ -464,11 +583,15 @@ func (m *Mapper) baseWithConfigMapResponse(
 ) (*tailcfg.MapResponse, error) {
 	resp := m.baseMapResponse()
 
-	_, matchers := m.polMan.Filter()
+	_, matchers, err := m.polMan.FilterForNode(node)
+	if err != nil {
+		return nil, err
+	}
+
 	tailnode, err := tailNode(
 		node, capVer, m.polMan,
 		func(id types.NodeID) []netip.Prefix {
-			return policy.ReduceRoutes(node, m.primary.PrimaryRoutes(id), matchers)
+			return policy.ReduceRoutes(node, m.primary.PrimaryRoutesFor(node.ID, id), matchers)
 		},
 		m.cfg)
 	if err != nil {
@@ -476,7 +599,7 @@ func (m *Mapper) baseWithConfigMapResponse(
 	}
 	resp.Node = tailnode
 
-	resp.DERPMap = m.derpMap
+	resp.DERPMap = nodeDERPMap(m.derpMap, node, m.polMan)
 
 	resp.Domain = m.cfg.Domain()
 
@@ -490,6 +613,8 @@ func (m *Mapper) baseWithConfigMapResponse(
 		DisableLogTail: !m.cfg.LogTail.Enabled,
 	}
 
+	resp.TKAInfo = m.tkaInfo.Load()
+
 	return &resp, nil
 }
 
@@ -542,9 +667,13 @@ func appendPeerChanges(
 	node *types.Node,
 	capVer tailcfg.CapabilityVersion,
 	changed types.Nodes,
+	nodes types.Nodes,
 	cfg *types.Config,
 ) error {
-	filter, matchers := polMan.Filter()
+	filter, matchers, err := polMan.FilterForNode(node)
+	if err != nil {
+		return err
+	}
 
 	sshPolicy, err := polMan.SSHPolicy(node)
 	if err != nil {
@@ -557,14 +686,23 @@ func appendPeerChanges(
 		changed = policy.ReduceNodes(node, changed, matchers)
 	}
 
+	// Nodes waiting for approval have no peers and are no peers.
+	if node.PendingApproval {
+		changed = nil
+	} else {
+		changed = slices.DeleteFunc(slices.Clone(changed), func(peer *types.Node) bool {
+			return peer.PendingApproval
+		})
+	}
+
 	profiles := generateUserProfiles(node, changed)
 
-	dnsConfig := generateDNSConfig(cfg, node)
+	dnsConfig := generateDNSConfig(cfg, polMan, node, nodes)
 
 	tailPeers, err := tailNodes(
 		changed, capVer, polMan,
 		func(id types.NodeID) []netip.Prefix {
-			return policy.ReduceRoutes(node, primary.PrimaryRoutes(id), matchers)
+			return policy.ReduceRoutes(node, primary.PrimaryRoutesFor(node.ID, id), matchers)
 		},
 		cfg)
 	if err != nil {
@@ -1,6 +1,7 @@
 package mapper
 
 import (
+	"context"
 	"fmt"
 	"net/netip"
 	"testing"
@@ -8,6 +9,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/notifier"
 	"github.com/juanfont/headscale/hscontrol/policy"
 	"github.com/juanfont/headscale/hscontrol/routes"
 	"github.com/juanfont/headscale/hscontrol/types"
@@ -17,6 +20,7 @@ import (
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/dnstype"
 	"tailscale.com/types/key"
+	"zgo.at/zcache/v2"
 )
 
 var iap = func(ipStr string) *netip.Addr {
@@ -74,7 +78,9 @@ func TestDNSConfigMapResponse(t *testing.T) {
 				&types.Config{
 					TailcfgDNSConfig: &dnsConfigOrig,
 				},
+				nil,
 				nodeInShared1,
+				nil,
 			)
 
 			if diff := cmp.Diff(tt.want, got, cmpopts.EquateEmpty()); diff != "" {
@@ -191,6 +197,7 @@ func Test_fullMapResponse(t *testing.T) {
 			tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 			tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 			tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+			tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 		},
 	}
 
@@ -246,9 +253,14 @@ func Test_fullMapResponse(t *testing.T) {
 			tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 			tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 			tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+			tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 		},
 	}
 
+	pendingPeer := *peer1
+	pendingPeer.ID = 3
+	pendingPeer.PendingApproval = true
+
 	tests := []struct {
 		name  string
 		pol   []byte
@@ -338,6 +350,42 @@ func Test_fullMapResponse(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "pending-peer-map-response",
+			node: mini,
+			peers: types.Nodes{
+				peer1,
+				&pendingPeer,
+			},
+			derpMap: &tailcfg.DERPMap{},
+			cfg: &types.Config{
+				BaseDomain:          "",
+				TailcfgDNSConfig:    &tailcfg.DNSConfig{},
+				LogTail:             types.LogTailConfig{Enabled: false},
+				RandomizeClientPort: false,
+			},
+			want: &tailcfg.MapResponse{
+				KeepAlive: false,
+				Node:      tailMini,
+				DERPMap:   &tailcfg.DERPMap{},
+				Peers: []*tailcfg.Node{
+					tailPeer1,
+				},
+				DNSConfig:       &tailcfg.DNSConfig{},
+				Domain:          "",
+				CollectServices: "false",
+				UserProfiles: []tailcfg.UserProfile{
+					{ID: tailcfg.UserID(user1.ID), LoginName: "user1", DisplayName: "user1"},
+					{ID: tailcfg.UserID(user2.ID), LoginName: "user2", DisplayName: "user2"},
+				},
+				ControlTime:   &time.Time{},
+				PacketFilters: map[string][]tailcfg.FilterRule{"base": tailcfg.FilterAllowAll},
+				Debug: &tailcfg.Debug{
+					DisableLogTail: true,
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "with-pol-map-response",
 			pol: []byte(`
@@ -449,3 +497,55 @@ func Test_fullMapResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestMapperDNSNodes(t *testing.T) {
+	hsdb, err := db.NewHeadscaleDatabase(
+		types.DatabaseConfig{
+			Type:   types.DatabaseSqlite,
+			Sqlite: types.SqliteConfig{Path: t.TempDir() + "/headscale_test.db"},
+		},
+		"",
+		zcache.New[types.RegistrationID, types.RegisterNode](time.Minute, time.Hour),
+	)
+	require.NoError(t, err)
+
+	notif := notifier.NewNotifier(&types.Config{
+		Tuning: types.Tuning{
+			BatchChangeDelay:    time.Hour,
+			NotifierSendTimeout: time.Second,
+		},
+	})
+	defer notif.Close()
+
+	m := NewMapper(hsdb, &types.Config{}, nil, notif, nil, nil)
+
+	user, err := hsdb.CreateUser(types.User{Name: "alice"})
+	require.NoError(t, err)
+
+	addNode := func(name string) {
+		require.NoError(t, hsdb.DB.Save(&types.Node{
+			MachineKey: key.NewMachine().Public(),
+			NodeKey:    key.NewNode().Public(),
+			Hostname:   name,
+			GivenName:  name,
+			UserID:     user.ID,
+		}).Error)
+	}
+
+	addNode("db")
+	nodes, err := m.listDNSNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	// The nodes are listed once for all nodes receiving a change.
+	addNode("web")
+	nodes, err = m.listDNSNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 1)
+
+	ctx := types.NotifyCtx(context.Background(), "test", "web")
+	notif.NotifyAll(ctx, types.UpdatePeerChanged(2))
+	nodes, err = m.listDNSNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 2)
+}
@@ -111,20 +111,27 @@ func tailNode(
 
 		Tags: tags,
 
-		MachineAuthorized: !node.IsExpired(),
+		MachineAuthorized: !node.IsExpired() && !node.PendingApproval && !node.User.Disabled,
 		Expired:           node.IsExpired(),
+
+		KeySignature: node.KeySignature,
 	}
 
-	tNode.CapMap = tailcfg.NodeCapMap{
+	capMap := tailcfg.NodeCapMap{
 		tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 		tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 		tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+		tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 	}
 
 	if cfg.RandomizeClientPort {
-		tNode.CapMap[tailcfg.NodeAttrRandomizeClientPort] = []tailcfg.RawMessage{}
+		capMap[tailcfg.NodeAttrRandomizeClientPort] = []tailcfg.RawMessage{}
 	}
 
+	// The nodeAttrs of the policy can add to, or remove from
+	// the default capabilities.
+	tNode.CapMap = polMan.NodeCapMap(node, capMap)
+
 	if node.IsOnline == nil || !*node.IsOnline {
 		// LastSeen is only set when node is
 		// not connected to the control server.
@@ -77,6 +77,7 @@ func TestTailNode(t *testing.T) {
 					tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 					tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 					tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+					tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 				},
 			},
 			wantErr: false,
@@ -165,6 +166,7 @@ func TestTailNode(t *testing.T) {
 					tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 					tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 					tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+					tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 				},
 			},
 			wantErr: false,
@@ -191,6 +193,71 @@ func TestTailNode(t *testing.T) {
 					tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
 					tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
 					tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+					tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "node-attrs-from-policy",
+			node: &types.Node{
+				GivenName: "minimal",
+				IPv4:      iap("100.64.0.1"),
+				Hostinfo:  &tailcfg.Hostinfo{},
+			},
+			pol: []byte(`{
+	"nodeAttrs": [
+		{
+			"target": ["100.64.0.1"],
+			"attr": ["funnel", "-https://tailscale.com/cap/file-sharing"]
+		}
+	]
+}`),
+			dnsConfig:  &tailcfg.DNSConfig{},
+			baseDomain: "example.com",
+			want: &tailcfg.Node{
+				Name:              "minimal.example.com.",
+				StableID:          "0",
+				Addresses:         []netip.Prefix{netip.MustParsePrefix("100.64.0.1/32")},
+				AllowedIPs:        []netip.Prefix{netip.MustParsePrefix("100.64.0.1/32")},
+				HomeDERP:          0,
+				LegacyDERPString:  "127.3.3.40:0",
+				Hostinfo:          hiview(tailcfg.Hostinfo{}),
+				Tags:              []string{},
+				MachineAuthorized: true,
+
+				CapMap: tailcfg.NodeCapMap{
+					tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
+					tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+					tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
+					tailcfg.NodeAttrFunnel:        []tailcfg.RawMessage{},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "pending-approval",
+			node: &types.Node{
+				GivenName:       "pending",
+				Hostinfo:        &tailcfg.Hostinfo{},
+				PendingApproval: true,
+			},
+			dnsConfig:  &tailcfg.DNSConfig{},
+			baseDomain: "",
+			want: &tailcfg.Node{
+				Name:              "pending",
+				StableID:          "0",
+				HomeDERP:          0,
+				LegacyDERPString:  "127.3.3.40:0",
+				Hostinfo:          hiview(tailcfg.Hostinfo{}),
+				Tags:              []string{},
+				MachineAuthorized: false,
+
+				CapMap: tailcfg.NodeCapMap{
+					tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
+					tailcfg.CapabilityAdmin:       []tailcfg.RawMessage{},
+					tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+					tailcfg.CapabilityTailnetLock: []tailcfg.RawMessage{},
 				},
 			},
 			wantErr: false,
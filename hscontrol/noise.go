@@ -102,6 +102,15 @@ func (h *Headscale) NoiseUpgradeHandler(
 		Methods(http.MethodPost)
 	router.HandleFunc("/machine/map", noiseServer.NoisePollNetMapHandler)
 
+	router.HandleFunc("/machine/tka/init/begin", noiseServer.NoiseTKAInitBeginHandler)
+	router.HandleFunc("/machine/tka/init/finish", noiseServer.NoiseTKAInitFinishHandler)
+	router.HandleFunc("/machine/tka/bootstrap", noiseServer.NoiseTKABootstrapHandler)
+	router.HandleFunc("/machine/tka/sync/offer", noiseServer.NoiseTKASyncOfferHandler)
+	router.HandleFunc("/machine/tka/sync/send", noiseServer.NoiseTKASyncSendHandler)
+	router.HandleFunc("/machine/tka/disable", noiseServer.NoiseTKADisableHandler)
+	router.HandleFunc("/machine/tka/sign", noiseServer.NoiseTKASignHandler)
+	router.HandleFunc("/machine/tka/affected-sigs", noiseServer.NoiseTKAAffectedSigsHandler)
+
 	noiseServer.httpBaseConfig = &http.Server{
 		Handler:           router,
 		ReadHeaderTimeout: types.HTTPTimeout,
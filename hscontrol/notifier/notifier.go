@@ -37,6 +37,9 @@ type Notifier struct {
 	b         *batcher
 	cfg       *types.Config
 	closed    bool
+
+	// observers are called with every update sent to all nodes.
+	observers []func(types.StateUpdate)
 }
 
 func NewNotifier(cfg *types.Config) *Notifier {
@@ -187,6 +190,13 @@ func (n *Notifier) LikelyConnectedMap() *xsync.MapOf[types.NodeID, bool] {
 	return n.connected
 }
 
+// Observe registers a function called with every update sent to all
+// nodes, before it is batched. It must not block and must be registered
+// before the notifier is used.
+func (n *Notifier) Observe(f func(types.StateUpdate)) {
+	n.observers = append(n.observers, f)
+}
+
 func (n *Notifier) NotifyAll(ctx context.Context, update types.StateUpdate) {
 	n.NotifyWithIgnore(ctx, update)
 }
@@ -201,6 +211,11 @@ func (n *Notifier) NotifyWithIgnore(
 	}
 
 	notifierUpdateReceived.WithLabelValues(update.Type.String(), types.NotifyOriginKey.Value(ctx)).Inc()
+
+	for _, observe := range n.observers {
+		observe(update)
+	}
+
 	n.b.addOrPassthrough(update)
 }
 
@@ -15,6 +15,7 @@ import (
 
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gorilla/mux"
+	"github.com/juanfont/headscale/hscontrol/audit"
 	"github.com/juanfont/headscale/hscontrol/db"
 	"github.com/juanfont/headscale/hscontrol/notifier"
 	"github.com/juanfont/headscale/hscontrol/policy"
@@ -45,6 +46,7 @@ var (
 		"requested node state key expired before authorisation completed",
 	)
 	errOIDCNodeKeyMissing = errors.New("could not get node key from cache")
+	errOIDCUserDisabled   = errors.New("the user of the authenticated principal is disabled")
 )
 
 // RegistrationInfo contains both machine key and verifier information for OIDC validation.
@@ -61,19 +63,29 @@ type AuthProviderOIDC struct {
 	notifier          *notifier.Notifier
 	ipAlloc           *db.IPAllocator
 	polMan            policy.PolicyManager
+	auditLog          *audit.Logger
+
+	// nodeApproval makes the new nodes wait for an admin to approve them.
+	nodeApproval bool
 
 	oidcProvider *oidc.Provider
 	oauth2Config *oauth2.Config
+
+	// tokens encrypts the refresh tokens, it is nil if the users are not
+	// re-validated.
+	tokens *tokenCipher
 }
 
 func NewAuthProviderOIDC(
 	ctx context.Context,
 	serverURL string,
 	cfg *types.OIDCConfig,
+	nodeApproval bool,
 	db *db.HSDatabase,
 	notif *notifier.Notifier,
 	ipAlloc *db.IPAllocator,
 	polMan policy.PolicyManager,
+	auditLog *audit.Logger,
 ) (*AuthProviderOIDC, error) {
 	var err error
 	// grab oidc config if it hasn't been already
@@ -98,6 +110,14 @@ func NewAuthProviderOIDC(
 		registerCacheCleanup,
 	)
 
+	var tokens *tokenCipher
+	if cfg.Revalidation.Enabled {
+		tokens, err = readOrCreateTokenCipher(cfg.Revalidation.KeyPath)
+		if err != nil {
+			return nil, fmt.Errorf("reading OIDC token key: %w", err)
+		}
+	}
+
 	return &AuthProviderOIDC{
 		serverURL:         serverURL,
 		cfg:               cfg,
@@ -106,9 +126,12 @@ func NewAuthProviderOIDC(
 		notifier:          notif,
 		ipAlloc:           ipAlloc,
 		polMan:            polMan,
+		auditLog:          auditLog,
+		nodeApproval:      nodeApproval,
 
 		oidcProvider: oidcProvider,
 		oauth2Config: oauth2Config,
+		tokens:       tokens,
 	}, nil
 }
 
@@ -198,8 +221,9 @@ func (a *AuthProviderOIDC) RegisterHandler(
 }
 
 type oidcCallbackTemplateConfig struct {
-	User string
-	Verb string
+	User    string
+	Verb    string
+	Pending bool
 }
 
 //go:embed assets/oidc_callback_template.html
@@ -281,29 +305,7 @@ func (a *AuthProviderOIDC) OIDCCallbackHandler(
 		return
 	}
 
-	var userinfo *oidc.UserInfo
-	userinfo, err = a.oidcProvider.UserInfo(req.Context(), oauth2.StaticTokenSource(oauth2Token))
-	if err != nil {
-		util.LogErr(err, "could not get userinfo; only checking claim")
-	}
-
-	// If the userinfo is available, we can check if the subject matches the
-	// claims, then use some of the userinfo fields to update the user.
-	// https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
-	if userinfo != nil && userinfo.Subject == claims.Sub {
-		claims.Email = cmp.Or(claims.Email, userinfo.Email)
-		claims.EmailVerified = cmp.Or(claims.EmailVerified, types.FlexibleBoolean(userinfo.EmailVerified))
-
-		// The userinfo has some extra fields that we can use to update the user but they are only
-		// available in the underlying claims struct.
-		// TODO(kradalby): there might be more interesting fields here that we have not found yet.
-		var userinfo2 types.OIDCUserInfo
-		if err := userinfo.Claims(&userinfo2); err == nil {
-			claims.Username = cmp.Or(claims.Username, userinfo2.PreferredUsername)
-			claims.Name = cmp.Or(claims.Name, userinfo2.Name)
-			claims.ProfilePictureURL = cmp.Or(claims.ProfilePictureURL, userinfo2.Picture)
-		}
-	}
+	a.enrichClaimsWithUserInfo(req.Context(), oauth2Token, &claims)
 
 	user, err := a.createOrUpdateUserFromClaim(&claims)
 	if err != nil {
@@ -311,6 +313,8 @@ func (a *AuthProviderOIDC) OIDCCallbackHandler(
 		return
 	}
 
+	a.storeRefreshToken(user, oauth2Token)
+
 	// TODO(kradalby): Is this comment right?
 	// If the node exists, then the node should be reauthenticated,
 	// if the node does not exist, and the machine key exists, then
@@ -320,7 +324,7 @@ func (a *AuthProviderOIDC) OIDCCallbackHandler(
 	// Register the node if it does not exist.
 	if registrationId != nil {
 		verb := "Reauthenticated"
-		newNode, err := a.handleRegistration(user, *registrationId, nodeExpiry)
+		node, newNode, err := a.handleRegistration(user, *registrationId, nodeExpiry)
 		if err != nil {
 			httpError(writer, err)
 			return
@@ -331,7 +335,7 @@ func (a *AuthProviderOIDC) OIDCCallbackHandler(
 		}
 
 		// TODO(kradalby): replace with go-elem
-		content, err := renderOIDCCallbackTemplate(user, verb)
+		content, err := renderOIDCCallbackTemplate(user, verb, node.PendingApproval)
 		if err != nil {
 			httpError(writer, err)
 			return
@@ -409,6 +413,38 @@ func (a *AuthProviderOIDC) extractIDToken(
 	return idToken, nil
 }
 
+// enrichClaimsWithUserInfo completes the claims with the userinfo of the
+// OIDC provider, if it is available.
+func (a *AuthProviderOIDC) enrichClaimsWithUserInfo(
+	ctx context.Context,
+	oauth2Token *oauth2.Token,
+	claims *types.OIDCClaims,
+) {
+	userinfo, err := a.oidcProvider.UserInfo(ctx, oauth2.StaticTokenSource(oauth2Token))
+	if err != nil {
+		util.LogErr(err, "could not get userinfo; only checking claim")
+		return
+	}
+
+	// If the userinfo is available, we can check if the subject matches the
+	// claims, then use some of the userinfo fields to update the user.
+	// https://openid.net/specs/openid-connect-core-1_0.html#UserInfo
+	if userinfo.Subject == claims.Sub {
+		claims.Email = cmp.Or(claims.Email, userinfo.Email)
+		claims.EmailVerified = cmp.Or(claims.EmailVerified, types.FlexibleBoolean(userinfo.EmailVerified))
+
+		// The userinfo has some extra fields that we can use to update the user but they are only
+		// available in the underlying claims struct.
+		// TODO(kradalby): there might be more interesting fields here that we have not found yet.
+		var userinfo2 types.OIDCUserInfo
+		if err := userinfo.Claims(&userinfo2); err == nil {
+			claims.Username = cmp.Or(claims.Username, userinfo2.PreferredUsername)
+			claims.Name = cmp.Or(claims.Name, userinfo2.Name)
+			claims.ProfilePictureURL = cmp.Or(claims.ProfilePictureURL, userinfo2.Picture)
+		}
+	}
+}
+
 // validateOIDCAllowedDomains checks that if AllowedDomains is provided,
 // that the authenticated principal ends with @<alloweddomain>.
 func validateOIDCAllowedDomains(
@@ -480,17 +516,51 @@ func (a *AuthProviderOIDC) createOrUpdateUserFromClaim(
 		return nil, fmt.Errorf("creating or updating user: %w", err)
 	}
 
+	// A user provisioned via SCIM is linked on the first login with a
+	// provider of the SCIM identity provider, by the subject of the
+	// configured issuer matching its external ID or by its verified
+	// email. The username is not verified by the provider, so it is never
+	// used to link a user.
+	if user == nil && a.cfg.SCIM {
+		externalID := ""
+		if claims.Iss == a.cfg.Issuer {
+			externalID = claims.Sub
+		}
+
+		email := ""
+		if claims.EmailVerified {
+			email = claims.Email
+		}
+
+		user, err = a.db.GetUnlinkedSCIMUser(externalID, email)
+		if err != nil && !errors.Is(err, db.ErrUserNotFound) {
+			return nil, fmt.Errorf("creating or updating user: %w", err)
+		}
+	}
+
+	// Disabled users, via SCIM or headscale users disable, cannot log
+	// in and register nodes.
+	if user != nil && user.Disabled {
+		return nil, NewHTTPError(http.StatusForbidden, "user is disabled", errOIDCUserDisabled)
+	}
+
 	// if the user is still not found, create a new empty user.
 	if user == nil {
 		user = &types.User{}
 	}
 
+	newUser := user.ID == 0
+
 	user.FromClaim(claims)
 	err = a.db.DB.Save(user).Error
 	if err != nil {
 		return nil, fmt.Errorf("creating or updating user: %w", err)
 	}
 
+	if newUser {
+		a.auditLog.Record("oidc:"+user.Username(), "user.create", userTarget(user.ID), nil, user.Proto())
+	}
+
 	err = usersChangedHook(a.db, a.polMan, a.notifier)
 	if err != nil {
 		return nil, fmt.Errorf("updating resources using user: %w", err)
@@ -503,10 +573,10 @@ func (a *AuthProviderOIDC) handleRegistration(
 	user *types.User,
 	registrationID types.RegistrationID,
 	expiry time.Time,
-) (bool, error) {
+) (*types.Node, bool, error) {
 	ipv4, ipv6, err := a.ipAlloc.Next()
 	if err != nil {
-		return false, err
+		return nil, false, err
 	}
 
 	node, newNode, err := a.db.HandleNodeFromAuthPath(
@@ -514,18 +584,25 @@ func (a *AuthProviderOIDC) handleRegistration(
 		types.UserID(user.ID),
 		&expiry,
 		util.RegisterMethodOIDC,
+		a.nodeApproval,
 		ipv4, ipv6,
 	)
 	if err != nil {
-		return false, fmt.Errorf("could not register node: %w", err)
+		return nil, false, fmt.Errorf("could not register node: %w", err)
+	}
+
+	action := "node.reauthenticate"
+	if newNode {
+		action = "node.register"
 	}
+	a.auditLog.Record("oidc:"+user.Username(), action, nodeTarget(node.ID), nil, node.Proto())
 
 	// Send an update to all nodes if this is a new node that they need to know
 	// about.
 	// If this is a refresh, just send new expiry updates.
 	updateSent, err := nodesChangedHook(a.db, a.polMan, a.notifier)
 	if err != nil {
-		return false, fmt.Errorf("updating resources using node: %w", err)
+		return nil, false, fmt.Errorf("updating resources using node: %w", err)
 	}
 
 	// This is a bit of a back and forth, but we have a bit of a chicken and egg
@@ -541,7 +618,7 @@ func (a *AuthProviderOIDC) handleRegistration(
 	// eventbus.
 	routesChanged := policy.AutoApproveRoutes(a.polMan, node)
 	if err := a.db.DB.Save(node).Error; err != nil {
-		return false, fmt.Errorf("saving auto approved routes to node: %w", err)
+		return nil, false, fmt.Errorf("saving auto approved routes to node: %w", err)
 	}
 
 	if !updateSent || routesChanged {
@@ -556,7 +633,7 @@ func (a *AuthProviderOIDC) handleRegistration(
 		a.notifier.NotifyWithIgnore(ctx, types.UpdatePeerChanged(node.ID), node.ID)
 	}
 
-	return newNode, nil
+	return node, newNode, nil
 }
 
 // TODO(kradalby):
@@ -564,11 +641,13 @@ func (a *AuthProviderOIDC) handleRegistration(
 func renderOIDCCallbackTemplate(
 	user *types.User,
 	verb string,
+	pending bool,
 ) (*bytes.Buffer, error) {
 	var content bytes.Buffer
 	if err := oidcCallbackTemplate.Execute(&content, oidcCallbackTemplateConfig{
-		User: user.Display(),
-		Verb: verb,
+		User:    user.Display(),
+		Verb:    verb,
+		Pending: pending,
 	}); err != nil {
 		return nil, fmt.Errorf("rendering OIDC callback template: %w", err)
 	}
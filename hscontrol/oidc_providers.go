@@ -0,0 +1,114 @@
+package hscontrol
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/juanfont/headscale/hscontrol/templates"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+)
+
+var errUnknownOIDCProvider = errors.New("unknown OIDC provider")
+
+// AuthProviderOIDCSet lets the users register nodes with one of several
+// OIDC providers. All providers share the /oidc/callback endpoint, a
+// callback is handled by the provider which started the login.
+type AuthProviderOIDCSet struct {
+	serverURL string
+	providers []*AuthProviderOIDC
+}
+
+func NewAuthProviderOIDCSet(serverURL string, providers []*AuthProviderOIDC) *AuthProviderOIDCSet {
+	return &AuthProviderOIDCSet{
+		serverURL: serverURL,
+		providers: providers,
+	}
+}
+
+func (s *AuthProviderOIDCSet) AuthURL(registrationID types.RegistrationID) string {
+	return fmt.Sprintf(
+		"%s/register/%s",
+		strings.TrimSuffix(s.serverURL, "/"),
+		registrationID.String())
+}
+
+// Providers returns the OIDC providers of the set.
+func (s *AuthProviderOIDCSet) Providers() []*AuthProviderOIDC {
+	return s.providers
+}
+
+// RegisterHandler redirects to the OIDC provider named by the provider
+// parameter, or to the only provider. Otherwise the user chooses the
+// provider from a list.
+// Listens in /register/:registration_id.
+func (s *AuthProviderOIDCSet) RegisterHandler(
+	writer http.ResponseWriter,
+	req *http.Request,
+) {
+	if name := req.URL.Query().Get("provider"); name != "" {
+		for _, provider := range s.providers {
+			if provider.cfg.Name == name {
+				provider.RegisterHandler(writer, req)
+				return
+			}
+		}
+
+		httpError(writer, NewHTTPError(http.StatusBadRequest, "unknown OIDC provider", errUnknownOIDCProvider))
+		return
+	}
+
+	if len(s.providers) == 1 {
+		s.providers[0].RegisterHandler(writer, req)
+		return
+	}
+
+	// We need to make sure we dont open for XSS style injections, if the parameter that
+	// is passed as a key is not parsable/validated as a NodePublic key, then fail to render
+	// the template and log an error.
+	registrationID, err := types.RegistrationIDFromString(mux.Vars(req)["registration_id"])
+	if err != nil {
+		httpError(writer, NewHTTPError(http.StatusBadRequest, "invalid registration id", err))
+		return
+	}
+
+	choices := make([]templates.OIDCProvider, len(s.providers))
+	for i, provider := range s.providers {
+		choices[i] = templates.OIDCProvider{
+			Name:        provider.cfg.Name,
+			DisplayName: cmp.Or(provider.cfg.DisplayName, provider.cfg.Name),
+		}
+	}
+
+	writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	if _, err := writer.Write([]byte(templates.RegisterOIDCProviders(registrationID, choices).Render())); err != nil {
+		util.LogErr(err, "Failed to write response")
+	}
+}
+
+// OIDCCallbackHandler passes the callback to the OIDC provider which
+// started the login with the state of the callback.
+func (s *AuthProviderOIDCSet) OIDCCallbackHandler(
+	writer http.ResponseWriter,
+	req *http.Request,
+) {
+	_, state, err := extractCodeAndStateParamFromRequest(req)
+	if err != nil {
+		httpError(writer, err)
+		return
+	}
+
+	for _, provider := range s.providers {
+		if _, ok := provider.registrationCache.Get(state); ok {
+			provider.OIDCCallbackHandler(writer, req)
+			return
+		}
+	}
+
+	httpError(writer, NewHTTPError(http.StatusGone, "login session expired, try again", nil))
+}
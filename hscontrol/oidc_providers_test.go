@@ -0,0 +1,144 @@
+package hscontrol
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/oauth2-proxy/mockoidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthProviderOIDCSet(t *testing.T) {
+	h := newTestHeadscale(t)
+	ctx := context.Background()
+
+	newProvider := func(name, displayName string) (*AuthProviderOIDC, *mockoidc.MockOIDC) {
+		m, err := mockoidc.Run()
+		require.NoError(t, err)
+		t.Cleanup(func() { m.Shutdown() })
+
+		cfg := &types.OIDCConfig{
+			Name:         name,
+			DisplayName:  displayName,
+			Issuer:       m.Issuer(),
+			ClientID:     m.ClientID,
+			ClientSecret: m.ClientSecret,
+			Scope:        []string{"openid", "email", "profile"},
+		}
+		provider, err := NewAuthProviderOIDC(ctx, "https://headscale.example.com", cfg, false, h.db, h.nodeNotifier, h.ipAlloc, h.polMan, h.auditLog)
+		require.NoError(t, err)
+
+		return provider, m
+	}
+
+	employees, _ := newProvider("employees", "Employees")
+	contractors, contractorsOIDC := newProvider("contractors", "")
+	set := NewAuthProviderOIDCSet("https://headscale.example.com", []*AuthProviderOIDC{employees, contractors})
+
+	router := mux.NewRouter()
+	router.HandleFunc("/register/{registration_id}", set.RegisterHandler)
+	router.HandleFunc("/oidc/callback", set.OIDCCallbackHandler)
+
+	registrationID, err := types.NewRegistrationID()
+	require.NoError(t, err)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+		return rec
+	}
+
+	t.Run("picker", func(t *testing.T) {
+		rec := get("/register/" + registrationID.String())
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		body, err := io.ReadAll(rec.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "/register/"+registrationID.String()+"?provider=employees")
+		assert.Contains(t, string(body), ">Employees<")
+		assert.Contains(t, string(body), "/register/"+registrationID.String()+"?provider=contractors")
+		assert.Contains(t, string(body), ">contractors<", "the name is shown without a display name")
+	})
+
+	t.Run("provider", func(t *testing.T) {
+		rec := get("/register/" + registrationID.String() + "?provider=contractors")
+		require.Equal(t, http.StatusFound, rec.Code)
+		assert.True(t, strings.HasPrefix(rec.Header().Get("Location"), contractorsOIDC.AuthorizationEndpoint()))
+	})
+
+	t.Run("scim-linking", func(t *testing.T) {
+		employees.cfg.SCIM = true
+		t.Cleanup(func() { employees.cfg.SCIM = false })
+
+		scimUser, err := h.db.CreateUser(types.User{
+			Name:     "alice",
+			Email:    "alice@example.com",
+			Provider: util.ProviderSCIM,
+		})
+		require.NoError(t, err)
+
+		claims := func(iss string) *types.OIDCClaims {
+			return &types.OIDCClaims{
+				Iss:           iss,
+				Sub:           "alice",
+				Email:         "alice@example.com",
+				EmailVerified: true,
+				Username:      "alice",
+			}
+		}
+
+		// The verified email of another identity provider does not link
+		// the SCIM user.
+		user, err := contractors.createOrUpdateUserFromClaim(claims(contractors.cfg.Issuer))
+		require.NoError(t, err)
+		assert.NotEqual(t, scimUser.ID, user.ID)
+
+		user, err = employees.createOrUpdateUserFromClaim(claims(employees.cfg.Issuer))
+		require.NoError(t, err)
+		assert.Equal(t, scimUser.ID, user.ID)
+	})
+
+	t.Run("disabled-user", func(t *testing.T) {
+		claims := &types.OIDCClaims{
+			Iss:      contractors.cfg.Issuer,
+			Sub:      "dave",
+			Username: "dave",
+		}
+
+		user, err := contractors.createOrUpdateUserFromClaim(claims)
+		require.NoError(t, err)
+
+		// A user disabled with headscale users disable cannot log in.
+		require.NoError(t, h.db.SetUserDisabled(types.UserID(user.ID), true))
+		_, err = contractors.createOrUpdateUserFromClaim(claims)
+		requireHTTPErrorCode(t, err, http.StatusForbidden)
+
+		require.NoError(t, h.db.SetUserDisabled(types.UserID(user.ID), false))
+		_, err = contractors.createOrUpdateUserFromClaim(claims)
+		require.NoError(t, err)
+	})
+
+	t.Run("unknown-provider", func(t *testing.T) {
+		rec := get("/register/" + registrationID.String() + "?provider=partners")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("invalid-registration-id", func(t *testing.T) {
+		rec := get("/register/<script>")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("unknown-state", func(t *testing.T) {
+		rec := get("/oidc/callback?code=code&state=state")
+		assert.Equal(t, http.StatusGone, rec.Code)
+	})
+}
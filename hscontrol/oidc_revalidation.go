@@ -0,0 +1,332 @@
+package hscontrol
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+const (
+	tokenKeySize = 32
+
+	// oidcRevalidationActor is the actor of the audit events of users
+	// failing the re-validation.
+	oidcRevalidationActor = "oidc-revalidation"
+)
+
+var (
+	errOIDCTokenRevoked      = errors.New("refresh token was revoked")
+	errOIDCSubjectMismatch   = errors.New("refreshed token is for another user")
+	errInvalidTokenKey       = errors.New("OIDC token key must be 32 hex encoded bytes")
+	errInvalidEncryptedToken = errors.New("encrypted token is too short")
+)
+
+// tokenCipher encrypts the OIDC refresh tokens stored in the database with
+// AES-256-GCM. The ID of the user is authenticated along with the token, a
+// token can't be moved to another user.
+type tokenCipher struct {
+	aead cipher.AEAD
+}
+
+// readOrCreateTokenCipher reads the key of the token cipher from path, or
+// creates a new key if there is no file at path.
+func readOrCreateTokenCipher(path string) (*tokenCipher, error) {
+	if err := util.EnsureDir(filepath.Dir(path)); err != nil {
+		return nil, fmt.Errorf("ensuring OIDC token key directory: %w", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Info().Str("path", path).Msg("No OIDC token key file at path, creating...")
+
+		key := make([]byte, tokenKeySize)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		data = []byte(hex.EncodeToString(key))
+		if err := os.WriteFile(path, data, privateKeyFileMode); err != nil {
+			return nil, fmt.Errorf("failed to save OIDC token key to disk at path %q: %w", path, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read OIDC token key file: %w", err)
+	}
+
+	key, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil || len(key) != tokenKeySize {
+		return nil, errInvalidTokenKey
+	}
+
+	return newTokenCipher(key)
+}
+
+func newTokenCipher(key []byte) (*tokenCipher, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tokenCipher{aead: aead}, nil
+}
+
+func tokenAdditionalData(userID uint) []byte {
+	return binary.BigEndian.AppendUint64(nil, uint64(userID))
+}
+
+// seal encrypts the token of the user, the nonce is prepended to the
+// result.
+func (c *tokenCipher) seal(userID uint, token string) ([]byte, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return c.aead.Seal(nonce, nonce, []byte(token), tokenAdditionalData(userID)), nil
+}
+
+// open decrypts a token of the user encrypted by seal.
+func (c *tokenCipher) open(userID uint, sealed []byte) (string, error) {
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errInvalidEncryptedToken
+	}
+
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	token, err := c.aead.Open(nil, nonce, ciphertext, tokenAdditionalData(userID))
+	if err != nil {
+		return "", err
+	}
+
+	return string(token), nil
+}
+
+// storeRefreshToken saves the encrypted refresh token of a login to
+// re-validate the user later. Nothing is saved if re-validation is
+// disabled.
+func (a *AuthProviderOIDC) storeRefreshToken(user *types.User, token *oauth2.Token) {
+	if a.tokens == nil {
+		return
+	}
+
+	if token.RefreshToken == "" {
+		log.Warn().
+			Str("user", user.Username()).
+			Msg("OIDC provider returned no refresh token, the user can't be re-validated; is the offline_access scope requested?")
+
+		return
+	}
+
+	sealed, err := a.tokens.seal(user.ID, token.RefreshToken)
+	if err != nil {
+		util.LogErr(err, "could not encrypt refresh token")
+		return
+	}
+
+	if err := a.db.SetOIDCRefreshToken(types.UserID(user.ID), a.cfg.Name, sealed); err != nil {
+		util.LogErr(err, "could not save refresh token")
+	}
+}
+
+// revalidateUsers re-validates the users with their refresh token
+// periodically.
+func (a *AuthProviderOIDC) revalidateUsers(ctx context.Context) {
+	ticker := time.NewTicker(a.cfg.Revalidation.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		a.revalidate(ctx)
+	}
+}
+
+// revalidate re-validates all users with a refresh token of this OIDC
+// provider. The nodes of users who are rejected by the OIDC provider are
+// expired. Users who can't be re-validated, e.g. because the OIDC provider
+// is not reachable, keep their nodes.
+func (a *AuthProviderOIDC) revalidate(ctx context.Context) {
+	users, err := a.db.ListUsers()
+	if err != nil {
+		log.Error().Err(err).Msg("listing users to re-validate")
+		return
+	}
+
+	for _, user := range users {
+		if len(user.OIDCRefreshToken) == 0 || !a.isProviderOf(&user) {
+			continue
+		}
+
+		err := a.revalidateUser(ctx, &user)
+		switch {
+		case err == nil:
+		case isOIDCRejection(err):
+			log.Info().
+				Err(err).
+				Str("user", user.Username()).
+				Msg("user failed OIDC re-validation, expiring its nodes")
+
+			if err := a.expireRejectedUser(&user); err != nil {
+				log.Error().Err(err).Str("user", user.Username()).Msg("expiring nodes of user")
+			}
+		default:
+			log.Warn().
+				Err(err).
+				Str("user", user.Username()).
+				Msg("could not re-validate user, keeping its nodes")
+		}
+	}
+}
+
+// isProviderOf reports whether the user last logged in with this OIDC
+// provider. Users without a recorded provider belong to the primary one.
+func (a *AuthProviderOIDC) isProviderOf(user *types.User) bool {
+	if user.OIDCProvider == "" {
+		return a.cfg.Primary
+	}
+
+	return user.OIDCProvider == a.cfg.Name
+}
+
+// isOIDCRejection reports whether the OIDC provider or the configuration
+// no longer allows the user.
+func isOIDCRejection(err error) bool {
+	return errors.Is(err, errOIDCTokenRevoked) ||
+		errors.Is(err, errOIDCAllowedDomains) ||
+		errors.Is(err, errOIDCAllowedGroups) ||
+		errors.Is(err, errOIDCAllowedUsers)
+}
+
+// revalidateUser refreshes the token of the user and checks the refreshed
+// claims against the allowed domains, groups and users, like a login. A
+// rotated refresh token and changed groups are saved.
+func (a *AuthProviderOIDC) revalidateUser(ctx context.Context, user *types.User) error {
+	refreshToken, err := a.tokens.open(user.ID, user.OIDCRefreshToken)
+	if err != nil {
+		return fmt.Errorf("decrypting refresh token: %w", err)
+	}
+
+	token, err := a.oauth2Config.TokenSource(ctx, &oauth2.Token{RefreshToken: refreshToken}).Token()
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) && retrieveErr.ErrorCode == "invalid_grant" {
+			return fmt.Errorf("%w: %s", errOIDCTokenRevoked, retrieveErr.ErrorDescription)
+		}
+
+		return fmt.Errorf("refreshing token: %w", err)
+	}
+
+	var claims types.OIDCClaims
+	if _, ok := token.Extra("id_token").(string); ok {
+		idToken, err := a.extractIDToken(ctx, token)
+		if err != nil {
+			return err
+		}
+
+		if err := idToken.Claims(&claims); err != nil {
+			return fmt.Errorf("decoding ID token claims: %w", err)
+		}
+
+		if claims.Identifier() != user.ProviderIdentifier.String {
+			return errOIDCSubjectMismatch
+		}
+
+		a.enrichClaimsWithUserInfo(ctx, token, &claims)
+	} else {
+		// Not all OIDC providers return an ID token for a refreshed
+		// token, the userinfo has the same claims.
+		userinfo, err := a.oidcProvider.UserInfo(ctx, oauth2.StaticTokenSource(token))
+		if err != nil {
+			return fmt.Errorf("getting userinfo: %w", err)
+		}
+
+		if err := userinfo.Claims(&claims); err != nil {
+			return fmt.Errorf("decoding userinfo claims: %w", err)
+		}
+	}
+
+	if err := validateOIDCAllowedDomains(a.cfg.AllowedDomains, &claims); err != nil {
+		return err
+	}
+
+	if err := validateOIDCAllowedGroups(a.cfg.AllowedGroups, &claims); err != nil {
+		return err
+	}
+
+	if err := validateOIDCAllowedUsers(a.cfg.AllowedUsers, &claims); err != nil {
+		return err
+	}
+
+	if token.RefreshToken != "" && token.RefreshToken != refreshToken {
+		a.storeRefreshToken(user, token)
+	}
+
+	if claims.Groups != nil {
+		groups := slices.Compact(slices.Sorted(slices.Values(claims.Groups)))
+		if !slices.Equal(groups, user.Groups) {
+			user.Groups = groups
+			if err := a.db.DB.Model(user).Select("groups").Updates(user).Error; err != nil {
+				return fmt.Errorf("saving groups: %w", err)
+			}
+
+			if err := usersChangedHook(a.db, a.polMan, a.notifier); err != nil {
+				return fmt.Errorf("updating resources using user: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// expireRejectedUser expires the nodes of a user rejected by the
+// re-validation and removes its refresh token, the user has to log in
+// again.
+func (a *AuthProviderOIDC) expireRejectedUser(user *types.User) error {
+	now := time.Now()
+	expired, err := db.Write(a.db.DB, func(tx *gorm.DB) (types.Nodes, error) {
+		if err := tx.Model(user).Update("oidc_refresh_token", nil).Error; err != nil {
+			return nil, err
+		}
+
+		return db.ExpireUserNodes(tx, types.UserID(user.ID), now)
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, node := range expired {
+		a.auditLog.Record(oidcRevalidationActor, "node.expire", nodeTarget(node.ID), nil, node.Proto())
+
+		ctx := types.NotifyCtx(context.Background(), "oidc-revalidation-self", node.Hostname)
+		a.notifier.NotifyByNodeID(ctx, types.UpdateSelf(node.ID), node.ID)
+
+		ctx = types.NotifyCtx(context.Background(), "oidc-revalidation-peers", node.Hostname)
+		a.notifier.NotifyWithIgnore(ctx, types.UpdateExpire(node.ID, now), node.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+package hscontrol
+
+import (
+	"context"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/oauth2-proxy/mockoidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestTokenCipher(t *testing.T) {
+	path := t.TempDir() + "/oidc_token.key"
+
+	tokens, err := readOrCreateTokenCipher(path)
+	require.NoError(t, err)
+
+	sealed, err := tokens.seal(1, "refresh-token")
+	require.NoError(t, err)
+	assert.NotContains(t, string(sealed), "refresh-token")
+
+	// The key is read from the file created above.
+	tokens, err = readOrCreateTokenCipher(path)
+	require.NoError(t, err)
+
+	token, err := tokens.open(1, sealed)
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-token", token)
+
+	_, err = tokens.open(2, sealed)
+	assert.Error(t, err, "a token can't be used for another user")
+}
+
+func TestOIDCRevalidation(t *testing.T) {
+	m, err := mockoidc.Run()
+	require.NoError(t, err)
+	defer m.Shutdown()
+
+	h := newTestHeadscale(t)
+	ctx := context.Background()
+
+	cfg := &types.OIDCConfig{
+		Name:          "oidc",
+		Primary:       true,
+		Issuer:        m.Issuer(),
+		ClientID:      m.ClientID,
+		ClientSecret:  m.ClientSecret,
+		Scope:         []string{"openid", "email", "profile", "groups"},
+		AllowedGroups: []string{"engineering"},
+		Revalidation: types.OIDCRevalidationConfig{
+			Enabled:  true,
+			Interval: time.Hour,
+			KeyPath:  t.TempDir() + "/oidc_token.key",
+		},
+	}
+	provider, err := NewAuthProviderOIDC(ctx, "https://headscale.example.com", cfg, false, h.db, h.nodeNotifier, h.ipAlloc, h.polMan, h.auditLog)
+	require.NoError(t, err)
+
+	// login creates the user of the mock user with a node, like an OIDC
+	// login, and returns the user and the node.
+	login := func(mockUser *mockoidc.MockUser, ip string) (*types.User, *types.Node) {
+		user, err := provider.createOrUpdateUserFromClaim(&types.OIDCClaims{
+			Iss:      m.Issuer(),
+			Sub:      mockUser.Subject,
+			Username: mockUser.PreferredUsername,
+			Groups:   mockUser.Groups,
+		})
+		require.NoError(t, err)
+
+		session, err := m.SessionStore.NewSession("openid email profile groups", "", mockUser, "", "")
+		require.NoError(t, err)
+		refreshToken, err := session.RefreshToken(m.Config(), m.Keypair, m.Now())
+		require.NoError(t, err)
+		provider.storeRefreshToken(user, &oauth2.Token{RefreshToken: refreshToken})
+
+		addr := netip.MustParseAddr(ip)
+		node := &types.Node{
+			MachineKey:     key.NewMachine().Public(),
+			NodeKey:        key.NewNode().Public(),
+			Hostname:       mockUser.PreferredUsername,
+			UserID:         user.ID,
+			IPv4:           &addr,
+			RegisterMethod: util.RegisterMethodOIDC,
+			Hostinfo:       &tailcfg.Hostinfo{},
+		}
+		require.NoError(t, h.db.DB.Save(node).Error)
+
+		return user, node
+	}
+
+	alice := &mockoidc.MockUser{
+		Subject:           "alice",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "alice",
+		Groups:            []string{"engineering"},
+	}
+	aliceUser, aliceNode := login(alice, "100.64.0.1")
+
+	bob := &mockoidc.MockUser{
+		Subject:           "bob",
+		Email:             "bob@example.com",
+		EmailVerified:     true,
+		PreferredUsername: "bob",
+		Groups:            []string{"engineering"},
+	}
+	_, bobNode := login(bob, "100.64.0.2")
+
+	isExpired := func(node *types.Node) bool {
+		node, err := h.db.GetNodeByID(node.ID)
+		require.NoError(t, err)
+
+		return node.IsExpired()
+	}
+
+	// Users who logged in before the provider was recorded are
+	// re-validated by the primary provider.
+	require.NoError(t, h.db.DB.Model(&types.User{}).Where("name = ?", "bob").Update("oidc_provider", "").Error)
+
+	// Both users are still allowed, their groups are updated.
+	bob.Groups = []string{"engineering", "sre"}
+	provider.revalidate(ctx)
+	assert.False(t, isExpired(aliceNode))
+	assert.False(t, isExpired(bobNode))
+
+	user, err := h.db.GetUserByName("bob")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"engineering", "sre"}, user.Groups)
+
+	// Bob left the allowed group.
+	bob.Groups = []string{"sre"}
+	provider.revalidate(ctx)
+	assert.False(t, isExpired(aliceNode))
+	assert.True(t, isExpired(bobNode))
+
+	user, err = h.db.GetUserByName("bob")
+	require.NoError(t, err)
+	assert.Empty(t, user.OIDCRefreshToken, "the user has to log in again")
+
+	// The sessions are revoked at the OIDC provider, the refresh token of
+	// Alice is rejected.
+	m.SessionStore = mockoidc.NewSessionStore()
+	provider.revalidate(ctx)
+	assert.True(t, isExpired(aliceNode))
+
+	// The OIDC provider is not reachable, the nodes are kept.
+	_, carolNode := login(&mockoidc.MockUser{
+		Subject:           "carol",
+		PreferredUsername: "carol",
+		Groups:            []string{"engineering"},
+	}, "100.64.0.3")
+	require.NoError(t, m.Shutdown())
+	provider.revalidate(ctx)
+	assert.False(t, isExpired(carolNode))
+
+	user, err = h.db.GetUserByID(types.UserID(aliceUser.ID))
+	require.NoError(t, err)
+	assert.Empty(t, user.OIDCRefreshToken)
+}
@@ -45,6 +45,12 @@ func MatchFromFilterRule(rule tailcfg.FilterRule) Match {
 		dests = append(dests, dest.IP)
 	}
 
+	for _, capGrant := range rule.CapGrant {
+		for _, dst := range capGrant.Dsts {
+			dests = append(dests, dst.String())
+		}
+	}
+
 	return MatchFromStrings(rule.SrcIPs, dests)
 }
 
@@ -10,19 +10,48 @@ import (
 	"tailscale.com/tailcfg"
 )
 
+// ErrPolicyTestsFailed is returned when the tests in a policy do not pass.
+var ErrPolicyTestsFailed = policyv2.ErrPolicyTestsFailed
+
 type PolicyManager interface {
 	// Filter returns the current filter rules for the entire tailnet and the associated matchers.
 	Filter() ([]tailcfg.FilterRule, []matcher.Match)
+	// FilterForNode returns the filter rules and matchers as seen from the given node,
+	// including rules that depend on the node, like autogroup:self.
+	FilterForNode(*types.Node) ([]tailcfg.FilterRule, []matcher.Match, error)
 	SSHPolicy(*types.Node) (*tailcfg.SSHPolicy, error)
+	// SetPolicy replaces the current policy, it fails if the tests in
+	// the new policy do not pass.
 	SetPolicy([]byte) (bool, error)
 	SetUsers(users []types.User) (bool, error)
 	SetNodes(nodes types.Nodes) (bool, error)
 	// NodeCanHaveTag reports whether the given node can have the given tag.
 	NodeCanHaveTag(*types.Node, string) bool
 
+	// NodeCapMap returns the capability map of the node, the given defaults
+	// with the nodeAttrs of the policy applied.
+	NodeCapMap(*types.Node, tailcfg.NodeCapMap) tailcfg.NodeCapMap
+
 	// NodeCanApproveRoute reports whether the given node can approve the given route.
 	NodeCanApproveRoute(*types.Node, netip.Prefix) bool
 
+	// RoutePriority returns the priority of the node to be the primary
+	// router of the route according to the policy, 0 if the policy has
+	// no preference for the node.
+	RoutePriority(*types.Node, netip.Prefix) int
+
+	// NodeRegion returns the region of the node according to the policy,
+	// an empty string if the node is in no region.
+	NodeRegion(*types.Node) string
+
+	// DNSProfile returns the DNS profile of the node according to the
+	// policy, nil if no profile applies to the node.
+	DNSProfile(*types.Node) *types.DNSProfile
+
+	// NodeCanUseDERPRegion reports if the node may use the DERP region
+	// according to the policy.
+	NodeCanUseDERPRegion(*types.Node, int) bool
+
 	Version() int
 	DebugString() string
 }
@@ -39,6 +68,17 @@ func NewPolicyManager(pol []byte, users []types.User, nodes types.Nodes) (Policy
 	return polMan, err
 }
 
+// CheckPolicy parses the policy and evaluates its tests against the
+// given users and nodes.
+func CheckPolicy(pol []byte, users []types.User, nodes types.Nodes) error {
+	polMan, err := policyv2.NewPolicyManager(pol, users, nodes)
+	if err != nil {
+		return err
+	}
+
+	return polMan.RunTests()
+}
+
 // PolicyManagersForTest returns all available PostureManagers to be used
 // in tests to validate them in tests that try to determine that they
 // behave the same.
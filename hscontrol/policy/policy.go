@@ -87,11 +87,31 @@ func ReduceFilterRules(node *types.Node, rules []tailcfg.FilterRule) []tailcfg.F
 			}
 		}
 
-		if len(dests) > 0 {
+		// Application capabilities are only relevant for the
+		// destinations they are granted to.
+		var capGrants []tailcfg.CapGrant
+		for _, capGrant := range rule.CapGrant {
+			var dsts []netip.Prefix
+			for _, dst := range capGrant.Dsts {
+				if slices.ContainsFunc(node.IPs(), dst.Contains) {
+					dsts = append(dsts, dst)
+				}
+			}
+
+			if len(dsts) > 0 {
+				capGrants = append(capGrants, tailcfg.CapGrant{
+					Dsts:   dsts,
+					CapMap: capGrant.CapMap,
+				})
+			}
+		}
+
+		if len(dests) > 0 || len(capGrants) > 0 {
 			ret = append(ret, tailcfg.FilterRule{
 				SrcIPs:   rule.SrcIPs,
 				DstPorts: dests,
 				IPProto:  rule.IPProto,
+				CapGrant: capGrants,
 			})
 		}
 	}
@@ -102,8 +122,10 @@ func ReduceFilterRules(node *types.Node, rules []tailcfg.FilterRule) []tailcfg.F
 // AutoApproveRoutes approves any route that can be autoapproved from
 // the nodes perspective according to the given policy.
 // It reports true if any routes were approved.
+// The routes of a node waiting for approval are not auto approved until
+// the node is approved.
 func AutoApproveRoutes(pm PolicyManager, node *types.Node) bool {
-	if pm == nil {
+	if pm == nil || node.PendingApproval {
 		return false
 	}
 	var newApproved []netip.Prefix
@@ -155,6 +155,50 @@ func TestReduceFilterRules(t *testing.T) {
 			},
 			want: []tailcfg.FilterRule{},
 		},
+		{
+			name: "grant-app-capabilities-only-to-destination",
+			pol: `
+{
+  "grants": [
+    {
+      "src": ["100.64.0.2"],
+      "dst": ["100.64.0.1", "100.64.0.3"],
+      "app": {
+        "tailscale.com/cap/example": [{}]
+      }
+    }
+  ],
+}
+`,
+			node: &types.Node{
+				IPv4: ap("100.64.0.1"),
+				IPv6: ap("fd7a:115c:a1e0::1"),
+				User: users[0],
+			},
+			peers: types.Nodes{
+				&types.Node{
+					IPv4: ap("100.64.0.2"),
+					IPv6: ap("fd7a:115c:a1e0::2"),
+					User: users[0],
+				},
+			},
+			want: []tailcfg.FilterRule{
+				{
+					SrcIPs: []string{"100.64.0.2/32", "fd7a:115c:a1e0::2/128"},
+					CapGrant: []tailcfg.CapGrant{
+						{
+							Dsts: []netip.Prefix{
+								netip.MustParsePrefix("100.64.0.1/32"),
+								netip.MustParsePrefix("fd7a:115c:a1e0::1/128"),
+							},
+							CapMap: tailcfg.PeerCapMap{
+								"tailscale.com/cap/example": []tailcfg.RawMessage{"{}"},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name: "1604-subnet-routers-are-preserved",
 			pol: `
@@ -821,7 +865,7 @@ func TestReduceFilterRules(t *testing.T) {
 				t.Logf("full filter:\n%s", must.Get(json.MarshalIndent(got, "", "  ")))
 				got = ReduceFilterRules(tt.node, got)
 
-				if diff := cmp.Diff(tt.want, got); diff != "" {
+				if diff := cmp.Diff(tt.want, got, util.Comparers...); diff != "" {
 					log.Trace().Interface("got", got).Msg("result")
 					t.Errorf("TestReduceFilterRules() unexpected result (-want +got):\n%s", diff)
 				}
@@ -1942,6 +1986,62 @@ func TestSSHPolicyRules(t *testing.T) {
 			expectErr:    true,
 			errorMessage: "autogroup \"autogroup:invalid\" is not supported",
 		},
+		{
+			name:       "member-to-autogroup-self",
+			targetNode: nodeUser1,
+			peers: types.Nodes{
+				&nodeUser2,
+				&taggedClient,
+				&types.Node{
+					Hostname: "user1-laptop",
+					IPv4:     ap("100.64.0.5"),
+					UserID:   1,
+					User:     users[0],
+				},
+			},
+			policy: `{
+				"ssh": [
+					{
+						"action": "accept",
+						"src": ["autogroup:member"],
+						"dst": ["autogroup:self"],
+						"users": ["autogroup:nonroot"]
+					}
+				]
+			}`,
+			wantSSH: &tailcfg.SSHPolicy{Rules: []*tailcfg.SSHRule{
+				{
+					Principals: []*tailcfg.SSHPrincipal{
+						{NodeIP: "100.64.0.1"},
+						{NodeIP: "100.64.0.5"},
+					},
+					SSHUsers: map[string]string{
+						"autogroup:nonroot": "=",
+					},
+					Action: &tailcfg.SSHAction{
+						Accept:                   true,
+						AllowAgentForwarding:     true,
+						AllowLocalPortForwarding: true,
+					},
+				},
+			}},
+		},
+		{
+			name:       "member-to-autogroup-self-tagged-target",
+			targetNode: taggedServer,
+			peers:      types.Nodes{&nodeUser1, &nodeUser2},
+			policy: `{
+				"ssh": [
+					{
+						"action": "accept",
+						"src": ["autogroup:member"],
+						"dst": ["autogroup:self"],
+						"users": ["autogroup:nonroot"]
+					}
+				]
+			}`,
+			wantSSH: &tailcfg.SSHPolicy{Rules: nil},
+		},
 	}
 
 	for _, tt := range tests {
@@ -3,6 +3,7 @@ package v2
 import (
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/juanfont/headscale/hscontrol/types"
@@ -13,7 +14,8 @@ import (
 )
 
 var (
-	ErrInvalidAction = errors.New("invalid action")
+	ErrInvalidAction             = errors.New("invalid action")
+	ErrAutogroupSelfRequiresNode = errors.New("autogroup:self can only be resolved for a given node")
 )
 
 // compileFilterRules takes a set of nodes and an ACLPolicy and generates a
@@ -51,6 +53,11 @@ func (pol *Policy) compileFilterRules(
 
 		var destPorts []tailcfg.NetPortRange
 		for _, dest := range acl.Destinations {
+			// autogroup:self is compiled per node in compileFilterRulesForNode.
+			if isAutogroupSelf(dest.Alias) {
+				continue
+			}
+
 			ips, err := dest.Alias.Resolve(pol, users, nodes)
 			if err != nil {
 				log.Trace().Err(err).Msgf("resolving destination ips")
@@ -82,6 +89,164 @@ func (pol *Policy) compileFilterRules(
 		})
 	}
 
+	grantRules, err := pol.compileGrantRules(users, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(rules, grantRules...), nil
+}
+
+// compileGrantRules generates the FilterRules for the grants in the policy.
+// Network access (ip) results in a rule per entry, while application
+// capabilities (app) are sent as a CapGrant to the destinations.
+func (pol *Policy) compileGrantRules(
+	users types.Users,
+	nodes types.Nodes,
+) ([]tailcfg.FilterRule, error) {
+	var rules []tailcfg.FilterRule
+
+	for _, grant := range pol.Grants {
+		srcIPs, err := grant.Sources.Resolve(pol, users, nodes)
+		if err != nil {
+			log.Trace().Err(err).Msgf("resolving source ips")
+		}
+
+		if srcIPs == nil || len(srcIPs.Prefixes()) == 0 {
+			continue
+		}
+
+		dstIPs, err := grant.Destinations.Resolve(pol, users, nodes)
+		if err != nil {
+			log.Trace().Err(err).Msgf("resolving destination ips")
+		}
+
+		if dstIPs == nil || len(dstIPs.Prefixes()) == 0 {
+			continue
+		}
+
+		srcs := ipSetToPrefixStringList(srcIPs)
+
+		for _, ip := range grant.IP {
+			protocols, _, err := parseProtocol(ip.Protocol)
+			if err != nil {
+				return nil, fmt.Errorf("parsing policy, protocol err: %w ", err)
+			}
+
+			var destPorts []tailcfg.NetPortRange
+			for _, pref := range dstIPs.Prefixes() {
+				for _, port := range ip.Ports {
+					destPorts = append(destPorts, tailcfg.NetPortRange{
+						IP:    pref.String(),
+						Ports: port,
+					})
+				}
+			}
+
+			rules = append(rules, tailcfg.FilterRule{
+				SrcIPs:   srcs,
+				DstPorts: destPorts,
+				IPProto:  protocols,
+			})
+		}
+
+		if len(grant.App) > 0 {
+			rules = append(rules, tailcfg.FilterRule{
+				SrcIPs: srcs,
+				CapGrant: []tailcfg.CapGrant{
+					{
+						Dsts:   dstIPs.Prefixes(),
+						CapMap: grant.App,
+					},
+				},
+			})
+		}
+	}
+
+	return rules, nil
+}
+
+// compileFilterRulesForNode generates the FilterRules that only make sense
+// from the perspective of a given node, which currently are the ACLs using
+// autogroup:self as destination. self is the resolved autogroup:self of
+// the node, the untagged devices owned by the same user. The rules are
+// meant to be appended to the result of compileFilterRules.
+func (pol *Policy) compileFilterRulesForNode(
+	users types.Users,
+	nodes types.Nodes,
+	self *netipx.IPSet,
+) ([]tailcfg.FilterRule, error) {
+	if pol == nil || self == nil {
+		return nil, nil
+	}
+
+	var rules []tailcfg.FilterRule
+
+	for _, acl := range pol.ACLs {
+		if acl.Action != "accept" {
+			return nil, ErrInvalidAction
+		}
+
+		if !slices.ContainsFunc(acl.Destinations, func(dest AliasWithPorts) bool {
+			return isAutogroupSelf(dest.Alias)
+		}) {
+			continue
+		}
+
+		srcIPs, err := acl.Sources.Resolve(pol, users, nodes)
+		if err != nil {
+			log.Trace().Err(err).Msgf("resolving source ips")
+		}
+
+		if srcIPs == nil {
+			continue
+		}
+
+		// Only the devices of the same user can reach autogroup:self.
+		var srcBuild netipx.IPSetBuilder
+		srcBuild.AddSet(srcIPs)
+		srcBuild.Intersect(self)
+		srcIPs, err = srcBuild.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(srcIPs.Prefixes()) == 0 {
+			continue
+		}
+
+		protocols, _, err := parseProtocol(acl.Protocol)
+		if err != nil {
+			return nil, fmt.Errorf("parsing policy, protocol err: %w ", err)
+		}
+
+		var destPorts []tailcfg.NetPortRange
+		for _, dest := range acl.Destinations {
+			if !isAutogroupSelf(dest.Alias) {
+				continue
+			}
+
+			for _, pref := range self.Prefixes() {
+				for _, port := range dest.Ports {
+					destPorts = append(destPorts, tailcfg.NetPortRange{
+						IP:    pref.String(),
+						Ports: port,
+					})
+				}
+			}
+		}
+
+		if len(destPorts) == 0 {
+			continue
+		}
+
+		rules = append(rules, tailcfg.FilterRule{
+			SrcIPs:   ipSetToPrefixStringList(srcIPs),
+			DstPorts: destPorts,
+			IPProto:  protocols,
+		})
+	}
+
 	return rules, nil
 }
 
@@ -95,10 +260,14 @@ func sshAction(accept bool, duration time.Duration) tailcfg.SSHAction {
 	}
 }
 
+// compileSSHPolicy generates the SSH policy for the given node.
+// self is the resolved autogroup:self of the node, it is nil if the
+// node is tagged or the policy does not use autogroup:self.
 func (pol *Policy) compileSSHPolicy(
 	users types.Users,
 	node *types.Node,
 	nodes types.Nodes,
+	self *netipx.IPSet,
 ) (*tailcfg.SSHPolicy, error) {
 	if pol == nil || pol.SSHs == nil || len(pol.SSHs) == 0 {
 		return nil, nil
@@ -108,7 +277,13 @@ func (pol *Policy) compileSSHPolicy(
 
 	for index, rule := range pol.SSHs {
 		var dest netipx.IPSetBuilder
+		var selfDest bool
 		for _, src := range rule.Destinations {
+			if isAutogroupSelf(src) {
+				selfDest = true
+				continue
+			}
+
 			ips, err := src.Resolve(pol, users, nodes)
 			if err != nil {
 				log.Trace().Err(err).Msgf("resolving destination ips")
@@ -121,8 +296,14 @@ func (pol *Policy) compileSSHPolicy(
 			return nil, err
 		}
 
+		// If the node is only a destination through autogroup:self,
+		// only the devices of the same user are allowed to connect.
+		onlySelf := false
 		if !node.InIPSet(destSet) {
-			continue
+			if !selfDest || self == nil || !node.InIPSet(self) {
+				continue
+			}
+			onlySelf = true
 		}
 
 		var action tailcfg.SSHAction
@@ -141,12 +322,26 @@ func (pol *Policy) compileSSHPolicy(
 			log.Trace().Err(err).Msgf("resolving source ips")
 		}
 
+		if onlySelf && srcIPs != nil {
+			var srcBuild netipx.IPSetBuilder
+			srcBuild.AddSet(srcIPs)
+			srcBuild.Intersect(self)
+			srcIPs, err = srcBuild.IPSet()
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		for addr := range util.IPSetAddrIter(srcIPs) {
 			principals = append(principals, &tailcfg.SSHPrincipal{
 				NodeIP: addr.String(),
 			})
 		}
 
+		if onlySelf && len(principals) == 0 {
+			continue
+		}
+
 		userMap := make(map[string]string, len(rule.Users))
 		for _, user := range rule.Users {
 			userMap[user.String()] = "="
@@ -1,10 +1,12 @@
 package v2
 
 import (
+	"net/netip"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
 	"gorm.io/gorm"
 	"tailscale.com/tailcfg"
 )
@@ -332,6 +334,118 @@ func TestParsing(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:   "grant-ip",
+			format: "hujson",
+			acl: `
+{
+	"hosts": {
+		"host-1": "100.100.100.100",
+	},
+
+	"grants": [
+		{
+			"src": ["testuser@"],
+			"dst": ["host-1"],
+			"ip": ["tcp:22", "udp:53,5353", "icmp:*"],
+		},
+	],
+}
+`,
+			want: []tailcfg.FilterRule{
+				{
+					SrcIPs: []string{"200.200.200.200/32"},
+					DstPorts: []tailcfg.NetPortRange{
+						{IP: "100.100.100.100/32", Ports: tailcfg.PortRange{First: 22, Last: 22}},
+					},
+					IPProto: []int{protocolTCP},
+				},
+				{
+					SrcIPs: []string{"200.200.200.200/32"},
+					DstPorts: []tailcfg.NetPortRange{
+						{IP: "100.100.100.100/32", Ports: tailcfg.PortRange{First: 53, Last: 53}},
+						{IP: "100.100.100.100/32", Ports: tailcfg.PortRange{First: 5353, Last: 5353}},
+					},
+					IPProto: []int{protocolUDP},
+				},
+				{
+					SrcIPs: []string{"200.200.200.200/32"},
+					DstPorts: []tailcfg.NetPortRange{
+						{IP: "100.100.100.100/32", Ports: tailcfg.PortRangeAny},
+					},
+					IPProto: []int{protocolICMP, protocolIPv6ICMP},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "grant-app",
+			format: "hujson",
+			acl: `
+{
+	"hosts": {
+		"host-1": "100.100.100.100",
+	},
+
+	"grants": [
+		{
+			"src": ["testuser@"],
+			"dst": ["host-1"],
+			"app": {
+				"tailscale.com/cap/example": [
+					{"role": "admin"},
+				],
+			},
+		},
+	],
+}
+`,
+			want: []tailcfg.FilterRule{
+				{
+					SrcIPs: []string{"200.200.200.200/32"},
+					CapGrant: []tailcfg.CapGrant{
+						{
+							Dsts: []netip.Prefix{netip.MustParsePrefix("100.100.100.100/32")},
+							CapMap: tailcfg.PeerCapMap{
+								"tailscale.com/cap/example": []tailcfg.RawMessage{`{"role":"admin"}`},
+							},
+						},
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:   "grant-without-ip-or-app",
+			format: "hujson",
+			acl: `
+{
+	"grants": [
+		{
+			"src": ["*"],
+			"dst": ["*"],
+		},
+	],
+}
+`,
+			wantErr: true,
+		},
+		{
+			name:   "grant-ports-not-allowed-for-protocol",
+			format: "hujson",
+			acl: `
+{
+	"grants": [
+		{
+			"src": ["*"],
+			"dst": ["*"],
+			"ip": ["icmp:22"],
+		},
+	],
+}
+`,
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -370,7 +484,7 @@ func TestParsing(t *testing.T) {
 				return
 			}
 
-			if diff := cmp.Diff(tt.want, rules); diff != "" {
+			if diff := cmp.Diff(tt.want, rules, util.Comparers...); diff != "" {
 				t.Errorf("parsing() unexpected result (-want +got):\n%s", diff)
 			}
 		})
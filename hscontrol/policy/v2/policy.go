@@ -28,16 +28,46 @@ type PolicyManager struct {
 	filter     []tailcfg.FilterRule
 	matchers   []matcher.Match
 
+	// selfSets is the resolved autogroup:self per user ID, it is
+	// only populated if the policy uses autogroup:self.
+	selfSetsHash deephash.Sum
+	selfSets     map[uint]*netipx.IPSet
+
 	tagOwnerMapHash deephash.Sum
 	tagOwnerMap     map[Tag]*netipx.IPSet
 
+	nodeAttrsHash deephash.Sum
+	nodeAttrs     []nodeAttr
+
 	exitSetHash        deephash.Sum
 	exitSet            *netipx.IPSet
 	autoApproveMapHash deephash.Sum
 	autoApproveMap     map[netip.Prefix]*netipx.IPSet
 
+	routePrioritiesHash deephash.Sum
+	routePriorities     map[netip.Prefix][]*netipx.IPSet
+
+	regionsHash deephash.Sum
+	regions     map[string]*netipx.IPSet
+
+	dnsProfilesHash deephash.Sum
+	dnsProfiles     []dnsProfile
+
+	derpRegionsHash deephash.Sum
+	derpRegions     map[int]*netipx.IPSet
+
 	// Lazy map of SSH policies
 	sshPolicyMap map[types.NodeID]*tailcfg.SSHPolicy
+
+	// Lazy map of per node filters, only used if the policy
+	// contains rules depending on the node, like autogroup:self.
+	filterMap map[types.NodeID]nodeFilter
+}
+
+// nodeFilter is the filter rules and matchers compiled for a given node.
+type nodeFilter struct {
+	filter   []tailcfg.FilterRule
+	matchers []matcher.Match
 }
 
 // NewPolicyManager creates a new PolicyManager from a policy file and a list of users and nodes.
@@ -54,6 +84,7 @@ func NewPolicyManager(b []byte, users []types.User, nodes types.Nodes) (*PolicyM
 		users:        users,
 		nodes:        nodes,
 		sshPolicyMap: make(map[types.NodeID]*tailcfg.SSHPolicy, len(nodes)),
+		filterMap:    make(map[types.NodeID]nodeFilter, len(nodes)),
 	}
 
 	_, err = pm.updateLocked()
@@ -72,6 +103,7 @@ func (pm *PolicyManager) updateLocked() (bool, error) {
 	// policies for nodes that have changed. Particularly if the only difference is
 	// that nodes has been added or removed.
 	defer clear(pm.sshPolicyMap)
+	defer clear(pm.filterMap)
 
 	filter, err := pm.pol.compileFilterRules(pm.users, pm.nodes)
 	if err != nil {
@@ -86,6 +118,16 @@ func (pm *PolicyManager) updateLocked() (bool, error) {
 		pm.matchers = matcher.MatchesFromFilterRules(pm.filter)
 	}
 
+	selfSets, err := resolveSelfSets(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving autogroup:self: %w", err)
+	}
+
+	selfSetsHash := deephash.Hash(&selfSets)
+	selfSetsChanged := selfSetsHash != pm.selfSetsHash
+	pm.selfSets = selfSets
+	pm.selfSetsHash = selfSetsHash
+
 	// Order matters, tags might be used in autoapprovers, so we need to ensure
 	// that the map for tag owners is resolved before resolving autoapprovers.
 	// TODO(kradalby): Order might not matter after #2417
@@ -99,6 +141,16 @@ func (pm *PolicyManager) updateLocked() (bool, error) {
 	pm.tagOwnerMap = tagMap
 	pm.tagOwnerMapHash = tagOwnerMapHash
 
+	nodeAttrs, err := resolveNodeAttrs(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving node attributes: %w", err)
+	}
+
+	nodeAttrsHash := deephash.Hash(&nodeAttrs)
+	nodeAttrsChanged := nodeAttrsHash != pm.nodeAttrsHash
+	pm.nodeAttrs = nodeAttrs
+	pm.nodeAttrsHash = nodeAttrsHash
+
 	autoMap, exitSet, err := resolveAutoApprovers(pm.pol, pm.users, pm.nodes)
 	if err != nil {
 		return false, fmt.Errorf("resolving auto approvers map: %w", err)
@@ -114,8 +166,48 @@ func (pm *PolicyManager) updateLocked() (bool, error) {
 	pm.exitSet = exitSet
 	pm.exitSetHash = exitSetHash
 
+	routePriorities, err := resolveRoutePriorities(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving route priorities: %w", err)
+	}
+
+	routePrioritiesHash := deephash.Hash(&routePriorities)
+	routePrioritiesChanged := routePrioritiesHash != pm.routePrioritiesHash
+	pm.routePriorities = routePriorities
+	pm.routePrioritiesHash = routePrioritiesHash
+
+	regions, err := resolveRegions(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving regions: %w", err)
+	}
+
+	regionsHash := deephash.Hash(&regions)
+	regionsChanged := regionsHash != pm.regionsHash
+	pm.regions = regions
+	pm.regionsHash = regionsHash
+
+	dnsProfiles, err := resolveDNSProfiles(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving DNS profiles: %w", err)
+	}
+
+	dnsProfilesHash := deephash.Hash(&dnsProfiles)
+	dnsProfilesChanged := dnsProfilesHash != pm.dnsProfilesHash
+	pm.dnsProfiles = dnsProfiles
+	pm.dnsProfilesHash = dnsProfilesHash
+
+	derpRegions, err := resolveDERPRegions(pm.pol, pm.users, pm.nodes)
+	if err != nil {
+		return false, fmt.Errorf("resolving DERP regions: %w", err)
+	}
+
+	derpRegionsHash := deephash.Hash(&derpRegions)
+	derpRegionsChanged := derpRegionsHash != pm.derpRegionsHash
+	pm.derpRegions = derpRegions
+	pm.derpRegionsHash = derpRegionsHash
+
 	// If neither of the calculated values changed, no need to update nodes
-	if !filterChanged && !tagOwnerChanged && !autoApproveChanged && !exitSetChanged {
+	if !filterChanged && !selfSetsChanged && !tagOwnerChanged && !nodeAttrsChanged && !autoApproveChanged && !exitSetChanged && !routePrioritiesChanged && !regionsChanged && !dnsProfilesChanged && !derpRegionsChanged {
 		return false, nil
 	}
 
@@ -130,7 +222,7 @@ func (pm *PolicyManager) SSHPolicy(node *types.Node) (*tailcfg.SSHPolicy, error)
 		return sshPol, nil
 	}
 
-	sshPol, err := pm.pol.compileSSHPolicy(pm.users, node, pm.nodes)
+	sshPol, err := pm.pol.compileSSHPolicy(pm.users, node, pm.nodes, pm.selfSetForNodeLocked(node))
 	if err != nil {
 		return nil, fmt.Errorf("compiling SSH policy: %w", err)
 	}
@@ -152,11 +244,30 @@ func (pm *PolicyManager) SetPolicy(polB []byte) (bool, error) {
 	pm.mu.Lock()
 	defer pm.mu.Unlock()
 
+	// Only apply the policy if all the tests in the
+	// policy pass with the current users and nodes.
+	if err := pol.runTests(pm.users, pm.nodes); err != nil {
+		return false, err
+	}
+
 	pm.pol = pol
 
 	return pm.updateLocked()
 }
 
+// RunTests evaluates the tests in the current policy against the
+// current users and nodes.
+func (pm *PolicyManager) RunTests() error {
+	if pm == nil {
+		return nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return pm.pol.runTests(pm.users, pm.nodes)
+}
+
 // Filter returns the current filter rules for the entire tailnet and the associated matchers.
 func (pm *PolicyManager) Filter() ([]tailcfg.FilterRule, []matcher.Match) {
 	if pm == nil {
@@ -168,6 +279,52 @@ func (pm *PolicyManager) Filter() ([]tailcfg.FilterRule, []matcher.Match) {
 	return pm.filter, pm.matchers
 }
 
+// FilterForNode returns the filter rules and matchers for the given node.
+// It includes the rules which can only be compiled from the perspective
+// of the node, like autogroup:self, on top of the rules from Filter.
+func (pm *PolicyManager) FilterForNode(node *types.Node) ([]tailcfg.FilterRule, []matcher.Match, error) {
+	if pm == nil {
+		return nil, nil, nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	if !pm.pol.usesAutogroupSelf() {
+		return pm.filter, pm.matchers, nil
+	}
+
+	if nf, ok := pm.filterMap[node.ID]; ok {
+		return nf.filter, nf.matchers, nil
+	}
+
+	selfRules, err := pm.pol.compileFilterRulesForNode(pm.users, pm.nodes, pm.selfSetForNodeLocked(node))
+	if err != nil {
+		return nil, nil, fmt.Errorf("compiling filter rules for node: %w", err)
+	}
+
+	filter := append(slices.Clone(pm.filter), selfRules...)
+	nf := nodeFilter{
+		filter:   filter,
+		matchers: matcher.MatchesFromFilterRules(filter),
+	}
+	pm.filterMap[node.ID] = nf
+
+	return nf.filter, nf.matchers, nil
+}
+
+// selfSetForNodeLocked returns the resolved autogroup:self for the node,
+// or nil if the node is tagged or autogroup:self is not used.
+// It must be called with the lock held.
+func (pm *PolicyManager) selfSetForNodeLocked(node *types.Node) *netipx.IPSet {
+	self, ok := pm.selfSets[node.UserID]
+	if !ok || !node.InIPSet(self) {
+		return nil
+	}
+
+	return self
+}
+
 // SetUsers updates the users in the policy manager and updates the filter rules.
 func (pm *PolicyManager) SetUsers(users []types.User) (bool, error) {
 	if pm == nil {
@@ -209,6 +366,19 @@ func (pm *PolicyManager) NodeCanHaveTag(node *types.Node, tag string) bool {
 	return false
 }
 
+// NodeCapMap returns the capability map of the node, which is the given
+// default capabilities with the nodeAttrs of the policy applied.
+func (pm *PolicyManager) NodeCapMap(node *types.Node, defaults tailcfg.NodeCapMap) tailcfg.NodeCapMap {
+	if pm == nil {
+		return defaults
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return applyNodeAttrs(pm.nodeAttrs, node, defaults)
+}
+
 func (pm *PolicyManager) NodeCanApproveRoute(node *types.Node, route netip.Prefix) bool {
 	if pm == nil {
 		return false
@@ -258,7 +428,68 @@ func (pm *PolicyManager) NodeCanApproveRoute(node *types.Node, route netip.Prefi
 		}
 	}
 
-	return false
+	// App connectors can approve the routes they discover for their
+	// domains, as long as they are within the routes of the app connector.
+	return appConnectorCanApproveRoute(pm.nodeAttrs, node, route)
+}
+
+// RoutePriority returns the priority of the node to be the primary router
+// of the route given by the routePriorities of the policy, 0 if the node is
+// not listed for the route.
+func (pm *PolicyManager) RoutePriority(node *types.Node, route netip.Prefix) int {
+	if pm == nil {
+		return 0
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return routePriority(pm.routePriorities, node, route)
+}
+
+// NodeRegion returns the region of the node given by the regions of the
+// policy, an empty string if the node is in no region.
+func (pm *PolicyManager) NodeRegion(node *types.Node) string {
+	if pm == nil {
+		return ""
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return nodeRegion(pm.regions, node)
+}
+
+// DNSProfile returns the DNS profile of the node given by the dnsProfiles
+// of the policy, nil if no profile applies to the node.
+func (pm *PolicyManager) DNSProfile(node *types.Node) *types.DNSProfile {
+	if pm == nil {
+		return nil
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	return nodeDNSProfile(pm.dnsProfiles, node)
+}
+
+// NodeCanUseDERPRegion reports if the node may use the DERP region given
+// by the derpRegions of the policy. Regions which are not restricted can be
+// used by all nodes.
+func (pm *PolicyManager) NodeCanUseDERPRegion(node *types.Node, regionID int) bool {
+	if pm == nil {
+		return true
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+
+	members, ok := pm.derpRegions[regionID]
+	if !ok {
+		return true
+	}
+
+	return node.InIPSet(members)
 }
 
 func (pm *PolicyManager) Version() int {
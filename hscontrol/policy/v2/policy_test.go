@@ -2,6 +2,7 @@ package v2
 
 import (
 	"github.com/juanfont/headscale/hscontrol/policy/matcher"
+	"net/netip"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -66,3 +67,560 @@ func TestPolicyManager(t *testing.T) {
 		})
 	}
 }
+
+func TestPolicyManagerFilterForNodeAutogroupSelf(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "testuser", Email: "testuser@headscale.net"},
+		{Model: gorm.Model{ID: 2}, Name: "otheruser", Email: "otheruser@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:server": ["testuser@"],
+	},
+	"acls": [
+		{
+			"action": "accept",
+			"src": ["autogroup:member"],
+			"dst": ["autogroup:self:*"],
+		},
+	],
+}`
+
+	laptop := node("laptop", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	laptop.ID = 1
+	phone := node("phone", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], nil)
+	phone.ID = 2
+	other := node("other", "100.64.0.3", "fd7a:115c:a1e0::3", users[1], nil)
+	other.ID = 3
+	server := node("server", "100.64.0.4", "fd7a:115c:a1e0::4", users[0], nil)
+	server.ID = 4
+	server.ForcedTags = []string{"tag:server"}
+
+	nodes := types.Nodes{laptop, phone, other, server}
+
+	pm, err := NewPolicyManager([]byte(pol), users, nodes)
+	require.NoError(t, err)
+
+	filter, _ := pm.Filter()
+	require.Empty(t, filter, "autogroup:self must not be part of the global filter")
+
+	tests := []struct {
+		name string
+		node *types.Node
+		want []tailcfg.FilterRule
+	}{
+		{
+			name: "laptop-can-be-reached-by-phone",
+			node: laptop,
+			want: []tailcfg.FilterRule{
+				{
+					SrcIPs: []string{
+						"100.64.0.1/32",
+						"100.64.0.2/32",
+						"fd7a:115c:a1e0::1/128",
+						"fd7a:115c:a1e0::2/128",
+					},
+					DstPorts: []tailcfg.NetPortRange{
+						{IP: "100.64.0.1/32", Ports: tailcfg.PortRangeAny},
+						{IP: "100.64.0.2/32", Ports: tailcfg.PortRangeAny},
+						{IP: "fd7a:115c:a1e0::1/128", Ports: tailcfg.PortRangeAny},
+						{IP: "fd7a:115c:a1e0::2/128", Ports: tailcfg.PortRangeAny},
+					},
+				},
+			},
+		},
+		{
+			name: "other-user-only-itself",
+			node: other,
+			want: []tailcfg.FilterRule{
+				{
+					SrcIPs: []string{
+						"100.64.0.3/32",
+						"fd7a:115c:a1e0::3/128",
+					},
+					DstPorts: []tailcfg.NetPortRange{
+						{IP: "100.64.0.3/32", Ports: tailcfg.PortRangeAny},
+						{IP: "fd7a:115c:a1e0::3/128", Ports: tailcfg.PortRangeAny},
+					},
+				},
+			},
+		},
+		{
+			name: "tagged-node-not-in-self",
+			node: server,
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, matchers, err := pm.FilterForNode(tt.node)
+			require.NoError(t, err)
+
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("FilterForNode() filter mismatch (-want +got):\n%s", diff)
+			}
+
+			for _, peer := range nodes {
+				if peer.ID == tt.node.ID {
+					continue
+				}
+
+				sameUser := tt.want != nil && peer.UserID == tt.node.UserID && !peer.IsTagged()
+				if got := tt.node.CanAccess(matchers, peer); got != sameUser {
+					t.Errorf("%s.CanAccess(%s) = %t, want %t", tt.node.Hostname, peer.Hostname, got, sameUser)
+				}
+			}
+		})
+	}
+}
+
+func TestPolicyManagerSetPolicyRunsTests(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "testuser", Email: "testuser@headscale.net"},
+	}
+
+	laptop := node("laptop", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	laptop.ID = 1
+	server := node("server", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], nil)
+	server.ID = 2
+
+	nodes := types.Nodes{laptop, server}
+
+	pol := `{
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["100.64.0.2:22"]},
+	],
+	"tests": [
+		{"src": "100.64.0.1", "accept": ["100.64.0.2:22"]},
+	],
+}`
+
+	pm, err := NewPolicyManager([]byte(pol), users, nodes)
+	require.NoError(t, err)
+
+	before, _ := pm.Filter()
+
+	broken := `{
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["100.64.0.2:80"]},
+	],
+	"tests": [
+		{"src": "100.64.0.1", "accept": ["100.64.0.2:22"]},
+	],
+}`
+
+	changed, err := pm.SetPolicy([]byte(broken))
+	require.ErrorIs(t, err, ErrPolicyTestsFailed)
+	require.False(t, changed)
+
+	after, _ := pm.Filter()
+	if diff := cmp.Diff(before, after); diff != "" {
+		t.Errorf("SetPolicy() with failing tests changed the filter (-before +after):\n%s", diff)
+	}
+}
+
+func TestPolicyManagerNodeCapMap(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "testuser", Email: "testuser@headscale.net"},
+		{Model: gorm.Model{ID: 2}, Name: "otheruser", Email: "otheruser@headscale.net"},
+	}
+
+	pol := `{
+	"groups": {
+		"group:funnel": ["testuser@"],
+	},
+	"tagOwners": {
+		"tag:server": ["testuser@"],
+	},
+	"nodeAttrs": [
+		{
+			"target": ["group:funnel"],
+			"attr": ["funnel"],
+		},
+		{
+			"target": ["tag:server"],
+			"attr": ["-https://tailscale.com/cap/file-sharing", "randomize-client-port"],
+			"app": {
+				"tailscale.com/cap/example": [{"role": "server"}],
+			},
+		},
+	],
+}`
+
+	laptop := node("laptop", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	laptop.ID = 1
+	other := node("other", "100.64.0.2", "fd7a:115c:a1e0::2", users[1], nil)
+	other.ID = 2
+	server := node("server", "100.64.0.3", "fd7a:115c:a1e0::3", users[0], nil)
+	server.ID = 3
+	server.ForcedTags = []string{"tag:server"}
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{laptop, other, server})
+	require.NoError(t, err)
+
+	defaults := func() tailcfg.NodeCapMap {
+		return tailcfg.NodeCapMap{
+			tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
+			tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+		}
+	}
+
+	tests := []struct {
+		name string
+		node *types.Node
+		want tailcfg.NodeCapMap
+	}{
+		{
+			name: "group-member-gets-funnel",
+			node: laptop,
+			want: tailcfg.NodeCapMap{
+				tailcfg.CapabilityFileSharing: []tailcfg.RawMessage{},
+				tailcfg.CapabilitySSH:         []tailcfg.RawMessage{},
+				tailcfg.NodeAttrFunnel:        []tailcfg.RawMessage{},
+			},
+		},
+		{
+			name: "not-targeted-keeps-defaults",
+			node: other,
+			want: defaults(),
+		},
+		{
+			name: "tag-removes-and-adds",
+			node: server,
+			want: tailcfg.NodeCapMap{
+				tailcfg.CapabilitySSH:               []tailcfg.RawMessage{},
+				tailcfg.NodeAttrRandomizeClientPort: []tailcfg.RawMessage{},
+				"tailscale.com/cap/example":         []tailcfg.RawMessage{`{"role":"server"}`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pm.NodeCapMap(tt.node, defaults())
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("NodeCapMap() mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPolicyManagerAppConnectors(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "testuser", Email: "testuser@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:connector": ["testuser@"],
+		"tag:other": ["testuser@"],
+	},
+	"autoApprovers": {
+		"routes": {
+			"192.168.0.0/16": ["tag:other"],
+		},
+	},
+	"nodeAttrs": [
+		{
+			"target": ["*"],
+			"app": {
+				"tailscale.com/app-connectors": [
+					{"name": "github", "connectors": ["tag:connector"], "domains": ["github.com"], "routes": ["140.82.112.0/20"]},
+					{"name": "any", "connectors": ["*"], "domains": ["example.com"]},
+				],
+			},
+		},
+	],
+}`
+
+	connector := node("connector", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], &tailcfg.Hostinfo{AppConnector: "true"})
+	connector.ID = 1
+	connector.ForcedTags = []string{"tag:connector"}
+	idle := node("idle", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], &tailcfg.Hostinfo{})
+	idle.ID = 2
+	idle.ForcedTags = []string{"tag:connector"}
+	other := node("other", "100.64.0.3", "fd7a:115c:a1e0::3", users[0], &tailcfg.Hostinfo{AppConnector: "true"})
+	other.ID = 3
+	other.ForcedTags = []string{"tag:other"}
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{connector, idle, other})
+	require.NoError(t, err)
+
+	github := tailcfg.RawMessage(`{"name":"github","connectors":["tag:connector"],"domains":["github.com"],"routes":["140.82.112.0/20"]}`)
+	anyConnector := tailcfg.RawMessage(`{"name":"any","connectors":["*"],"domains":["example.com"]}`)
+
+	tests := []struct {
+		name        string
+		node        *types.Node
+		wantCaps    []tailcfg.RawMessage
+		route       netip.Prefix
+		wantApprove bool
+	}{
+		{
+			name:        "connector-approves-discovered-route",
+			node:        connector,
+			wantCaps:    []tailcfg.RawMessage{github, anyConnector},
+			route:       netip.MustParsePrefix("140.82.113.4/32"),
+			wantApprove: true,
+		},
+		{
+			name:        "connector-route-outside-allowed-prefixes",
+			node:        connector,
+			wantCaps:    []tailcfg.RawMessage{github, anyConnector},
+			route:       netip.MustParsePrefix("93.184.216.34/32"),
+			wantApprove: false,
+		},
+		{
+			name:        "not-advertising-as-connector",
+			node:        idle,
+			wantCaps:    []tailcfg.RawMessage{github, anyConnector},
+			route:       netip.MustParsePrefix("140.82.113.4/32"),
+			wantApprove: false,
+		},
+		{
+			name:        "other-connector-not-serving",
+			node:        other,
+			wantCaps:    []tailcfg.RawMessage{anyConnector},
+			route:       netip.MustParsePrefix("140.82.113.4/32"),
+			wantApprove: false,
+		},
+		{
+			name:        "other-connector-auto-approvers",
+			node:        other,
+			wantCaps:    []tailcfg.RawMessage{anyConnector},
+			route:       netip.MustParsePrefix("192.168.1.1/32"),
+			wantApprove: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pm.NodeCapMap(tt.node, nil)
+			if diff := cmp.Diff(tt.wantCaps, got[appConnectorsCapability]); diff != "" {
+				t.Errorf("NodeCapMap() app connectors mismatch (-want +got):\n%s", diff)
+			}
+
+			if got := pm.NodeCanApproveRoute(tt.node, tt.route); got != tt.wantApprove {
+				t.Errorf("NodeCanApproveRoute(%s) = %v, want %v", tt.route, got, tt.wantApprove)
+			}
+		})
+	}
+}
+
+func TestPolicyManagerRoutePriority(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "testuser", Email: "testuser@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:primary": ["testuser@"],
+		"tag:backup": ["testuser@"],
+	},
+	"routePriorities": {
+		"10.0.0.0/8": ["tag:backup"],
+		"10.1.0.0/16": ["tag:primary", "tag:backup"],
+	},
+}`
+
+	primary := node("primary", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	primary.ID = 1
+	primary.ForcedTags = []string{"tag:primary"}
+	backup := node("backup", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], nil)
+	backup.ID = 2
+	backup.ForcedTags = []string{"tag:backup"}
+	other := node("other", "100.64.0.3", "fd7a:115c:a1e0::3", users[0], nil)
+	other.ID = 3
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{primary, backup, other})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		node  *types.Node
+		route string
+		want  int
+	}{
+		{name: "first-router-is-highest", node: primary, route: "10.1.0.0/16", want: 2},
+		{name: "second-router", node: backup, route: "10.1.0.0/16", want: 1},
+		{name: "most-specific-route-is-used", node: backup, route: "10.1.2.0/24", want: 1},
+		{name: "not-listed-in-most-specific-route", node: primary, route: "10.2.0.0/16", want: 0},
+		{name: "containing-route", node: backup, route: "10.2.0.0/16", want: 1},
+		{name: "not-listed", node: other, route: "10.1.0.0/16", want: 0},
+		{name: "no-route", node: primary, route: "192.168.0.0/24", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pm.RoutePriority(tt.node, netip.MustParsePrefix(tt.route))
+			if got != tt.want {
+				t.Errorf("RoutePriority() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	_, err = NewPolicyManager([]byte(`{"routePriorities": {"0.0.0.0/0": ["testuser@"]}}`), users, nil)
+	require.ErrorContains(t, err, "exit route")
+}
+
+func TestPolicyManagerNodeRegion(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "eu", Email: "eu@headscale.net"},
+		{Model: gorm.Model{ID: 2}, Name: "us", Email: "us@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:router-eu": ["eu@"],
+	},
+	"regions": {
+		"eu": ["eu@", "tag:router-eu"],
+		"us": ["us@", "100.64.0.3/32"],
+	},
+}`
+
+	laptop := node("laptop", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	laptop.ID = 1
+	router := node("router", "100.64.0.2", "fd7a:115c:a1e0::2", users[1], nil)
+	router.ID = 2
+	router.ForcedTags = []string{"tag:router-eu"}
+	both := node("both", "100.64.0.3", "fd7a:115c:a1e0::3", users[0], nil)
+	both.ID = 3
+	other := node("other", "100.64.0.4", "fd7a:115c:a1e0::4", types.User{Model: gorm.Model{ID: 3}}, nil)
+	other.ID = 4
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{laptop, router, both, other})
+	require.NoError(t, err)
+
+	require.Equal(t, "eu", pm.NodeRegion(laptop))
+	require.Equal(t, "eu", pm.NodeRegion(router))
+	require.Equal(t, "eu", pm.NodeRegion(both), "the first region by name is used")
+	require.Empty(t, pm.NodeRegion(other))
+
+	_, err = NewPolicyManager([]byte(`{"regions": {"derp:1": ["eu@"]}}`), users, nil)
+	require.ErrorContains(t, err, "region name")
+}
+
+func TestPolicyManagerDNSProfile(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "ops", Email: "ops@headscale.net"},
+		{Model: gorm.Model{ID: 2}, Name: "dev", Email: "dev@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:prod": ["ops@"],
+	},
+	"dnsProfiles": [
+		{
+			"name": "prod",
+			"target": ["tag:prod"],
+			"nameservers": ["10.0.0.53"],
+			"split": {"corp.example.com": ["10.0.0.54"]},
+			"overrideLocalDNS": true,
+		},
+		{
+			"name": "ops",
+			"target": ["ops@"],
+			"nameservers": ["https://dns.example.com/dns-query"],
+		},
+	],
+}`
+
+	server := node("server", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	server.ID = 1
+	server.ForcedTags = []string{"tag:prod"}
+	laptop := node("laptop", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], nil)
+	laptop.ID = 2
+	other := node("other", "100.64.0.3", "fd7a:115c:a1e0::3", users[1], nil)
+	other.ID = 3
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{server, laptop, other})
+	require.NoError(t, err)
+
+	require.Equal(t, "prod", pm.DNSProfile(server).Name, "the first matching profile is used")
+	require.Equal(t, "ops", pm.DNSProfile(laptop).Name)
+	require.Nil(t, pm.DNSProfile(other))
+
+	_, err = NewPolicyManager([]byte(`{"dnsProfiles": [{"name": "bad", "target": ["ops@"], "nameservers": ["not-a-nameserver"]}]}`), users, nil)
+	require.ErrorContains(t, err, "invalid nameserver")
+}
+
+func TestPolicyManagerNodeCanUseDERPRegion(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "ops", Email: "ops@headscale.net"},
+	}
+
+	pol := `{
+	"tagOwners": {
+		"tag:infra": ["ops@"],
+	},
+	"derpRegions": {
+		"999": ["tag:infra"],
+	},
+}`
+
+	server := node("server", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	server.ID = 1
+	server.ForcedTags = []string{"tag:infra"}
+	laptop := node("laptop", "100.64.0.2", "fd7a:115c:a1e0::2", users[0], nil)
+	laptop.ID = 2
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{server, laptop})
+	require.NoError(t, err)
+
+	require.True(t, pm.NodeCanUseDERPRegion(server, 999))
+	require.False(t, pm.NodeCanUseDERPRegion(laptop, 999))
+	require.True(t, pm.NodeCanUseDERPRegion(laptop, 1), "regions which are not listed are open")
+
+	_, err = NewPolicyManager([]byte(`{"derpRegions": {"0": ["ops@"]}}`), users, nil)
+	require.ErrorContains(t, err, "must be positive")
+
+	_, err = NewPolicyManager([]byte(`{"derpRegions": {"999": ["autogroup:internet"]}}`), users, nil)
+	require.ErrorContains(t, err, "not supported in derpRegions")
+}
+
+func TestPolicyManagerOIDCGroups(t *testing.T) {
+	users := types.Users{
+		// alice is a member of the group provisioned via SCIM.
+		{Model: gorm.Model{ID: 1}, Name: "alice", Email: "alice@headscale.net", SCIMGroups: []string{"platform-team"}},
+		{Model: gorm.Model{ID: 2}, Name: "bob", Email: "bob@headscale.net"},
+	}
+
+	pol := `{
+	"acls": [
+		{
+			"action": "accept",
+			"src": ["group:oidc:platform-team"],
+			"dst": ["*:22"],
+		},
+	],
+}`
+
+	alice := node("alice", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil)
+	alice.ID = 1
+	bob := node("bob", "100.64.0.2", "fd7a:115c:a1e0::2", users[1], nil)
+	bob.ID = 2
+
+	pm, err := NewPolicyManager([]byte(pol), users, types.Nodes{alice, bob})
+	require.NoError(t, err)
+
+	filter, _ := pm.Filter()
+	require.Len(t, filter, 1)
+	require.Equal(t, []string{"100.64.0.1/32", "fd7a:115c:a1e0::1/128"}, filter[0].SrcIPs)
+
+	// bob joins the group at the OIDC provider and logs in again.
+	users[1].Groups = []string{"platform-team"}
+	changed, err := pm.SetUsers(users)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	filter, _ = pm.Filter()
+	require.Len(t, filter, 1)
+	require.ElementsMatch(t, []string{"100.64.0.1/32", "100.64.0.2/32", "fd7a:115c:a1e0::1/128", "fd7a:115c:a1e0::2/128"}, filter[0].SrcIPs)
+
+	_, err = NewPolicyManager([]byte(`{"groups": {"group:oidc:platform-team": ["alice@"]}}`), users, nil)
+	require.ErrorContains(t, err, "groups of the OIDC provider")
+}
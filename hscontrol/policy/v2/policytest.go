@@ -0,0 +1,373 @@
+package v2
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"go4.org/netipx"
+	"tailscale.com/tailcfg"
+)
+
+var ErrPolicyTestsFailed = errors.New("policy tests failed")
+
+// PolicyTest is an entry in the tests section of the policy. It asserts
+// that the source can reach the Accept destinations and cannot reach
+// the Deny destinations with the filter compiled from the policy.
+// https://tailscale.com/kb/1337/policy-syntax#tests
+type PolicyTest struct {
+	Source Alias
+	Proto  string
+	Accept []AliasWithPorts
+	Deny   []AliasWithPorts
+}
+
+type policyTestJSON struct {
+	Source AliasEnc         `json:"src"`
+	Proto  string           `json:"proto,omitempty"`
+	Accept []AliasWithPorts `json:"accept,omitempty"`
+	Deny   []AliasWithPorts `json:"deny,omitempty"`
+}
+
+func (pt *PolicyTest) UnmarshalJSON(b []byte) error {
+	var raw policyTestJSON
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	if raw.Source.Alias == nil {
+		return errors.New("test must have a src")
+	}
+
+	*pt = PolicyTest{
+		Source: raw.Source.Alias,
+		Proto:  raw.Proto,
+		Accept: raw.Accept,
+		Deny:   raw.Deny,
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the PolicyTest to JSON.
+func (pt PolicyTest) MarshalJSON() ([]byte, error) {
+	src, err := AliasWithPorts{Alias: pt.Source}.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(struct {
+		Source json.RawMessage  `json:"src"`
+		Proto  string           `json:"proto,omitempty"`
+		Accept []AliasWithPorts `json:"accept,omitempty"`
+		Deny   []AliasWithPorts `json:"deny,omitempty"`
+	}{
+		Source: src,
+		Proto:  pt.Proto,
+		Accept: pt.Accept,
+		Deny:   pt.Deny,
+	})
+}
+
+func (pt PolicyTest) destinations() []Alias {
+	var ret []Alias
+	for _, dst := range append(slices.Clone(pt.Accept), pt.Deny...) {
+		ret = append(ret, dst.Alias)
+	}
+
+	return ret
+}
+
+// string returns the AliasWithPorts in the format it was written in
+// the policy, for use in error messages.
+func (a AliasWithPorts) string() string {
+	b, err := a.MarshalJSON()
+	if err != nil {
+		return fmt.Sprintf("%v", a.Alias)
+	}
+
+	return strings.Trim(string(b), `"`)
+}
+
+// runTests evaluates the tests in the policy against the filter compiled
+// for the given users and nodes. All failing assertions are collected and
+// returned as one error, wrapping ErrPolicyTestsFailed.
+func (pol *Policy) runTests(users types.Users, nodes types.Nodes) error {
+	if pol == nil || len(pol.Tests) == 0 {
+		return nil
+	}
+
+	filter, err := pol.compileFilterRules(users, nodes)
+	if err != nil {
+		return fmt.Errorf("compiling filter rules: %w", err)
+	}
+
+	selfSets, err := resolveSelfSets(pol, users, nodes)
+	if err != nil {
+		return fmt.Errorf("resolving autogroup:self: %w", err)
+	}
+
+	// The rules are parsed once, not for every address checked.
+	parsedFilter := parseTestRules(filter)
+
+	// rulesFor returns the rules the node owning the destination
+	// would receive, including autogroup:self.
+	nodeRules := make(map[types.NodeID][]testRule)
+	rulesFor := func(dst netip.Addr) ([]testRule, error) {
+		owners := nodes.FilterByIP(dst)
+		if len(owners) == 0 || len(selfSets) == 0 {
+			return parsedFilter, nil
+		}
+
+		node := owners[0]
+		if rules, ok := nodeRules[node.ID]; ok {
+			return rules, nil
+		}
+
+		var self *netipx.IPSet
+		if set, ok := selfSets[node.UserID]; ok && node.InIPSet(set) {
+			self = set
+		}
+
+		selfRules, err := pol.compileFilterRulesForNode(users, nodes, self)
+		if err != nil {
+			return nil, err
+		}
+
+		rules := append(slices.Clone(parsedFilter), parseTestRules(selfRules)...)
+		nodeRules[node.ID] = rules
+
+		return rules, nil
+	}
+
+	var failures []string
+	for index, test := range pol.Tests {
+		src := AliasWithPorts{Alias: test.Source}.string()
+
+		// Parsed and validated when unmarshalling the policy.
+		protocols, needsWildcard, _ := parseProtocol(test.Proto)
+		if protocols == nil {
+			protocols = []int{protocolTCP}
+		}
+
+		srcIPs, _ := test.Source.Resolve(pol, users, nodes)
+		if srcIPs == nil || len(srcIPs.Prefixes()) == 0 {
+			failures = append(failures, fmt.Sprintf("test %d (src %q): src does not resolve to any IP", index, src))
+			continue
+		}
+
+		check := func(dst AliasWithPorts, wantAccept bool) error {
+			dstIPs, _ := dst.Alias.Resolve(pol, users, nodes)
+			if dstIPs == nil || len(dstIPs.Prefixes()) == 0 {
+				failures = append(failures, fmt.Sprintf("test %d (src %q): %q does not resolve to any IP", index, src, dst.string()))
+				return nil
+			}
+
+			for _, dstAddr := range testAddrs(dstIPs, nodes) {
+				rules, err := rulesFor(dstAddr)
+				if err != nil {
+					return err
+				}
+
+				for _, srcAddr := range testAddrs(srcIPs, nodes) {
+					accepted := filterAcceptedPorts(rules, srcAddr, dstAddr, protocols, needsWildcard)
+
+					for _, ports := range dst.Ports {
+						var port uint16
+						var failed bool
+						if wantAccept {
+							port, failed = firstPortNotIn(ports, accepted)
+						} else {
+							port, failed = firstPortIn(ports, accepted)
+						}
+						if !failed {
+							continue
+						}
+
+						want, got := "accept", "deny"
+						if !wantAccept {
+							want, got = got, want
+						}
+
+						failures = append(failures, fmt.Sprintf(
+							"test %d (src %q): %s %q: %s -> %s:%d, want %s, got %s",
+							index, src, want, dst.string(), srcAddr, dstAddr, port, want, got,
+						))
+					}
+				}
+			}
+
+			return nil
+		}
+
+		for _, dst := range test.Accept {
+			if err := check(dst, true); err != nil {
+				return err
+			}
+		}
+
+		for _, dst := range test.Deny {
+			if err := check(dst, false); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%w:\n%s", ErrPolicyTestsFailed, strings.Join(failures, "\n"))
+	}
+
+	return nil
+}
+
+// testAddrs returns the addresses of the set the tests are checked for:
+// every IP of the nodes in the set, and the first address of the ranges
+// without any node.
+func testAddrs(set *netipx.IPSet, nodes types.Nodes) []netip.Addr {
+	var addrs []netip.Addr
+	for _, node := range nodes {
+		for _, ip := range node.IPs() {
+			if set.Contains(ip) && !slices.Contains(addrs, ip) {
+				addrs = append(addrs, ip)
+			}
+		}
+	}
+
+	for _, rng := range set.Ranges() {
+		if !slices.ContainsFunc(addrs, rng.Contains) {
+			addrs = append(addrs, rng.From())
+		}
+	}
+
+	return addrs
+}
+
+// testRule is a filter rule with its addresses parsed, to check the
+// tests against.
+type testRule struct {
+	protocols []int
+	srcs      *netipx.IPSet
+	dsts      []testRuleDst
+}
+
+type testRuleDst struct {
+	ips   *netipx.IPSet
+	ports tailcfg.PortRange
+}
+
+// parseTestRules parses the addresses of the filter rules. Addresses
+// which cannot be parsed are left out, they never match.
+func parseTestRules(rules []tailcfg.FilterRule) []testRule {
+	parsed := make([]testRule, 0, len(rules))
+	for _, rule := range rules {
+		protocols := rule.IPProto
+		if len(protocols) == 0 {
+			protocols = []int{protocolTCP, protocolUDP, protocolICMP, protocolIPv6ICMP}
+		}
+
+		var srcs netipx.IPSetBuilder
+		for _, srcIP := range rule.SrcIPs {
+			if set, err := util.ParseIPSet(srcIP, nil); err == nil {
+				srcs.AddSet(set)
+			}
+		}
+		srcSet, _ := srcs.IPSet()
+
+		dsts := make([]testRuleDst, 0, len(rule.DstPorts))
+		for _, dstPort := range rule.DstPorts {
+			set, err := util.ParseIPSet(dstPort.IP, nil)
+			if err != nil {
+				continue
+			}
+
+			dsts = append(dsts, testRuleDst{ips: set, ports: dstPort.Ports})
+		}
+
+		parsed = append(parsed, testRule{
+			protocols: protocols,
+			srcs:      srcSet,
+			dsts:      dsts,
+		})
+	}
+
+	return parsed
+}
+
+// filterAcceptedPorts returns the port ranges on which any of the rules
+// accepts traffic from src to dst for one of the protocols. All ports are
+// returned if the protocols do not have ports.
+func filterAcceptedPorts(
+	rules []testRule,
+	src, dst netip.Addr,
+	protocols []int,
+	ignorePort bool,
+) []tailcfg.PortRange {
+	var ports []tailcfg.PortRange
+	for _, rule := range rules {
+		if !slices.ContainsFunc(protocols, func(proto int) bool {
+			return slices.Contains(rule.protocols, proto)
+		}) {
+			continue
+		}
+
+		if !rule.srcs.Contains(src) {
+			continue
+		}
+
+		for _, dstPort := range rule.dsts {
+			if !dstPort.ips.Contains(dst) {
+				continue
+			}
+
+			if ignorePort {
+				return []tailcfg.PortRange{tailcfg.PortRangeAny}
+			}
+
+			ports = append(ports, dstPort.ports)
+		}
+	}
+
+	return ports
+}
+
+// firstPortIn returns the first port of want which is in any of the
+// ranges, and false if there is none.
+func firstPortIn(want tailcfg.PortRange, ranges []tailcfg.PortRange) (uint16, bool) {
+	var port uint16
+	var found bool
+	for _, rng := range ranges {
+		if rng.Last < want.First || rng.First > want.Last {
+			continue
+		}
+
+		first := max(rng.First, want.First)
+		if !found || first < port {
+			port, found = first, true
+		}
+	}
+
+	return port, found
+}
+
+// firstPortNotIn returns the first port of want which is not in any of
+// the ranges, and false if there is none.
+func firstPortNotIn(want tailcfg.PortRange, ranges []tailcfg.PortRange) (uint16, bool) {
+	port := int(want.First)
+	for port <= int(want.Last) {
+		idx := slices.IndexFunc(ranges, func(rng tailcfg.PortRange) bool {
+			return rng.Contains(uint16(port))
+		})
+		if idx < 0 {
+			return uint16(port), true
+		}
+
+		port = int(ranges[idx].Last) + 1
+	}
+
+	return 0, false
+}
@@ -0,0 +1,253 @@
+package v2
+
+import (
+	"testing"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestRunTests(t *testing.T) {
+	users := types.Users{
+		{Model: gorm.Model{ID: 1}, Name: "oncall"},
+		{Model: gorm.Model{ID: 2}, Name: "intern"},
+	}
+
+	nodes := types.Nodes{
+		node("oncall-laptop", "100.64.0.1", "fd7a:115c:a1e0::1", users[0], nil),
+		node("intern-laptop", "100.64.0.2", "fd7a:115c:a1e0::2", users[1], nil),
+		node("prod", "100.64.0.10", "fd7a:115c:a1e0::10", users[0], nil),
+		node("intern-desktop", "100.64.0.3", "fd7a:115c:a1e0::3", users[1], nil),
+	}
+	nodes[0].ID = 1
+	nodes[1].ID = 2
+	nodes[2].ID = 3
+	nodes[3].ID = 4
+	nodes[2].ForcedTags = []string{"tag:prod"}
+
+	tests := []struct {
+		name    string
+		pol     string
+		wantErr string
+	}{
+		{
+			name: "no-tests",
+			pol:  `{}`,
+		},
+		{
+			name: "accept-and-deny-pass",
+			pol: `{
+	"groups": {"group:oncall": ["oncall@"]},
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["group:oncall"], "dst": ["tag:prod:22"]},
+	],
+	"tests": [
+		{
+			"src": "oncall@",
+			"accept": ["tag:prod:22"],
+			"deny": ["tag:prod:80"],
+		},
+		{
+			"src": "intern@",
+			"deny": ["tag:prod:22"],
+		},
+	],
+}`,
+		},
+		{
+			name: "accept-fails",
+			pol: `{
+	"groups": {"group:oncall": ["oncall@"]},
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["group:oncall"], "dst": ["tag:prod:80"]},
+	],
+	"tests": [
+		{
+			"src": "group:oncall",
+			"accept": ["tag:prod:22"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "group:oncall"): accept "tag:prod:22": 100.64.0.1 -> 100.64.0.10:22, want accept, got deny`,
+		},
+		{
+			name: "deny-fails",
+			pol: `{
+	"acls": [
+		{"action": "accept", "src": ["*"], "dst": ["*:*"]},
+	],
+	"tests": [
+		{
+			"src": "100.64.0.2",
+			"proto": "udp",
+			"deny": ["100.64.0.10:53"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "100.64.0.2/32"): deny "100.64.0.10/32:53": 100.64.0.2 -> 100.64.0.10:53, want deny, got accept`,
+		},
+		{
+			// The IPs of the intern nodes are merged into 100.64.0.2/31,
+			// every one of them is checked.
+			name: "every-ip-is-checked",
+			pol: `{
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.2"], "dst": ["tag:prod:22"]},
+	],
+	"tests": [
+		{
+			"src": "intern@",
+			"accept": ["tag:prod:22"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "intern@"): accept "tag:prod:22": 100.64.0.3 -> 100.64.0.10:22, want accept, got deny`,
+		},
+		{
+			name: "every-port-is-checked-accept",
+			pol: `{
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["tag:prod:20-22"]},
+	],
+	"tests": [
+		{
+			"src": "100.64.0.1",
+			"accept": ["tag:prod:20-23"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "100.64.0.1/32"): accept "tag:prod:20-23": 100.64.0.1 -> 100.64.0.10:23, want accept, got deny`,
+		},
+		{
+			name: "every-port-is-checked-deny",
+			pol: `{
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["tag:prod:22"]},
+	],
+	"tests": [
+		{
+			"src": "100.64.0.1",
+			"deny": ["tag:prod:20-30"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "100.64.0.1/32"): deny "tag:prod:20-30": 100.64.0.1 -> 100.64.0.10:22, want deny, got accept`,
+		},
+		{
+			name: "port-ranges-are-combined",
+			pol: `{
+	"tagOwners": {"tag:prod": ["oncall@"]},
+	"acls": [
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["tag:prod:20-22"]},
+		{"action": "accept", "src": ["100.64.0.1"], "dst": ["tag:prod:23-30"]},
+	],
+	"tests": [
+		{
+			"src": "100.64.0.1",
+			"accept": ["tag:prod:20-30"],
+			"deny": ["tag:prod:31-65535"],
+		},
+	],
+}`,
+		},
+		{
+			name: "proto-is-respected",
+			pol: `{
+	"acls": [
+		{"action": "accept", "proto": "udp", "src": ["100.64.0.1"], "dst": ["100.64.0.10:53"]},
+	],
+	"tests": [
+		{
+			"src": "100.64.0.1",
+			"proto": "udp",
+			"accept": ["100.64.0.10:53"],
+		},
+		{
+			"src": "100.64.0.1",
+			"deny": ["100.64.0.10:53"],
+		},
+	],
+}`,
+		},
+		{
+			name: "autogroup-self",
+			pol: `{
+	"acls": [
+		{"action": "accept", "src": ["autogroup:member"], "dst": ["autogroup:self:*"]},
+	],
+	"tests": [
+		{
+			"src": "oncall@",
+			"accept": ["oncall@:22"],
+			"deny": ["intern@:22"],
+		},
+	],
+}`,
+		},
+		{
+			name: "src-without-ips",
+			pol: `{
+	"groups": {"group:empty": []},
+	"tests": [
+		{
+			"src": "group:empty",
+			"deny": ["100.64.0.10:22"],
+		},
+	],
+}`,
+			wantErr: `test 0 (src "group:empty"): src does not resolve to any IP`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pol, err := unmarshalPolicy([]byte(tt.pol))
+			require.NoError(t, err)
+
+			err = pol.runTests(users, nodes)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.ErrorIs(t, err, ErrPolicyTestsFailed)
+			require.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestPolicyTestValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		pol     string
+		wantErr string
+	}{
+		{
+			name: "missing-src",
+			pol: `{
+	"tests": [{"accept": ["100.64.0.1:22"]}],
+}`,
+			wantErr: "test must have a src",
+		},
+		{
+			name: "undefined-group",
+			pol: `{
+	"tests": [{"src": "group:nope", "accept": ["100.64.0.2:22"]}],
+}`,
+			wantErr: `Group "group:nope" is not defined in the Policy`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := unmarshalPolicy([]byte(tt.pol))
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/netip"
 	"strings"
 
+	"maps"
 	"slices"
 
 	"github.com/juanfont/headscale/hscontrol/types"
@@ -17,6 +18,7 @@ import (
 	"go4.org/netipx"
 	"tailscale.com/net/tsaddr"
 	"tailscale.com/tailcfg"
+	"tailscale.com/types/appctype"
 	"tailscale.com/types/ptr"
 	"tailscale.com/util/multierr"
 )
@@ -204,6 +206,19 @@ func (u Username) Resolve(_ *Policy, users types.Users, nodes types.Nodes) (*net
 // Group is a special string which is always prefixed with `group:`
 type Group string
 
+// oidcGroupPrefix is the prefix of the groups whose members are the users
+// in the group of the same name at the OIDC provider, e.g.
+// `group:oidc:platform-team`. They can't be defined in the policy.
+const oidcGroupPrefix = "group:oidc:"
+
+// oidcGroup returns the name of the group at the OIDC provider if the group
+// is an OIDC group.
+func (g Group) oidcGroup() (string, bool) {
+	name, ok := strings.CutPrefix(string(g), oidcGroupPrefix)
+
+	return name, ok && name != ""
+}
+
 func (g Group) Validate() error {
 	if isGroup(string(g)) {
 		return nil
@@ -250,6 +265,27 @@ func (g Group) Resolve(p *Policy, users types.Users, nodes types.Nodes) (*netipx
 	var ips netipx.IPSetBuilder
 	var errs []error
 
+	if name, ok := g.oidcGroup(); ok {
+		members := make(map[uint]bool)
+		for _, user := range users {
+			if slices.Contains(user.Groups, name) || slices.Contains(user.SCIMGroups, name) {
+				members[user.ID] = true
+			}
+		}
+
+		for _, node := range nodes {
+			if node.IsTagged() {
+				continue
+			}
+
+			if members[node.User.ID] {
+				node.AppendToIPSet(&ips)
+			}
+		}
+
+		return buildIPSetMultiErr(&ips, errs)
+	}
+
 	for _, user := range p.Groups[g] {
 		uips, err := user.Resolve(nil, users, nodes)
 		if err != nil {
@@ -467,7 +503,8 @@ const (
 	AutoGroupNonRoot  AutoGroup = "autogroup:nonroot"
 	AutoGroupTagged   AutoGroup = "autogroup:tagged"
 
-	// These are not yet implemented.
+	// AutoGroupSelf can only be resolved from the perspective of a
+	// given node, see resolveSelfSets.
 	AutoGroupSelf AutoGroup = "autogroup:self"
 )
 
@@ -476,6 +513,7 @@ var autogroups = []AutoGroup{
 	AutoGroupMember,
 	AutoGroupNonRoot,
 	AutoGroupTagged,
+	AutoGroupSelf,
 }
 
 func (ag AutoGroup) Validate() error {
@@ -566,6 +604,11 @@ func (ag AutoGroup) Resolve(p *Policy, users types.Users, nodes types.Nodes) (*n
 
 		return build.IPSet()
 
+	case AutoGroupSelf:
+		// autogroup:self depends on which node is asking and
+		// cannot be resolved for the tailnet as a whole.
+		return nil, ErrAutogroupSelfRequiresNode
+
 	default:
 		return nil, fmt.Errorf("unknown autogroup %q", ag)
 	}
@@ -803,6 +846,19 @@ type AutoApprover interface {
 
 type AutoApprovers []AutoApprover
 
+// aliases returns the auto approvers as aliases, auto approvers are
+// groups and tags.
+func (aa AutoApprovers) aliases() []Alias {
+	aliases := make([]Alias, 0, len(aa))
+	for _, approver := range aa {
+		if alias, ok := approver.(Alias); ok {
+			aliases = append(aliases, alias)
+		}
+	}
+
+	return aliases
+}
+
 func (aa *AutoApprovers) UnmarshalJSON(b []byte) error {
 	var autoApprovers []AutoApproverEnc
 	err := json.Unmarshal(b, &autoApprovers)
@@ -955,6 +1011,11 @@ func (g Groups) Contains(group *Group) error {
 		return nil
 	}
 
+	// OIDC groups are defined by the OIDC provider.
+	if _, ok := group.oidcGroup(); ok {
+		return nil
+	}
+
 	for defined := range map[Group]Usernames(g) {
 		if defined == *group {
 			return nil
@@ -980,6 +1041,9 @@ func (g *Groups) UnmarshalJSON(b []byte) error {
 		if err := group.Validate(); err != nil {
 			return err
 		}
+		if strings.HasPrefix(key, oidcGroupPrefix) {
+			return fmt.Errorf("Group %q can't be defined, groups starting with %q are the groups of the OIDC provider", group, oidcGroupPrefix)
+		}
 
 		var usernames Usernames
 
@@ -1126,6 +1190,217 @@ func resolveTagOwners(p *Policy, users types.Users, nodes types.Nodes) (map[Tag]
 	return ret, nil
 }
 
+// resolveSelfSets resolves autogroup:self for every user, mapping the
+// ID of the user to the IPs of all the untagged devices it owns.
+// The set is empty for policies not using autogroup:self.
+func resolveSelfSets(p *Policy, users types.Users, nodes types.Nodes) (map[uint]*netipx.IPSet, error) {
+	ret := make(map[uint]*netipx.IPSet)
+
+	if p == nil || !p.usesAutogroupSelf() {
+		return ret, nil
+	}
+
+	members, err := AutoGroupMember.Resolve(p, users, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	builders := make(map[uint]*netipx.IPSetBuilder)
+	for _, node := range nodes {
+		if !node.InIPSet(members) {
+			continue
+		}
+
+		if _, ok := builders[node.UserID]; !ok {
+			builders[node.UserID] = &netipx.IPSetBuilder{}
+		}
+		node.AppendToIPSet(builders[node.UserID])
+	}
+
+	for userID, build := range builders {
+		ips, err := build.IPSet()
+		if err != nil {
+			return nil, err
+		}
+		ret[userID] = ips
+	}
+
+	return ret, nil
+}
+
+// appConnectorsCapability is the node capability carrying the app
+// connector configuration to the connector nodes, each value is an
+// appctype.AppConnectorAttr.
+const appConnectorsCapability tailcfg.NodeCapability = "tailscale.com/app-connectors"
+
+// nodeAttr is a NodeAttrGrant with the targets resolved.
+type nodeAttr struct {
+	targets *netipx.IPSet
+	attrs   []string
+	app     tailcfg.NodeCapMap
+
+	// appConnectors holds the resolved app connector values of app,
+	// in the same order.
+	appConnectors []appConnector
+}
+
+// appConnector is an appctype.AppConnectorAttr with the connectors
+// resolved.
+type appConnector struct {
+	// connectors is nil if the attribute is served by any connector.
+	connectors *netipx.IPSet
+	routes     []netip.Prefix
+}
+
+// serves reports if the app connector attribute is served by the node.
+func (ac appConnector) serves(node *types.Node) bool {
+	return ac.connectors == nil || node.InIPSet(ac.connectors)
+}
+
+// resolveAppConnector resolves the connector tags of an app connector
+// value of the tailscale.com/app-connectors capability.
+func resolveAppConnector(p *Policy, users types.Users, nodes types.Nodes, value tailcfg.RawMessage) (appConnector, error) {
+	var attr appctype.AppConnectorAttr
+	if err := json.Unmarshal([]byte(value), &attr); err != nil {
+		return appConnector{}, err
+	}
+
+	if slices.Contains(attr.Connectors, "*") {
+		return appConnector{routes: attr.Routes}, nil
+	}
+
+	var ips netipx.IPSetBuilder
+	for _, connector := range attr.Connectors {
+		// If it does not resolve, that means there is no connector with the tag.
+		resolved, _ := Tag(connector).Resolve(p, users, nodes)
+		ips.AddSet(resolved)
+	}
+
+	connectors, err := ips.IPSet()
+	if err != nil {
+		return appConnector{}, err
+	}
+
+	return appConnector{connectors: connectors, routes: attr.Routes}, nil
+}
+
+// appConnectorCanApproveRoute reports if the node is an app connector
+// serving an app connector attribute with routes covering the route.
+// Routes discovered by the connector from the domains of the attribute
+// are only approved within these routes.
+func appConnectorCanApproveRoute(nodeAttrs []nodeAttr, node *types.Node, route netip.Prefix) bool {
+	if node.Hostinfo == nil || !node.Hostinfo.AppConnector.EqualBool(true) {
+		return false
+	}
+
+	for _, na := range nodeAttrs {
+		if !node.InIPSet(na.targets) {
+			continue
+		}
+
+		for _, ac := range na.appConnectors {
+			if !ac.serves(node) {
+				continue
+			}
+
+			for _, prefix := range ac.routes {
+				if prefix.Bits() <= route.Bits() && prefix.Overlaps(route) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// resolveNodeAttrs resolves the targets of the NodeAttrs in the policy,
+// keeping the order of the policy.
+// It is intended for internal use in a PolicyManager.
+func resolveNodeAttrs(p *Policy, users types.Users, nodes types.Nodes) ([]nodeAttr, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	ret := make([]nodeAttr, 0, len(p.NodeAttrs))
+
+	for _, na := range p.NodeAttrs {
+		var ips netipx.IPSetBuilder
+
+		for _, target := range na.Targets {
+			// If it does not resolve, that means the target is not associated with any IP addresses.
+			resolved, _ := target.Resolve(p, users, nodes)
+			ips.AddSet(resolved)
+		}
+
+		ipSet, err := ips.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		var appConnectors []appConnector
+		for _, value := range na.App[appConnectorsCapability] {
+			ac, err := resolveAppConnector(p, users, nodes, value)
+			if err != nil {
+				return nil, fmt.Errorf("resolving app connector: %w", err)
+			}
+			appConnectors = append(appConnectors, ac)
+		}
+
+		ret = append(ret, nodeAttr{
+			targets:       ipSet,
+			attrs:         na.Attrs,
+			app:           na.App,
+			appConnectors: appConnectors,
+		})
+	}
+
+	return ret, nil
+}
+
+// applyNodeAttrs applies the resolved NodeAttrs matching the node to
+// the capability map, in policy order. An attribute prefixed with "-"
+// removes the capability. App connector values are only applied to the
+// connectors serving them.
+func applyNodeAttrs(nodeAttrs []nodeAttr, node *types.Node, capMap tailcfg.NodeCapMap) tailcfg.NodeCapMap {
+	for _, na := range nodeAttrs {
+		if !node.InIPSet(na.targets) {
+			continue
+		}
+
+		if capMap == nil {
+			capMap = make(tailcfg.NodeCapMap)
+		}
+
+		for _, attr := range na.attrs {
+			if remove, ok := strings.CutPrefix(attr, "-"); ok {
+				delete(capMap, tailcfg.NodeCapability(remove))
+				continue
+			}
+
+			if _, ok := capMap[tailcfg.NodeCapability(attr)]; !ok {
+				capMap[tailcfg.NodeCapability(attr)] = []tailcfg.RawMessage{}
+			}
+		}
+
+		for capability, values := range na.app {
+			if capability == appConnectorsCapability {
+				for i, value := range values {
+					if na.appConnectors[i].serves(node) {
+						capMap[capability] = append(capMap[capability], value)
+					}
+				}
+
+				continue
+			}
+
+			capMap[capability] = append(capMap[capability], values...)
+		}
+	}
+
+	return capMap
+}
+
 type AutoApproverPolicy struct {
 	Routes   map[netip.Prefix]AutoApprovers `json:"routes,omitempty"`
 	ExitNode AutoApprovers                  `json:"exitNode,omitempty"`
@@ -1155,6 +1430,221 @@ func (ap AutoApproverPolicy) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&obj)
 }
 
+// Regions assigns nodes to named regions. Nodes prefer the subnet routers
+// of their own region.
+type Regions map[string]Aliases
+
+// resolveRegions resolves the members of the Regions to a map of region
+// names to IPSets.
+// It is intended for internal use in a PolicyManager.
+func resolveRegions(p *Policy, users types.Users, nodes types.Nodes) (map[string]*netipx.IPSet, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	ret := make(map[string]*netipx.IPSet, len(p.Regions))
+
+	for region, members := range p.Regions {
+		var ips netipx.IPSetBuilder
+
+		for _, member := range members {
+			// If it does not resolve, that means the member is not associated with any IP addresses.
+			resolved, _ := member.Resolve(p, users, nodes)
+			ips.AddSet(resolved)
+		}
+
+		ipSet, err := ips.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		ret[region] = ipSet
+	}
+
+	return ret, nil
+}
+
+// nodeRegion returns the first region, by name, the node is a member of,
+// or an empty string.
+func nodeRegion(regions map[string]*netipx.IPSet, node *types.Node) string {
+	names := slices.Sorted(maps.Keys(regions))
+	for _, name := range names {
+		if node.InIPSet(regions[name]) {
+			return name
+		}
+	}
+
+	return ""
+}
+
+// DNSProfile changes the DNS configuration of the nodes matching Targets.
+// The first profile matching a node, in policy order, is applied.
+type DNSProfile struct {
+	Name    string  `json:"name"`
+	Targets Aliases `json:"target"`
+
+	// Nameservers replace the global nameservers, Split the split DNS
+	// nameservers of the given domains.
+	Nameservers []string            `json:"nameservers,omitempty"`
+	Split       map[string][]string `json:"split,omitempty"`
+
+	// OverrideLocalDNS replaces dns.override_local_dns of the configuration.
+	OverrideLocalDNS *bool `json:"overrideLocalDNS,omitempty"`
+}
+
+// dnsProfile is a DNSProfile with the targets resolved.
+type dnsProfile struct {
+	targets *netipx.IPSet
+	profile types.DNSProfile
+}
+
+// resolveDNSProfiles resolves the targets of the DNSProfiles in the policy,
+// keeping the order of the policy.
+// It is intended for internal use in a PolicyManager.
+func resolveDNSProfiles(p *Policy, users types.Users, nodes types.Nodes) ([]dnsProfile, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	ret := make([]dnsProfile, 0, len(p.DNSProfiles))
+
+	for _, profile := range p.DNSProfiles {
+		var ips netipx.IPSetBuilder
+
+		for _, target := range profile.Targets {
+			// If it does not resolve, that means the target is not associated with any IP addresses.
+			resolved, _ := target.Resolve(p, users, nodes)
+			ips.AddSet(resolved)
+		}
+
+		ipSet, err := ips.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		ret = append(ret, dnsProfile{
+			targets: ipSet,
+			profile: types.DNSProfile{
+				Name:             profile.Name,
+				Nameservers:      profile.Nameservers,
+				Split:            profile.Split,
+				OverrideLocalDNS: profile.OverrideLocalDNS,
+			},
+		})
+	}
+
+	return ret, nil
+}
+
+// nodeDNSProfile returns the first DNS profile the node is a target of,
+// or nil.
+func nodeDNSProfile(profiles []dnsProfile, node *types.Node) *types.DNSProfile {
+	for _, profile := range profiles {
+		if node.InIPSet(profile.targets) {
+			return &profile.profile
+		}
+	}
+
+	return nil
+}
+
+// DERPRegions restricts the DERP regions, by region ID, to the nodes
+// matching the aliases. Regions which are not listed can be used by all
+// nodes.
+type DERPRegions map[int]Aliases
+
+// resolveDERPRegions resolves the members of the DERPRegions to a map of
+// region IDs to IPSets.
+// It is intended for internal use in a PolicyManager.
+func resolveDERPRegions(p *Policy, users types.Users, nodes types.Nodes) (map[int]*netipx.IPSet, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	ret := make(map[int]*netipx.IPSet, len(p.DERPRegions))
+
+	for regionID, members := range p.DERPRegions {
+		var ips netipx.IPSetBuilder
+
+		for _, member := range members {
+			// If it does not resolve, that means the member is not associated with any IP addresses.
+			resolved, _ := member.Resolve(p, users, nodes)
+			ips.AddSet(resolved)
+		}
+
+		ipSet, err := ips.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		ret[regionID] = ipSet
+	}
+
+	return ret, nil
+}
+
+// RoutePriorities lists per route the subnet routers in order of
+// preference to be the primary router of the route.
+type RoutePriorities map[netip.Prefix]AutoApprovers
+
+// resolveRoutePriorities resolves the routers of the RoutePriorities to
+// a map of netip.Prefix to the IPSets of the routers, keeping their order.
+// It is intended for internal use in a PolicyManager.
+func resolveRoutePriorities(p *Policy, users types.Users, nodes types.Nodes) (map[netip.Prefix][]*netipx.IPSet, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	ret := make(map[netip.Prefix][]*netipx.IPSet, len(p.RoutePriorities))
+
+	for prefix, routers := range p.RoutePriorities {
+		for _, router := range routers {
+			alias, ok := router.(Alias)
+			if !ok {
+				// Should never happen
+				return nil, fmt.Errorf("route priority %v is not an Alias", router)
+			}
+
+			// If it does not resolve, that means the router is not associated with any IP addresses.
+			ips, _ := alias.Resolve(p, users, nodes)
+			if ips == nil {
+				ips = &netipx.IPSet{}
+			}
+
+			ret[prefix] = append(ret[prefix], ips)
+		}
+	}
+
+	return ret, nil
+}
+
+// routePriority returns the priority of the node to be the primary router
+// of the route according to the resolved RoutePriorities, the first router
+// listed has the highest priority. The most specific route containing the
+// given route is used. It returns 0 if the node is not listed.
+func routePriority(priorities map[netip.Prefix][]*netipx.IPSet, node *types.Node, route netip.Prefix) int {
+	var (
+		match   netip.Prefix
+		routers []*netipx.IPSet
+	)
+
+	for prefix, ips := range priorities {
+		if prefix.Bits() <= route.Bits() && prefix.Contains(route.Addr()) &&
+			(!match.IsValid() || prefix.Bits() > match.Bits()) {
+			match = prefix
+			routers = ips
+		}
+	}
+
+	for i, ips := range routers {
+		if node.InIPSet(ips) {
+			return len(routers) - i
+		}
+	}
+
+	return 0
+}
+
 // resolveAutoApprovers resolves the AutoApprovers to a map of netip.Prefix to netipx.IPSet.
 // The resulting map can be used to quickly look up if a node can self-approve a route.
 // It is intended for internal use in a PolicyManager.
@@ -1223,6 +1713,143 @@ type ACL struct {
 	Destinations []AliasWithPorts `json:"dst"`
 }
 
+// Grant gives the sources network access (IP) and/or application
+// capabilities (App) to the destinations.
+// https://tailscale.com/kb/1324/grants
+type Grant struct {
+	Sources      Aliases            `json:"src"`
+	Destinations Aliases            `json:"dst"`
+	IP           []ProtocolPort     `json:"ip,omitempty"`
+	App          tailcfg.PeerCapMap `json:"app,omitempty"`
+}
+
+func (g *Grant) UnmarshalJSON(b []byte) error {
+	type grantAlias Grant
+	var grant grantAlias
+	if err := json.Unmarshal(b, &grant); err != nil {
+		return err
+	}
+
+	// Compact the capability values, they are sent to the clients
+	// as is and should not carry the formatting of the policy.
+	for capability, values := range grant.App {
+		for i, value := range values {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, []byte(value)); err != nil {
+				return fmt.Errorf("parsing grant app %q: %w", capability, err)
+			}
+			values[i] = tailcfg.RawMessage(buf.String())
+		}
+	}
+
+	*g = Grant(grant)
+
+	return nil
+}
+
+// ProtocolPort is an entry in the ip field of a Grant, it is either
+// "*", a list of ports like "22,80-90" or "proto:ports" like "tcp:443".
+type ProtocolPort struct {
+	Protocol string
+	Ports    []tailcfg.PortRange
+}
+
+func (pp *ProtocolPort) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	return pp.parseString(s)
+}
+
+func (pp *ProtocolPort) parseString(s string) error {
+	if s == "*" {
+		pp.Protocol = ""
+		pp.Ports = []tailcfg.PortRange{tailcfg.PortRangeAny}
+
+		return nil
+	}
+
+	ports := s
+	if proto, rest, ok := strings.Cut(s, ":"); ok {
+		pp.Protocol = proto
+		ports = rest
+	}
+
+	_, needsWildcard, err := parseProtocol(pp.Protocol)
+	if err != nil {
+		return fmt.Errorf("parsing grant ip %q: %w", s, err)
+	}
+
+	pp.Ports, err = parsePortRange(ports)
+	if err != nil {
+		return fmt.Errorf("parsing grant ip %q: %w", s, err)
+	}
+
+	if needsWildcard && !slices.Equal(pp.Ports, []tailcfg.PortRange{tailcfg.PortRangeAny}) {
+		return fmt.Errorf("parsing grant ip %q: protocol %q does not support specifying ports, use \"*\"", s, pp.Protocol)
+	}
+
+	return nil
+}
+
+// MarshalJSON marshals the ProtocolPort to JSON.
+func (pp ProtocolPort) MarshalJSON() ([]byte, error) {
+	var ports []string
+	for _, port := range pp.Ports {
+		switch {
+		case port == tailcfg.PortRangeAny:
+			ports = append(ports, "*")
+		case port.First == port.Last:
+			ports = append(ports, fmt.Sprintf("%d", port.First))
+		default:
+			ports = append(ports, fmt.Sprintf("%d-%d", port.First, port.Last))
+		}
+	}
+
+	if pp.Protocol == "" {
+		return json.Marshal(strings.Join(ports, ","))
+	}
+
+	return json.Marshal(fmt.Sprintf("%s:%s", pp.Protocol, strings.Join(ports, ",")))
+}
+
+// NodeAttrGrant adds attributes (Attrs) and capabilities (App) to the
+// nodes matching Targets. An attribute prefixed with "-" is removed
+// from the nodes instead, allowing to turn off the capabilities that
+// are set by default.
+// https://tailscale.com/kb/1337/policy-syntax#nodeattrs
+type NodeAttrGrant struct {
+	Targets Aliases            `json:"target"`
+	Attrs   []string           `json:"attr,omitempty"`
+	App     tailcfg.NodeCapMap `json:"app,omitempty"`
+}
+
+func (na *NodeAttrGrant) UnmarshalJSON(b []byte) error {
+	type nodeAttrAlias NodeAttrGrant
+	var nodeAttr nodeAttrAlias
+	if err := json.Unmarshal(b, &nodeAttr); err != nil {
+		return err
+	}
+
+	// Compact the capability values, they are sent to the clients
+	// as is and should not carry the formatting of the policy.
+	for capability, values := range nodeAttr.App {
+		for i, value := range values {
+			var buf bytes.Buffer
+			if err := json.Compact(&buf, []byte(value)); err != nil {
+				return fmt.Errorf("parsing nodeAttrs app %q: %w", capability, err)
+			}
+			values[i] = tailcfg.RawMessage(buf.String())
+		}
+	}
+
+	*na = NodeAttrGrant(nodeAttr)
+
+	return nil
+}
+
 // Policy represents a Tailscale Network Policy.
 // TODO(kradalby):
 // Add validation method checking:
@@ -1236,12 +1863,51 @@ type Policy struct {
 	// callers using it should panic if not
 	validated bool `json:"-"`
 
-	Groups        Groups             `json:"groups,omitempty"`
-	Hosts         Hosts              `json:"hosts,omitempty"`
-	TagOwners     TagOwners          `json:"tagOwners,omitempty"`
-	ACLs          []ACL              `json:"acls,omitempty"`
-	AutoApprovers AutoApproverPolicy `json:"autoApprovers,omitempty"`
-	SSHs          []SSH              `json:"ssh,omitempty"`
+	Groups          Groups             `json:"groups,omitempty"`
+	Hosts           Hosts              `json:"hosts,omitempty"`
+	TagOwners       TagOwners          `json:"tagOwners,omitempty"`
+	ACLs            []ACL              `json:"acls,omitempty"`
+	Grants          []Grant            `json:"grants,omitempty"`
+	NodeAttrs       []NodeAttrGrant    `json:"nodeAttrs,omitempty"`
+	AutoApprovers   AutoApproverPolicy `json:"autoApprovers,omitempty"`
+	RoutePriorities RoutePriorities    `json:"routePriorities,omitempty"`
+	Regions         Regions            `json:"regions,omitempty"`
+	DNSProfiles     []DNSProfile       `json:"dnsProfiles,omitempty"`
+	DERPRegions     DERPRegions        `json:"derpRegions,omitempty"`
+	SSHs            []SSH              `json:"ssh,omitempty"`
+	Tests           []PolicyTest       `json:"tests,omitempty"`
+}
+
+// usesAutogroupSelf reports if any ACL or SSH destination in the
+// policy is autogroup:self, meaning that the filter has to be
+// compiled per node.
+func (p *Policy) usesAutogroupSelf() bool {
+	if p == nil {
+		return false
+	}
+
+	for _, acl := range p.ACLs {
+		for _, dst := range acl.Destinations {
+			if isAutogroupSelf(dst.Alias) {
+				return true
+			}
+		}
+	}
+
+	for _, ssh := range p.SSHs {
+		for _, dst := range ssh.Destinations {
+			if isAutogroupSelf(dst) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isAutogroupSelf(alias Alias) bool {
+	ag, ok := alias.(*AutoGroup)
+	return ok && ag.Is(AutoGroupSelf)
 }
 
 // MarshalJSON is deliberately not implemented for Policy.
@@ -1250,11 +1916,11 @@ type Policy struct {
 var (
 	// TODO(kradalby): Add these checks for tagOwners and autoApprovers
 	autogroupForSrc       = []AutoGroup{AutoGroupMember, AutoGroupTagged}
-	autogroupForDst       = []AutoGroup{AutoGroupInternet, AutoGroupMember, AutoGroupTagged}
+	autogroupForDst       = []AutoGroup{AutoGroupInternet, AutoGroupMember, AutoGroupTagged, AutoGroupSelf}
 	autogroupForSSHSrc    = []AutoGroup{AutoGroupMember, AutoGroupTagged}
-	autogroupForSSHDst    = []AutoGroup{AutoGroupMember, AutoGroupTagged}
+	autogroupForSSHDst    = []AutoGroup{AutoGroupMember, AutoGroupTagged, AutoGroupSelf}
 	autogroupForSSHUser   = []AutoGroup{AutoGroupNonRoot}
-	autogroupNotSupported = []AutoGroup{AutoGroupSelf}
+	autogroupNotSupported = []AutoGroup{}
 )
 
 func validateAutogroupSupported(ag *AutoGroup) error {
@@ -1341,6 +2007,64 @@ func validateAutogroupForSSHUser(user *AutoGroup) error {
 	return nil
 }
 
+func validateAutogroupForGrantDst(dst *AutoGroup) error {
+	if dst.Is(AutoGroupSelf) {
+		return errors.New(`"autogroup:self" is not supported in grant destinations`)
+	}
+
+	return validateAutogroupForDst(dst)
+}
+
+// validateAliases reports the hosts, groups and tags referenced by the
+// aliases which are not defined in the policy, and the autogroups which
+// are not supported in the given context, for example "regions".
+func (p *Policy) validateAliases(aliases []Alias, context string, disallowedAutogroups ...AutoGroup) []error {
+	var errs []error
+	for _, alias := range aliases {
+		switch alias := alias.(type) {
+		case *Host:
+			if !p.Hosts.exist(*alias) {
+				errs = append(errs, fmt.Errorf(`Host %q is not defined in the Policy, please define or remove the reference to it`, *alias))
+			}
+		case *AutoGroup:
+			if slices.Contains(disallowedAutogroups, *alias) {
+				errs = append(errs, fmt.Errorf("autogroup %q is not supported in %s", *alias, context))
+			}
+		case *Group:
+			if err := p.Groups.Contains(alias); err != nil {
+				errs = append(errs, err)
+			}
+		case *Tag:
+			if err := p.TagOwners.Contains(alias); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateAutogroups runs the validations on the autogroups of the aliases,
+// and reports the first error of each autogroup.
+func validateAutogroups(aliases []Alias, validations ...func(*AutoGroup) error) []error {
+	var errs []error
+	for _, alias := range aliases {
+		ag, ok := alias.(*AutoGroup)
+		if !ok {
+			continue
+		}
+
+		for _, validate := range validations {
+			if err := validate(ag); err != nil {
+				errs = append(errs, err)
+				break
+			}
+		}
+	}
+
+	return errs
+}
+
 // validate reports if there are any errors in a policy after
 // the unmarshaling process.
 // It runs through all rules and checks if there are any inconsistencies
@@ -1420,6 +2144,78 @@ func (p *Policy) validate() error {
 		}
 	}
 
+	for _, grant := range p.Grants {
+		if len(grant.IP) == 0 && len(grant.App) == 0 {
+			errs = append(errs, errors.New("grant must have at least one of ip or app"))
+		}
+
+		errs = append(errs, p.validateAliases(grant.Sources, "grant sources")...)
+		errs = append(errs, validateAutogroups(grant.Sources, validateAutogroupForSrc)...)
+
+		errs = append(errs, p.validateAliases(grant.Destinations, "grant destinations")...)
+		errs = append(errs, validateAutogroups(grant.Destinations, validateAutogroupForGrantDst)...)
+	}
+
+	for _, nodeAttr := range p.NodeAttrs {
+		if len(nodeAttr.Attrs) == 0 && len(nodeAttr.App) == 0 {
+			errs = append(errs, errors.New("nodeAttrs must have at least one of attr or app"))
+		}
+
+		for _, attr := range nodeAttr.Attrs {
+			if strings.TrimPrefix(attr, "-") == "" {
+				errs = append(errs, fmt.Errorf("nodeAttrs attr %q is not valid", attr))
+			}
+		}
+
+		for _, value := range nodeAttr.App[appConnectorsCapability] {
+			var attr appctype.AppConnectorAttr
+			if err := json.Unmarshal([]byte(value), &attr); err != nil {
+				errs = append(errs, fmt.Errorf("nodeAttrs app connector %s is not valid: %w", value, err))
+				continue
+			}
+
+			if len(attr.Domains) == 0 {
+				errs = append(errs, fmt.Errorf("nodeAttrs app connector %q must have at least one domain", attr.Name))
+			}
+
+			if len(attr.Connectors) == 0 {
+				errs = append(errs, fmt.Errorf("nodeAttrs app connector %q must have at least one connector", attr.Name))
+			}
+
+			for _, connector := range attr.Connectors {
+				if connector == "*" {
+					continue
+				}
+
+				tag := Tag(connector)
+				if err := tag.Validate(); err != nil {
+					errs = append(errs, fmt.Errorf("nodeAttrs app connector %q: connector must be \"*\" or a tag: %w", attr.Name, err))
+					continue
+				}
+
+				if err := p.TagOwners.Contains(&tag); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			for _, route := range attr.Routes {
+				if tsaddr.IsExitRoute(route) {
+					errs = append(errs, fmt.Errorf("nodeAttrs app connector %q: exit route %s is not allowed", attr.Name, route))
+				}
+			}
+		}
+
+		errs = append(errs, p.validateAliases(nodeAttr.Targets, "nodeAttrs targets", AutoGroupSelf, AutoGroupInternet)...)
+	}
+
+	for _, test := range p.Tests {
+		if _, _, err := parseProtocol(test.Proto); err != nil {
+			errs = append(errs, fmt.Errorf("test proto %q is not valid: %w", test.Proto, err))
+		}
+
+		errs = append(errs, p.validateAliases(append([]Alias{test.Source}, test.destinations()...), "tests", AutoGroupSelf, AutoGroupInternet)...)
+	}
+
 	for _, ssh := range p.SSHs {
 		if ssh.Action != "accept" && ssh.Action != "check" {
 			errs = append(errs, fmt.Errorf("SSH action %q is not valid, must be accept or check", ssh.Action))
@@ -1512,21 +2308,60 @@ func (p *Policy) validate() error {
 		}
 	}
 
-	for _, approver := range p.AutoApprovers.ExitNode {
-		switch approver.(type) {
-		case *Group:
-			g := approver.(*Group)
-			if err := p.Groups.Contains(g); err != nil {
-				errs = append(errs, err)
+	for region, members := range p.Regions {
+		if region == "" || strings.Contains(region, ":") {
+			errs = append(errs, fmt.Errorf(`region name %q is not valid, it must not be empty or contain ":"`, region))
+		}
+
+		errs = append(errs, p.validateAliases(members, "regions", AutoGroupSelf, AutoGroupInternet)...)
+	}
+
+	dnsProfileNames := make(map[string]bool)
+	for _, profile := range p.DNSProfiles {
+		if profile.Name == "" {
+			errs = append(errs, errors.New("dnsProfiles must have a name"))
+		} else if dnsProfileNames[profile.Name] {
+			errs = append(errs, fmt.Errorf("dnsProfiles name %q is used more than once", profile.Name))
+		}
+		dnsProfileNames[profile.Name] = true
+
+		for _, address := range profile.Nameservers {
+			ns := types.Nameserver{Address: address}
+			if err := ns.Validate(); err != nil {
+				errs = append(errs, fmt.Errorf("dnsProfiles %q: %w", profile.Name, err))
 			}
-		case *Tag:
-			tagOwner := approver.(*Tag)
-			if err := p.TagOwners.Contains(tagOwner); err != nil {
-				errs = append(errs, err)
+		}
+
+		for domain, nameservers := range profile.Split {
+			for _, address := range nameservers {
+				ns := types.Nameserver{Address: address, Domain: domain}
+				if err := ns.Validate(); err != nil {
+					errs = append(errs, fmt.Errorf("dnsProfiles %q: %w", profile.Name, err))
+				}
 			}
 		}
+
+		errs = append(errs, p.validateAliases(profile.Targets, "dnsProfiles targets", AutoGroupSelf, AutoGroupInternet)...)
+	}
+
+	for regionID, members := range p.DERPRegions {
+		if regionID <= 0 {
+			errs = append(errs, fmt.Errorf("derpRegions region ID %d is not valid, it must be positive", regionID))
+		}
+
+		errs = append(errs, p.validateAliases(members, "derpRegions", AutoGroupSelf, AutoGroupInternet)...)
+	}
+
+	for prefix, routers := range p.RoutePriorities {
+		if tsaddr.IsExitRoute(prefix) {
+			errs = append(errs, fmt.Errorf(`routePriorities cannot contain the exit route %q, exit nodes have no primary`, prefix))
+		}
+
+		errs = append(errs, p.validateAliases(routers.aliases(), "routePriorities")...)
 	}
 
+	errs = append(errs, p.validateAliases(p.AutoApprovers.ExitNode.aliases(), "autoApprovers")...)
+
 	if len(errs) > 0 {
 		return multierr.New(errs...)
 	}
@@ -439,7 +439,7 @@ func TestUnmarshalPolicy(t *testing.T) {
 	],
 }
 `,
-			wantErr: `AutoGroup is invalid, got: "autogroup:invalid", must be one of [autogroup:internet autogroup:member autogroup:nonroot autogroup:tagged]`,
+			wantErr: `AutoGroup is invalid, got: "autogroup:invalid", must be one of [autogroup:internet autogroup:member autogroup:nonroot autogroup:tagged autogroup:self]`,
 		},
 		{
 			name: "undefined-hostname-errors-2490",
@@ -553,6 +553,219 @@ func TestUnmarshalPolicy(t *testing.T) {
 `,
 			wantErr: `"autogroup:internet" used in source, it can only be used in ACL destinations`,
 		},
+		{
+			name: "autogroup:self-in-dst-allowed",
+			input: `
+{
+  "acls": [
+    {
+      "action": "accept",
+      "src": [
+        "autogroup:member"
+      ],
+      "dst": [
+        "autogroup:self:*"
+      ]
+    }
+  ]
+}
+`,
+			want: &Policy{
+				ACLs: []ACL{
+					{
+						Action: "accept",
+						Sources: Aliases{
+							ptr.To(AutoGroup("autogroup:member")),
+						},
+						Destinations: []AliasWithPorts{
+							{
+								Alias: ptr.To(AutoGroup("autogroup:self")),
+								Ports: []tailcfg.PortRange{tailcfg.PortRangeAny},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "autogroup:self-in-src-not-allowed",
+			input: `
+{
+  "acls": [
+    {
+      "action": "accept",
+      "src": [
+        "autogroup:self"
+      ],
+      "dst": [
+        "10.0.0.1:*"
+      ]
+    }
+  ]
+}
+`,
+			wantErr: `autogroup "autogroup:self" is not supported for ACL sources, can be [autogroup:member autogroup:tagged]`,
+		},
+		{
+			name: "grants",
+			input: `
+{
+  "groups": {
+    "group:eng": ["testuser@"]
+  },
+  "tagOwners": {
+    "tag:app": ["group:eng"]
+  },
+  "grants": [
+    {
+      "src": ["group:eng"],
+      "dst": ["tag:app"],
+      "ip": ["*", "443", "tcp:80-90,8080"],
+      "app": {
+        "tailscale.com/cap/example": [
+          {"role": "admin"}
+        ]
+      }
+    }
+  ]
+}
+`,
+			want: &Policy{
+				Groups: Groups{
+					Group("group:eng"): []Username{Username("testuser@")},
+				},
+				TagOwners: TagOwners{
+					Tag("tag:app"): Owners{gp("group:eng")},
+				},
+				Grants: []Grant{
+					{
+						Sources:      Aliases{gp("group:eng")},
+						Destinations: Aliases{tp("tag:app")},
+						IP: []ProtocolPort{
+							{Ports: []tailcfg.PortRange{tailcfg.PortRangeAny}},
+							{Ports: []tailcfg.PortRange{{First: 443, Last: 443}}},
+							{Protocol: "tcp", Ports: []tailcfg.PortRange{{First: 80, Last: 90}, {First: 8080, Last: 8080}}},
+						},
+						App: tailcfg.PeerCapMap{
+							"tailscale.com/cap/example": []tailcfg.RawMessage{`{"role":"admin"}`},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "grants-autogroup-self-not-allowed",
+			input: `
+{
+  "grants": [
+    {
+      "src": ["autogroup:member"],
+      "dst": ["autogroup:self"],
+      "ip": ["*"]
+    }
+  ]
+}
+`,
+			wantErr: `"autogroup:self" is not supported in grant destinations`,
+		},
+		{
+			name: "node-attrs",
+			input: `
+{
+  "groups": {
+    "group:eng": ["testuser@"]
+  },
+  "nodeAttrs": [
+    {
+      "target": ["group:eng", "autogroup:tagged"],
+      "attr": ["funnel", "-https://tailscale.com/cap/file-sharing"],
+      "app": {
+        "tailscale.com/cap/example": [
+          {"role": "admin"}
+        ]
+      }
+    }
+  ]
+}
+`,
+			want: &Policy{
+				Groups: Groups{
+					Group("group:eng"): []Username{Username("testuser@")},
+				},
+				NodeAttrs: []NodeAttrGrant{
+					{
+						Targets: Aliases{gp("group:eng"), agp("autogroup:tagged")},
+						Attrs:   []string{"funnel", "-https://tailscale.com/cap/file-sharing"},
+						App: tailcfg.NodeCapMap{
+							"tailscale.com/cap/example": []tailcfg.RawMessage{`{"role":"admin"}`},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "node-attrs-without-attr-or-app",
+			input: `
+{
+  "nodeAttrs": [
+    {
+      "target": ["*"]
+    }
+  ]
+}
+`,
+			wantErr: `nodeAttrs must have at least one of attr or app`,
+		},
+		{
+			name: "node-attrs-autogroup-self-not-allowed",
+			input: `
+{
+  "nodeAttrs": [
+    {
+      "target": ["autogroup:self"],
+      "attr": ["funnel"]
+    }
+  ]
+}
+`,
+			wantErr: `autogroup "autogroup:self" is not supported in nodeAttrs targets`,
+		},
+		{
+			name: "node-attrs-app-connector-undefined-tag",
+			input: `
+{
+  "nodeAttrs": [
+    {
+      "target": ["*"],
+      "app": {
+        "tailscale.com/app-connectors": [
+          {"name": "github", "connectors": ["tag:connector"], "domains": ["github.com"]}
+        ]
+      }
+    }
+  ]
+}
+`,
+			wantErr: `Tag "tag:connector" is not defined in the Policy, please define or remove the reference to it`,
+		},
+		{
+			name: "node-attrs-app-connector-exit-route",
+			input: `
+{
+  "nodeAttrs": [
+    {
+      "target": ["*"],
+      "app": {
+        "tailscale.com/app-connectors": [
+          {"name": "github", "connectors": ["*"], "domains": ["github.com"], "routes": ["0.0.0.0/0"]}
+        ]
+      }
+    }
+  ]
+}
+`,
+			wantErr: `nodeAttrs app connector "github": exit route 0.0.0.0/0 is not allowed`,
+		},
 		{
 			name: "autogroup:internet-in-ssh-src-not-allowed",
 			input: `
@@ -11,6 +11,7 @@ import (
 
 	"github.com/juanfont/headscale/hscontrol/mapper"
 	"github.com/juanfont/headscale/hscontrol/policy"
+	"github.com/juanfont/headscale/hscontrol/routes"
 	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/rs/zerolog/log"
 	"github.com/sasha-s/go-deadlock"
@@ -208,7 +209,8 @@ func (m *mapSession) serveLongPoll() {
 			// send a full update to all nodes.
 			// TODO(kradalby): This can likely be made more effective, but likely most
 			// nodes has access to the same routes, so it might not be a big deal.
-			if m.h.primaryRoutes.SetRoutes(m.node.ID) {
+			healthChanged := m.h.primaryRoutes.SetHealth(m.node.ID, nodeHealth(m.node, false))
+			if m.h.primaryRoutes.SetRoutes(m.node.ID) || healthChanged {
 				ctx := types.NotifyCtx(context.Background(), "poll-primary-change", m.node.Hostname)
 				m.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
 			}
@@ -222,7 +224,10 @@ func (m *mapSession) serveLongPoll() {
 	m.h.pollNetMapStreamWG.Add(1)
 	defer m.h.pollNetMapStreamWG.Done()
 
-	if m.h.primaryRoutes.SetRoutes(m.node.ID, m.node.SubnetRoutes()...) {
+	healthChanged := m.h.primaryRoutes.SetHealth(m.node.ID, nodeHealth(m.node, true))
+	prioritiesChanged := m.h.primaryRoutes.SetPriorities(m.node.ID, m.h.routePriorities(m.node))
+	regionChanged := m.h.primaryRoutes.SetRegion(m.node.ID, m.h.nodeRegion(m.node))
+	if m.h.primaryRoutes.SetRoutes(m.node.ID, m.node.SubnetRoutes()...) || healthChanged || prioritiesChanged || regionChanged {
 		ctx := types.NotifyCtx(context.Background(), "poll-primary-change", m.node.Hostname)
 		m.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
 	}
@@ -327,7 +332,7 @@ func (m *mapSession) serveLongPoll() {
 				updateType = "remove"
 			case types.StateDERPUpdated:
 				m.tracef("Sending DERPUpdate MapResponse")
-				data, err = m.mapper.DERPMapResponse(m.req, m.node, m.h.DERPMap)
+				data, err = m.mapper.DERPMapResponse(m.req, m.node, m.h.currentDERPMap())
 				updateType = "derp"
 			}
 
@@ -388,6 +393,11 @@ func (m *mapSession) serveLongPoll() {
 				mapResponseLastSentSeconds.WithLabelValues("keepalive", m.node.ID.String()).Set(float64(time.Now().Unix()))
 			}
 			mapResponseSent.WithLabelValues("ok", "keepalive").Inc()
+
+			if m.h.primaryRoutes.SetLastSeen(m.node.ID, time.Now()) {
+				ctx := types.NotifyCtx(context.Background(), "poll-primary-change", m.node.Hostname)
+				m.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+			}
 		}
 	}
 }
@@ -441,6 +451,15 @@ func (m *mapSession) handleEndpointUpdate() {
 	}
 	m.node.Hostinfo = m.req.Hostinfo
 
+	// The endpoint update might change the DERP reachability of the node,
+	// fail over its routes if it is no longer fit to be a primary, and its
+	// preferred DERP server might move it to another region.
+	healthChanged := m.h.primaryRoutes.SetHealth(m.node.ID, nodeHealth(m.node, online))
+	if m.h.primaryRoutes.SetRegion(m.node.ID, m.h.nodeRegion(m.node)) || healthChanged {
+		ctx := types.NotifyCtx(m.ctx, "poll-primary-change", m.node.Hostname)
+		m.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+	}
+
 	logTracePeerChange(m.node.Hostname, sendUpdate, &change)
 
 	// If there is no changes and nothing to save,
@@ -467,10 +486,12 @@ func (m *mapSession) handleEndpointUpdate() {
 		// actual state change will be detected when the route manager
 		// is updated.
 		policy.AutoApproveRoutes(m.h.polMan, m.node)
+		m.h.notifyPendingRoutes(m.node)
 
 		// Update the routes of the given node in the route manager to
 		// see if an update needs to be sent.
-		if m.h.primaryRoutes.SetRoutes(m.node.ID, m.node.SubnetRoutes()...) {
+		prioritiesChanged := m.h.primaryRoutes.SetPriorities(m.node.ID, m.h.routePriorities(m.node))
+		if m.h.primaryRoutes.SetRoutes(m.node.ID, m.node.SubnetRoutes()...) || prioritiesChanged {
 			ctx := types.NotifyCtx(m.ctx, "poll-primary-change", m.node.Hostname)
 			m.h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
 		} else {
@@ -670,3 +691,19 @@ func hostInfoChanged(old, new *tailcfg.Hostinfo) (bool, bool) {
 
 	return false, false
 }
+
+// nodeHealth returns the health of the node used to choose the primary
+// subnet routers. Nodes that have not reported their network conditions
+// yet are assumed to reach DERP.
+func nodeHealth(node *types.Node, online bool) routes.NodeHealth {
+	derpReachable := true
+	if node.Hostinfo != nil && node.Hostinfo.NetInfo != nil {
+		derpReachable = node.Hostinfo.NetInfo.PreferredDERP != 0
+	}
+
+	return routes.NodeHealth{
+		Online:        online,
+		LastSeen:      time.Now(),
+		DERPReachable: derpReachable,
+	}
+}
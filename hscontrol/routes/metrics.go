@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const prometheusNamespace = "headscale"
+
+var primaryRouteFailovers = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: prometheusNamespace,
+	Name:      "primary_route_failovers_total",
+	Help:      "total count of primary subnet route failovers",
+}, []string{"reason"})
@@ -1,12 +1,15 @@
 package routes
 
 import (
+	"cmp"
 	"fmt"
+	"maps"
 	"net/netip"
 	"slices"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/juanfont/headscale/hscontrol/types"
 	"github.com/juanfont/headscale/hscontrol/util"
@@ -15,9 +18,15 @@ import (
 	"tailscale.com/util/set"
 )
 
+// maxFailoverEvents is the number of failover events kept in memory.
+const maxFailoverEvents = 100
+
 type PrimaryRoutes struct {
 	mu sync.Mutex
 
+	cfg types.RouteFailoverConfig
+	now func() time.Time
+
 	// routes is a map of prefixes that are adverties and approved and available
 	// in the global headscale state.
 	routes map[types.NodeID]set.Set[netip.Prefix]
@@ -25,25 +34,144 @@ type PrimaryRoutes struct {
 	// primaries is a map of prefixes to the node that is the primary for that prefix.
 	primaries map[netip.Prefix]types.NodeID
 	isPrimary map[types.NodeID]bool
+
+	// health is the last reported health of the nodes, nodes without a
+	// reported health are considered healthy.
+	health map[types.NodeID]NodeHealth
+
+	// healthySince is when a node last became healthy.
+	healthySince map[types.NodeID]time.Time
+
+	// priorities are the priorities of the nodes for their routes, nodes
+	// without a priority for a route have the zero Priority.
+	priorities map[types.NodeID]map[netip.Prefix]Priority
+
+	// regions are the regions of the nodes. Nodes in a region use the
+	// primaries of their region, regionalPrimaries, if there is a healthy
+	// node announcing the route in the region.
+	regions           map[types.NodeID]string
+	regionalPrimaries map[string]map[netip.Prefix]types.NodeID
+
+	// failovers is a ring of the most recent failover events, pending are
+	// the events not yet passed to the observers.
+	failovers []FailoverEvent
+	pending   []FailoverEvent
+	observers []func(FailoverEvent)
+}
+
+// NodeHealth describes the signals used to decide if a node is fit to be
+// the primary subnet router of a route.
+type NodeHealth struct {
+	// Online is true if the node is connected to headscale.
+	Online bool
+
+	// LastSeen is when headscale last heard from the node.
+	LastSeen time.Time
+
+	// DERPReachable is false if the node reports that it cannot reach
+	// any DERP server.
+	DERPReachable bool
+}
+
+// Priority is the preference of a node to be the primary router of a
+// route. The operator set priority takes precedence over the priority
+// given by the policy, and higher priorities are preferred.
+type Priority struct {
+	Operator int
+	Policy   int
+}
+
+// Compare returns -1, 0 or 1 if p is lower, equal or higher than o.
+func (p Priority) Compare(o Priority) int {
+	if c := cmp.Compare(p.Operator, o.Operator); c != 0 {
+		return c
+	}
+
+	return cmp.Compare(p.Policy, o.Policy)
+}
+
+// FailoverReason describes why the primary of a route changed.
+type FailoverReason string
+
+const (
+	FailoverReasonNotAdvertised   FailoverReason = "not_advertised"
+	FailoverReasonOffline         FailoverReason = "offline"
+	FailoverReasonStale           FailoverReason = "stale"
+	FailoverReasonDERPUnreachable FailoverReason = "derp_unreachable"
+
+	// FailoverReasonPreferred is used when the route moves back to a
+	// node with a higher priority once it is healthy.
+	FailoverReasonPreferred FailoverReason = "preferred"
+)
+
+// FailoverEvent records that the primary of a route moved away from a
+// node. To is 0 if no other node serves the route. Region is set if the
+// primary of a region moved, To is then the global primary if no other
+// node of the region serves the route.
+type FailoverEvent struct {
+	Time   time.Time
+	Prefix netip.Prefix
+	Region string
+	From   types.NodeID
+	To     types.NodeID
+	Reason FailoverReason
+}
+
+func (e FailoverEvent) String() string {
+	to := "none"
+	if e.To != 0 {
+		to = fmt.Sprintf("node %d", e.To)
+	}
+
+	route := e.Prefix.String()
+	if e.Region != "" {
+		route += " in region " + e.Region
+	}
+
+	return fmt.Sprintf("%s: route %s failed over from node %d to %s (%s)",
+		e.Time.Format(time.RFC3339), route, e.From, to, e.Reason)
 }
 
+// New returns a PrimaryRoutes only failing over when the primary stops
+// announcing a route or reports to be unhealthy.
 func New() *PrimaryRoutes {
+	return NewWithConfig(types.RouteFailoverConfig{})
+}
+
+// NewWithConfig returns a PrimaryRoutes using the given failover
+// configuration.
+func NewWithConfig(cfg types.RouteFailoverConfig) *PrimaryRoutes {
 	return &PrimaryRoutes{
-		routes:    make(map[types.NodeID]set.Set[netip.Prefix]),
-		primaries: make(map[netip.Prefix]types.NodeID),
-		isPrimary: make(map[types.NodeID]bool),
+		cfg:          cfg,
+		now:          time.Now,
+		routes:       make(map[types.NodeID]set.Set[netip.Prefix]),
+		primaries:    make(map[netip.Prefix]types.NodeID),
+		isPrimary:    make(map[types.NodeID]bool),
+		health:       make(map[types.NodeID]NodeHealth),
+		healthySince: make(map[types.NodeID]time.Time),
+		priorities:   make(map[types.NodeID]map[netip.Prefix]Priority),
+		regions:      make(map[types.NodeID]string),
 	}
 }
 
+// ObserveFailovers registers a function called with every failover
+// event. It is called without holding the lock, and must be registered
+// before the PrimaryRoutes is used.
+func (pr *PrimaryRoutes) ObserveFailovers(f func(FailoverEvent)) {
+	pr.observers = append(pr.observers, f)
+}
+
 // updatePrimaryLocked recalculates the primary routes and updates the internal state.
 // It returns true if the primary routes have changed.
 // It is assumed that the caller holds the lock.
 // The algorthm is as follows:
 // 1. Reset the primaries map.
-// 2. Iterate over the routes and count the number of times a prefix is advertised.
-// 3. If a prefix is advertised by at least two nodes, it is a primary route.
-// 4. If the primary routes have changed, update the internal state and return true.
-// 5. Otherwise, return false.
+// 2. Iterate over the routes and collect the nodes advertising each prefix.
+// 3. Keep the current primary of a prefix if it still advertises it, is healthy
+// and no healthy node with a higher priority is available.
+// 4. Otherwise, select a new primary and record the failover.
+// 5. If the primary routes have changed, update the internal state and return true.
+// 6. Otherwise, return false.
 func (pr *PrimaryRoutes) updatePrimaryLocked() bool {
 	// reset the primaries map, as we are going to recalculate it.
 	allPrimaries := make(map[netip.Prefix][]types.NodeID)
@@ -70,34 +198,158 @@ func (pr *PrimaryRoutes) updatePrimaryLocked() bool {
 	}
 
 	// Go through all prefixes and determine the primary route for each.
-	// If the number of routes is below the minimum, remove the primary.
-	// If the current primary is still available, continue.
-	// If the current primary is not available, select a new one.
+	now := pr.now()
 	for prefix, nodes := range allPrimaries {
-		if node, ok := pr.primaries[prefix]; ok {
-			// If the current primary is still available, continue.
-			if slices.Contains(nodes, node) {
-				continue
-			}
+		current, hasCurrent := pr.primaries[prefix]
+
+		next, reason, moved := pr.choosePrimaryLocked(prefix, nodes, current, hasCurrent, now)
+		if !moved {
+			continue
 		}
-		if len(nodes) >= 1 {
-			pr.primaries[prefix] = nodes[0]
-			changed = true
+
+		pr.primaries[prefix] = next
+		changed = true
+
+		if hasCurrent {
+			pr.recordFailoverLocked(FailoverEvent{
+				Time:   now,
+				Prefix: prefix,
+				From:   current,
+				To:     next,
+				Reason: reason,
+			})
 		}
 	}
 
 	// Clean up any remaining primaries that are no longer valid.
-	for prefix := range pr.primaries {
+	for prefix, current := range pr.primaries {
 		if _, ok := allPrimaries[prefix]; !ok {
 			delete(pr.primaries, prefix)
 			changed = true
+
+			pr.recordFailoverLocked(FailoverEvent{
+				Time:   now,
+				Prefix: prefix,
+				From:   current,
+				Reason: FailoverReasonNotAdvertised,
+			})
 		}
 	}
 
+	if pr.updateRegionalPrimariesLocked(allPrimaries, now) {
+		changed = true
+	}
+
 	// Populate the quick lookup index for primary routes
 	for _, nodeID := range pr.primaries {
 		pr.isPrimary[nodeID] = true
 	}
+	for _, primaries := range pr.regionalPrimaries {
+		for _, nodeID := range primaries {
+			pr.isPrimary[nodeID] = true
+		}
+	}
+
+	return changed
+}
+
+// choosePrimaryLocked returns the primary of the prefix among the given
+// sorted nodes announcing it. The current primary is kept if it still
+// announces the prefix, is healthy and no healthy node with a higher
+// priority is available. Otherwise, a new one is selected, preferring
+// healthy nodes with the highest priority that are not in their hold-down
+// period. It reports if the primary moved, and why it moved away from the
+// current primary.
+func (pr *PrimaryRoutes) choosePrimaryLocked(
+	prefix netip.Prefix,
+	nodes []types.NodeID,
+	current types.NodeID,
+	hasCurrent bool,
+	now time.Time,
+) (types.NodeID, FailoverReason, bool) {
+	next, settled := pr.selectPrimaryLocked(prefix, nodes, now)
+
+	if !hasCurrent || !slices.Contains(nodes, current) {
+		return next, FailoverReasonNotAdvertised, true
+	}
+
+	reason := pr.unhealthyReasonLocked(current, now)
+	if reason == "" {
+		if next == current || !settled ||
+			pr.priorityLocked(next, prefix).Compare(pr.priorityLocked(current, prefix)) <= 0 {
+			return current, "", false
+		}
+
+		return next, FailoverReasonPreferred, true
+	}
+
+	if next == current || pr.unhealthyReasonLocked(next, now) != "" {
+		// No healthier node is available, keep the current
+		// primary to not move the route for nothing.
+		return current, "", false
+	}
+
+	return next, reason, true
+}
+
+// updateRegionalPrimariesLocked recalculates the primary of the prefixes
+// for each region, among the nodes of the region announcing them. A region
+// without a healthy node announcing a prefix uses the global primary.
+// It returns true if the regional primaries have changed.
+func (pr *PrimaryRoutes) updateRegionalPrimariesLocked(allPrimaries map[netip.Prefix][]types.NodeID, now time.Time) bool {
+	regional := make(map[string]map[netip.Prefix]types.NodeID)
+
+	for prefix, nodes := range allPrimaries {
+		byRegion := make(map[string][]types.NodeID)
+		for _, node := range nodes {
+			if region := pr.regions[node]; region != "" {
+				byRegion[region] = append(byRegion[region], node)
+			}
+		}
+
+		for region, regionNodes := range byRegion {
+			current, hasCurrent := pr.regionalPrimaries[region][prefix]
+
+			next, reason, moved := pr.choosePrimaryLocked(prefix, regionNodes, current, hasCurrent, now)
+			if !moved {
+				reason = pr.unhealthyReasonLocked(current, now)
+			}
+
+			if pr.unhealthyReasonLocked(next, now) != "" {
+				if hasCurrent {
+					pr.recordFailoverLocked(FailoverEvent{
+						Time:   now,
+						Prefix: prefix,
+						Region: region,
+						From:   current,
+						To:     pr.primaries[prefix],
+						Reason: reason,
+					})
+				}
+
+				continue
+			}
+
+			if moved && hasCurrent {
+				pr.recordFailoverLocked(FailoverEvent{
+					Time:   now,
+					Prefix: prefix,
+					Region: region,
+					From:   current,
+					To:     next,
+					Reason: reason,
+				})
+			}
+
+			if _, ok := regional[region]; !ok {
+				regional[region] = make(map[netip.Prefix]types.NodeID)
+			}
+			regional[region][prefix] = next
+		}
+	}
+
+	changed := !maps.EqualFunc(regional, pr.regionalPrimaries, maps.Equal)
+	pr.regionalPrimaries = regional
 
 	return changed
 }
@@ -108,12 +360,14 @@ func (pr *PrimaryRoutes) updatePrimaryLocked() bool {
 // All exit routes are ignored as they are not used in primary route context.
 func (pr *PrimaryRoutes) SetRoutes(node types.NodeID, prefixes ...netip.Prefix) bool {
 	pr.mu.Lock()
+	defer pr.emitFailovers()
 	defer pr.mu.Unlock()
 
 	// If no routes are being set, remove the node from the routes map.
 	if len(prefixes) == 0 {
-		if _, ok := pr.routes[node]; ok {
-			delete(pr.routes, node)
+		_, ok := pr.routes[node]
+		pr.forgetLocked(node)
+		if ok {
 			return pr.updatePrimaryLocked()
 		}
 
@@ -130,12 +384,245 @@ func (pr *PrimaryRoutes) SetRoutes(node types.NodeID, prefixes ...netip.Prefix)
 	if rs.Len() != 0 {
 		pr.routes[node] = rs
 	} else {
-		delete(pr.routes, node)
+		pr.forgetLocked(node)
+	}
+
+	return pr.updatePrimaryLocked()
+}
+
+// forgetLocked removes the routes of a node and the state kept for them.
+// The health and region of an online node are kept, the region is also
+// used to pick the primaries the node sees as a viewer. It is assumed
+// that the caller holds the lock.
+func (pr *PrimaryRoutes) forgetLocked(node types.NodeID) {
+	delete(pr.routes, node)
+	delete(pr.priorities, node)
+
+	if !pr.health[node].Online {
+		delete(pr.health, node)
+		delete(pr.healthySince, node)
+		delete(pr.regions, node)
+	}
+}
+
+// SetPriorities sets the priorities of a node for its routes and
+// recalculates the primary routes. It returns true if there was a change
+// in primary routes.
+func (pr *PrimaryRoutes) SetPriorities(node types.NodeID, priorities map[netip.Prefix]Priority) bool {
+	pr.mu.Lock()
+	defer pr.emitFailovers()
+	defer pr.mu.Unlock()
+
+	if len(priorities) == 0 {
+		delete(pr.priorities, node)
+	} else {
+		pr.priorities[node] = priorities
+	}
+
+	return pr.updatePrimaryLocked()
+}
+
+// SetRegion sets the region of a node, the empty region removes it, and
+// recalculates the primary routes. It returns true if there was a change
+// in the primary routes seen by any node.
+func (pr *PrimaryRoutes) SetRegion(node types.NodeID, region string) bool {
+	pr.mu.Lock()
+	defer pr.emitFailovers()
+	defer pr.mu.Unlock()
+
+	previous := pr.regions[node]
+	if region == "" {
+		delete(pr.regions, node)
+	} else {
+		pr.regions[node] = region
+	}
+
+	// The routes seen by the node changes if it moves into or out of
+	// a region with its own primaries.
+	viewChanged := previous != region &&
+		(len(pr.regionalPrimaries[previous]) > 0 || len(pr.regionalPrimaries[region]) > 0)
+
+	return pr.updatePrimaryLocked() || viewChanged
+}
+
+// SetHealth records the health of a node and recalculates the primary
+// routes. It returns true if there was a change in primary routes.
+func (pr *PrimaryRoutes) SetHealth(node types.NodeID, health NodeHealth) bool {
+	pr.mu.Lock()
+	defer pr.emitFailovers()
+	defer pr.mu.Unlock()
+
+	now := pr.now()
+	wasHealthy := pr.unhealthyReasonLocked(node, now) == ""
+	_, known := pr.health[node]
+
+	pr.health[node] = health
+
+	if pr.unhealthyReasonLocked(node, now) == "" && (!wasHealthy || !known) {
+		pr.healthySince[node] = now
+	}
+
+	return pr.updatePrimaryLocked()
+}
+
+// SetLastSeen records that a node with a reported health was seen, and
+// recalculates the primary routes. It returns true if there was a change
+// in primary routes.
+func (pr *PrimaryRoutes) SetLastSeen(node types.NodeID, lastSeen time.Time) bool {
+	pr.mu.Lock()
+	defer pr.emitFailovers()
+	defer pr.mu.Unlock()
+
+	health, ok := pr.health[node]
+	if !ok {
+		return false
+	}
+
+	now := pr.now()
+	wasHealthy := pr.unhealthyReasonLocked(node, now) == ""
+
+	health.LastSeen = lastSeen
+	pr.health[node] = health
+
+	if pr.unhealthyReasonLocked(node, now) == "" && !wasHealthy {
+		pr.healthySince[node] = now
 	}
 
 	return pr.updatePrimaryLocked()
 }
 
+// CheckHealth recalculates the primary routes, failing over from the
+// nodes that have not been seen for too long. It returns true if there
+// was a change in primary routes.
+func (pr *PrimaryRoutes) CheckHealth() bool {
+	pr.mu.Lock()
+	defer pr.emitFailovers()
+	defer pr.mu.Unlock()
+
+	return pr.updatePrimaryLocked()
+}
+
+// unhealthyReasonLocked returns why a node is unhealthy, or an empty
+// reason if it is healthy.
+func (pr *PrimaryRoutes) unhealthyReasonLocked(node types.NodeID, now time.Time) FailoverReason {
+	health, ok := pr.health[node]
+	if !ok {
+		return ""
+	}
+
+	switch {
+	case !health.Online:
+		return FailoverReasonOffline
+	case !health.DERPReachable:
+		return FailoverReasonDERPUnreachable
+	case pr.cfg.StaleAfter > 0 && now.Sub(health.LastSeen) > pr.cfg.StaleAfter:
+		return FailoverReasonStale
+	}
+
+	return ""
+}
+
+// selectPrimaryLocked returns the node to use as primary of the prefix
+// among the given sorted nodes, in order of their priority: the first
+// healthy node past its hold-down period, else the first healthy node,
+// else the first node. It reports if the node is healthy and past its
+// hold-down period.
+func (pr *PrimaryRoutes) selectPrimaryLocked(prefix netip.Prefix, nodes []types.NodeID, now time.Time) (types.NodeID, bool) {
+	nodes = slices.Clone(nodes)
+	slices.SortStableFunc(nodes, func(a, b types.NodeID) int {
+		return pr.priorityLocked(b, prefix).Compare(pr.priorityLocked(a, prefix))
+	})
+
+	var healthy []types.NodeID
+	for _, node := range nodes {
+		if pr.unhealthyReasonLocked(node, now) == "" {
+			healthy = append(healthy, node)
+		}
+	}
+
+	for _, node := range healthy {
+		if since, ok := pr.healthySince[node]; !ok || now.Sub(since) >= pr.cfg.HoldDown {
+			return node, true
+		}
+	}
+
+	if len(healthy) > 0 {
+		return healthy[0], false
+	}
+
+	return nodes[0], false
+}
+
+func (pr *PrimaryRoutes) priorityLocked(node types.NodeID, prefix netip.Prefix) Priority {
+	return pr.priorities[node][prefix]
+}
+
+func (pr *PrimaryRoutes) recordFailoverLocked(event FailoverEvent) {
+	pr.failovers = append(pr.failovers, event)
+	if len(pr.failovers) > maxFailoverEvents {
+		pr.failovers = pr.failovers[len(pr.failovers)-maxFailoverEvents:]
+	}
+
+	pr.pending = append(pr.pending, event)
+	primaryRouteFailovers.WithLabelValues(string(event.Reason)).Inc()
+}
+
+// emitFailovers passes the pending failover events to the observers, it
+// must be called without holding the lock.
+func (pr *PrimaryRoutes) emitFailovers() {
+	pr.mu.Lock()
+	pending := pr.pending
+	pr.pending = nil
+	pr.mu.Unlock()
+
+	for _, event := range pending {
+		for _, observe := range pr.observers {
+			observe(event)
+		}
+	}
+}
+
+// FailoverEvents returns the most recent failover events, oldest first.
+func (pr *PrimaryRoutes) FailoverEvents() []FailoverEvent {
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	return slices.Clone(pr.failovers)
+}
+
+// PrimaryRoutesFor returns the routes the node serves as primary as seen
+// by the viewer. Viewers in a region use the primaries of their region,
+// and the global primaries for the routes not served in their region.
+func (pr *PrimaryRoutes) PrimaryRoutesFor(viewer, id types.NodeID) []netip.Prefix {
+	if pr == nil {
+		return nil
+	}
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if _, ok := pr.isPrimary[id]; !ok {
+		return nil
+	}
+
+	regional := pr.regionalPrimaries[pr.regions[viewer]]
+
+	var routes []netip.Prefix
+
+	for prefix, node := range pr.primaries {
+		if regionalNode, ok := regional[prefix]; ok {
+			node = regionalNode
+		}
+
+		if node == id {
+			routes = append(routes, prefix)
+		}
+	}
+
+	tsaddr.SortPrefixes(routes)
+	return routes
+}
+
 func (pr *PrimaryRoutes) PrimaryRoutes(id types.NodeID) []netip.Prefix {
 	if pr == nil {
 		return nil
@@ -161,6 +648,86 @@ func (pr *PrimaryRoutes) PrimaryRoutes(id types.NodeID) []netip.Prefix {
 	return routes
 }
 
+// PrimaryReasons returns, for each route the node is the primary router
+// of, a description of why the node was chosen.
+func (pr *PrimaryRoutes) PrimaryReasons(id types.NodeID) map[netip.Prefix]string {
+	if pr == nil {
+		return nil
+	}
+
+	pr.mu.Lock()
+	defer pr.mu.Unlock()
+
+	if _, ok := pr.isPrimary[id]; !ok {
+		return nil
+	}
+
+	reasons := make(map[netip.Prefix]string)
+	for prefix, node := range pr.primaries {
+		if node == id {
+			reasons[prefix] = pr.primaryReasonLocked(prefix, id)
+		}
+	}
+
+	return reasons
+}
+
+func (pr *PrimaryRoutes) primaryReasonLocked(prefix netip.Prefix, id types.NodeID) string {
+	var others []types.NodeID
+	for node, routes := range pr.routes {
+		if node != id && routes.Contains(prefix) {
+			others = append(others, node)
+		}
+	}
+
+	if len(others) == 0 {
+		return "only router"
+	}
+
+	slices.Sort(others)
+
+	now := pr.now()
+	priority := pr.priorityLocked(id, prefix)
+	higherOperator, higherPolicy := true, true
+	for _, other := range others {
+		otherPriority := pr.priorityLocked(other, prefix)
+		if otherPriority.Compare(priority) > 0 {
+			state := string(pr.unhealthyReasonLocked(other, now))
+			if state == "" {
+				state = "in hold-down"
+			}
+
+			return fmt.Sprintf("failover, node %d with a higher priority is %s", other, state)
+		}
+
+		if otherPriority.Operator >= priority.Operator {
+			higherOperator = false
+		}
+		if otherPriority.Compare(priority) == 0 {
+			higherPolicy = false
+		}
+	}
+
+	switch {
+	case higherOperator:
+		return fmt.Sprintf("highest priority (%d)", priority.Operator)
+	case higherPolicy:
+		return "preferred by policy"
+	}
+
+	for _, event := range slices.Backward(pr.failovers) {
+		if event.Prefix == prefix {
+			if event.To == id {
+				return fmt.Sprintf("failover from node %d (%s)", event.From, event.Reason)
+			}
+
+			break
+		}
+	}
+
+	return "first available"
+}
+
 func (pr *PrimaryRoutes) String() string {
 	pr.mu.Lock()
 	defer pr.mu.Unlock()
@@ -185,5 +752,21 @@ func (pr *PrimaryRoutes) stringLocked() string {
 		fmt.Fprintf(&sb, "\nRoute %s: %d", route, nodeID)
 	}
 
+	regions := xmaps.Keys(pr.regionalPrimaries)
+	slices.Sort(regions)
+	for _, region := range regions {
+		fmt.Fprintf(&sb, "\n\nPrimary routes in region %s:\n", region)
+		for route, nodeID := range pr.regionalPrimaries[region] {
+			fmt.Fprintf(&sb, "\nRoute %s: %d", route, nodeID)
+		}
+	}
+
+	if len(pr.failovers) > 0 {
+		fmt.Fprintln(&sb, "\n\nRecent failovers:")
+		for _, event := range pr.failovers {
+			fmt.Fprintf(&sb, "\n%s", event)
+		}
+	}
+
 	return sb.String()
 }
@@ -4,6 +4,7 @@ import (
 	"net/netip"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
@@ -466,3 +467,345 @@ func TestPrimaryRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestPrimaryRoutesFailover(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	healthy := NodeHealth{Online: true, LastSeen: now, DERPReachable: true}
+	route := mp("192.168.1.0/24")
+
+	newPR := func() (*PrimaryRoutes, *[]FailoverEvent) {
+		pr := NewWithConfig(types.RouteFailoverConfig{
+			StaleAfter: 3 * time.Minute,
+			HoldDown:   time.Minute,
+		})
+		pr.now = func() time.Time { return now }
+
+		var events []FailoverEvent
+		pr.ObserveFailovers(func(e FailoverEvent) {
+			events = append(events, e)
+		})
+
+		pr.SetHealth(1, healthy)
+		pr.SetHealth(2, healthy)
+		pr.SetRoutes(1, route)
+		pr.SetRoutes(2, route)
+
+		return pr, &events
+	}
+
+	tests := []struct {
+		name            string
+		operations      func(pr *PrimaryRoutes) bool
+		expectedPrimary types.NodeID
+		expectedChange  bool
+		expectedEvents  []FailoverEvent
+	}{
+		{
+			name: "healthy-primary-is-kept",
+			operations: func(pr *PrimaryRoutes) bool {
+				return pr.SetHealth(2, healthy)
+			},
+			expectedPrimary: 1,
+		},
+		{
+			name: "offline-primary-fails-over",
+			operations: func(pr *PrimaryRoutes) bool {
+				return pr.SetHealth(1, NodeHealth{LastSeen: now, DERPReachable: true})
+			},
+			expectedPrimary: 2,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now, Prefix: route, From: 1, To: 2, Reason: FailoverReasonOffline},
+			},
+		},
+		{
+			name: "derp-unreachable-primary-fails-over",
+			operations: func(pr *PrimaryRoutes) bool {
+				return pr.SetHealth(1, NodeHealth{Online: true, LastSeen: now})
+			},
+			expectedPrimary: 2,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now, Prefix: route, From: 1, To: 2, Reason: FailoverReasonDERPUnreachable},
+			},
+		},
+		{
+			name: "stale-primary-fails-over",
+			operations: func(pr *PrimaryRoutes) bool {
+				pr.SetLastSeen(2, now.Add(5*time.Minute))
+
+				now = now.Add(5 * time.Minute)
+				defer func() { now = now.Add(-5 * time.Minute) }()
+
+				return pr.CheckHealth()
+			},
+			expectedPrimary: 2,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now.Add(5 * time.Minute), Prefix: route, From: 1, To: 2, Reason: FailoverReasonStale},
+			},
+		},
+		{
+			name: "primary-kept-without-healthier-node",
+			operations: func(pr *PrimaryRoutes) bool {
+				pr.SetHealth(2, NodeHealth{LastSeen: now, DERPReachable: true})
+
+				return pr.SetHealth(1, NodeHealth{Online: true, LastSeen: now})
+			},
+			expectedPrimary: 1,
+		},
+		{
+			name: "withdrawn-route-fails-over",
+			operations: func(pr *PrimaryRoutes) bool {
+				return pr.SetRoutes(1)
+			},
+			expectedPrimary: 2,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now, Prefix: route, From: 1, To: 2, Reason: FailoverReasonNotAdvertised},
+			},
+		},
+		{
+			name: "recovered-node-in-hold-down-is-not-preferred",
+			operations: func(pr *PrimaryRoutes) bool {
+				pr.SetRoutes(3, route)
+				pr.SetHealth(2, NodeHealth{LastSeen: now, DERPReachable: true})
+				pr.SetHealth(2, healthy)
+
+				// Node 2 just recovered, node 3 has been healthy
+				// for longer and is preferred.
+				return pr.SetHealth(1, NodeHealth{LastSeen: now, DERPReachable: true})
+			},
+			expectedPrimary: 3,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now, Prefix: route, From: 1, To: 3, Reason: FailoverReasonOffline},
+			},
+		},
+		{
+			name: "recovered-node-in-hold-down-is-used-as-last-resort",
+			operations: func(pr *PrimaryRoutes) bool {
+				pr.SetHealth(2, NodeHealth{LastSeen: now, DERPReachable: true})
+				pr.SetHealth(2, healthy)
+
+				return pr.SetHealth(1, NodeHealth{LastSeen: now, DERPReachable: true})
+			},
+			expectedPrimary: 2,
+			expectedChange:  true,
+			expectedEvents: []FailoverEvent{
+				{Time: now, Prefix: route, From: 1, To: 2, Reason: FailoverReasonOffline},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pr, events := newPR()
+			change := tt.operations(pr)
+			if change != tt.expectedChange {
+				t.Errorf("change = %v, want %v", change, tt.expectedChange)
+			}
+			if got := pr.primaries[route]; got != tt.expectedPrimary {
+				t.Errorf("primary = %d, want %d", got, tt.expectedPrimary)
+			}
+			comps := append(util.Comparers, cmpopts.EquateEmpty())
+			if diff := cmp.Diff(tt.expectedEvents, *events, comps...); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tt.expectedEvents, pr.FailoverEvents(), comps...); diff != "" {
+				t.Errorf("FailoverEvents mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestPrimaryRoutesPriorities(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	healthy := NodeHealth{Online: true, LastSeen: now, DERPReachable: true}
+	offline := NodeHealth{LastSeen: now, DERPReachable: true}
+	route := mp("192.168.1.0/24")
+
+	pr := NewWithConfig(types.RouteFailoverConfig{HoldDown: time.Minute})
+	pr.now = func() time.Time { return now }
+
+	pr.SetRoutes(1, route)
+	pr.SetRoutes(2, route)
+	if got := pr.primaries[route]; got != 1 {
+		t.Fatalf("primary = %d, want 1", got)
+	}
+	if diff := cmp.Diff(map[netip.Prefix]string{route: "first available"}, pr.PrimaryReasons(1), util.Comparers...); diff != "" {
+		t.Errorf("PrimaryReasons mismatch (-want +got):\n%s", diff)
+	}
+
+	// The pinned node takes over as it is healthy.
+	if !pr.SetPriorities(2, map[netip.Prefix]Priority{route: {Operator: 100}}) {
+		t.Errorf("SetPriorities did not change the primary")
+	}
+	if got := pr.primaries[route]; got != 2 {
+		t.Fatalf("primary = %d, want 2", got)
+	}
+	if diff := cmp.Diff(map[netip.Prefix]string{route: "highest priority (100)"}, pr.PrimaryReasons(2), util.Comparers...); diff != "" {
+		t.Errorf("PrimaryReasons mismatch (-want +got):\n%s", diff)
+	}
+
+	// The policy is only used if the operator priorities are equal.
+	pr.SetPriorities(1, map[netip.Prefix]Priority{route: {Policy: 2}})
+	if got := pr.primaries[route]; got != 2 {
+		t.Fatalf("primary = %d, want 2", got)
+	}
+
+	// Fall back if the preferred node fails.
+	pr.SetHealth(1, healthy)
+	pr.SetHealth(2, offline)
+	if got := pr.primaries[route]; got != 1 {
+		t.Fatalf("primary = %d, want 1", got)
+	}
+	if diff := cmp.Diff(map[netip.Prefix]string{route: "failover, node 2 with a higher priority is offline"}, pr.PrimaryReasons(1), util.Comparers...); diff != "" {
+		t.Errorf("PrimaryReasons mismatch (-want +got):\n%s", diff)
+	}
+
+	// The preferred node is healthy again, but in its hold-down period.
+	pr.SetHealth(2, healthy)
+	if got := pr.primaries[route]; got != 1 {
+		t.Fatalf("primary = %d, want 1", got)
+	}
+	if diff := cmp.Diff(map[netip.Prefix]string{route: "failover, node 2 with a higher priority is in hold-down"}, pr.PrimaryReasons(1), util.Comparers...); diff != "" {
+		t.Errorf("PrimaryReasons mismatch (-want +got):\n%s", diff)
+	}
+
+	// The route returns to the preferred node after the hold-down.
+	now = now.Add(2 * time.Minute)
+	pr.SetLastSeen(1, now)
+	pr.SetLastSeen(2, now)
+	if got := pr.primaries[route]; got != 2 {
+		t.Fatalf("primary = %d, want 2", got)
+	}
+
+	events := pr.FailoverEvents()
+	if len(events) == 0 || events[len(events)-1].Reason != FailoverReasonPreferred {
+		t.Errorf("last failover event = %v, want reason %s", events, FailoverReasonPreferred)
+	}
+
+	// Removing the priorities keeps the current primary.
+	pr.SetPriorities(1, nil)
+	pr.SetPriorities(2, nil)
+	if got := pr.primaries[route]; got != 2 {
+		t.Fatalf("primary = %d, want 2", got)
+	}
+	if diff := cmp.Diff(map[netip.Prefix]string{route: "failover from node 1 (preferred)"}, pr.PrimaryReasons(2), util.Comparers...); diff != "" {
+		t.Errorf("PrimaryReasons mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrimaryRoutesRegions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	healthy := NodeHealth{Online: true, LastSeen: now, DERPReachable: true}
+	offline := NodeHealth{LastSeen: now, DERPReachable: true}
+	route := mp("192.168.1.0/24")
+
+	pr := New()
+	pr.now = func() time.Time { return now }
+
+	// Node 1 and 2 are subnet routers in eu and us, node 3 and 4 clients
+	// in eu and us, node 5 a client in no region.
+	pr.SetRoutes(1, route)
+	pr.SetRoutes(2, route)
+	pr.SetRegion(1, "eu")
+	if !pr.SetRegion(2, "us") {
+		t.Errorf("SetRegion did not change the primary routes")
+	}
+	pr.SetRegion(3, "eu")
+	if !pr.SetRegion(4, "us") {
+		t.Errorf("SetRegion did not change the primary routes seen by the node")
+	}
+	if pr.SetRegion(5, "") {
+		t.Errorf("SetRegion changed the primary routes")
+	}
+
+	want := map[[2]types.NodeID][]netip.Prefix{
+		{1, 1}: {route},
+		{1, 2}: nil,
+		{3, 1}: {route},
+		{3, 2}: nil,
+		{4, 1}: nil,
+		{4, 2}: {route},
+		{5, 1}: {route},
+		{5, 2}: nil,
+	}
+	comps := append(util.Comparers, cmpopts.EquateEmpty())
+	for ids, routes := range want {
+		if diff := cmp.Diff(routes, pr.PrimaryRoutesFor(ids[0], ids[1]), comps...); diff != "" {
+			t.Errorf("PrimaryRoutesFor(%d, %d) mismatch (-want +got):\n%s", ids[0], ids[1], diff)
+		}
+	}
+
+	// Without a healthy router in eu, the eu clients use the global
+	// primary.
+	pr.SetHealth(2, healthy)
+	pr.SetHealth(1, offline)
+	if diff := cmp.Diff([]netip.Prefix{route}, pr.PrimaryRoutesFor(3, 2), comps...); diff != "" {
+		t.Errorf("PrimaryRoutesFor(3, 2) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]FailoverEvent{
+		{Time: now, Prefix: route, From: 1, To: 2, Reason: FailoverReasonOffline},
+		{Time: now, Prefix: route, Region: "eu", From: 1, To: 2, Reason: FailoverReasonOffline},
+	}, pr.FailoverEvents(), comps...); diff != "" {
+		t.Errorf("FailoverEvents mismatch (-want +got):\n%s", diff)
+	}
+
+	// The eu router serves the eu clients again once it is healthy.
+	pr.SetHealth(1, healthy)
+	if diff := cmp.Diff([]netip.Prefix{route}, pr.PrimaryRoutesFor(3, 1), comps...); diff != "" {
+		t.Errorf("PrimaryRoutesFor(3, 1) mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff([]netip.Prefix{route}, pr.PrimaryRoutesFor(5, 2), comps...); diff != "" {
+		t.Errorf("PrimaryRoutesFor(5, 2) mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestPrimaryRoutesForgetNodes(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	healthy := NodeHealth{Online: true, LastSeen: now, DERPReachable: true}
+	offline := NodeHealth{LastSeen: now, DERPReachable: true}
+	route := mp("192.168.1.0/24")
+
+	pr := New()
+	pr.now = func() time.Time { return now }
+
+	connect := func(node types.NodeID, prefixes ...netip.Prefix) {
+		pr.SetHealth(node, healthy)
+		pr.SetRoutes(node, prefixes...)
+	}
+
+	connect(1, route)
+	pr.SetPriorities(1, map[netip.Prefix]Priority{route: {Operator: 10}})
+	connect(2)
+	pr.SetRegion(2, "eu")
+
+	// An online node without routes keeps its health and region.
+	if _, ok := pr.health[2]; !ok {
+		t.Errorf("health of online node 2 was removed")
+	}
+	if pr.regions[2] != "eu" {
+		t.Errorf("region of online node 2 was removed")
+	}
+
+	// Disconnected nodes are forgotten.
+	for _, node := range []types.NodeID{1, 2} {
+		pr.SetHealth(node, offline)
+		pr.SetRoutes(node)
+	}
+
+	if len(pr.routes) != 0 || len(pr.health) != 0 || len(pr.healthySince) != 0 {
+		t.Errorf("state of disconnected nodes was kept: routes %v, health %v, healthySince %v",
+			pr.routes, pr.health, pr.healthySince)
+	}
+
+	if len(pr.regions) != 0 {
+		t.Errorf("regions of disconnected nodes were kept: %v", pr.regions)
+	}
+
+	if len(pr.priorities) != 0 {
+		t.Errorf("priorities of nodes without routes were kept: %v", pr.priorities)
+	}
+}
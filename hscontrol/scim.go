@@ -0,0 +1,785 @@
+package hscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/scim"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// scimPath is the base path of the SCIM 2.0 endpoint.
+const scimPath = "/scim/v2"
+
+var errSCIMNotFound = scim.NewError(http.StatusNotFound, "", "resource not found")
+
+// registerSCIMRoutes adds the SCIM 2.0 endpoint provisioning users and
+// groups. It authenticates with API keys of the admin or scim scope.
+func (h *Headscale) registerSCIMRoutes(router *mux.Router) {
+	r := router.PathPrefix(scimPath).Subrouter()
+	r.Use(h.httpAuthenticationMiddleware, h.scimAuthorizationMiddleware)
+
+	r.HandleFunc("/ServiceProviderConfig", func(w http.ResponseWriter, _ *http.Request) {
+		scimWrite(w, http.StatusOK, scim.ServiceProviderConfig())
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/ResourceTypes", func(w http.ResponseWriter, _ *http.Request) {
+		resourceTypes := scim.ResourceTypes()
+		scimWrite(w, http.StatusOK, scim.NewListResponse(resourceTypes, 1, len(resourceTypes)))
+	}).Methods(http.MethodGet)
+
+	r.HandleFunc("/Users", h.scimListUsers).Methods(http.MethodGet)
+	r.HandleFunc("/Users", h.scimCreateUser).Methods(http.MethodPost)
+	r.HandleFunc("/Users/{id}", h.scimGetUser).Methods(http.MethodGet)
+	r.HandleFunc("/Users/{id}", h.scimReplaceUser).Methods(http.MethodPut)
+	r.HandleFunc("/Users/{id}", h.scimPatchUser).Methods(http.MethodPatch)
+	r.HandleFunc("/Users/{id}", h.scimDeleteUser).Methods(http.MethodDelete)
+
+	r.HandleFunc("/Groups", h.scimListGroups).Methods(http.MethodGet)
+	r.HandleFunc("/Groups", h.scimCreateGroup).Methods(http.MethodPost)
+	r.HandleFunc("/Groups/{id}", h.scimGetGroup).Methods(http.MethodGet)
+	r.HandleFunc("/Groups/{id}", h.scimReplaceGroup).Methods(http.MethodPut)
+	r.HandleFunc("/Groups/{id}", h.scimPatchGroup).Methods(http.MethodPatch)
+	r.HandleFunc("/Groups/{id}", h.scimDeleteGroup).Methods(http.MethodDelete)
+}
+
+// scimAuthorizationMiddleware only lets API keys of the admin or scim scope
+// through, which are not restricted to a set of users.
+func (h *Headscale) scimAuthorizationMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		key, err := h.db.GetAPIKey(scimKeyPrefix(req))
+		if err != nil {
+			scimWriteError(w, scim.NewError(http.StatusUnauthorized, "", "invalid token"))
+
+			return
+		}
+
+		scope := key.EffectiveScope()
+		if scope != types.APIKeyScopeAdmin && scope != types.APIKeyScopeSCIM {
+			scimWriteError(w, scim.NewError(
+				http.StatusForbidden,
+				"",
+				fmt.Sprintf("API key with scope %q cannot provision users", scope),
+			))
+
+			return
+		}
+
+		if key.IsUserRestricted() {
+			scimWriteError(w, scim.NewError(http.StatusForbidden, "", "API key restricted to users cannot provision users"))
+
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
+
+func scimKeyPrefix(req *http.Request) string {
+	prefix, _, _ := strings.Cut(strings.TrimPrefix(req.Header.Get("authorization"), AuthPrefix), ".")
+
+	return prefix
+}
+
+func scimActor(req *http.Request) string {
+	return "api-key:" + scimKeyPrefix(req)
+}
+
+func scimWrite(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", scim.ContentType)
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Error().Err(err).Msg("failed to write SCIM response")
+	}
+}
+
+// scimWriteError writes the error as SCIM error response, errors which are
+// not SCIM errors are logged and hidden from the client.
+func scimWriteError(w http.ResponseWriter, err error) {
+	var scimErr *scim.Error
+	switch {
+	case errors.As(err, &scimErr):
+	case errors.Is(err, db.ErrUserNotFound), errors.Is(err, db.ErrUserGroupNotFound):
+		scimErr = errSCIMNotFound
+	case errors.Is(err, db.ErrUserGroupExists):
+		scimErr = scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, "a group with this name exists")
+	default:
+		log.Error().Err(err).Msg("SCIM request failed")
+		scimErr = scim.NewError(http.StatusInternalServerError, "", "internal server error")
+	}
+
+	scimWrite(w, scimErr.StatusCode(), scimErr)
+}
+
+func scimDecode(req *http.Request, v any) error {
+	if err := json.NewDecoder(req.Body).Decode(v); err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidSyntax, fmt.Sprintf("invalid request body: %s", err))
+	}
+
+	return nil
+}
+
+// scimID parses the ID of the resource in the path.
+func scimID(req *http.Request) (uint64, error) {
+	id, err := strconv.ParseUint(mux.Vars(req)["id"], 10, 64)
+	if err != nil {
+		return 0, errSCIMNotFound
+	}
+
+	return id, nil
+}
+
+func (h *Headscale) scimLocation(resource string, id uint64) string {
+	return fmt.Sprintf("%s%s/%s/%d", h.cfg.ServerURL, scimPath, resource, id)
+}
+
+// scimGroupIDs returns the IDs of the SCIM groups by name.
+func (h *Headscale) scimGroupIDs() (map[string]uint64, error) {
+	groups, err := h.db.ListUserGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[string]uint64, len(groups))
+	for _, group := range groups {
+		ids[group.Name] = group.ID
+	}
+
+	return ids, nil
+}
+
+func (h *Headscale) scimUser(user *types.User, groupIDs map[string]uint64) scim.User {
+	active := !user.Disabled
+	ret := scim.User{
+		Schemas:     []string{scim.SchemaUser},
+		ID:          strconv.FormatUint(uint64(user.ID), 10),
+		ExternalID:  user.ExternalID,
+		UserName:    user.Name,
+		DisplayName: user.DisplayName,
+		Active:      &active,
+		Meta: &scim.Meta{
+			ResourceType: "User",
+			Created:      &user.CreatedAt,
+			LastModified: &user.UpdatedAt,
+			Location:     h.scimLocation("Users", uint64(user.ID)),
+		},
+	}
+
+	if user.DisplayName != "" {
+		ret.Name = &scim.Name{Formatted: user.DisplayName}
+	}
+	if user.Email != "" {
+		ret.Emails = []scim.MultiValue{{Value: user.Email, Type: "work", Primary: true}}
+	}
+	if user.ProfilePicURL != "" {
+		ret.Photos = []scim.MultiValue{{Value: user.ProfilePicURL, Type: "photo"}}
+	}
+
+	for _, name := range user.SCIMGroups {
+		if id, ok := groupIDs[name]; ok {
+			ret.Groups = append(ret.Groups, scim.MultiValue{
+				Value:   strconv.FormatUint(id, 10),
+				Display: name,
+				Ref:     h.scimLocation("Groups", id),
+			})
+		}
+	}
+
+	return ret
+}
+
+// applySCIMUser sets the attributes of the SCIM user on the user, except
+// active which has side effects. The user name must be unique.
+func (h *Headscale) applySCIMUser(user *types.User, u *scim.User) error {
+	if err := util.ValidateUsername(u.UserName); err != nil {
+		return scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, fmt.Sprintf("invalid userName: %s", err))
+	}
+
+	existing, err := h.db.ListUsers(&types.User{Name: u.UserName})
+	if err != nil {
+		return err
+	}
+	for _, other := range existing {
+		if other.ID != user.ID {
+			return scim.NewError(http.StatusConflict, scim.ErrorTypeUniqueness, fmt.Sprintf("user %q exists", u.UserName))
+		}
+	}
+
+	user.Name = u.UserName
+	user.DisplayName = u.FormattedName()
+	user.Email = u.PrimaryEmail()
+	user.ExternalID = u.ExternalID
+	user.ProfilePicURL = ""
+	if len(u.Photos) > 0 {
+		user.ProfilePicURL = u.Photos[0].Value
+	}
+
+	return nil
+}
+
+func (h *Headscale) scimListUsers(w http.ResponseWriter, req *http.Request) {
+	startIndex, count, err := scim.ParsePagination(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	filter, err := scim.ParseFilter(req.URL.Query().Get("filter"))
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err := h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	groupIDs, err := h.scimGroupIDs()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	var matched []scim.User
+	for _, user := range users {
+		u := h.scimUser(&user, groupIDs)
+		match, err := filter.Match(func(attribute string) ([]string, bool) {
+			switch attribute {
+			case "id":
+				return []string{u.ID}, true
+			case "username":
+				return []string{u.UserName}, true
+			case "externalid":
+				return []string{u.ExternalID}, true
+			case "emails", "emails.value":
+				return []string{u.PrimaryEmail()}, true
+			case "active":
+				return []string{strconv.FormatBool(u.IsActive())}, true
+			default:
+				return nil, false
+			}
+		})
+		if err != nil {
+			scimWriteError(w, err)
+			return
+		}
+
+		if match {
+			matched = append(matched, u)
+		}
+	}
+
+	scimWrite(w, http.StatusOK, scim.NewListResponse(matched, startIndex, count))
+}
+
+func (h *Headscale) scimGetUser(w http.ResponseWriter, req *http.Request) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	user, err := h.db.GetUserByID(types.UserID(id))
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	groupIDs, err := h.scimGroupIDs()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusOK, h.scimUser(user, groupIDs))
+}
+
+func (h *Headscale) scimCreateUser(w http.ResponseWriter, req *http.Request) {
+	var u scim.User
+	if err := scimDecode(req, &u); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	newUser := types.User{Provider: util.ProviderSCIM}
+	if err := h.applySCIMUser(&newUser, &u); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+	newUser.Disabled = !u.IsActive()
+
+	user, err := h.db.CreateUser(newUser)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	h.auditLog.Record(scimActor(req), "user.create", userTarget(user.ID), nil, user.Proto())
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusCreated, h.scimUser(user, nil))
+}
+
+func (h *Headscale) scimReplaceUser(w http.ResponseWriter, req *http.Request) {
+	h.scimUpdateUser(w, req, func(u *scim.User) error {
+		var replacement scim.User
+		if err := scimDecode(req, &replacement); err != nil {
+			return err
+		}
+		*u = replacement
+
+		return nil
+	})
+}
+
+func (h *Headscale) scimPatchUser(w http.ResponseWriter, req *http.Request) {
+	h.scimUpdateUser(w, req, func(u *scim.User) error {
+		var patch scim.PatchRequest
+		if err := scimDecode(req, &patch); err != nil {
+			return err
+		}
+
+		return u.Patch(patch.Operations)
+	})
+}
+
+// scimUpdateUser updates the user with the attributes changed by update.
+// Deactivating the user disables it and expires its nodes.
+func (h *Headscale) scimUpdateUser(w http.ResponseWriter, req *http.Request, update func(*scim.User) error) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	user, err := h.db.GetUserByID(types.UserID(id))
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+	before := user.Proto()
+
+	groupIDs, err := h.scimGroupIDs()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	u := h.scimUser(user, groupIDs)
+	if err := update(&u); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	if err := h.applySCIMUser(user, &u); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	wasDisabled := user.Disabled
+	user.Disabled = !u.IsActive()
+
+	now := time.Now()
+	expired, err := db.Write(h.db.DB, func(tx *gorm.DB) (types.Nodes, error) {
+		if err := tx.Save(user).Error; err != nil {
+			return nil, err
+		}
+
+		if user.Disabled && !wasDisabled {
+			return db.ExpireUserNodes(tx, types.UserID(user.ID), now)
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	actor := scimActor(req)
+	h.auditLog.Record(actor, "user.update", userTarget(user.ID), before, user.Proto())
+	for _, node := range expired {
+		h.auditLog.Record(actor, "node.expire", nodeTarget(node.ID), nil, node.Proto())
+	}
+
+	if user.Disabled != wasDisabled {
+		// The nodes of the user are shown as (not) authorized and expired
+		// to their peers.
+		ctx := types.NotifyCtx(context.Background(), "scim-user-disabled", user.Name)
+		h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+	}
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusOK, h.scimUser(user, groupIDs))
+}
+
+// scimDeleteUser deletes the user together with its nodes.
+func (h *Headscale) scimDeleteUser(w http.ResponseWriter, req *http.Request) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	user, err := h.db.GetUserByID(types.UserID(id))
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	nodes, err := db.Write(h.db.DB, func(tx *gorm.DB) (types.Nodes, error) {
+		return db.DestroyUserAndNodes(tx, types.UserID(user.ID))
+	})
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	actor := scimActor(req)
+	for _, node := range nodes {
+		h.auditLog.Record(actor, "node.delete", nodeTarget(node.ID), node.Proto(), nil)
+	}
+	h.auditLog.Record(actor, "user.delete", userTarget(user.ID), user.Proto(), nil)
+
+	if len(nodes) > 0 {
+		ids := make([]types.NodeID, len(nodes))
+		for i, node := range nodes {
+			ids[i] = node.ID
+		}
+
+		ctx := types.NotifyCtx(context.Background(), "scim-user-deleted", user.Name)
+		h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerRemoved(ids...))
+	}
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scimGroup returns the SCIM group with the given users as members if the
+// group is in their groups.
+func (h *Headscale) scimGroup(group *types.UserGroup, users []types.User) scim.Group {
+	ret := scim.Group{
+		Schemas:     []string{scim.SchemaGroup},
+		ID:          strconv.FormatUint(group.ID, 10),
+		ExternalID:  group.ExternalID,
+		DisplayName: group.Name,
+		Meta: &scim.Meta{
+			ResourceType: "Group",
+			Created:      &group.CreatedAt,
+			LastModified: &group.UpdatedAt,
+			Location:     h.scimLocation("Groups", group.ID),
+		},
+	}
+
+	for _, user := range users {
+		if slices.Contains(user.SCIMGroups, group.Name) {
+			ret.Members = append(ret.Members, scim.MultiValue{
+				Value:   strconv.FormatUint(uint64(user.ID), 10),
+				Display: user.Username(),
+				Ref:     h.scimLocation("Users", uint64(user.ID)),
+			})
+		}
+	}
+
+	return ret
+}
+
+// scimGroupMembers returns the IDs of the members of the SCIM group, which
+// must be existing users.
+func scimGroupMembers(g *scim.Group, users []types.User) ([]types.UserID, error) {
+	var ids []types.UserID
+	for _, member := range g.Members {
+		id, err := strconv.ParseUint(member.Value, 10, 64)
+		if err != nil || !slices.ContainsFunc(users, func(user types.User) bool {
+			return uint64(user.ID) == id
+		}) {
+			return nil, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, fmt.Sprintf("unknown member %q", member.Value))
+		}
+		ids = append(ids, types.UserID(id))
+	}
+
+	return ids, nil
+}
+
+func scimGroupProto(group *types.UserGroup, members []types.UserID) *v1.UserGroup {
+	ids := make([]uint64, len(members))
+	for i, id := range members {
+		ids[i] = uint64(id)
+	}
+
+	return group.Proto(ids)
+}
+
+func (h *Headscale) scimListGroups(w http.ResponseWriter, req *http.Request) {
+	startIndex, count, err := scim.ParsePagination(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	filter, err := scim.ParseFilter(req.URL.Query().Get("filter"))
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	groups, err := h.db.ListUserGroups()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err := h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	excludeMembers := strings.Contains(strings.ToLower(req.URL.Query().Get("excludedAttributes")), "members")
+
+	var matched []scim.Group
+	for _, group := range groups {
+		g := h.scimGroup(&group, users)
+		match, err := filter.Match(func(attribute string) ([]string, bool) {
+			switch attribute {
+			case "id":
+				return []string{g.ID}, true
+			case "displayname":
+				return []string{g.DisplayName}, true
+			case "externalid":
+				return []string{g.ExternalID}, true
+			case "members", "members.value":
+				var ids []string
+				for _, member := range g.Members {
+					ids = append(ids, member.Value)
+				}
+
+				return ids, true
+			default:
+				return nil, false
+			}
+		})
+		if err != nil {
+			scimWriteError(w, err)
+			return
+		}
+
+		if match {
+			if excludeMembers {
+				g.Members = nil
+			}
+			matched = append(matched, g)
+		}
+	}
+
+	scimWrite(w, http.StatusOK, scim.NewListResponse(matched, startIndex, count))
+}
+
+func (h *Headscale) scimGetGroup(w http.ResponseWriter, req *http.Request) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	group, err := h.db.GetUserGroup(id)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err := h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusOK, h.scimGroup(group, users))
+}
+
+func (h *Headscale) scimCreateGroup(w http.ResponseWriter, req *http.Request) {
+	var g scim.Group
+	if err := scimDecode(req, &g); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	if g.DisplayName == "" {
+		scimWriteError(w, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "displayName is required"))
+		return
+	}
+
+	users, err := h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	members, err := scimGroupMembers(&g, users)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	group := types.UserGroup{Name: g.DisplayName, ExternalID: g.ExternalID}
+	if err := h.db.CreateUserGroup(&group, members); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	h.auditLog.Record(scimActor(req), "user-group.create", userGroupTarget(group.ID), nil, scimGroupProto(&group, members))
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err = h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusCreated, h.scimGroup(&group, users))
+}
+
+func (h *Headscale) scimReplaceGroup(w http.ResponseWriter, req *http.Request) {
+	h.scimUpdateGroup(w, req, func(g *scim.Group) error {
+		var replacement scim.Group
+		if err := scimDecode(req, &replacement); err != nil {
+			return err
+		}
+		*g = replacement
+
+		return nil
+	})
+}
+
+func (h *Headscale) scimPatchGroup(w http.ResponseWriter, req *http.Request) {
+	h.scimUpdateGroup(w, req, func(g *scim.Group) error {
+		var patch scim.PatchRequest
+		if err := scimDecode(req, &patch); err != nil {
+			return err
+		}
+
+		return g.Patch(patch.Operations)
+	})
+}
+
+// scimUpdateGroup renames the group and replaces its members with the
+// attributes changed by update.
+func (h *Headscale) scimUpdateGroup(w http.ResponseWriter, req *http.Request, update func(*scim.Group) error) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	group, err := h.db.GetUserGroup(id)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err := h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	g := h.scimGroup(group, users)
+	before, err := scimGroupMembers(&g, users)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	if err := update(&g); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	if g.DisplayName == "" {
+		scimWriteError(w, scim.NewError(http.StatusBadRequest, scim.ErrorTypeInvalidValue, "displayName is required"))
+		return
+	}
+
+	members, err := scimGroupMembers(&g, users)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	beforeProto := scimGroupProto(group, before)
+	group.Name = g.DisplayName
+	group.ExternalID = g.ExternalID
+	if err := h.db.UpdateUserGroup(group, members); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	h.auditLog.Record(scimActor(req), "user-group.update", userGroupTarget(group.ID), beforeProto, scimGroupProto(group, members))
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	users, err = h.db.ListUsers()
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	scimWrite(w, http.StatusOK, h.scimGroup(group, users))
+}
+
+func (h *Headscale) scimDeleteGroup(w http.ResponseWriter, req *http.Request) {
+	id, err := scimID(req)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	group, err := h.db.DeleteUserGroup(id)
+	if err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	h.auditLog.Record(scimActor(req), "user-group.delete", userGroupTarget(group.ID), scimGroupProto(group, nil), nil)
+
+	if err := usersChangedHook(h.db, h.polMan, h.nodeNotifier); err != nil {
+		scimWriteError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
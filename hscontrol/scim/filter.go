@@ -0,0 +1,170 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Condition is an equality condition of a filter. Attribute is lowercase,
+// a value filter like members[value eq "1"] has the attribute
+// members.value.
+type Condition struct {
+	Attribute string
+	Value     string
+}
+
+// Filter is a conjunction of equality conditions, the subset of the filter
+// syntax of RFC 7644 section 3.4.2.2 the identity providers use to look up
+// resources, e.g. `userName eq "alice" and active eq true`.
+type Filter []Condition
+
+// ParseFilter parses a filter, an empty filter matches all resources.
+func ParseFilter(s string) (Filter, error) {
+	var filter Filter
+
+	p := filterParser{s: s}
+	for {
+		p.skipSpace()
+		if p.done() {
+			break
+		}
+
+		if len(filter) > 0 {
+			if !strings.EqualFold(p.word(), "and") {
+				return nil, invalidFilter(s, `only "and" is supported to combine conditions`)
+			}
+			p.skipSpace()
+		}
+
+		cond, err := p.condition()
+		if err != nil {
+			return nil, invalidFilter(s, err.Error())
+		}
+		filter = append(filter, cond)
+	}
+
+	return filter, nil
+}
+
+// Match reports if a resource matches the filter. values returns the values
+// of an attribute of the resource, and false if the attribute can't be
+// filtered on. Values are compared case-insensitively.
+func (f Filter) Match(values func(attribute string) ([]string, bool)) (bool, error) {
+	for _, cond := range f {
+		vals, ok := values(cond.Attribute)
+		if !ok {
+			return false, NewError(
+				http.StatusBadRequest,
+				ErrorTypeInvalidFilter,
+				fmt.Sprintf("filtering on %q is not supported", cond.Attribute),
+			)
+		}
+
+		if !slices.ContainsFunc(vals, func(v string) bool {
+			return strings.EqualFold(v, cond.Value)
+		}) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func invalidFilter(filter, detail string) *Error {
+	return NewError(http.StatusBadRequest, ErrorTypeInvalidFilter, fmt.Sprintf("invalid filter %q: %s", filter, detail))
+}
+
+type filterParser struct {
+	s string
+	i int
+}
+
+func (p *filterParser) done() bool {
+	return p.i >= len(p.s)
+}
+
+func (p *filterParser) skipSpace() {
+	for !p.done() && p.s[p.i] == ' ' {
+		p.i++
+	}
+}
+
+// word returns the characters up to the next space or bracket.
+func (p *filterParser) word() string {
+	start := p.i
+	for !p.done() && !strings.ContainsRune(" []", rune(p.s[p.i])) {
+		p.i++
+	}
+
+	return p.s[start:p.i]
+}
+
+// condition parses `attr eq value` or `attr[subattr eq value]`.
+func (p *filterParser) condition() (Condition, error) {
+	attr := p.word()
+	if attr == "" {
+		return Condition{}, fmt.Errorf("expected an attribute at offset %d", p.i)
+	}
+
+	if !p.done() && p.s[p.i] == '[' {
+		p.i++
+		p.skipSpace()
+		sub, err := p.condition()
+		if err != nil {
+			return Condition{}, err
+		}
+		p.skipSpace()
+		if p.done() || p.s[p.i] != ']' {
+			return Condition{}, fmt.Errorf("expected ] at offset %d", p.i)
+		}
+		p.i++
+
+		return Condition{Attribute: strings.ToLower(attr) + "." + sub.Attribute, Value: sub.Value}, nil
+	}
+
+	p.skipSpace()
+	if op := p.word(); !strings.EqualFold(op, "eq") {
+		return Condition{}, fmt.Errorf("operator %q is not supported, only eq is", op)
+	}
+	p.skipSpace()
+
+	value, err := p.value()
+	if err != nil {
+		return Condition{}, err
+	}
+
+	return Condition{Attribute: strings.ToLower(attr), Value: value}, nil
+}
+
+// value parses a JSON string, or a bare value like true or 42.
+func (p *filterParser) value() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("expected a value at offset %d", p.i)
+	}
+
+	if p.s[p.i] != '"' {
+		return p.word(), nil
+	}
+
+	start := p.i
+	for p.i++; !p.done(); p.i++ {
+		switch p.s[p.i] {
+		case '\\':
+			p.i++
+		case '"':
+			p.i++
+
+			var value string
+			if err := json.Unmarshal([]byte(p.s[start:p.i]), &value); err != nil {
+				return "", fmt.Errorf("invalid string at offset %d: %w", start, err)
+			}
+
+			return value, nil
+		}
+	}
+
+	return "", fmt.Errorf("unterminated string at offset %d", start)
+}
@@ -0,0 +1,86 @@
+package scim
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		filter  string
+		want    Filter
+		wantErr bool
+	}{
+		{filter: "", want: nil},
+		{
+			filter: `userName eq "alice@example.com"`,
+			want:   Filter{{Attribute: "username", Value: "alice@example.com"}},
+		},
+		{
+			filter: `externalId eq "a \"quoted\" id" and active eq true`,
+			want: Filter{
+				{Attribute: "externalid", Value: `a "quoted" id`},
+				{Attribute: "active", Value: "true"},
+			},
+		},
+		{
+			filter: `id eq "3" and members[value eq "7"]`,
+			want: Filter{
+				{Attribute: "id", Value: "3"},
+				{Attribute: "members.value", Value: "7"},
+			},
+		},
+		{filter: `userName sw "a"`, wantErr: true},
+		{filter: `userName eq "a" or userName eq "b"`, wantErr: true},
+		{filter: `userName eq "a`, wantErr: true},
+		{filter: `userName eq`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.filter, func(t *testing.T) {
+			got, err := ParseFilter(tt.filter)
+			if tt.wantErr {
+				var scimErr *Error
+				require.ErrorAs(t, err, &scimErr)
+				assert.Equal(t, ErrorTypeInvalidFilter, scimErr.ScimType)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	values := func(attribute string) ([]string, bool) {
+		switch attribute {
+		case "username":
+			return []string{"Alice"}, true
+		case "emails.value", "emails":
+			return []string{"alice@example.com", "a@example.com"}, true
+		default:
+			return nil, false
+		}
+	}
+
+	filter, err := ParseFilter(`userName eq "alice" and emails[value eq "a@example.com"]`)
+	require.NoError(t, err)
+	match, err := filter.Match(values)
+	require.NoError(t, err)
+	assert.True(t, match, "values are compared case-insensitively")
+
+	filter, err = ParseFilter(`userName eq "bob"`)
+	require.NoError(t, err)
+	match, err = filter.Match(values)
+	require.NoError(t, err)
+	assert.False(t, match)
+
+	filter, err = ParseFilter(`title eq "boss"`)
+	require.NoError(t, err)
+	_, err = filter.Match(values)
+	assert.Error(t, err)
+}
@@ -0,0 +1,343 @@
+package scim
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// PatchRequest is the body of a PATCH request, see RFC 7644 section 3.5.2.
+type PatchRequest struct {
+	Schemas    []string         `json:"schemas"`
+	Operations []PatchOperation `json:"Operations"`
+}
+
+// PatchOperation is an operation of a PATCH request. Op is add, replace or
+// remove, the identity providers differ in the case.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// patchPath is a parsed attribute path like name.givenName or
+// emails[type eq "work"].value.
+type patchPath struct {
+	attr   string
+	filter *Condition
+	sub    string
+}
+
+func parsePatchPath(path string) (patchPath, error) {
+	// Attributes can be prefixed with the URN of their schema.
+	for _, schema := range []string{SchemaUser, SchemaGroup} {
+		if len(path) > len(schema) && strings.EqualFold(path[:len(schema)+1], schema+":") {
+			path = path[len(schema)+1:]
+		}
+	}
+
+	var ret patchPath
+	if i := strings.IndexByte(path, '['); i >= 0 {
+		j := strings.LastIndexByte(path, ']')
+		if j < i {
+			return patchPath{}, invalidPath(path)
+		}
+
+		p := filterParser{s: path[i+1 : j]}
+		p.skipSpace()
+		cond, err := p.condition()
+		if err != nil {
+			return patchPath{}, invalidPath(path)
+		}
+		ret.filter = &cond
+		ret.attr = path[:i]
+		ret.sub = strings.TrimPrefix(path[j+1:], ".")
+	} else {
+		ret.attr, ret.sub, _ = strings.Cut(path, ".")
+	}
+
+	ret.attr = strings.ToLower(ret.attr)
+	ret.sub = strings.ToLower(ret.sub)
+
+	return ret, nil
+}
+
+func invalidPath(path string) *Error {
+	return NewError(http.StatusBadRequest, ErrorTypeInvalidPath, fmt.Sprintf("invalid path %q", path))
+}
+
+func invalidValue(path string, err error) *Error {
+	return NewError(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("invalid value for %q: %s", path, err))
+}
+
+// applyPatch applies the operations with the given apply function, which
+// is called once per attribute. Operations without a path set the
+// attributes of the object in their value.
+func applyPatch(ops []PatchOperation, apply func(op string, path patchPath, value json.RawMessage) error) error {
+	for _, operation := range ops {
+		op := strings.ToLower(operation.Op)
+		if op != "add" && op != "replace" && op != "remove" {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidSyntax, fmt.Sprintf("invalid op %q", operation.Op))
+		}
+
+		if operation.Path != "" {
+			path, err := parsePatchPath(operation.Path)
+			if err != nil {
+				return err
+			}
+			if err := apply(op, path, operation.Value); err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		if op == "remove" {
+			return NewError(http.StatusBadRequest, ErrorTypeInvalidPath, "remove requires a path")
+		}
+
+		var attrs map[string]json.RawMessage
+		if err := json.Unmarshal(operation.Value, &attrs); err != nil {
+			return invalidValue("", err)
+		}
+
+		// Sorted for a deterministic result.
+		for _, name := range slices.Sorted(maps.Keys(attrs)) {
+			path, err := parsePatchPath(name)
+			if err != nil {
+				return err
+			}
+			if err := apply(op, path, attrs[name]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Patch applies the operations of a PATCH request to the user. Attributes
+// headscale does not store are ignored.
+func (u *User) Patch(ops []PatchOperation) error {
+	return applyPatch(ops, func(op string, path patchPath, value json.RawMessage) error {
+		str := func(dst *string) error {
+			if op == "remove" {
+				*dst = ""
+				return nil
+			}
+
+			if err := json.Unmarshal(value, dst); err != nil {
+				return invalidValue(path.attr, err)
+			}
+
+			return nil
+		}
+
+		switch path.attr {
+		case "username":
+			if op == "remove" {
+				return NewError(http.StatusBadRequest, ErrorTypeMutability, "userName is required")
+			}
+			return str(&u.UserName)
+		case "displayname":
+			return str(&u.DisplayName)
+		case "externalid":
+			return str(&u.ExternalID)
+		case "active":
+			if op == "remove" {
+				u.Active = nil
+				return nil
+			}
+
+			active, err := decodeBool(value)
+			if err != nil {
+				return invalidValue(path.attr, err)
+			}
+			u.Active = &active
+		case "name":
+			if u.Name == nil {
+				u.Name = &Name{}
+			}
+
+			switch path.sub {
+			case "":
+				if op == "remove" {
+					u.Name = nil
+					return nil
+				}
+
+				var name Name
+				if err := json.Unmarshal(value, &name); err != nil {
+					return invalidValue(path.attr, err)
+				}
+				u.Name = &name
+			case "formatted":
+				return str(&u.Name.Formatted)
+			case "givenname":
+				return str(&u.Name.GivenName)
+			case "familyname":
+				return str(&u.Name.FamilyName)
+			}
+		case "emails":
+			emails, err := patchMultiValue(u.Emails, op, path, value)
+			if err != nil {
+				return err
+			}
+			u.Emails = emails
+		case "photos":
+			photos, err := patchMultiValue(u.Photos, op, path, value)
+			if err != nil {
+				return err
+			}
+			u.Photos = photos
+		}
+
+		return nil
+	})
+}
+
+// Patch applies the operations of a PATCH request to the group.
+func (g *Group) Patch(ops []PatchOperation) error {
+	return applyPatch(ops, func(op string, path patchPath, value json.RawMessage) error {
+		switch path.attr {
+		case "displayname":
+			if op == "remove" {
+				return NewError(http.StatusBadRequest, ErrorTypeMutability, "displayName is required")
+			}
+			if err := json.Unmarshal(value, &g.DisplayName); err != nil {
+				return invalidValue(path.attr, err)
+			}
+		case "externalid":
+			if op == "remove" {
+				g.ExternalID = ""
+				return nil
+			}
+			if err := json.Unmarshal(value, &g.ExternalID); err != nil {
+				return invalidValue(path.attr, err)
+			}
+		case "members":
+			members, err := patchMultiValue(g.Members, op, path, value)
+			if err != nil {
+				return err
+			}
+			g.Members = members
+		}
+
+		return nil
+	})
+}
+
+// patchMultiValue applies an operation to a multi-valued attribute. Items
+// are identified by their value, or selected with a value filter like
+// emails[type eq "work"].value.
+func patchMultiValue(items []MultiValue, op string, path patchPath, value json.RawMessage) ([]MultiValue, error) {
+	if path.filter != nil {
+		matches := func(item MultiValue) bool {
+			var v string
+			switch path.filter.Attribute {
+			case "value":
+				v = item.Value
+			case "type":
+				v = item.Type
+			case "primary":
+				v = strconv.FormatBool(item.Primary)
+			default:
+				return false
+			}
+
+			return strings.EqualFold(v, path.filter.Value)
+		}
+
+		if op == "remove" {
+			return slices.DeleteFunc(items, matches), nil
+		}
+
+		// Only the value of the selected items can be set.
+		if path.sub != "value" {
+			return items, nil
+		}
+
+		var v string
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, invalidValue(path.attr, err)
+		}
+
+		found := false
+		for i := range items {
+			if matches(items[i]) {
+				items[i].Value = v
+				found = true
+			}
+		}
+		if !found {
+			item := MultiValue{Value: v}
+			if path.filter.Attribute == "type" {
+				item.Type = path.filter.Value
+			}
+			items = append(items, item)
+		}
+
+		return items, nil
+	}
+
+	if path.sub != "" {
+		return items, nil
+	}
+
+	var values []MultiValue
+	if len(value) > 0 {
+		if err := json.Unmarshal(value, &values); err != nil {
+			// A single item is accepted as well.
+			var single MultiValue
+			if err := json.Unmarshal(value, &single); err != nil {
+				return nil, invalidValue(path.attr, err)
+			}
+			values = []MultiValue{single}
+		}
+	}
+
+	switch op {
+	case "replace":
+		return values, nil
+	case "remove":
+		if len(values) == 0 {
+			return nil, nil
+		}
+
+		return slices.DeleteFunc(items, func(item MultiValue) bool {
+			return slices.ContainsFunc(values, func(v MultiValue) bool {
+				return v.Value == item.Value
+			})
+		}), nil
+	default:
+		for _, v := range values {
+			if !slices.ContainsFunc(items, func(item MultiValue) bool {
+				return item.Value == v.Value
+			}) {
+				items = append(items, v)
+			}
+		}
+
+		return items, nil
+	}
+}
+
+// decodeBool decodes a JSON boolean, or a string like "True" as sent by
+// some identity providers.
+func decodeBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+
+	var s string
+	if err := json.Unmarshal(value, &s); err != nil {
+		return false, err
+	}
+
+	return strconv.ParseBool(strings.ToLower(s))
+}
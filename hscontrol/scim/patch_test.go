@@ -0,0 +1,89 @@
+package scim
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parsePatch(t *testing.T, body string) []PatchOperation {
+	t.Helper()
+
+	var req PatchRequest
+	require.NoError(t, json.Unmarshal([]byte(body), &req))
+
+	return req.Operations
+}
+
+func TestUserPatch(t *testing.T) {
+	user := User{
+		UserName: "alice@example.com",
+		Emails:   []MultiValue{{Value: "alice@example.com", Type: "work", Primary: true}},
+	}
+
+	// Operations as sent by Microsoft Entra ID.
+	require.NoError(t, user.Patch(parsePatch(t, `{
+		"schemas": ["urn:ietf:params:scim:api:messages:2.0:PatchOp"],
+		"Operations": [
+			{"op": "Replace", "path": "active", "value": "False"},
+			{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "alice@corp.example.com"},
+			{"op": "Add", "path": "name.givenName", "value": "Alice"},
+			{"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "IT"}
+		]
+	}`)))
+	assert.False(t, user.IsActive())
+	assert.Equal(t, "alice@corp.example.com", user.PrimaryEmail())
+	assert.Equal(t, "Alice", user.FormattedName())
+
+	// Operations as sent by Okta, without a path.
+	require.NoError(t, user.Patch(parsePatch(t, `{
+		"Operations": [
+			{"op": "replace", "value": {"active": true, "displayName": "Alice Liddell"}}
+		]
+	}`)))
+	assert.True(t, user.IsActive())
+	assert.Equal(t, "Alice Liddell", user.FormattedName())
+
+	err := user.Patch(parsePatch(t, `{"Operations": [{"op": "remove", "path": "userName"}]}`))
+	var scimErr *Error
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, ErrorTypeMutability, scimErr.ScimType)
+
+	err = user.Patch(parsePatch(t, `{"Operations": [{"op": "move", "path": "userName"}]}`))
+	require.ErrorAs(t, err, &scimErr)
+	assert.Equal(t, ErrorTypeInvalidSyntax, scimErr.ScimType)
+}
+
+func TestGroupPatch(t *testing.T) {
+	group := Group{DisplayName: "platform"}
+
+	require.NoError(t, group.Patch(parsePatch(t, `{
+		"Operations": [
+			{"op": "add", "path": "members", "value": [{"value": "1"}, {"value": "2"}, {"value": "3"}]},
+			{"op": "add", "path": "members", "value": [{"value": "1"}]},
+			{"op": "remove", "path": "members[value eq \"2\"]"},
+			{"op": "remove", "path": "members", "value": [{"value": "3"}]},
+			{"op": "replace", "path": "displayName", "value": "platform-team"}
+		]
+	}`)))
+	assert.Equal(t, []MultiValue{{Value: "1"}}, group.Members)
+	assert.Equal(t, "platform-team", group.DisplayName)
+
+	require.NoError(t, group.Patch(parsePatch(t, `{"Operations": [{"op": "remove", "path": "members"}]}`)))
+	assert.Empty(t, group.Members)
+}
+
+func TestNewListResponse(t *testing.T) {
+	resources := []int{1, 2, 3, 4, 5}
+
+	resp := NewListResponse(resources, 2, 2)
+	assert.Equal(t, 5, resp.TotalResults)
+	assert.Equal(t, 2, resp.ItemsPerPage)
+	assert.Equal(t, []any{2, 3}, resp.Resources)
+
+	resp = NewListResponse(resources, 10, 2)
+	assert.Equal(t, 0, resp.ItemsPerPage)
+	assert.Equal(t, []any{}, resp.Resources)
+}
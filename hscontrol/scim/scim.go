@@ -0,0 +1,256 @@
+// Package scim implements the resources and messages of SCIM 2.0 (RFC 7643
+// and RFC 7644) used by the provisioning endpoint of headscale.
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	SchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SchemaResourceType          = "urn:ietf:params:scim:schemas:core:2.0:ResourceType"
+	SchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// ContentType is the media type of SCIM messages.
+	ContentType = "application/scim+json"
+
+	// MaxResults is the maximum number of resources returned by a list
+	// request.
+	MaxResults = 200
+)
+
+// Meta is the metadata of a resource.
+type Meta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location,omitempty"`
+}
+
+// Name is the name of a user.
+type Name struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+// MultiValue is an item of a multi-valued attribute, like the emails of a
+// user or the members of a group.
+type MultiValue struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// User is a SCIM user resource.
+type User struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	UserName    string       `json:"userName"`
+	Name        *Name        `json:"name,omitempty"`
+	DisplayName string       `json:"displayName,omitempty"`
+	Emails      []MultiValue `json:"emails,omitempty"`
+	Photos      []MultiValue `json:"photos,omitempty"`
+	// Active is nil if the request does not set it, users are active by
+	// default.
+	Active *bool        `json:"active,omitempty"`
+	Groups []MultiValue `json:"groups,omitempty"`
+	Meta   *Meta        `json:"meta,omitempty"`
+}
+
+// IsActive reports if the user is active, which it is unless set otherwise.
+func (u *User) IsActive() bool {
+	return u.Active == nil || *u.Active
+}
+
+// PrimaryEmail returns the primary email of the user, or the first one.
+func (u *User) PrimaryEmail() string {
+	for _, email := range u.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+
+	if len(u.Emails) > 0 {
+		return u.Emails[0].Value
+	}
+
+	return ""
+}
+
+// FormattedName returns the display name of the user, built from the name
+// if it is not set.
+func (u *User) FormattedName() string {
+	switch {
+	case u.DisplayName != "":
+		return u.DisplayName
+	case u.Name == nil:
+		return ""
+	case u.Name.Formatted != "":
+		return u.Name.Formatted
+	case u.Name.GivenName != "" && u.Name.FamilyName != "":
+		return u.Name.GivenName + " " + u.Name.FamilyName
+	default:
+		return u.Name.GivenName + u.Name.FamilyName
+	}
+}
+
+// Group is a SCIM group resource.
+type Group struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	ExternalID  string       `json:"externalId,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []MultiValue `json:"members,omitempty"`
+	Meta        *Meta        `json:"meta,omitempty"`
+}
+
+// ListResponse is the response to a list request.
+type ListResponse struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []any    `json:"Resources"`
+}
+
+// NewListResponse returns the page of the resources starting at the
+// 1-based startIndex with at most count resources.
+func NewListResponse[T any](resources []T, startIndex, count int) ListResponse {
+	resp := ListResponse{
+		Schemas:      []string{SchemaListResponse},
+		TotalResults: len(resources),
+		StartIndex:   startIndex,
+		Resources:    []any{},
+	}
+
+	for i := startIndex - 1; i < len(resources) && len(resp.Resources) < count; i++ {
+		resp.Resources = append(resp.Resources, resources[i])
+	}
+	resp.ItemsPerPage = len(resp.Resources)
+
+	return resp
+}
+
+// ParsePagination returns the startIndex and count query parameters of a
+// list request, with their defaults applied.
+func ParsePagination(req *http.Request) (int, int, error) {
+	startIndex, count := 1, MaxResults
+
+	if s := req.URL.Query().Get("startIndex"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("invalid startIndex %q", s))
+		}
+		startIndex = max(i, 1)
+	}
+
+	if s := req.URL.Query().Get("count"); s != "" {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return 0, 0, NewError(http.StatusBadRequest, ErrorTypeInvalidValue, fmt.Sprintf("invalid count %q", s))
+		}
+		count = min(max(i, 0), MaxResults)
+	}
+
+	return startIndex, count, nil
+}
+
+// The scimType of errors, see RFC 7644 section 3.12.
+const (
+	ErrorTypeInvalidFilter = "invalidFilter"
+	ErrorTypeUniqueness    = "uniqueness"
+	ErrorTypeInvalidSyntax = "invalidSyntax"
+	ErrorTypeInvalidPath   = "invalidPath"
+	ErrorTypeInvalidValue  = "invalidValue"
+	ErrorTypeMutability    = "mutability"
+)
+
+// Error is a SCIM error response.
+type Error struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	ScimType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+func NewError(status int, scimType, detail string) *Error {
+	return &Error{
+		Schemas:  []string{SchemaError},
+		Status:   strconv.Itoa(status),
+		ScimType: scimType,
+		Detail:   detail,
+	}
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("scim error %s: %s", e.Status, e.Detail)
+}
+
+// StatusCode returns the HTTP status code of the error.
+func (e *Error) StatusCode() int {
+	code, err := strconv.Atoi(e.Status)
+	if err != nil {
+		return http.StatusInternalServerError
+	}
+
+	return code
+}
+
+// ServiceProviderConfig describes the features of the SCIM server.
+func ServiceProviderConfig() map[string]any {
+	supported := func(b bool) map[string]any {
+		return map[string]any{"supported": b}
+	}
+
+	return map[string]any{
+		"schemas":        []string{SchemaServiceProviderConfig},
+		"patch":          supported(true),
+		"bulk":           map[string]any{"supported": false, "maxOperations": 0, "maxPayloadSize": 0},
+		"filter":         map[string]any{"supported": true, "maxResults": MaxResults},
+		"changePassword": supported(false),
+		"sort":           supported(false),
+		"etag":           supported(false),
+		"authenticationSchemes": []map[string]any{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "OAuth Bearer Token",
+				"description": "Authentication with a headscale API key",
+				"primary":     true,
+			},
+		},
+		"meta": Meta{ResourceType: "ServiceProviderConfig"},
+	}
+}
+
+// ResourceTypes describes the resources served by the SCIM server.
+func ResourceTypes() []map[string]any {
+	return []map[string]any{
+		{
+			"schemas":  []string{SchemaResourceType},
+			"id":       "User",
+			"name":     "User",
+			"endpoint": "/Users",
+			"schema":   SchemaUser,
+			"meta":     Meta{ResourceType: "ResourceType"},
+		},
+		{
+			"schemas":  []string{SchemaResourceType},
+			"id":       "Group",
+			"name":     "Group",
+			"endpoint": "/Groups",
+			"schema":   SchemaGroup,
+			"meta":     Meta{ResourceType: "ResourceType"},
+		},
+	}
+}
@@ -0,0 +1,203 @@
+package hscontrol
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/juanfont/headscale/hscontrol/scim"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/oauth2-proxy/mockoidc"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+)
+
+func TestSCIM(t *testing.T) {
+	h := newTestHeadscale(t)
+
+	router := mux.NewRouter()
+	h.registerSCIMRoutes(router)
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	expiration := time.Now().Add(time.Hour)
+	scimKey, _, err := h.db.CreateScopedAPIKey(&expiration, types.APIKeyScopeSCIM, nil)
+	require.NoError(t, err)
+	readOnlyKey, _, err := h.db.CreateScopedAPIKey(&expiration, types.APIKeyScopeReadOnly, nil)
+	require.NoError(t, err)
+
+	do := func(apiKey, method, path, body string, out any) int {
+		t.Helper()
+
+		req, err := http.NewRequest(method, srv.URL+scimPath+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", AuthPrefix+apiKey)
+		req.Header.Set("Content-Type", scim.ContentType)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		if out != nil && len(data) > 0 {
+			require.NoError(t, json.Unmarshal(data, out), string(data))
+		}
+
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusForbidden, do(readOnlyKey, http.MethodGet, "/Users", "", nil))
+	assert.Equal(t, http.StatusOK, do(scimKey, http.MethodGet, "/ServiceProviderConfig", "", nil))
+
+	var alice scim.User
+	require.Equal(t, http.StatusCreated, do(scimKey, http.MethodPost, "/Users", `{
+		"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User"],
+		"userName": "alice@example.com",
+		"externalId": "00u1",
+		"name": {"givenName": "Alice", "familyName": "Liddell"},
+		"emails": [{"value": "alice@example.com", "type": "work", "primary": true}],
+		"active": true
+	}`, &alice))
+	assert.Equal(t, "alice@example.com", alice.UserName)
+	assert.True(t, alice.IsActive())
+
+	var scimErr scim.Error
+	assert.Equal(t, http.StatusConflict, do(scimKey, http.MethodPost, "/Users", `{"userName": "alice@example.com"}`, &scimErr))
+	assert.Equal(t, scim.ErrorTypeUniqueness, scimErr.ScimType)
+
+	var list scim.ListResponse
+	filter := url.QueryEscape(`userName eq "Alice@example.com"`)
+	require.Equal(t, http.StatusOK, do(scimKey, http.MethodGet, "/Users?filter="+filter, "", &list))
+	assert.Equal(t, 1, list.TotalResults)
+
+	user, err := h.db.GetUserByName("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, util.ProviderSCIM, user.Provider)
+	assert.Equal(t, "Alice Liddell", user.DisplayName)
+
+	addr := netip.MustParseAddr("100.64.0.1")
+	node := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "laptop",
+		UserID:         user.ID,
+		IPv4:           &addr,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Hostinfo:       &tailcfg.Hostinfo{},
+	}
+	require.NoError(t, h.db.DB.Save(node).Error)
+
+	var group scim.Group
+	require.Equal(t, http.StatusCreated, do(scimKey, http.MethodPost, "/Groups", `{
+		"displayName": "platform",
+		"members": [{"value": "`+alice.ID+`"}]
+	}`, &group))
+	assert.Len(t, group.Members, 1)
+	assert.Equal(t, http.StatusConflict, do(scimKey, http.MethodPost, "/Groups", `{"displayName": "platform"}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(scimKey, http.MethodPost, "/Groups", `{"displayName": "sre", "members": [{"value": "42"}]}`, nil))
+
+	user, err = h.db.GetUserByName("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform"}, user.SCIMGroups)
+
+	require.Equal(t, http.StatusOK, do(scimKey, http.MethodPatch, "/Groups/"+group.ID, `{
+		"Operations": [{"op": "replace", "path": "displayName", "value": "platform-team"}]
+	}`, &group))
+	user, err = h.db.GetUserByName("alice@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"platform-team"}, user.SCIMGroups)
+
+	// Deactivating the user disables it and expires its nodes.
+	require.Equal(t, http.StatusOK, do(scimKey, http.MethodPatch, "/Users/"+alice.ID, `{
+		"Operations": [{"op": "Replace", "path": "active", "value": "False"}]
+	}`, &alice))
+	assert.False(t, alice.IsActive())
+
+	user, err = h.db.GetUserByName("alice@example.com")
+	require.NoError(t, err)
+	assert.True(t, user.Disabled)
+	node, err = h.db.GetNodeByID(node.ID)
+	require.NoError(t, err)
+	assert.True(t, node.IsExpired())
+
+	require.Equal(t, http.StatusNoContent, do(scimKey, http.MethodDelete, "/Groups/"+group.ID, "", nil))
+	user, err = h.db.GetUserByName("alice@example.com")
+	require.NoError(t, err)
+	assert.Empty(t, user.SCIMGroups)
+
+	require.Equal(t, http.StatusNoContent, do(scimKey, http.MethodDelete, "/Users/"+alice.ID, "", nil))
+	assert.Equal(t, http.StatusNotFound, do(scimKey, http.MethodGet, "/Users/"+alice.ID, "", nil))
+	_, err = h.db.GetNodeByID(node.ID)
+	assert.Error(t, err)
+}
+
+func TestSCIMUserOIDCLogin(t *testing.T) {
+	m, err := mockoidc.Run()
+	require.NoError(t, err)
+	defer m.Shutdown()
+
+	h := newTestHeadscale(t)
+
+	cfg := &types.OIDCConfig{
+		Name:         "oidc",
+		Issuer:       m.Issuer(),
+		ClientID:     m.ClientID,
+		ClientSecret: m.ClientSecret,
+		Scope:        []string{"openid", "email", "profile", "groups"},
+		SCIM:         true,
+	}
+	provider, err := NewAuthProviderOIDC(context.Background(), "https://headscale.example.com", cfg, false, h.db, h.nodeNotifier, h.ipAlloc, h.polMan, h.auditLog)
+	require.NoError(t, err)
+
+	scimUser, err := h.db.CreateUser(types.User{
+		Name:        "alice@example.com",
+		DisplayName: "Alice Smith",
+		Email:       "alice@example.com",
+		Provider:    util.ProviderSCIM,
+	})
+	require.NoError(t, err)
+	require.NoError(t, h.db.CreateUserGroup(&types.UserGroup{Name: "platform"}, []types.UserID{types.UserID(scimUser.ID)}))
+
+	login := func() *types.User {
+		user, err := provider.createOrUpdateUserFromClaim(&types.OIDCClaims{
+			Iss:           m.Issuer(),
+			Sub:           "alice",
+			Name:          "alice",
+			Email:         "alice@example.com",
+			EmailVerified: true,
+			Username:      "alice",
+			Groups:        []string{"engineering"},
+		})
+		require.NoError(t, err)
+
+		return user
+	}
+
+	// The groups claim does not replace the SCIM groups, and the
+	// attributes owned by SCIM are kept.
+	for range 2 {
+		user := login()
+		assert.Equal(t, scimUser.ID, user.ID)
+
+		user, err = h.db.GetUserByID(types.UserID(user.ID))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"engineering"}, user.Groups)
+		assert.Equal(t, []string{"platform"}, user.SCIMGroups)
+		assert.Equal(t, []string{"engineering", "platform"}, user.AllGroups())
+		assert.Equal(t, "alice@example.com", user.Name)
+		assert.Equal(t, "Alice Smith", user.DisplayName)
+		assert.Equal(t, util.ProviderSCIM, user.Provider)
+	}
+}
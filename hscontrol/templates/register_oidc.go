@@ -0,0 +1,41 @@
+package templates
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+
+	"github.com/chasefleming/elem-go"
+	"github.com/chasefleming/elem-go/attrs"
+	"github.com/juanfont/headscale/hscontrol/types"
+)
+
+// OIDCProvider is an OIDC provider a user can choose to log in with.
+type OIDCProvider struct {
+	Name        string
+	DisplayName string
+}
+
+// RegisterOIDCProviders lets the user choose the OIDC provider to register
+// the machine with.
+func RegisterOIDCProviders(registrationID types.RegistrationID, providers []OIDCProvider) *elem.Element {
+	items := make([]elem.Node, 0, len(providers))
+	for _, provider := range providers {
+		href := fmt.Sprintf("/register/%s?provider=%s", registrationID.String(), url.QueryEscape(provider.Name))
+		items = append(items, elem.Li(nil,
+			elem.A(attrs.Props{attrs.Href: href}, elem.Text(html.EscapeString(provider.DisplayName))),
+		))
+	}
+
+	return HtmlStructure(
+		elem.Title(nil, elem.Text("Registration - Headscale")),
+		elem.Body(attrs.Props{
+			attrs.Style: bodyStyle.ToInline(),
+		},
+			headerOne("headscale"),
+			headerTwo("Machine registration"),
+			elem.P(nil, elem.Text("Choose how to log in to add this machine to your network:")),
+			elem.Ul(nil, items...),
+		),
+	)
+}
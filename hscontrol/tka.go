@@ -0,0 +1,695 @@
+package hscontrol
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/juanfont/headscale/hscontrol/db"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"tailscale.com/tailcfg"
+	"tailscale.com/tka"
+	"tailscale.com/types/key"
+	"tailscale.com/types/tkatype"
+)
+
+var (
+	errTKANotEnabled     = NewHTTPError(http.StatusBadRequest, types.ErrTKANotEnabled.Error(), nil)
+	errTKAAlreadyEnabled = NewHTTPError(http.StatusConflict, types.ErrTKAAlreadyEnabled.Error(), nil)
+)
+
+// tkaRequestHeader holds the fields common to all the
+// tailnet lock (TKA) requests sent by the nodes.
+type tkaRequestHeader struct {
+	Version tailcfg.CapabilityVersion
+	NodeKey key.NodePublic
+}
+
+// readTKARequest decodes the tailnet lock request into tkaReq and
+// returns the node sending it. If the request cannot be handled, an
+// error is written to the client and false is returned.
+func (ns *noiseServer) readTKARequest(
+	writer http.ResponseWriter,
+	req *http.Request,
+	tkaReq any,
+) (*types.Node, bool) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		httpError(writer, err)
+		return nil, false
+	}
+
+	var header tkaRequestHeader
+	if err := json.Unmarshal(body, &header); err != nil {
+		httpError(writer, NewHTTPError(http.StatusBadRequest, "invalid request", err))
+		return nil, false
+	}
+
+	if rejectUnsupported(writer, header.Version, ns.machineKey, header.NodeKey) {
+		return nil, false
+	}
+
+	if err := json.Unmarshal(body, tkaReq); err != nil {
+		httpError(writer, NewHTTPError(http.StatusBadRequest, "invalid request", err))
+		return nil, false
+	}
+
+	node, err := ns.headscale.db.GetNodeByNodeKey(header.NodeKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			httpError(writer, NewHTTPError(http.StatusNotFound, "node not found", nil))
+			return nil, false
+		}
+		httpError(writer, err)
+		return nil, false
+	}
+
+	// The node key is sent by the client, ensure that it belongs to
+	// the machine on the other end of the Noise connection.
+	if node.MachineKey != ns.machineKey {
+		httpError(writer, NewHTTPError(http.StatusUnauthorized, "node exist with different machine key", nil))
+		return nil, false
+	}
+
+	return node, true
+}
+
+// writeTKAResponse writes the response of a tailnet lock request,
+// or the error if the request failed.
+func writeTKAResponse(writer http.ResponseWriter, resp any, err error) {
+	if err != nil {
+		httpError(writer, err)
+		return
+	}
+
+	respBody, err := json.Marshal(resp)
+	if err != nil {
+		httpError(writer, err)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json; charset=utf-8")
+	writer.WriteHeader(http.StatusOK)
+	writer.Write(respBody)
+}
+
+// NoiseTKAInitBeginHandler handles /machine/tka/init/begin, the first
+// step of enabling tailnet lock, where a node proposes a genesis AUM.
+func (ns *noiseServer) NoiseTKAInitBeginHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKAInitBeginRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaInitBegin(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKAInitFinishHandler handles /machine/tka/init/finish, where the
+// node sends the signatures of all the nodes and tailnet lock is enabled.
+func (ns *noiseServer) NoiseTKAInitFinishHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKAInitFinishRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaInitFinish(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKABootstrapHandler handles /machine/tka/bootstrap, which nodes
+// use to enable or disable tailnet lock locally.
+func (ns *noiseServer) NoiseTKABootstrapHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKABootstrapRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaBootstrap(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKASyncOfferHandler handles /machine/tka/sync/offer, the first
+// step of synchronising the AUMs between the node and headscale.
+func (ns *noiseServer) NoiseTKASyncOfferHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKASyncOfferRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaSyncOffer(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKASyncSendHandler handles /machine/tka/sync/send, where the node
+// sends the AUMs headscale is missing.
+func (ns *noiseServer) NoiseTKASyncSendHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKASyncSendRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaSyncSend(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKADisableHandler handles /machine/tka/disable, which disables
+// tailnet lock given a valid disablement secret.
+func (ns *noiseServer) NoiseTKADisableHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKADisableRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaDisable(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKASignHandler handles /machine/tka/sign, where a node with a
+// trusted tailnet lock key submits the signature of a node key.
+func (ns *noiseServer) NoiseTKASignHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKASubmitSignatureRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaSubmitSignature(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// NoiseTKAAffectedSigsHandler handles /machine/tka/affected-sigs, which
+// returns the node key signatures made by a given tailnet lock key.
+func (ns *noiseServer) NoiseTKAAffectedSigsHandler(writer http.ResponseWriter, req *http.Request) {
+	var tkaReq tailcfg.TKASignaturesUsingKeyRequest
+	node, ok := ns.readTKARequest(writer, req, &tkaReq)
+	if !ok {
+		return
+	}
+
+	resp, err := ns.headscale.tkaSignaturesUsingKey(node, tkaReq)
+	writeTKAResponse(writer, resp, err)
+}
+
+// currentTKAState returns a copy of the tailnet lock state, to be
+// changed and saved with setTKAState. It is assumed that the caller holds
+// tkaMu.
+func (h *Headscale) currentTKAState() *types.TKAState {
+	state := *h.tkaState
+
+	return &state
+}
+
+// setTKAState sets the tailnet lock state after it has been saved, and
+// the tailnet lock info sent to the nodes. It is assumed that the caller
+// holds tkaMu.
+func (h *Headscale) setTKAState(state *types.TKAState) {
+	h.tkaState = state
+	if h.mapper != nil {
+		h.mapper.SetTKAInfo(state.TKAInfo())
+	}
+}
+
+// tkaEnabledState returns the tailnet lock state, or an error
+// if tailnet lock is not enabled. It is assumed that the caller holds
+// tkaMu.
+func (h *Headscale) tkaEnabledState() (*types.TKAState, error) {
+	state := h.currentTKAState()
+	if !state.Enabled {
+		return nil, errTKANotEnabled
+	}
+
+	return state, nil
+}
+
+func (h *Headscale) tkaNotifyAll(origin string) {
+	ctx := types.NotifyCtx(context.Background(), origin, "na")
+	h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+}
+
+func (h *Headscale) tkaInitBegin(
+	node *types.Node,
+	req tailcfg.TKAInitBeginRequest,
+) (*tailcfg.TKAInitBeginResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	state := h.currentTKAState()
+
+	if state.Enabled {
+		return nil, errTKAAlreadyEnabled
+	}
+
+	var genesis tka.AUM
+	if err := genesis.Unserialize(req.GenesisAUM); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid genesis AUM", err)
+	}
+
+	// Validate the genesis AUM without storing it, it is only
+	// committed when all the node keys have been signed.
+	if _, err := tka.Bootstrap(&tka.Mem{}, genesis); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid genesis AUM", err)
+	}
+
+	state.GenesisAUM = req.GenesisAUM
+	if err := db.SaveTKAState(h.db.DB, state); err != nil {
+		return nil, fmt.Errorf("saving tailnet lock state: %w", err)
+	}
+	h.setTKAState(state)
+
+	nodes, err := h.db.ListNodes()
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	resp := &tailcfg.TKAInitBeginResponse{}
+	for _, n := range nodes {
+		info := tailcfg.TKASignInfo{
+			NodeID:     n.ID.NodeID(),
+			NodePublic: n.NodeKey,
+		}
+		if !n.NLKey.IsZero() {
+			info.RotationPubkey = n.NLKey.KeyID()
+		}
+
+		resp.NeedSignatures = append(resp.NeedSignatures, info)
+	}
+
+	log.Info().
+		Caller().
+		Str("node", node.Hostname).
+		Int("need_signatures", len(resp.NeedSignatures)).
+		Msg("Tailnet lock initialisation started")
+
+	return resp, nil
+}
+
+func (h *Headscale) tkaInitFinish(
+	node *types.Node,
+	req tailcfg.TKAInitFinishRequest,
+) (*tailcfg.TKAInitFinishResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	state := h.currentTKAState()
+
+	if state.Enabled {
+		return nil, errTKAAlreadyEnabled
+	}
+
+	if len(state.GenesisAUM) == 0 {
+		return nil, NewHTTPError(http.StatusBadRequest, "tailnet lock initialisation has not been started", nil)
+	}
+
+	var genesis tka.AUM
+	if err := genesis.Unserialize(state.GenesisAUM); err != nil {
+		return nil, fmt.Errorf("unserializing genesis AUM: %w", err)
+	}
+
+	authority, err := tka.Bootstrap(&tka.Mem{}, genesis)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrapping tailnet lock: %w", err)
+	}
+
+	nodes, err := h.db.ListNodes()
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	// Every node must be signed before tailnet lock is enforced,
+	// otherwise they lose connectivity to all the other nodes.
+	for _, n := range nodes {
+		sig, ok := req.Signatures[n.ID.NodeID()]
+		if !ok {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("missing signature for node %d", n.ID), nil)
+		}
+
+		if err := authority.NodeKeyAuthorized(n.NodeKey, sig); err != nil {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid signature for node %d", n.ID), err)
+		}
+	}
+
+	state, err = db.Write(h.db.DB, func(tx *gorm.DB) (*types.TKAState, error) {
+		// Remove anything left behind by a previous
+		// enablement of tailnet lock.
+		if err := db.ResetTKA(tx); err != nil {
+			return nil, err
+		}
+
+		state.Enabled = true
+		state.DisablementSecret = nil
+		state.SupportDisablement = req.SupportDisablement
+		state.LastActiveAncestor = ""
+		if err := db.SaveTKAState(tx, state); err != nil {
+			return nil, err
+		}
+
+		authority, err := tka.Bootstrap(db.TKAChonk(tx), genesis)
+		if err != nil {
+			return nil, fmt.Errorf("bootstrapping tailnet lock: %w", err)
+		}
+
+		for _, n := range nodes {
+			if err := db.NodeSetKeySignature(tx, n.ID, req.Signatures[n.ID.NodeID()]); err != nil {
+				return nil, fmt.Errorf("storing signature for node %d: %w", n.ID, err)
+			}
+		}
+
+		// Bootstrap updates the state in the database,
+		// so read it back before setting the head.
+		state, err := db.GetTKAState(tx)
+		if err != nil {
+			return nil, err
+		}
+		state.Head = authority.Head().String()
+
+		return state, db.SaveTKAState(tx, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.setTKAState(state)
+
+	log.Info().
+		Caller().
+		Str("node", node.Hostname).
+		Msg("Tailnet lock enabled")
+
+	h.tkaNotifyAll("tka-init-finish")
+
+	return &tailcfg.TKAInitFinishResponse{}, nil
+}
+
+func (h *Headscale) tkaBootstrap(
+	node *types.Node,
+	req tailcfg.TKABootstrapRequest,
+) (*tailcfg.TKABootstrapResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	state := h.currentTKAState()
+
+	if state.Enabled {
+		return &tailcfg.TKABootstrapResponse{
+			GenesisAUM: state.GenesisAUM,
+		}, nil
+	}
+
+	return &tailcfg.TKABootstrapResponse{
+		DisablementSecret: state.DisablementSecret,
+	}, nil
+}
+
+func toSyncOffer(head string, ancestors []string) (tka.SyncOffer, error) {
+	var offer tka.SyncOffer
+	if err := offer.Head.UnmarshalText([]byte(head)); err != nil {
+		return tka.SyncOffer{}, fmt.Errorf("parsing head: %w", err)
+	}
+
+	offer.Ancestors = make([]tka.AUMHash, len(ancestors))
+	for i, ancestor := range ancestors {
+		if err := offer.Ancestors[i].UnmarshalText([]byte(ancestor)); err != nil {
+			return tka.SyncOffer{}, fmt.Errorf("parsing ancestor %d: %w", i, err)
+		}
+	}
+
+	return offer, nil
+}
+
+func fromSyncOffer(offer tka.SyncOffer) (string, []string) {
+	ancestors := make([]string, len(offer.Ancestors))
+	for i, ancestor := range offer.Ancestors {
+		ancestors[i] = ancestor.String()
+	}
+
+	return offer.Head.String(), ancestors
+}
+
+func (h *Headscale) tkaSyncOffer(
+	node *types.Node,
+	req tailcfg.TKASyncOfferRequest,
+) (*tailcfg.TKASyncOfferResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	if _, err := h.tkaEnabledState(); err != nil {
+		return nil, err
+	}
+
+	remoteOffer, err := toSyncOffer(req.Head, req.Ancestors)
+	if err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid sync offer", err)
+	}
+
+	chonk := db.TKAChonk(h.db.DB)
+	authority, err := tka.Open(chonk)
+	if err != nil {
+		return nil, fmt.Errorf("opening tailnet lock authority: %w", err)
+	}
+
+	offer, err := authority.SyncOffer(chonk)
+	if err != nil {
+		return nil, fmt.Errorf("computing sync offer: %w", err)
+	}
+
+	resp := &tailcfg.TKASyncOfferResponse{}
+	resp.Head, resp.Ancestors = fromSyncOffer(offer)
+
+	if remoteOffer.Head == offer.Head {
+		return resp, nil
+	}
+
+	missing, err := authority.MissingAUMs(chonk, remoteOffer)
+	if err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "computing missing AUMs", err)
+	}
+
+	for _, aum := range missing {
+		resp.MissingAUMs = append(resp.MissingAUMs, aum.Serialize())
+	}
+
+	return resp, nil
+}
+
+func (h *Headscale) tkaSyncSend(
+	node *types.Node,
+	req tailcfg.TKASyncSendRequest,
+) (*tailcfg.TKASyncSendResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	state, err := h.tkaEnabledState()
+	if err != nil {
+		return nil, err
+	}
+
+	aums := make([]tka.AUM, len(req.MissingAUMs))
+	for i, aum := range req.MissingAUMs {
+		if err := aums[i].Unserialize(aum); err != nil {
+			return nil, NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid AUM %d", i), err)
+		}
+	}
+
+	oldHead := state.Head
+
+	state, err = db.Write(h.db.DB, func(tx *gorm.DB) (*types.TKAState, error) {
+		chonk := db.TKAChonk(tx)
+		authority, err := tka.Open(chonk)
+		if err != nil {
+			return nil, fmt.Errorf("opening tailnet lock authority: %w", err)
+		}
+
+		if len(aums) > 0 {
+			if err := authority.Inform(chonk, aums); err != nil {
+				return nil, NewHTTPError(http.StatusBadRequest, "applying AUMs", err)
+			}
+		}
+
+		state, err := db.GetTKAState(tx)
+		if err != nil {
+			return nil, err
+		}
+		state.Head = authority.Head().String()
+
+		return state, db.SaveTKAState(tx, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.setTKAState(state)
+
+	head := state.Head
+
+	if head != oldHead {
+		log.Info().
+			Caller().
+			Str("node", node.Hostname).
+			Str("head", head).
+			Msg("Tailnet lock head updated")
+
+		h.tkaNotifyAll("tka-sync-send")
+	}
+
+	return &tailcfg.TKASyncSendResponse{Head: head}, nil
+}
+
+func (h *Headscale) tkaDisable(
+	node *types.Node,
+	req tailcfg.TKADisableRequest,
+) (*tailcfg.TKADisableResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	if _, err := h.tkaEnabledState(); err != nil {
+		return nil, err
+	}
+
+	authority, err := tka.Open(db.TKAChonk(h.db.DB))
+	if err != nil {
+		return nil, fmt.Errorf("opening tailnet lock authority: %w", err)
+	}
+
+	if !authority.ValidDisablement(req.DisablementSecret) {
+		return nil, NewHTTPError(http.StatusForbidden, "invalid disablement secret", nil)
+	}
+
+	state, err := db.Write(h.db.DB, func(tx *gorm.DB) (*types.TKAState, error) {
+		if err := db.ResetTKA(tx); err != nil {
+			return nil, err
+		}
+
+		state, err := db.GetTKAState(tx)
+		if err != nil {
+			return nil, err
+		}
+
+		// The disablement secret is kept, the nodes fetch it to
+		// verify that tailnet lock has been disabled.
+		state.Enabled = false
+		state.Head = ""
+		state.GenesisAUM = nil
+		state.SupportDisablement = nil
+		state.LastActiveAncestor = ""
+		state.DisablementSecret = req.DisablementSecret
+
+		return state, db.SaveTKAState(tx, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+	h.setTKAState(state)
+
+	log.Info().
+		Caller().
+		Str("node", node.Hostname).
+		Msg("Tailnet lock disabled")
+
+	h.tkaNotifyAll("tka-disable")
+
+	return &tailcfg.TKADisableResponse{}, nil
+}
+
+func (h *Headscale) tkaSubmitSignature(
+	node *types.Node,
+	req tailcfg.TKASubmitSignatureRequest,
+) (*tailcfg.TKASubmitSignatureResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	if _, err := h.tkaEnabledState(); err != nil {
+		return nil, err
+	}
+
+	var sig tka.NodeKeySignature
+	if err := sig.Unserialize(req.Signature); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid signature", err)
+	}
+
+	var nodeKey key.NodePublic
+	if err := nodeKey.UnmarshalBinary(sig.Pubkey); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid signature node key", err)
+	}
+
+	authority, err := tka.Open(db.TKAChonk(h.db.DB))
+	if err != nil {
+		return nil, fmt.Errorf("opening tailnet lock authority: %w", err)
+	}
+
+	if err := authority.NodeKeyAuthorized(nodeKey, req.Signature); err != nil {
+		return nil, NewHTTPError(http.StatusBadRequest, "signature is not authorized", err)
+	}
+
+	signed, err := h.db.GetNodeByNodeKey(nodeKey)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, NewHTTPError(http.StatusNotFound, "signed node not found", nil)
+		}
+
+		return nil, err
+	}
+
+	if err := db.NodeSetKeySignature(h.db.DB, signed.ID, req.Signature); err != nil {
+		return nil, fmt.Errorf("storing signature: %w", err)
+	}
+
+	log.Info().
+		Caller().
+		Str("node", node.Hostname).
+		Str("signed_node", signed.Hostname).
+		Msg("Tailnet lock signature submitted")
+
+	ctx := types.NotifyCtx(context.Background(), "tka-sign", signed.Hostname)
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerChanged(signed.ID))
+
+	return &tailcfg.TKASubmitSignatureResponse{}, nil
+}
+
+func (h *Headscale) tkaSignaturesUsingKey(
+	node *types.Node,
+	req tailcfg.TKASignaturesUsingKeyRequest,
+) (*tailcfg.TKASignaturesUsingKeyResponse, error) {
+	h.tkaMu.Lock()
+	defer h.tkaMu.Unlock()
+
+	if _, err := h.tkaEnabledState(); err != nil {
+		return nil, err
+	}
+
+	nodes, err := h.db.ListNodes()
+	if err != nil {
+		return nil, fmt.Errorf("listing nodes: %w", err)
+	}
+
+	resp := &tailcfg.TKASignaturesUsingKeyResponse{}
+	for _, n := range nodes {
+		if len(n.KeySignature) == 0 {
+			continue
+		}
+
+		var sig tka.NodeKeySignature
+		if err := sig.Unserialize(n.KeySignature); err != nil {
+			continue
+		}
+
+		keyID, err := sig.UnverifiedAuthorizingKeyID()
+		if err != nil || !bytes.Equal(keyID, req.KeyID) {
+			continue
+		}
+
+		resp.Signatures = append(resp.Signatures, tkatype.MarshaledSignature(n.KeySignature))
+	}
+
+	return resp, nil
+}
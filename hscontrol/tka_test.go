@@ -0,0 +1,193 @@
+package hscontrol
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/tka"
+	"tailscale.com/types/key"
+	"tailscale.com/types/tkatype"
+)
+
+func tkaSignNode(t *testing.T, signer key.NLPrivate, node *types.Node) tkatype.MarshaledSignature {
+	t.Helper()
+
+	pub, err := node.NodeKey.MarshalBinary()
+	require.NoError(t, err)
+
+	sig := tka.NodeKeySignature{
+		SigKind: tka.SigDirect,
+		KeyID:   signer.KeyID(),
+		Pubkey:  pub,
+	}
+	sig.Signature, err = signer.SignNKS(sig.SigHash())
+	require.NoError(t, err)
+
+	return sig.Serialize()
+}
+
+func TestTKALifecycle(t *testing.T) {
+	h := newTestHeadscale(t)
+
+	user, err := h.db.CreateUser(types.User{Name: "tka"})
+	require.NoError(t, err)
+
+	nlPriv := key.NewNLPrivate()
+	disablementSecret := bytes.Repeat([]byte{0xa5}, 32)
+
+	var nodes types.Nodes
+	for _, hostname := range []string{"node1", "node2"} {
+		node := &types.Node{
+			MachineKey:     key.NewMachine().Public(),
+			NodeKey:        key.NewNode().Public(),
+			Hostname:       hostname,
+			UserID:         user.ID,
+			RegisterMethod: util.RegisterMethodAuthKey,
+			Hostinfo:       &tailcfg.Hostinfo{},
+		}
+		require.NoError(t, h.db.DB.Save(node).Error)
+		nodes = append(nodes, node)
+	}
+	nodes[0].NLKey = nlPriv.Public()
+	require.NoError(t, h.db.DB.Save(nodes[0]).Error)
+
+	_, err = h.tkaSyncOffer(nodes[0], tailcfg.TKASyncOfferRequest{})
+	requireHTTPErrorCode(t, err, http.StatusBadRequest)
+
+	// Enable tailnet lock, trusting nlPriv.
+	_, genesis, err := tka.Create(&tka.Mem{}, tka.State{
+		Keys: []tka.Key{
+			{Kind: tka.Key25519, Public: nlPriv.Public().Verifier(), Votes: 2},
+		},
+		DisablementSecrets: [][]byte{tka.DisablementKDF(disablementSecret)},
+	}, nlPriv)
+	require.NoError(t, err)
+
+	_, err = h.tkaInitFinish(nodes[0], tailcfg.TKAInitFinishRequest{})
+	requireHTTPErrorCode(t, err, http.StatusBadRequest)
+
+	beginResp, err := h.tkaInitBegin(nodes[0], tailcfg.TKAInitBeginRequest{
+		GenesisAUM: genesis.Serialize(),
+	})
+	require.NoError(t, err)
+	require.Len(t, beginResp.NeedSignatures, 2)
+	require.Equal(t, nodes[0].NodeKey, beginResp.NeedSignatures[0].NodePublic)
+	require.Equal(t, []byte(nlPriv.KeyID()), beginResp.NeedSignatures[0].RotationPubkey)
+	require.Empty(t, beginResp.NeedSignatures[1].RotationPubkey)
+
+	_, err = h.tkaInitFinish(nodes[0], tailcfg.TKAInitFinishRequest{
+		Signatures: map[tailcfg.NodeID]tkatype.MarshaledSignature{
+			nodes[0].ID.NodeID(): tkaSignNode(t, nlPriv, nodes[0]),
+		},
+	})
+	requireHTTPErrorCode(t, err, http.StatusBadRequest)
+
+	_, err = h.tkaInitFinish(nodes[0], tailcfg.TKAInitFinishRequest{
+		Signatures: map[tailcfg.NodeID]tkatype.MarshaledSignature{
+			nodes[0].ID.NodeID(): tkaSignNode(t, nlPriv, nodes[0]),
+			nodes[1].ID.NodeID(): tkaSignNode(t, nlPriv, nodes[1]),
+		},
+	})
+	require.NoError(t, err)
+
+	state, err := h.db.GetTKAState()
+	require.NoError(t, err)
+	require.Equal(t, &tailcfg.TKAInfo{Head: genesis.Hash().String()}, state.TKAInfo())
+	require.Equal(t, state.TKAInfo(), h.tkaState.TKAInfo(), "the state in memory is updated")
+
+	signed, err := h.db.GetNodeByID(nodes[1].ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, signed.KeySignature)
+
+	_, err = h.tkaInitBegin(nodes[0], tailcfg.TKAInitBeginRequest{
+		GenesisAUM: genesis.Serialize(),
+	})
+	requireHTTPErrorCode(t, err, http.StatusConflict)
+
+	bootstrapResp, err := h.tkaBootstrap(nodes[1], tailcfg.TKABootstrapRequest{})
+	require.NoError(t, err)
+	require.Equal(t, genesis.Serialize(), bootstrapResp.GenesisAUM)
+
+	// A node adds a key and synchronises it with headscale.
+	clientChonk := &tka.Mem{}
+	clientAuthority, err := tka.Bootstrap(clientChonk, genesis)
+	require.NoError(t, err)
+
+	newKey := key.NewNLPrivate()
+	updater := clientAuthority.NewUpdater(nlPriv)
+	require.NoError(t, updater.AddKey(tka.Key{Kind: tka.Key25519, Public: newKey.Public().Verifier(), Votes: 1}))
+	updates, err := updater.Finalize(clientChonk)
+	require.NoError(t, err)
+	require.NoError(t, clientAuthority.Inform(clientChonk, updates))
+
+	clientOffer, err := clientAuthority.SyncOffer(clientChonk)
+	require.NoError(t, err)
+	head, ancestors := fromSyncOffer(clientOffer)
+
+	offerResp, err := h.tkaSyncOffer(nodes[0], tailcfg.TKASyncOfferRequest{
+		Head:      head,
+		Ancestors: ancestors,
+	})
+	require.NoError(t, err)
+	require.Equal(t, genesis.Hash().String(), offerResp.Head)
+	require.Empty(t, offerResp.MissingAUMs)
+
+	serverOffer, err := toSyncOffer(offerResp.Head, offerResp.Ancestors)
+	require.NoError(t, err)
+	missing, err := clientAuthority.MissingAUMs(clientChonk, serverOffer)
+	require.NoError(t, err)
+	require.Len(t, missing, 1)
+
+	sendResp, err := h.tkaSyncSend(nodes[0], tailcfg.TKASyncSendRequest{
+		Head:        head,
+		MissingAUMs: []tkatype.MarshaledAUM{missing[0].Serialize()},
+	})
+	require.NoError(t, err)
+	require.Equal(t, clientAuthority.Head().String(), sendResp.Head)
+
+	// The new key can sign nodes.
+	_, err = h.tkaSubmitSignature(nodes[0], tailcfg.TKASubmitSignatureRequest{
+		Signature: tkaSignNode(t, newKey, nodes[1]),
+	})
+	require.NoError(t, err)
+
+	_, err = h.tkaSubmitSignature(nodes[0], tailcfg.TKASubmitSignatureRequest{
+		Signature: tkaSignNode(t, key.NewNLPrivate(), nodes[1]),
+	})
+	requireHTTPErrorCode(t, err, http.StatusBadRequest)
+
+	sigsResp, err := h.tkaSignaturesUsingKey(nodes[0], tailcfg.TKASignaturesUsingKeyRequest{
+		KeyID: nlPriv.KeyID(),
+	})
+	require.NoError(t, err)
+	require.Len(t, sigsResp.Signatures, 1)
+
+	// Disable tailnet lock.
+	_, err = h.tkaDisable(nodes[0], tailcfg.TKADisableRequest{
+		DisablementSecret: []byte("wrong"),
+	})
+	requireHTTPErrorCode(t, err, http.StatusForbidden)
+
+	_, err = h.tkaDisable(nodes[0], tailcfg.TKADisableRequest{
+		DisablementSecret: disablementSecret,
+	})
+	require.NoError(t, err)
+
+	state, err = h.db.GetTKAState()
+	require.NoError(t, err)
+	require.Equal(t, &tailcfg.TKAInfo{Disabled: true}, state.TKAInfo())
+	require.Equal(t, state.TKAInfo(), h.tkaState.TKAInfo(), "the state in memory is updated")
+
+	bootstrapResp, err = h.tkaBootstrap(nodes[1], tailcfg.TKABootstrapRequest{})
+	require.NoError(t, err)
+	require.Equal(t, disablementSecret, bootstrapResp.DisablementSecret)
+
+	signed, err = h.db.GetNodeByID(nodes[1].ID)
+	require.NoError(t, err)
+	require.Empty(t, signed.KeySignature)
+}
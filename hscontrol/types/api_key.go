@@ -1,12 +1,58 @@
 package types
 
 import (
+	"fmt"
+	"slices"
 	"time"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// APIKeyScope limits which parts of the API an API key can use.
+type APIKeyScope string
+
+const (
+	// APIKeyScopeAdmin gives full access to the API.
+	APIKeyScopeAdmin APIKeyScope = "admin"
+	// APIKeyScopeReadOnly allows listing users, nodes, pre auth keys,
+	// the policy and the audit log.
+	APIKeyScopeReadOnly APIKeyScope = "read-only"
+	// APIKeyScopeNodeOperator allows reading and changing nodes.
+	APIKeyScopeNodeOperator APIKeyScope = "node-operator"
+	// APIKeyScopeKeyIssuer allows creating and expiring pre auth keys.
+	APIKeyScopeKeyIssuer APIKeyScope = "key-issuer"
+	// APIKeyScopePolicyAdmin allows reading and changing the policy.
+	APIKeyScopePolicyAdmin APIKeyScope = "policy-admin"
+	// APIKeyScopeSCIM allows provisioning users and groups via SCIM, and
+	// nothing else.
+	APIKeyScopeSCIM APIKeyScope = "scim"
+)
+
+var APIKeyScopes = []APIKeyScope{
+	APIKeyScopeAdmin,
+	APIKeyScopeReadOnly,
+	APIKeyScopeNodeOperator,
+	APIKeyScopeKeyIssuer,
+	APIKeyScopePolicyAdmin,
+	APIKeyScopeSCIM,
+}
+
+// ParseAPIKeyScope parses an API key scope, an empty string is the admin
+// scope.
+func ParseAPIKeyScope(s string) (APIKeyScope, error) {
+	if s == "" {
+		return APIKeyScopeAdmin, nil
+	}
+
+	scope := APIKeyScope(s)
+	if !slices.Contains(APIKeyScopes, scope) {
+		return "", fmt.Errorf("invalid API key scope %q, must be one of %v", s, APIKeyScopes)
+	}
+
+	return scope, nil
+}
+
 // APIKey describes the datamodel for API keys used to remotely authenticate with
 // headscale.
 type APIKey struct {
@@ -14,15 +60,43 @@ type APIKey struct {
 	Prefix string `gorm:"uniqueIndex"`
 	Hash   []byte
 
+	// Scope limits the API calls the key can make, keys without scope
+	// are admin keys.
+	Scope APIKeyScope
+	// UserIDs restricts the key to the given users, the key has access
+	// to all users if empty.
+	UserIDs []uint64 `gorm:"serializer:json"`
+
 	CreatedAt  *time.Time
 	Expiration *time.Time
 	LastSeen   *time.Time
 }
 
+// EffectiveScope returns the scope of the key, admin if unset.
+func (key *APIKey) EffectiveScope() APIKeyScope {
+	if key.Scope == "" {
+		return APIKeyScopeAdmin
+	}
+
+	return key.Scope
+}
+
+// IsUserRestricted reports whether the key is restricted to a set of users.
+func (key *APIKey) IsUserRestricted() bool {
+	return len(key.UserIDs) > 0
+}
+
+// CanAccessUser reports whether the key can act on the given user.
+func (key *APIKey) CanAccessUser(id uint64) bool {
+	return !key.IsUserRestricted() || slices.Contains(key.UserIDs, id)
+}
+
 func (key *APIKey) Proto() *v1.ApiKey {
 	protoKey := v1.ApiKey{
-		Id:     key.ID,
-		Prefix: key.Prefix,
+		Id:      key.ID,
+		Prefix:  key.Prefix,
+		Scope:   string(key.EffectiveScope()),
+		UserIds: key.UserIDs,
 	}
 
 	if key.Expiration != nil {
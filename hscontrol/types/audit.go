@@ -0,0 +1,53 @@
+package types
+
+import (
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// AuditEvent records a change made to the tailnet by an administrator or
+// by the registration of a node.
+type AuditEvent struct {
+	ID        uint64    `gorm:"primary_key"         json:"id,omitempty"`
+	CreatedAt time.Time `gorm:"index"               json:"time"`
+
+	// Actor is who made the change, for example "api-key:<prefix>",
+	// "unix-socket", "preauthkey:<id>" or "oidc:<user>".
+	Actor string `gorm:"index" json:"actor"`
+
+	// Action is what was changed, for example "user.create" or "node.delete".
+	Action string `gorm:"index" json:"action"`
+
+	// Target is the object that was changed, for example "node:1".
+	Target string `gorm:"index" json:"target,omitempty"`
+
+	// Before and After contain the JSON representation of the target
+	// before and after the change, they are empty if the target did not
+	// exist.
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+func (e *AuditEvent) Proto() *v1.AuditEvent {
+	return &v1.AuditEvent{
+		Id:     e.ID,
+		Time:   timestamppb.New(e.CreatedAt),
+		Actor:  e.Actor,
+		Action: e.Action,
+		Target: e.Target,
+		Before: e.Before,
+		After:  e.After,
+	}
+}
+
+// AuditEventFilter selects audit events, empty fields match all events.
+type AuditEventFilter struct {
+	Actor  string
+	Action string
+	Target string
+	Since  time.Time
+	Until  time.Time
+	Limit  int
+}
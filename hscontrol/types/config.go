@@ -7,7 +7,10 @@ import (
 	"net/netip"
 	"net/url"
 	"os"
+	"regexp"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/coreos/go-oidc/v3/oidc"
@@ -30,6 +33,10 @@ const (
 	PKCEMethodS256        string        = "S256"
 )
 
+// validDERPMeshKey matches the mesh keys accepted by the Tailscale DERP
+// server.
+var validDERPMeshKey = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
 var (
 	errOidcMutuallyExclusive = errors.New("oidc_client_secret and oidc_client_secret_path are mutually exclusive")
 	errServerURLSuffix       = errors.New("server_url cannot be part of base_domain in a way that could make the DERP and headscale server unreachable")
@@ -83,13 +90,23 @@ type Config struct {
 
 	// TailcfgDNSConfig is the tailcfg representation of the DNS configuration,
 	// it can be used directly when sending Netmaps to clients.
+	// It is not updated at runtime, use TailcfgDNS to read the DNS
+	// configuration sent to the nodes.
 	TailcfgDNSConfig *tailcfg.DNSConfig
 
+	// tailcfgDNS is the DNS configuration set at runtime, see SetTailcfgDNS.
+	tailcfgDNS atomic.Pointer[tailcfg.DNSConfig]
+
 	UnixSocket           string
 	UnixSocketPermission fs.FileMode
 
 	OIDC OIDCConfig
 
+	// OIDCProviders are all OIDC providers the users can log in with, the
+	// one of the oidc section first, if it has an issuer, followed by the
+	// ones of oidc.providers.
+	OIDCProviders []OIDCConfig
+
 	LogTail             LogTailConfig
 	RandomizeClientPort bool
 
@@ -97,6 +114,14 @@ type Config struct {
 
 	Policy PolicyConfig
 
+	Audit AuditConfig
+
+	Webhooks WebhookConfig
+
+	Routes RoutesConfig
+
+	NodeApproval NodeApprovalConfig
+
 	Tuning Tuning
 }
 
@@ -185,6 +210,36 @@ type OIDCConfig struct {
 	Expiry                     time.Duration
 	UseExpiryFromToken         bool
 	PKCE                       PKCEConfig
+	Revalidation               OIDCRevalidationConfig
+
+	// Name identifies the provider, it is used to choose the provider when
+	// registering a node.
+	Name string
+
+	// DisplayName is shown to the users choosing a provider, it defaults
+	// to Name.
+	DisplayName string
+
+	// Primary is set for the provider of the oidc section. Users who
+	// logged in before the provider was recorded belong to it.
+	Primary bool
+
+	// SCIM is set for the providers of the identity providers provisioning
+	// users via SCIM. Only they link the SCIM users on their first login.
+	SCIM bool
+}
+
+// OIDCRevalidationConfig configures the periodic re-validation of the users
+// with the refresh token of their last login.
+type OIDCRevalidationConfig struct {
+	Enabled bool
+
+	// Interval is how often all users are re-validated.
+	Interval time.Duration
+
+	// KeyPath is the file with the key the refresh tokens are encrypted
+	// with, it is created if it does not exist.
+	KeyPath string
 }
 
 type DERPConfig struct {
@@ -202,12 +257,113 @@ type DERPConfig struct {
 	UpdateFrequency                    time.Duration
 	IPv4                               string
 	IPv6                               string
+	Probe                              DERPProbeConfig
+
+	// MeshKey is the pre-shared key of the DERP servers the embedded DERP
+	// server meshes with, clients connected to different servers of the
+	// mesh can reach each other.
+	MeshKey   string
+	MeshPeers []DERPMeshPeer
+
+	// ServerVerifyClients makes the embedded DERP server only admit the
+	// nodes headscale admits on its /verify endpoint.
+	ServerVerifyClients bool
+}
+
+// DERPMeshPeer is a DERP server of the region of the embedded DERP server
+// it meshes with.
+type DERPMeshPeer struct {
+	HostName string `mapstructure:"hostname"`
+	IPv4     string `mapstructure:"ipv4"`
+	IPv6     string `mapstructure:"ipv6"`
+	DERPPort int    `mapstructure:"derp_port"`
+	STUNPort int    `mapstructure:"stun_port"`
+}
+
+// DERPProbeConfig configures the prober checking the DERP and STUN servers
+// of the DERP map.
+type DERPProbeConfig struct {
+	Enabled bool
+
+	// Interval is how often all DERP and STUN servers are probed.
+	Interval time.Duration
+
+	// Timeout is how long a single probe may take.
+	Timeout time.Duration
 }
 
 type LogTailConfig struct {
 	Enabled bool
 }
 
+type AuditConfig struct {
+	// FilePath is an optional file the audit events are appended to,
+	// in addition to the database, as JSON lines.
+	FilePath string
+}
+
+type WebhookConfig struct {
+	// KeyExpiryWarning is how long before a node key expires the
+	// node.key_expiring event is sent.
+	KeyExpiryWarning time.Duration
+
+	// Retention is how long delivered and given up deliveries are kept
+	// in the database, zero keeps them forever.
+	Retention time.Duration
+
+	Endpoints []WebhookEndpoint
+}
+
+// WebhookEndpoint is an HTTP endpoint receiving the events as signed JSON
+// POST requests.
+type WebhookEndpoint struct {
+	URL string `mapstructure:"url"`
+
+	// Secret is used to sign the requests with HMAC-SHA256.
+	Secret string `mapstructure:"secret"`
+
+	// Events the endpoint receives, all events if empty.
+	Events []string `mapstructure:"events"`
+}
+
+// Wants reports whether the endpoint receives the given event.
+func (e WebhookEndpoint) Wants(event string) bool {
+	return len(e.Events) == 0 || slices.Contains(e.Events, event)
+}
+
+type RoutesConfig struct {
+	Failover RouteFailoverConfig
+	Steering RouteSteeringConfig
+}
+
+// RouteFailoverConfig configures how the primary subnet router of a route
+// is chosen from the nodes announcing it.
+type RouteFailoverConfig struct {
+	// StaleAfter is how long after it was last seen an online node is
+	// considered unhealthy, 0 disables the check.
+	StaleAfter time.Duration
+
+	// HoldDown is how long a node has to be healthy, after it was
+	// unhealthy or offline, before it is preferred as a new primary.
+	// This keeps routes from failing over to flapping nodes.
+	HoldDown time.Duration
+}
+
+// RouteSteeringConfig configures how nodes are steered to the subnet
+// routers of their region.
+type RouteSteeringConfig struct {
+	// DERPRegion puts the nodes that are not in a region of the policy in
+	// the region of their preferred DERP server.
+	DERPRegion bool
+}
+
+// NodeApprovalConfig configures the approval of new nodes by an admin.
+type NodeApprovalConfig struct {
+	// Enabled makes the nodes registered via OIDC wait for an admin to
+	// approve them before they can connect to other nodes.
+	Enabled bool
+}
+
 type CLIConfig struct {
 	Address  string
 	APIKey   string
@@ -235,6 +391,128 @@ type Tuning struct {
 	NodeMapSessionBufferedChanSize int
 }
 
+// parseOIDCExpiry parses the expiry of an OIDC provider, 0 means no expiry.
+func parseOIDCExpiry(key, value string) time.Duration {
+	// if set to 0, we assume no expiry
+	if value == "0" {
+		return maxDuration
+	}
+
+	if value == "" {
+		return defaultOIDCExpiryTime
+	}
+
+	expiry, err := model.ParseDuration(value)
+	if err != nil {
+		log.Warn().Msgf("failed to parse %s, defaulting back to 180 days", key)
+
+		return defaultOIDCExpiryTime
+	}
+
+	return time.Duration(expiry)
+}
+
+// oidcProviderEntry is an entry of oidc.providers.
+type oidcProviderEntry struct {
+	Name               string            `mapstructure:"name"`
+	DisplayName        string            `mapstructure:"display_name"`
+	Issuer             string            `mapstructure:"issuer"`
+	ClientID           string            `mapstructure:"client_id"`
+	ClientSecret       string            `mapstructure:"client_secret"`
+	ClientSecretPath   string            `mapstructure:"client_secret_path"`
+	Scope              []string          `mapstructure:"scope"`
+	ExtraParams        map[string]string `mapstructure:"extra_params"`
+	AllowedDomains     []string          `mapstructure:"allowed_domains"`
+	AllowedUsers       []string          `mapstructure:"allowed_users"`
+	AllowedGroups      []string          `mapstructure:"allowed_groups"`
+	Expiry             string            `mapstructure:"expiry"`
+	UseExpiryFromToken bool              `mapstructure:"use_expiry_from_token"`
+	SCIM               bool              `mapstructure:"scim"`
+	PKCE               struct {
+		Enabled bool   `mapstructure:"enabled"`
+		Method  string `mapstructure:"method"`
+	} `mapstructure:"pkce"`
+}
+
+// oidcProviderConfigs returns the OIDC providers, the one of the oidc
+// section followed by the ones of oidc.providers. The providers share
+// only_start_if_oidc_is_available and the revalidation settings of the oidc
+// section.
+func oidcProviderConfigs(oidcConfig OIDCConfig) ([]OIDCConfig, error) {
+	var providers []OIDCConfig
+	if oidcConfig.Issuer != "" {
+		oidcConfig.Primary = true
+		providers = append(providers, oidcConfig)
+	}
+
+	var entries []oidcProviderEntry
+	if viper.IsSet("oidc.providers") {
+		if err := viper.UnmarshalKey("oidc.providers", &entries); err != nil {
+			return nil, fmt.Errorf("unmarshalling OIDC providers: %w", err)
+		}
+	}
+
+	for i, entry := range entries {
+		if entry.Name == "" || entry.Issuer == "" || entry.ClientID == "" {
+			return nil, fmt.Errorf("oidc.providers[%d] requires a name, an issuer and a client_id", i)
+		}
+
+		if entry.ClientSecretPath != "" && entry.ClientSecret != "" {
+			return nil, fmt.Errorf("OIDC provider %q: %w", entry.Name, errOidcMutuallyExclusive)
+		}
+		if entry.ClientSecretPath != "" {
+			secretBytes, err := os.ReadFile(os.ExpandEnv(entry.ClientSecretPath))
+			if err != nil {
+				return nil, err
+			}
+			entry.ClientSecret = strings.TrimSpace(string(secretBytes))
+		}
+
+		if entry.Scope == nil {
+			entry.Scope = []string{oidc.ScopeOpenID, "profile", "email"}
+		}
+
+		if entry.PKCE.Method == "" {
+			entry.PKCE.Method = PKCEMethodS256
+		}
+		if err := validatePKCEMethod(entry.PKCE.Method); err != nil {
+			return nil, fmt.Errorf("OIDC provider %q: %w", entry.Name, err)
+		}
+
+		providers = append(providers, OIDCConfig{
+			OnlyStartIfOIDCIsAvailable: oidcConfig.OnlyStartIfOIDCIsAvailable,
+			Name:                       entry.Name,
+			DisplayName:                entry.DisplayName,
+			Issuer:                     entry.Issuer,
+			ClientID:                   entry.ClientID,
+			ClientSecret:               entry.ClientSecret,
+			Scope:                      entry.Scope,
+			ExtraParams:                entry.ExtraParams,
+			AllowedDomains:             entry.AllowedDomains,
+			AllowedUsers:               entry.AllowedUsers,
+			AllowedGroups:              entry.AllowedGroups,
+			Expiry:                     parseOIDCExpiry(fmt.Sprintf("oidc.providers[%d].expiry", i), entry.Expiry),
+			UseExpiryFromToken:         entry.UseExpiryFromToken,
+			SCIM:                       entry.SCIM,
+			PKCE: PKCEConfig{
+				Enabled: entry.PKCE.Enabled,
+				Method:  entry.PKCE.Method,
+			},
+			Revalidation: oidcConfig.Revalidation,
+		})
+	}
+
+	seen := make(map[string]bool, len(providers))
+	for _, provider := range providers {
+		if seen[provider.Name] {
+			return nil, fmt.Errorf("OIDC provider name %q is used more than once", provider.Name)
+		}
+		seen[provider.Name] = true
+	}
+
+	return providers, nil
+}
+
 func validatePKCEMethod(method string) error {
 	if method != PKCEMethodPlain && method != PKCEMethodS256 {
 		return errInvalidPKCEMethod
@@ -253,6 +531,23 @@ func (c *Config) Domain() string {
 	return u.Hostname()
 }
 
+// TailcfgDNS returns the DNS configuration sent to the nodes, the one set
+// with SetTailcfgDNS or else TailcfgDNSConfig. It is safe to call while the
+// configuration is replaced, the returned configuration must not be
+// modified.
+func (c *Config) TailcfgDNS() *tailcfg.DNSConfig {
+	if dnsConfig := c.tailcfgDNS.Load(); dnsConfig != nil {
+		return dnsConfig
+	}
+
+	return c.TailcfgDNSConfig
+}
+
+// SetTailcfgDNS replaces the DNS configuration sent to the nodes.
+func (c *Config) SetTailcfgDNS(dnsConfig *tailcfg.DNSConfig) {
+	c.tailcfgDNS.Store(dnsConfig)
+}
+
 // LoadConfig prepares and loads the Headscale configuration into Viper.
 // This means it sets the default values, reads the configuration file and
 // environment variables, and handles deprecated configuration options.
@@ -297,6 +592,9 @@ func LoadConfig(path string, isFile bool) error {
 	viper.SetDefault("derp.server.enabled", false)
 	viper.SetDefault("derp.server.stun.enabled", true)
 	viper.SetDefault("derp.server.automatically_add_embedded_derp_region", true)
+	viper.SetDefault("derp.probe.enabled", false)
+	viper.SetDefault("derp.probe.interval", "1m")
+	viper.SetDefault("derp.probe.timeout", "10s")
 
 	viper.SetDefault("unix_socket", "/var/run/headscale/headscale.sock")
 	viper.SetDefault("unix_socket_permission", "0o770")
@@ -321,6 +619,20 @@ func LoadConfig(path string, isFile bool) error {
 	viper.SetDefault("oidc.use_expiry_from_token", false)
 	viper.SetDefault("oidc.pkce.enabled", false)
 	viper.SetDefault("oidc.pkce.method", "S256")
+	viper.SetDefault("oidc.name", "oidc")
+	viper.SetDefault("oidc.scim", true)
+	viper.SetDefault("oidc.revalidation.enabled", false)
+	viper.SetDefault("oidc.revalidation.interval", "1h")
+	viper.SetDefault("oidc.revalidation.key_path", "/var/lib/headscale/oidc_token.key")
+
+	viper.SetDefault("webhooks.key_expiry_warning", "24h")
+	viper.SetDefault("webhooks.retention", "720h")
+
+	viper.SetDefault("routes.failover.stale_after", "3m")
+	viper.SetDefault("routes.failover.hold_down", "1m")
+	viper.SetDefault("routes.steering.derp_region", false)
+
+	viper.SetDefault("node_approval.enabled", false)
 
 	viper.SetDefault("logtail.enabled", false)
 	viper.SetDefault("randomize_client_port", false)
@@ -387,6 +699,10 @@ func validateServerConfig() error {
 		errorText += "Fatal config error: set either tls_letsencrypt_hostname or tls_cert_path/tls_key_path, not both\n"
 	}
 
+	if viper.GetBool("oidc.revalidation.enabled") && viper.GetDuration("oidc.revalidation.interval") <= 0 {
+		errorText += "Fatal config error: oidc.revalidation.interval must be positive\n"
+	}
+
 	if !viper.IsSet("noise") || viper.GetString("noise.private_key_path") == "" {
 		errorText += "Fatal config error: headscale now requires a new `noise.private_key_path` field in the config file for the Tailscale v2 protocol\n"
 	}
@@ -494,6 +810,32 @@ func derpConfig() DERPConfig {
 			Msg("Disabling derp.server.automatically_add_embedded_derp_region requires to configure the derp server in derp.paths")
 	}
 
+	var meshKey string
+	if path := viper.GetString("derp.server.mesh.key_path"); path != "" {
+		key, err := os.ReadFile(util.AbsolutePathFromConfigPath(path))
+		if err != nil {
+			log.Fatal().Err(err).Msg("Failed to read derp.server.mesh.key_path")
+		}
+
+		meshKey = strings.TrimSpace(string(key))
+		if !validDERPMeshKey.MatchString(meshKey) {
+			log.Fatal().Msg("derp.server.mesh.key_path must contain 64 lowercase hex characters")
+		}
+	}
+
+	var meshPeers []DERPMeshPeer
+	if err := viper.UnmarshalKey("derp.server.mesh.peers", &meshPeers); err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse derp.server.mesh.peers")
+	}
+	for _, peer := range meshPeers {
+		if peer.HostName == "" {
+			log.Fatal().Msg("derp.server.mesh.peers requires a hostname for each peer")
+		}
+	}
+	if len(meshPeers) > 0 && meshKey == "" {
+		log.Fatal().Msg("derp.server.mesh.peers requires derp.server.mesh.key_path to be set")
+	}
+
 	autoUpdate := viper.GetBool("derp.auto_update_enabled")
 	updateFrequency := viper.GetDuration("derp.update_frequency")
 
@@ -511,6 +853,14 @@ func derpConfig() DERPConfig {
 		IPv4:                               ipv4,
 		IPv6:                               ipv6,
 		AutomaticallyAddEmbeddedDerpRegion: automaticallyAddEmbeddedDerpRegion,
+		Probe: DERPProbeConfig{
+			Enabled:  viper.GetBool("derp.probe.enabled"),
+			Interval: viper.GetDuration("derp.probe.interval"),
+			Timeout:  viper.GetDuration("derp.probe.timeout"),
+		},
+		MeshKey:             meshKey,
+		MeshPeers:           meshPeers,
+		ServerVerifyClients: viper.GetBool("derp.server.verify_clients"),
 	}
 }
 
@@ -644,6 +994,51 @@ func dns() (DNSConfig, error) {
 	return dns, nil
 }
 
+func webhooks() (WebhookConfig, error) {
+	cfg := WebhookConfig{
+		KeyExpiryWarning: viper.GetDuration("webhooks.key_expiry_warning"),
+		Retention:        viper.GetDuration("webhooks.retention"),
+	}
+
+	if !viper.IsSet("webhooks.endpoints") {
+		return cfg, nil
+	}
+
+	if err := viper.UnmarshalKey("webhooks.endpoints", &cfg.Endpoints); err != nil {
+		return WebhookConfig{}, fmt.Errorf("unmarshalling webhook endpoints: %w", err)
+	}
+
+	for i, endpoint := range cfg.Endpoints {
+		u, err := url.Parse(endpoint.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return WebhookConfig{}, fmt.Errorf("invalid webhook endpoint URL %q", endpoint.URL)
+		}
+
+		if slices.ContainsFunc(cfg.Endpoints[:i], func(e WebhookEndpoint) bool {
+			return e.URL == endpoint.URL
+		}) {
+			return WebhookConfig{}, fmt.Errorf("webhook endpoint %q is configured more than once", endpoint.URL)
+		}
+
+		if endpoint.Secret == "" {
+			return WebhookConfig{}, fmt.Errorf("webhook endpoint %q has no secret", endpoint.URL)
+		}
+
+		for _, event := range endpoint.Events {
+			if !slices.Contains(WebhookEvents, event) {
+				return WebhookConfig{}, fmt.Errorf(
+					"unknown event %q for webhook endpoint %q, valid events are: %s",
+					event,
+					endpoint.URL,
+					strings.Join(WebhookEvents, ", "),
+				)
+			}
+		}
+	}
+
+	return cfg, nil
+}
+
 // globalResolvers returns the global DNS resolvers
 // defined in the config file.
 // If a nameserver is a valid IP, it will be used as a regular resolver.
@@ -805,6 +1200,10 @@ func LoadCLIConfig() (*Config, error) {
 	return &Config{
 		DisableUpdateCheck: viper.GetBool("disable_check_updates"),
 		UnixSocket:         viper.GetString("unix_socket"),
+		NodeApproval: NodeApprovalConfig{
+			Enabled: viper.GetBool("node_approval.enabled"),
+		},
+
 		CLI: CLIConfig{
 			Address:  viper.GetString("cli.address"),
 			APIKey:   viper.GetString("cli.api_key"),
@@ -860,6 +1259,11 @@ func LoadServerConfig() (*Config, error) {
 		return nil, err
 	}
 
+	webhookConfig, err := webhooks()
+	if err != nil {
+		return nil, err
+	}
+
 	derpConfig := derpConfig()
 	logTailConfig := logtailConfig()
 	randomizeClientPort := viper.GetBool("randomize_client_port")
@@ -877,6 +1281,41 @@ func LoadServerConfig() (*Config, error) {
 		oidcClientSecret = strings.TrimSpace(string(secretBytes))
 	}
 
+	oidcConfig := OIDCConfig{
+		OnlyStartIfOIDCIsAvailable: viper.GetBool(
+			"oidc.only_start_if_oidc_is_available",
+		),
+		Name:               viper.GetString("oidc.name"),
+		DisplayName:        viper.GetString("oidc.display_name"),
+		Issuer:             viper.GetString("oidc.issuer"),
+		ClientID:           viper.GetString("oidc.client_id"),
+		ClientSecret:       oidcClientSecret,
+		Scope:              viper.GetStringSlice("oidc.scope"),
+		ExtraParams:        viper.GetStringMapString("oidc.extra_params"),
+		AllowedDomains:     viper.GetStringSlice("oidc.allowed_domains"),
+		AllowedUsers:       viper.GetStringSlice("oidc.allowed_users"),
+		AllowedGroups:      viper.GetStringSlice("oidc.allowed_groups"),
+		Expiry:             parseOIDCExpiry("oidc.expiry", viper.GetString("oidc.expiry")),
+		UseExpiryFromToken: viper.GetBool("oidc.use_expiry_from_token"),
+		SCIM:               viper.GetBool("oidc.scim"),
+		PKCE: PKCEConfig{
+			Enabled: viper.GetBool("oidc.pkce.enabled"),
+			Method:  viper.GetString("oidc.pkce.method"),
+		},
+		Revalidation: OIDCRevalidationConfig{
+			Enabled:  viper.GetBool("oidc.revalidation.enabled"),
+			Interval: viper.GetDuration("oidc.revalidation.interval"),
+			KeyPath: util.AbsolutePathFromConfigPath(
+				viper.GetString("oidc.revalidation.key_path"),
+			),
+		},
+	}
+
+	oidcProviders, err := oidcProviderConfigs(oidcConfig)
+	if err != nil {
+		return nil, err
+	}
+
 	serverURL := viper.GetString("server_url")
 
 	// BaseDomain cannot be the same as the server URL.
@@ -928,45 +1367,30 @@ func LoadServerConfig() (*Config, error) {
 		UnixSocket:           viper.GetString("unix_socket"),
 		UnixSocketPermission: util.GetFileMode("unix_socket_permission"),
 
-		OIDC: OIDCConfig{
-			OnlyStartIfOIDCIsAvailable: viper.GetBool(
-				"oidc.only_start_if_oidc_is_available",
-			),
-			Issuer:         viper.GetString("oidc.issuer"),
-			ClientID:       viper.GetString("oidc.client_id"),
-			ClientSecret:   oidcClientSecret,
-			Scope:          viper.GetStringSlice("oidc.scope"),
-			ExtraParams:    viper.GetStringMapString("oidc.extra_params"),
-			AllowedDomains: viper.GetStringSlice("oidc.allowed_domains"),
-			AllowedUsers:   viper.GetStringSlice("oidc.allowed_users"),
-			AllowedGroups:  viper.GetStringSlice("oidc.allowed_groups"),
-			Expiry: func() time.Duration {
-				// if set to 0, we assume no expiry
-				if value := viper.GetString("oidc.expiry"); value == "0" {
-					return maxDuration
-				} else {
-					expiry, err := model.ParseDuration(value)
-					if err != nil {
-						log.Warn().Msg("failed to parse oidc.expiry, defaulting back to 180 days")
-
-						return defaultOIDCExpiryTime
-					}
-
-					return time.Duration(expiry)
-				}
-			}(),
-			UseExpiryFromToken: viper.GetBool("oidc.use_expiry_from_token"),
-			PKCE: PKCEConfig{
-				Enabled: viper.GetBool("oidc.pkce.enabled"),
-				Method:  viper.GetString("oidc.pkce.method"),
-			},
-		},
+		OIDC:          oidcConfig,
+		OIDCProviders: oidcProviders,
 
 		LogTail:             logTailConfig,
 		RandomizeClientPort: randomizeClientPort,
 
 		Policy: policyConfig(),
 
+		Audit: AuditConfig{
+			FilePath: util.AbsolutePathFromConfigPath(viper.GetString("audit.file_path")),
+		},
+
+		Webhooks: webhookConfig,
+
+		Routes: RoutesConfig{
+			Failover: RouteFailoverConfig{
+				StaleAfter: viper.GetDuration("routes.failover.stale_after"),
+				HoldDown:   viper.GetDuration("routes.failover.hold_down"),
+			},
+			Steering: RouteSteeringConfig{
+				DERPRegion: viper.GetBool("routes.steering.derp_region"),
+			},
+		},
+
 		CLI: CLIConfig{
 			Address:  viper.GetString("cli.address"),
 			APIKey:   viper.GetString("cli.api_key"),
@@ -237,6 +237,64 @@ func TestReadConfig(t *testing.T) {
 				"policy.path": "/etc/policy.hujson",
 			},
 		},
+		{
+			name:       "oidc-providers",
+			configPath: "testdata/oidc-providers.yaml",
+			setup: func(t *testing.T) (any, error) {
+				cfg, err := LoadServerConfig()
+				if err != nil {
+					return nil, err
+				}
+
+				var providers []map[string]any
+				for _, provider := range cfg.OIDCProviders {
+					providers = append(providers, map[string]any{
+						"name":            provider.Name,
+						"display_name":    provider.DisplayName,
+						"issuer":          provider.Issuer,
+						"scope":           provider.Scope,
+						"allowed_domains": provider.AllowedDomains,
+						"pkce":            provider.PKCE,
+					})
+				}
+
+				return providers, nil
+			},
+			want: []map[string]any{
+				{
+					"name":            "oidc",
+					"display_name":    "Employees",
+					"issuer":          "https://sso.example.com",
+					"scope":           []string{"openid", "profile", "email"},
+					"allowed_domains": []string(nil),
+					"pkce":            PKCEConfig{Method: PKCEMethodS256},
+				},
+				{
+					"name":            "contractors",
+					"display_name":    "Contractors",
+					"issuer":          "https://contractors.example.com",
+					"scope":           []string{"openid", "profile", "email"},
+					"allowed_domains": []string{"contractors.example.com"},
+					"pkce":            PKCEConfig{Enabled: true, Method: PKCEMethodS256},
+				},
+			},
+		},
+		{
+			name:       "oidc-providers-duplicate-name-err",
+			configPath: "testdata/oidc-providers-duplicate-name.yaml",
+			setup: func(t *testing.T) (any, error) {
+				return LoadServerConfig()
+			},
+			wantErr: `OIDC provider name "oidc" is used more than once`,
+		},
+		{
+			name:       "webhooks-duplicate-endpoint-err",
+			configPath: "testdata/webhooks-duplicate-endpoint.yaml",
+			setup: func(t *testing.T) (any, error) {
+				return LoadServerConfig()
+			},
+			wantErr: `webhook endpoint "https://hooks.example.com/headscale" is configured more than once`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,192 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"tailscale.com/tailcfg"
+)
+
+var (
+	ErrDERPRegionInvalid = errors.New("invalid DERP region")
+	ErrDERPNodeInvalid   = errors.New("invalid DERP node")
+)
+
+// The sources of the DERP regions and nodes listed by the API.
+const (
+	DERPSourceConfig = "config"
+	DERPSourceAPI    = "api"
+)
+
+// DERPRegion is a DERP region managed through the API. It adds a region to
+// the DERP map, or changes the region with the same ID from the
+// configuration. A region with only an ID and Disabled set disables a
+// region of the configuration.
+type DERPRegion struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	RegionID int `gorm:"uniqueIndex"`
+	Code     string
+	Name     string
+	Disabled bool
+}
+
+// Validate checks the region and normalises its code.
+func (r *DERPRegion) Validate() error {
+	if r.RegionID <= 0 {
+		return fmt.Errorf("%w: region ID must be positive", ErrDERPRegionInvalid)
+	}
+
+	r.Code = strings.TrimSpace(r.Code)
+	if r.Code == "" {
+		return fmt.Errorf("%w: region code must be set", ErrDERPRegionInvalid)
+	}
+
+	r.Name = strings.TrimSpace(r.Name)
+	if r.Name == "" {
+		r.Name = r.Code
+	}
+
+	return nil
+}
+
+func (r *DERPRegion) Proto() *v1.DERPRegion {
+	return &v1.DERPRegion{
+		RegionId: int32(r.RegionID),
+		Code:     r.Code,
+		Name:     r.Name,
+		Source:   DERPSourceAPI,
+		Disabled: r.Disabled,
+	}
+}
+
+// DERPNode is a DERP server managed through the API. It adds a server to a
+// region of the DERP map, or replaces the server with the same name from
+// the configuration. A node without host name only disables or enables the
+// server with the same name from the configuration.
+type DERPNode struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	Name     string `gorm:"uniqueIndex"`
+	RegionID int
+	HostName string
+	IPv4     string
+	IPv6     string
+	STUNPort int
+	DERPPort int
+	STUNOnly bool
+	Disabled bool
+}
+
+// Validate checks the node and normalises its addresses.
+func (n *DERPNode) Validate() error {
+	n.Name = strings.TrimSpace(n.Name)
+	if n.Name == "" {
+		return fmt.Errorf("%w: name must be set", ErrDERPNodeInvalid)
+	}
+
+	if n.RegionID <= 0 {
+		return fmt.Errorf("%w: region ID must be positive", ErrDERPNodeInvalid)
+	}
+
+	n.HostName = strings.TrimSuffix(strings.TrimSpace(n.HostName), ".")
+	if n.HostName == "" {
+		return fmt.Errorf("%w: host name must be set", ErrDERPNodeInvalid)
+	}
+
+	for _, ip := range []struct {
+		addr *string
+		is4  bool
+	}{{&n.IPv4, true}, {&n.IPv6, false}} {
+		*ip.addr = strings.TrimSpace(*ip.addr)
+		if *ip.addr == "" {
+			continue
+		}
+
+		addr, err := netip.ParseAddr(*ip.addr)
+		if err != nil || addr.Is4() != ip.is4 {
+			return fmt.Errorf("%w: %q is not a valid IPv4 or IPv6 address", ErrDERPNodeInvalid, *ip.addr)
+		}
+	}
+
+	// Like in tailcfg, -1 disables STUN and 0 is the default port.
+	if n.STUNPort < -1 || n.STUNPort > 65535 || n.DERPPort < 0 || n.DERPPort > 65535 {
+		return fmt.Errorf("%w: invalid port", ErrDERPNodeInvalid)
+	}
+
+	return nil
+}
+
+func (n *DERPNode) Proto() *v1.DERPNode {
+	return &v1.DERPNode{
+		Name:     n.Name,
+		RegionId: int32(n.RegionID),
+		HostName: n.HostName,
+		Ipv4:     n.IPv4,
+		Ipv6:     n.IPv6,
+		StunPort: int32(n.STUNPort),
+		DerpPort: int32(n.DERPPort),
+		StunOnly: n.STUNOnly,
+		Source:   DERPSourceAPI,
+		Disabled: n.Disabled,
+	}
+}
+
+// Tailcfg returns the node as a node of the DERP map.
+func (n *DERPNode) Tailcfg() *tailcfg.DERPNode {
+	return &tailcfg.DERPNode{
+		Name:     n.Name,
+		RegionID: n.RegionID,
+		HostName: n.HostName,
+		IPv4:     n.IPv4,
+		IPv6:     n.IPv6,
+		STUNPort: n.STUNPort,
+		DERPPort: n.DERPPort,
+		STUNOnly: n.STUNOnly,
+	}
+}
+
+// DERPProbeResult is the result of probing a DERP server.
+type DERPProbeResult struct {
+	ProbedAt time.Time
+
+	// DERPErr and STUNErr are the errors of the DERP and STUN probes,
+	// nil if the probe succeeded or was not done.
+	DERPErr error
+	STUNErr error
+
+	DERPLatency time.Duration
+	STUNLatency time.Duration
+}
+
+// Healthy reports if the DERP server could be reached.
+func (r DERPProbeResult) Healthy() bool {
+	return r.DERPErr == nil && r.STUNErr == nil
+}
+
+func (r DERPProbeResult) Proto() *v1.DERPProbeResult {
+	ret := &v1.DERPProbeResult{
+		Healthy:         r.Healthy(),
+		ProbedAt:        timestamppb.New(r.ProbedAt),
+		DerpLatencyMsec: float64(r.DERPLatency.Microseconds()) / 1000,
+		StunLatencyMsec: float64(r.STUNLatency.Microseconds()) / 1000,
+	}
+
+	var errs []string
+	if r.DERPErr != nil {
+		errs = append(errs, "derp: "+r.DERPErr.Error())
+	}
+	if r.STUNErr != nil {
+		errs = append(errs, "stun: "+r.STUNErr.Error())
+	}
+	ret.Error = strings.Join(errs, "; ")
+
+	return ret
+}
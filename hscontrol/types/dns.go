@@ -0,0 +1,273 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/dnstype"
+	"tailscale.com/util/dnsname"
+)
+
+var (
+	ErrDNSRecordInvalid    = errors.New("invalid DNS record")
+	ErrNameserverInvalid   = errors.New("invalid nameserver")
+	ErrSearchDomainInvalid = errors.New("invalid search domain")
+)
+
+const (
+	// DNSRecordTypeCNAME makes a name resolve to the addresses of another
+	// name, an extra record or the MagicDNS name of a node.
+	DNSRecordTypeCNAME = "CNAME"
+	// DNSRecordTypeAlias makes a name resolve to the addresses of a node,
+	// given by ID or name.
+	DNSRecordTypeAlias = "ALIAS"
+)
+
+// IsWildcardDNSName reports if the name is a wildcard, e.g.
+// *.svc.example.com.
+func IsWildcardDNSName(name string) bool {
+	return strings.HasPrefix(name, "*.")
+}
+
+// DNSRecord is an extra DNS record managed through the API, it is served
+// to the nodes together with the extra records of the configuration.
+type DNSRecord struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	Name  string `gorm:"uniqueIndex:idx_dns_records_name_type_value"`
+	Type  string `gorm:"uniqueIndex:idx_dns_records_name_type_value"`
+	Value string `gorm:"uniqueIndex:idx_dns_records_name_type_value"`
+}
+
+// Validate checks the record and normalises its name and type. The type
+// of an address record is derived from the value if it is empty.
+func (r *DNSRecord) Validate() error {
+	r.Name = strings.TrimSuffix(strings.TrimSpace(r.Name), ".")
+	if _, err := dnsname.ToFQDN(strings.TrimPrefix(r.Name, "*.")); err != nil || r.Name == "" {
+		return fmt.Errorf("%w: name %q is not a valid DNS name", ErrDNSRecordInvalid, r.Name)
+	}
+
+	r.Type = strings.ToUpper(r.Type)
+	r.Value = strings.TrimSpace(r.Value)
+	switch r.Type {
+	case "", "A", "AAAA":
+		addr, err := netip.ParseAddr(r.Value)
+		if err != nil {
+			return fmt.Errorf("%w: value %q is not an IP address", ErrDNSRecordInvalid, r.Value)
+		}
+
+		want := "A"
+		if addr.Is6() {
+			want = "AAAA"
+		}
+
+		if r.Type == "" {
+			r.Type = want
+		} else if r.Type != want {
+			return fmt.Errorf("%w: value %q is not valid for a %s record", ErrDNSRecordInvalid, r.Value, r.Type)
+		}
+	case DNSRecordTypeCNAME:
+		r.Value = strings.TrimSuffix(r.Value, ".")
+		if _, err := dnsname.ToFQDN(r.Value); err != nil || r.Value == "" || IsWildcardDNSName(r.Value) {
+			return fmt.Errorf("%w: CNAME target %q is not a valid DNS name", ErrDNSRecordInvalid, r.Value)
+		}
+	case DNSRecordTypeAlias:
+		if r.Value == "" {
+			return fmt.Errorf("%w: ALIAS record must name a node by ID or name", ErrDNSRecordInvalid)
+		}
+	default:
+		return fmt.Errorf(
+			"%w: type %q is not supported, Tailscale clients only resolve addresses, must be A, AAAA, CNAME or ALIAS",
+			ErrDNSRecordInvalid,
+			r.Type,
+		)
+	}
+
+	return nil
+}
+
+func (r *DNSRecord) Proto() *v1.DNSRecord {
+	return &v1.DNSRecord{
+		Id:        r.ID,
+		Name:      r.Name,
+		Type:      r.Type,
+		Value:     r.Value,
+		CreatedAt: timestamppb.New(r.CreatedAt),
+	}
+}
+
+func (r *DNSRecord) Tailcfg() tailcfg.DNSRecord {
+	return tailcfg.DNSRecord{
+		Name:  r.Name,
+		Type:  r.Type,
+		Value: r.Value,
+	}
+}
+
+// Nameserver is a DNS resolver managed through the API. Without domain it
+// is a global nameserver, otherwise it is a split DNS nameserver for the
+// domain.
+type Nameserver struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	// Address is an IP address or a DNS-over-HTTPS URL.
+	Address string `gorm:"uniqueIndex:idx_nameservers_address_domain"`
+	Domain  string `gorm:"uniqueIndex:idx_nameservers_address_domain"`
+}
+
+// Validate checks the nameserver and normalises its domain.
+func (ns *Nameserver) Validate() error {
+	ns.Address = strings.TrimSpace(ns.Address)
+	if _, err := netip.ParseAddr(ns.Address); err != nil {
+		u, err := url.Parse(ns.Address)
+		if err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("%w: address %q must be an IP address or an https URL", ErrNameserverInvalid, ns.Address)
+		}
+	}
+
+	ns.Domain = strings.TrimSuffix(strings.TrimSpace(ns.Domain), ".")
+	if ns.Domain != "" {
+		if _, err := dnsname.ToFQDN(ns.Domain); err != nil {
+			return fmt.Errorf("%w: domain %q is not a valid DNS name", ErrNameserverInvalid, ns.Domain)
+		}
+	}
+
+	return nil
+}
+
+func (ns *Nameserver) Proto() *v1.Nameserver {
+	return &v1.Nameserver{
+		Id:        ns.ID,
+		Address:   ns.Address,
+		Domain:    ns.Domain,
+		CreatedAt: timestamppb.New(ns.CreatedAt),
+	}
+}
+
+// SearchDomain is a search domain managed through the API.
+type SearchDomain struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	Domain string `gorm:"uniqueIndex"`
+}
+
+// Validate checks the search domain and normalises it.
+func (sd *SearchDomain) Validate() error {
+	sd.Domain = strings.TrimSuffix(strings.TrimSpace(sd.Domain), ".")
+	if _, err := dnsname.ToFQDN(sd.Domain); err != nil || sd.Domain == "" {
+		return fmt.Errorf("%w: %q is not a valid DNS name", ErrSearchDomainInvalid, sd.Domain)
+	}
+
+	return nil
+}
+
+func (sd *SearchDomain) Proto() *v1.SearchDomain {
+	return &v1.SearchDomain{
+		Id:        sd.ID,
+		Domain:    sd.Domain,
+		CreatedAt: timestamppb.New(sd.CreatedAt),
+	}
+}
+
+// DNSSettings are the DNS records, nameservers and search domains
+// managed through the API.
+type DNSSettings struct {
+	Records       []DNSRecord
+	Nameservers   []Nameserver
+	SearchDomains []SearchDomain
+}
+
+// Apply adds the settings to the DNS configuration sent to the nodes.
+// Global nameservers are used like the global nameservers of the
+// configuration file, depending on override_local_dns.
+func (s DNSSettings) Apply(cfg *tailcfg.DNSConfig, overrideLocalDNS bool) {
+	for _, record := range s.Records {
+		cfg.ExtraRecords = append(cfg.ExtraRecords, record.Tailcfg())
+	}
+
+	for _, ns := range s.Nameservers {
+		resolver := &dnstype.Resolver{Addr: ns.Address}
+
+		switch {
+		case ns.Domain != "":
+			if cfg.Routes == nil {
+				cfg.Routes = make(map[string][]*dnstype.Resolver)
+			}
+			cfg.Routes[ns.Domain] = append(cfg.Routes[ns.Domain], resolver)
+		case overrideLocalDNS:
+			cfg.Resolvers = append(cfg.Resolvers, resolver)
+		default:
+			cfg.FallbackResolvers = append(cfg.FallbackResolvers, resolver)
+		}
+	}
+
+	for _, sd := range s.SearchDomains {
+		cfg.Domains = append(cfg.Domains, sd.Domain)
+	}
+}
+
+// DNSProfile changes the DNS configuration of the nodes it is applied to,
+// for example to give servers internal resolvers.
+type DNSProfile struct {
+	Name string
+
+	// Nameservers replace the global nameservers if not empty.
+	Nameservers []string
+
+	// Split maps domains to the nameservers resolving them, replacing the
+	// split DNS nameservers of the same domains.
+	Split map[string][]string
+
+	// OverrideLocalDNS replaces dns.override_local_dns if set.
+	OverrideLocalDNS *bool
+}
+
+// Apply applies the profile to the DNS configuration, overrideLocalDNS is
+// the setting the configuration was built with.
+func (p *DNSProfile) Apply(cfg *tailcfg.DNSConfig, overrideLocalDNS bool) {
+	global := cfg.FallbackResolvers
+	if overrideLocalDNS {
+		global = cfg.Resolvers
+	}
+
+	if len(p.Nameservers) > 0 {
+		global = resolvers(p.Nameservers)
+	}
+
+	if p.OverrideLocalDNS != nil {
+		overrideLocalDNS = *p.OverrideLocalDNS
+	}
+
+	cfg.Resolvers, cfg.FallbackResolvers = nil, nil
+	if overrideLocalDNS {
+		cfg.Resolvers = global
+	} else {
+		cfg.FallbackResolvers = global
+	}
+
+	for domain, nameservers := range p.Split {
+		if cfg.Routes == nil {
+			cfg.Routes = make(map[string][]*dnstype.Resolver)
+		}
+		cfg.Routes[domain] = resolvers(nameservers)
+	}
+}
+
+func resolvers(nameservers []string) []*dnstype.Resolver {
+	ret := make([]*dnstype.Resolver, len(nameservers))
+	for i, ns := range nameservers {
+		ret[i] = &dnstype.Resolver{Addr: ns}
+	}
+
+	return ret
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/dnstype"
+	"tailscale.com/types/ptr"
+)
+
+func TestDNSProfileApply(t *testing.T) {
+	base := func() *tailcfg.DNSConfig {
+		return &tailcfg.DNSConfig{
+			FallbackResolvers: []*dnstype.Resolver{{Addr: "1.1.1.1"}},
+			Routes: map[string][]*dnstype.Resolver{
+				"corp.example.com": {{Addr: "10.0.0.1"}},
+				"lab.example.com":  {{Addr: "10.0.0.2"}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		profile DNSProfile
+		want    *tailcfg.DNSConfig
+	}{
+		{
+			name: "internal-resolvers-override-local",
+			profile: DNSProfile{
+				Nameservers:      []string{"10.0.0.53"},
+				Split:            map[string][]string{"corp.example.com": {"10.0.0.54"}},
+				OverrideLocalDNS: ptr.To(true),
+			},
+			want: &tailcfg.DNSConfig{
+				Resolvers: []*dnstype.Resolver{{Addr: "10.0.0.53"}},
+				Routes: map[string][]*dnstype.Resolver{
+					"corp.example.com": {{Addr: "10.0.0.54"}},
+					"lab.example.com":  {{Addr: "10.0.0.2"}},
+				},
+			},
+		},
+		{
+			name: "override-only-keeps-global-resolvers",
+			profile: DNSProfile{
+				OverrideLocalDNS: ptr.To(true),
+			},
+			want: &tailcfg.DNSConfig{
+				Resolvers: []*dnstype.Resolver{{Addr: "1.1.1.1"}},
+				Routes: map[string][]*dnstype.Resolver{
+					"corp.example.com": {{Addr: "10.0.0.1"}},
+					"lab.example.com":  {{Addr: "10.0.0.2"}},
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := base()
+			tt.profile.Apply(got, false)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf("Apply() unexpected result (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
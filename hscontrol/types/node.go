@@ -18,6 +18,7 @@ import (
 	"tailscale.com/net/tsaddr"
 	"tailscale.com/tailcfg"
 	"tailscale.com/types/key"
+	"tailscale.com/types/tkatype"
 )
 
 var (
@@ -106,6 +107,23 @@ type Node struct {
 	// See [Node.Hostinfo]
 	ApprovedRoutes []netip.Prefix `gorm:"column:approved_routes;serializer:json"`
 
+	// RoutePriorities are the priorities set by an operator for the routes
+	// of the node. Among the nodes announcing a route, the one with the
+	// highest priority is preferred as primary router.
+	RoutePriorities map[netip.Prefix]int `gorm:"column:route_priorities;serializer:json"`
+
+	// NLKey is the tailnet lock key of the node, it is used as the
+	// rotation key when the node key is signed.
+	NLKey key.NLPublic `gorm:"column:nl_key;serializer:text"`
+
+	// KeySignature is the tailnet lock signature of the NodeKey.
+	KeySignature tkatype.MarshaledSignature `gorm:"column:key_signature"`
+
+	// PendingApproval is set on nodes registered while node approval is
+	// enabled, until an admin approves them. Nodes waiting for approval are
+	// not authorized and are not peers of other nodes.
+	PendingApproval bool `gorm:"column:pending_approval;not null;default:false"`
+
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt *time.Time
@@ -352,6 +370,15 @@ func (node *Node) Proto() *v1.Node {
 		RegisterMethod: node.RegisterMethodToV1Enum(),
 
 		CreatedAt: timestamppb.New(node.CreatedAt),
+
+		PendingApproval: node.PendingApproval,
+	}
+
+	if len(node.RoutePriorities) > 0 {
+		nodeProto.RoutePriorities = make(map[string]int32, len(node.RoutePriorities))
+		for prefix, priority := range node.RoutePriorities {
+			nodeProto.RoutePriorities[prefix.String()] = int32(priority)
+		}
 	}
 
 	if node.AuthKey != nil {
@@ -406,7 +433,12 @@ func (node *Node) AnnouncedRoutes() []netip.Prefix {
 }
 
 // SubnetRoutes returns the list of routes that the node announces and are approved.
+// A node waiting for approval has no subnet routes until it is approved.
 func (node *Node) SubnetRoutes() []netip.Prefix {
+	if node.PendingApproval {
+		return nil
+	}
+
 	var routes []netip.Prefix
 
 	for _, route := range node.AnnouncedRoutes() {
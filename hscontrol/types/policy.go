@@ -3,6 +3,8 @@ package types
 import (
 	"errors"
 
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/protobuf/types/known/timestamppb"
 	"gorm.io/gorm"
 )
 
@@ -17,4 +19,16 @@ type Policy struct {
 
 	// Data contains the policy in HuJSON format.
 	Data string
+
+	// Author describes who stored this revision of the policy.
+	Author string
+}
+
+// Proto returns the policy as a revision, without its data.
+func (p *Policy) Proto() *v1.PolicyRevision {
+	return &v1.PolicyRevision{
+		Id:        uint64(p.ID),
+		Author:    p.Author,
+		CreatedAt: timestamppb.New(p.CreatedAt),
+	}
 }
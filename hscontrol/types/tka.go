@@ -0,0 +1,69 @@
+package types
+
+import (
+	"errors"
+	"time"
+
+	"tailscale.com/tailcfg"
+)
+
+var (
+	ErrTKANotEnabled     = errors.New("tailnet lock is not enabled")
+	ErrTKAAlreadyEnabled = errors.New("tailnet lock is already enabled")
+)
+
+// TKAState is the state of the Tailnet Key Authority (Tailnet Lock)
+// of the tailnet. There is at most one row in the database.
+type TKAState struct {
+	ID uint `gorm:"primary_key"`
+
+	// Enabled is set when tailnet lock has been initialised and
+	// node key signatures are enforced by the nodes.
+	Enabled bool
+
+	// Head is the hash of the latest AUM known to headscale.
+	Head string
+
+	// GenesisAUM is the serialized genesis AUM of the authority. While
+	// tailnet lock is being initialised, it is the pending genesis AUM.
+	GenesisAUM []byte
+
+	// DisablementSecret is the secret used to disable tailnet lock, it
+	// is handed out to nodes so they can verify and apply the disablement.
+	DisablementSecret []byte
+
+	// SupportDisablement is a disablement secret given to the control
+	// server on initialisation, allowing it to disable tailnet lock.
+	SupportDisablement []byte
+
+	// LastActiveAncestor is the hash of the oldest AUM that
+	// contributed to the current state.
+	LastActiveAncestor string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// TKAInfo returns the TKAInfo to send to the nodes in the MapResponse.
+func (s *TKAState) TKAInfo() *tailcfg.TKAInfo {
+	switch {
+	case s == nil:
+		return nil
+	case s.Enabled:
+		return &tailcfg.TKAInfo{Head: s.Head}
+	case len(s.DisablementSecret) > 0:
+		return &tailcfg.TKAInfo{Disabled: true}
+	}
+
+	return nil
+}
+
+// TKAAUM is a serialized Authority Update Message of the Tailnet Key
+// Authority.
+type TKAAUM struct {
+	Hash     string `gorm:"primary_key"`
+	PrevHash string `gorm:"index"`
+	AUM      []byte
+
+	CreatedAt time.Time
+}
@@ -7,8 +7,10 @@ import (
 	"fmt"
 	"net/mail"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
 	"github.com/juanfont/headscale/hscontrol/util"
@@ -68,6 +70,53 @@ type User struct {
 	Provider string
 
 	ProfilePicURL string
+
+	// Disabled users can't use their nodes or register new ones, the nodes
+	// are not authorized and are refused by the DERP servers.
+	Disabled bool
+
+	// Groups are the groups of the user at the OIDC provider, from the
+	// groups claim of the last login. The policy refers to them and to the
+	// SCIMGroups as `group:oidc:<name>`.
+	Groups []string `gorm:"serializer:json"`
+
+	// SCIMGroups are the groups provisioned via SCIM the user is a member
+	// of. They are kept apart from Groups, which OIDC logins replace.
+	SCIMGroups []string `gorm:"column:scim_groups;serializer:json"`
+
+	// ExternalID is the ID of the user at the identity provider
+	// provisioning it via SCIM.
+	ExternalID string
+
+	// OIDCRefreshToken is the refresh token of the last OIDC login,
+	// encrypted at rest. It is used to re-validate the user with the OIDC
+	// provider and never leaves headscale.
+	OIDCRefreshToken []byte `gorm:"column:oidc_refresh_token" json:"-"`
+
+	// OIDCProvider is the name of the OIDC provider of the last login, the
+	// refresh token is re-validated with it.
+	OIDCProvider string `gorm:"column:oidc_provider"`
+}
+
+// UserGroup is a group of users provisioned via SCIM. The members have the
+// name of the group in their SCIMGroups.
+type UserGroup struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Name       string `gorm:"uniqueIndex"`
+	ExternalID string
+}
+
+func (g *UserGroup) Proto(memberIDs []uint64) *v1.UserGroup {
+	return &v1.UserGroup{
+		Id:         g.ID,
+		Name:       g.Name,
+		ExternalId: g.ExternalID,
+		MemberIds:  memberIDs,
+		CreatedAt:  timestamppb.New(g.CreatedAt),
+	}
 }
 
 func (u *User) StringID() string {
@@ -146,7 +195,19 @@ func (u *User) Proto() *v1.User {
 		ProviderId:    u.ProviderIdentifier.String,
 		Provider:      u.Provider,
 		ProfilePicUrl: u.ProfilePicURL,
+		Disabled:      u.Disabled,
+		Groups:        u.AllGroups(),
+	}
+}
+
+// AllGroups returns the groups of the OIDC groups claim and the groups
+// provisioned via SCIM of the user.
+func (u *User) AllGroups() []string {
+	if len(u.SCIMGroups) == 0 {
+		return u.Groups
 	}
+
+	return slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(u.Groups), u.SCIMGroups...))))
 }
 
 // JumpCloud returns a JSON where email_verified is returned as a
@@ -312,8 +373,26 @@ type OIDCUserInfo struct {
 }
 
 // FromClaim overrides a User from OIDC claims.
-// All fields will be updated, except for the ID.
+// All fields will be updated, except for the ID. The name, email, display
+// name, profile picture and provider of users provisioned via SCIM are
+// kept, the SCIM identity provider owns them.
 func (u *User) FromClaim(claims *OIDCClaims) {
+	// Get provider identifier
+	identifier := claims.Identifier()
+	// Ensure provider identifier always has a leading slash for backward compatibility
+	if claims.Iss == "" && !strings.HasPrefix(identifier, "/") {
+		identifier = "/" + identifier
+	}
+	u.ProviderIdentifier = sql.NullString{String: identifier, Valid: true}
+	// Without a groups claim the groups of the last login are kept.
+	if claims.Groups != nil {
+		u.Groups = slices.Compact(slices.Sorted(slices.Values(claims.Groups)))
+	}
+
+	if u.Provider == util.ProviderSCIM {
+		return
+	}
+
 	err := util.ValidateUsername(claims.Username)
 	if err == nil {
 		u.Name = claims.Username
@@ -328,13 +407,6 @@ func (u *User) FromClaim(claims *OIDCClaims) {
 		}
 	}
 
-	// Get provider identifier
-	identifier := claims.Identifier()
-	// Ensure provider identifier always has a leading slash for backward compatibility
-	if claims.Iss == "" && !strings.HasPrefix(identifier, "/") {
-		identifier = "/" + identifier
-	}
-	u.ProviderIdentifier = sql.NullString{String: identifier, Valid: true}
 	u.DisplayName = claims.Name
 	u.ProfilePicURL = claims.ProfilePictureURL
 	u.Provider = util.RegisterMethodOIDC
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"time"
+)
+
+const (
+	WebhookEventNodeRegistered      = "node.registered"
+	WebhookEventNodeDeleted         = "node.deleted"
+	WebhookEventNodeKeyExpiring     = "node.key_expiring"
+	WebhookEventPolicyChanged       = "policy.changed"
+	WebhookEventUserCreated         = "user.created"
+	WebhookEventRouteApprovalNeeded = "route.approval_needed"
+)
+
+// WebhookEvents are the events that can be sent to webhook endpoints.
+var WebhookEvents = []string{
+	WebhookEventNodeRegistered,
+	WebhookEventNodeDeleted,
+	WebhookEventNodeKeyExpiring,
+	WebhookEventPolicyChanged,
+	WebhookEventUserCreated,
+	WebhookEventRouteApprovalNeeded,
+}
+
+// WebhookDelivery is an event queued for delivery to a webhook endpoint.
+// Deliveries are kept in the database so they are retried after a restart.
+type WebhookDelivery struct {
+	ID        uint64 `gorm:"primary_key"`
+	CreatedAt time.Time
+
+	// Endpoint is the URL of the endpoint the event is delivered to.
+	Endpoint string `gorm:"index"`
+	Event    string
+
+	// DedupKey, if set, makes sure an event is only queued once per
+	// endpoint, for example only one key expiry warning per node key.
+	DedupKey string `gorm:"index"`
+
+	// Payload is the JSON body sent to the endpoint.
+	Payload string
+
+	Attempts      int
+	NextAttemptAt time.Time `gorm:"index"`
+	LastError     string
+
+	// DeliveredAt is set once the endpoint accepted the event, FailedAt
+	// once the delivery has been given up.
+	DeliveredAt *time.Time
+	FailedAt    *time.Time
+}
@@ -5,3 +5,6 @@ const (
 	RegisterMethodOIDC    = "oidc"
 	RegisterMethodCLI     = "cli"
 )
+
+// ProviderSCIM is the provider of the users provisioned via SCIM.
+const ProviderSCIM = "scim"
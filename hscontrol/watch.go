@@ -0,0 +1,441 @@
+package hscontrol
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const nodeEventTypePrefix = "NODE_EVENT_TYPE_"
+
+var ErrInvalidNodeEventType = errors.New("invalid node event type")
+
+// nodeEventsPath serves the node events as Server-Sent Events.
+const nodeEventsPath = "/api/v1/node/events"
+
+const (
+	nodeWatcherUpdateBufferSize = 256
+	nodeWatcherEventBufferSize  = 64
+)
+
+// nodeEventsKeepAliveInterval is how often a comment is sent on an idle
+// node events stream, so proxies don't close it.
+const nodeEventsKeepAliveInterval = 15 * time.Second
+
+// nodeWatcher turns the state updates sent to the nodes into typed node
+// events for the WatchNodes API.
+// It keeps the last known state of every node while there are
+// subscribers, and emits an event for each difference it sees.
+type nodeWatcher struct {
+	h       *Headscale
+	updates chan types.StateUpdate
+
+	// active is the number of subscribers, updates are ignored when
+	// there are none.
+	active atomic.Int32
+
+	mu          sync.Mutex
+	nodes       map[types.NodeID]*v1.Node
+	subscribers map[chan *v1.NodeEvent]struct{}
+}
+
+func newNodeWatcher(h *Headscale) *nodeWatcher {
+	w := &nodeWatcher{
+		h:           h,
+		updates:     make(chan types.StateUpdate, nodeWatcherUpdateBufferSize),
+		subscribers: make(map[chan *v1.NodeEvent]struct{}),
+	}
+
+	go w.run()
+
+	return w
+}
+
+// observe is registered with the notifier and receives every update sent
+// to all nodes.
+func (w *nodeWatcher) observe(update types.StateUpdate) {
+	if w.active.Load() == 0 {
+		return
+	}
+
+	select {
+	case w.updates <- update:
+	default:
+		log.Warn().
+			Str("type", update.Type.String()).
+			Msg("node watcher is lagging behind, dropping update")
+	}
+}
+
+// subscribe returns a channel receiving the node events, and a function
+// to unsubscribe and close the channel.
+func (w *nodeWatcher) subscribe() (<-chan *v1.NodeEvent, func(), error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.subscribers) == 0 {
+		nodes, err := w.loadNodes()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		w.nodes = make(map[types.NodeID]*v1.Node, len(nodes))
+		for _, node := range nodes {
+			w.nodes[types.NodeID(node.GetId())] = node
+		}
+	}
+
+	c := make(chan *v1.NodeEvent, nodeWatcherEventBufferSize)
+	w.subscribers[c] = struct{}{}
+	w.active.Add(1)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			w.mu.Lock()
+			defer w.mu.Unlock()
+
+			delete(w.subscribers, c)
+			close(c)
+			w.active.Add(-1)
+
+			if len(w.subscribers) == 0 {
+				w.nodes = nil
+			}
+		})
+	}
+
+	return c, unsubscribe, nil
+}
+
+// watch sends the events of the given types, or of all types if none are
+// given, until the context is done or sending fails.
+func (w *nodeWatcher) watch(
+	ctx context.Context,
+	eventTypes []v1.NodeEventType,
+	send func(*v1.NodeEvent) error,
+) error {
+	events, unsubscribe, err := w.subscribe()
+	if err != nil {
+		return status.Errorf(codes.Internal, "subscribing to node events: %s", err)
+	}
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-events:
+			if len(eventTypes) > 0 && !slices.Contains(eventTypes, event.GetType()) {
+				continue
+			}
+
+			if err := send(event); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (w *nodeWatcher) run() {
+	for update := range w.updates {
+		w.handle(update)
+	}
+}
+
+func (w *nodeWatcher) handle(update types.StateUpdate) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.subscribers) == 0 {
+		return
+	}
+
+	switch update.Type {
+	case types.StateFullUpdate:
+		w.reload()
+	case types.StatePeerChanged:
+		if len(update.ChangeNodes) > 0 {
+			w.reload(update.ChangeNodes...)
+		}
+	case types.StatePeerRemoved:
+		for _, id := range update.Removed {
+			if node, ok := w.nodes[id]; ok {
+				delete(w.nodes, id)
+				w.publish(v1.NodeEventType_NODE_EVENT_TYPE_REMOVED, node)
+			}
+		}
+	case types.StatePeerChangedPatch:
+		for _, change := range update.ChangePatches {
+			node, ok := w.nodes[types.NodeID(change.NodeID)]
+			if !ok {
+				continue
+			}
+
+			updated := proto.Clone(node).(*v1.Node)
+			if change.Online != nil {
+				updated.Online = *change.Online
+			}
+			if change.LastSeen != nil {
+				updated.LastSeen = timestamppb.New(*change.LastSeen)
+			}
+			if change.KeyExpiry != nil {
+				updated.Expiry = timestamppb.New(*change.KeyExpiry)
+			}
+
+			w.update(node, updated)
+		}
+	}
+}
+
+// reload loads the given nodes, or all nodes if none are given, from the
+// database and emits the events for their changes.
+func (w *nodeWatcher) reload(ids ...types.NodeID) {
+	nodes, err := w.loadNodes(ids...)
+	if err != nil {
+		log.Error().Err(err).Msg("node watcher failed to load nodes")
+
+		return
+	}
+
+	seen := make(map[types.NodeID]bool, len(nodes))
+	for _, node := range nodes {
+		id := types.NodeID(node.GetId())
+		seen[id] = true
+
+		w.update(w.nodes[id], node)
+	}
+
+	// Nodes that are asked for but are gone, or that are not part of a
+	// full reload, have been removed.
+	for id, node := range w.nodes {
+		if seen[id] || (len(ids) > 0 && !slices.Contains(ids, id)) {
+			continue
+		}
+
+		delete(w.nodes, id)
+		w.publish(v1.NodeEventType_NODE_EVENT_TYPE_REMOVED, node)
+	}
+}
+
+// update stores the new state of a node and emits an event for every
+// change from its previous state, which is nil for new nodes.
+func (w *nodeWatcher) update(prev, curr *v1.Node) {
+	w.nodes[types.NodeID(curr.GetId())] = curr
+
+	if prev == nil {
+		w.publish(v1.NodeEventType_NODE_EVENT_TYPE_ADDED, curr)
+
+		return
+	}
+
+	if prev.GetOnline() != curr.GetOnline() {
+		if curr.GetOnline() {
+			w.publish(v1.NodeEventType_NODE_EVENT_TYPE_ONLINE, curr)
+		} else {
+			w.publish(v1.NodeEventType_NODE_EVENT_TYPE_OFFLINE, curr)
+		}
+	}
+
+	if !slices.Equal(prev.GetApprovedRoutes(), curr.GetApprovedRoutes()) ||
+		!slices.Equal(prev.GetAvailableRoutes(), curr.GetAvailableRoutes()) ||
+		!slices.Equal(prev.GetSubnetRoutes(), curr.GetSubnetRoutes()) {
+		w.publish(v1.NodeEventType_NODE_EVENT_TYPE_ROUTES_CHANGED, curr)
+	}
+
+	if !slices.Equal(prev.GetForcedTags(), curr.GetForcedTags()) ||
+		!slices.Equal(prev.GetValidTags(), curr.GetValidTags()) ||
+		!slices.Equal(prev.GetInvalidTags(), curr.GetInvalidTags()) {
+		w.publish(v1.NodeEventType_NODE_EVENT_TYPE_TAGS_CHANGED, curr)
+	}
+
+	if !nodeProtoExpired(prev) && nodeProtoExpired(curr) {
+		w.publish(v1.NodeEventType_NODE_EVENT_TYPE_EXPIRED, curr)
+	}
+}
+
+func (w *nodeWatcher) publish(eventType v1.NodeEventType, node *v1.Node) {
+	event := &v1.NodeEvent{
+		Type:   eventType,
+		Time:   timestamppb.Now(),
+		NodeId: node.GetId(),
+		Node:   node,
+	}
+
+	for c := range w.subscribers {
+		select {
+		case c <- event:
+		default:
+			log.Warn().
+				Str("type", eventType.String()).
+				Uint64("node.id", node.GetId()).
+				Msg("node watch subscriber is lagging behind, dropping event")
+		}
+	}
+}
+
+func (w *nodeWatcher) loadNodes(ids ...types.NodeID) ([]*v1.Node, error) {
+	nodes, err := w.h.db.ListNodes(ids...)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodesToProto(w.h.polMan, w.h.nodeNotifier.LikelyConnectedMap(), w.h.primaryRoutes, nodes), nil
+}
+
+func nodeProtoExpired(node *v1.Node) bool {
+	if node.GetExpiry() == nil {
+		return false
+	}
+
+	expiry := node.GetExpiry().AsTime()
+
+	return !expiry.IsZero() && expiry.Before(time.Now())
+}
+
+// NodeEventsHandler streams the node events as Server-Sent Events.
+// The event types can be filtered with one or more type query parameters,
+// for example ?type=online&type=offline.
+// The API key is validated by httpAuthenticationMiddleware, its scope and
+// user restriction are enforced here.
+func (h *Headscale) NodeEventsHandler(
+	writer http.ResponseWriter,
+	req *http.Request,
+) {
+	var eventTypes []v1.NodeEventType
+	for _, name := range req.URL.Query()["type"] {
+		eventType, err := ParseNodeEventType(name)
+		if err != nil {
+			httpError(writer, NewHTTPError(http.StatusBadRequest, err.Error(), err))
+
+			return
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	prefix, _, _ := strings.Cut(strings.TrimPrefix(req.Header.Get("authorization"), AuthPrefix), ".")
+	key, err := h.db.GetAPIKey(prefix)
+	if err != nil {
+		httpError(writer, NewHTTPError(http.StatusUnauthorized, "Unauthorized", err))
+
+		return
+	}
+
+	if !apiKeyScopeAllows(key.EffectiveScope(), v1.HeadscaleService_WatchNodes_FullMethodName) {
+		httpError(writer, NewHTTPError(
+			http.StatusForbidden,
+			fmt.Sprintf("API key with scope %q cannot watch nodes", key.EffectiveScope()),
+			nil,
+		))
+
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Cache-Control", "no-cache")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(writer)
+	if err := rc.Flush(); err != nil {
+		log.Error().Err(err).Msg("node events: failed to flush headers")
+
+		return
+	}
+
+	// The stream will break if there is a write timeout,
+	// so it needs to be disabled.
+	rc.SetWriteDeadline(time.Time{})
+
+	ctx, cancel := context.WithCancel(req.Context())
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer cancel()
+
+	var mu sync.Mutex
+	write := func(format string, args ...any) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if _, err := fmt.Fprintf(writer, format, args...); err != nil {
+			return err
+		}
+
+		return rc.Flush()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		ticker := time.NewTicker(nodeEventsKeepAliveInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := write(": keepalive\n\n"); err != nil {
+					cancel()
+
+					return
+				}
+			}
+		}
+	}()
+
+	err = h.nodeWatcher.watch(ctx, eventTypes, func(event *v1.NodeEvent) error {
+		if !key.CanAccessUser(event.GetNode().GetUser().GetId()) {
+			return nil
+		}
+
+		data, err := protojson.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		return write("event: %s\ndata: %s\n\n", NodeEventTypeName(event.GetType()), data)
+	})
+	if err != nil {
+		log.Debug().Err(err).Msg("node events stream ended")
+	}
+}
+
+// NodeEventTypeName returns the short name of a node event type, for
+// example "online" for NODE_EVENT_TYPE_ONLINE.
+func NodeEventTypeName(eventType v1.NodeEventType) string {
+	return strings.ToLower(strings.TrimPrefix(eventType.String(), nodeEventTypePrefix))
+}
+
+// ParseNodeEventType parses a node event type from its short name, such
+// as "online" or "routes_changed", or its full enum name.
+func ParseNodeEventType(name string) (v1.NodeEventType, error) {
+	upper := strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+	if !strings.HasPrefix(upper, nodeEventTypePrefix) {
+		upper = nodeEventTypePrefix + upper
+	}
+
+	value, ok := v1.NodeEventType_value[upper]
+	if !ok || value == int32(v1.NodeEventType_NODE_EVENT_TYPE_UNSPECIFIED) {
+		return v1.NodeEventType_NODE_EVENT_TYPE_UNSPECIFIED, fmt.Errorf("%w: %q", ErrInvalidNodeEventType, name)
+	}
+
+	return v1.NodeEventType(value), nil
+}
@@ -0,0 +1,184 @@
+package hscontrol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+	"tailscale.com/types/ptr"
+)
+
+func requireNodeEvent(t *testing.T, events <-chan *v1.NodeEvent, want v1.NodeEventType) *v1.NodeEvent {
+	t.Helper()
+
+	select {
+	case event := <-events:
+		require.Equal(t, want, event.GetType())
+
+		return event
+	case <-time.After(5 * time.Second):
+		require.FailNowf(t, "timed out waiting for node event", "want %s", want)
+
+		return nil
+	}
+}
+
+func TestNodeWatcher(t *testing.T) {
+	h := newTestHeadscale(t)
+	ctx := context.Background()
+
+	user, err := h.db.CreateUser(types.User{Name: "watch"})
+	require.NoError(t, err)
+
+	existing := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "existing",
+		UserID:         user.ID,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Hostinfo:       &tailcfg.Hostinfo{},
+	}
+	require.NoError(t, h.db.DB.Save(existing).Error)
+
+	events, unsubscribe, err := h.nodeWatcher.subscribe()
+	require.NoError(t, err)
+	defer unsubscribe()
+
+	node := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "node",
+		UserID:         user.ID,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Hostinfo:       &tailcfg.Hostinfo{},
+	}
+	require.NoError(t, h.db.DB.Save(node).Error)
+
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerChanged(node.ID))
+	added := requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_ADDED)
+	assert.Equal(t, uint64(node.ID), added.GetNodeId())
+	assert.Equal(t, "watch", added.GetNode().GetUser().GetName())
+
+	node.ForcedTags = []string{"tag:test"}
+	require.NoError(t, h.db.DB.Save(node).Error)
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerChanged(node.ID))
+	tagged := requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_TAGS_CHANGED)
+	assert.Equal(t, []string{"tag:test"}, tagged.GetNode().GetForcedTags())
+
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerPatch(&tailcfg.PeerChange{
+		NodeID: tailcfg.NodeID(node.ID),
+		Online: ptr.To(true),
+	}))
+	requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_ONLINE)
+
+	// Repeating the same state does not emit an event.
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerPatch(&tailcfg.PeerChange{
+		NodeID: tailcfg.NodeID(node.ID),
+		Online: ptr.To(true),
+	}))
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerPatch(&tailcfg.PeerChange{
+		NodeID: tailcfg.NodeID(node.ID),
+		Online: ptr.To(false),
+	}))
+	requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_OFFLINE)
+
+	h.nodeNotifier.NotifyAll(ctx, types.UpdateExpire(node.ID, time.Now().Add(-time.Minute)))
+	requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_EXPIRED)
+
+	require.NoError(t, h.db.DB.Delete(node).Error)
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerRemoved(node.ID))
+	removed := requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_REMOVED)
+	assert.Equal(t, "node", removed.GetNode().GetName())
+
+	// A full update notices the nodes removed without a notification.
+	require.NoError(t, h.db.DB.Delete(existing).Error)
+	h.nodeNotifier.NotifyAll(ctx, types.UpdateFull())
+	removed = requireNodeEvent(t, events, v1.NodeEventType_NODE_EVENT_TYPE_REMOVED)
+	assert.Equal(t, uint64(existing.ID), removed.GetNodeId())
+}
+
+func TestNodeWatcherFilter(t *testing.T) {
+	h := newTestHeadscale(t)
+
+	user, err := h.db.CreateUser(types.User{Name: "watch"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	received := make(chan *v1.NodeEvent, 10)
+	done := make(chan error)
+	go func() {
+		done <- h.nodeWatcher.watch(ctx,
+			[]v1.NodeEventType{v1.NodeEventType_NODE_EVENT_TYPE_REMOVED},
+			func(event *v1.NodeEvent) error {
+				received <- event
+
+				return nil
+			},
+		)
+	}()
+
+	require.Eventually(t, func() bool {
+		return h.nodeWatcher.active.Load() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	node := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "node",
+		UserID:         user.ID,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Hostinfo:       &tailcfg.Hostinfo{},
+	}
+	require.NoError(t, h.db.DB.Save(node).Error)
+
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerChanged(node.ID))
+	h.nodeNotifier.NotifyAll(ctx, types.UpdatePeerRemoved(node.ID))
+
+	event := requireNodeEvent(t, received, v1.NodeEventType_NODE_EVENT_TYPE_REMOVED)
+	assert.Equal(t, uint64(node.ID), event.GetNodeId())
+
+	cancel()
+	require.NoError(t, <-done)
+	assert.Equal(t, int32(0), h.nodeWatcher.active.Load())
+}
+
+func TestParseNodeEventType(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    v1.NodeEventType
+		wantErr bool
+	}{
+		{name: "online", want: v1.NodeEventType_NODE_EVENT_TYPE_ONLINE},
+		{name: "routes-changed", want: v1.NodeEventType_NODE_EVENT_TYPE_ROUTES_CHANGED},
+		{name: "NODE_EVENT_TYPE_EXPIRED", want: v1.NodeEventType_NODE_EVENT_TYPE_EXPIRED},
+		{name: "unspecified", wantErr: true},
+		{name: "rebooted", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseNodeEventType(tt.name)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidNodeEventType)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNodeEventTypeName(t *testing.T) {
+	assert.Equal(t, "online", NodeEventTypeName(v1.NodeEventType_NODE_EVENT_TYPE_ONLINE))
+	assert.Equal(t, "routes_changed", NodeEventTypeName(v1.NodeEventType_NODE_EVENT_TYPE_ROUTES_CHANGED))
+}
@@ -0,0 +1,120 @@
+package hscontrol
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/protobuf/encoding/protojson"
+	"tailscale.com/net/tsaddr"
+)
+
+// keyExpiryCheckInterval is how often the nodes are checked for keys
+// expiring soon.
+const keyExpiryCheckInterval = time.Minute
+
+// webhookPruneInterval is how often the old webhook deliveries are deleted.
+const webhookPruneInterval = time.Hour
+
+// pruneWebhookDeliveries deletes the delivered and given up webhook
+// deliveries older than the retention period, as well as the deliveries
+// for endpoints that were removed from the configuration.
+func (h *Headscale) pruneWebhookDeliveries() {
+	endpoints := make([]string, len(h.cfg.Webhooks.Endpoints))
+	for i, endpoint := range h.cfg.Webhooks.Endpoints {
+		endpoints[i] = endpoint.URL
+	}
+
+	deleted, err := h.db.DeleteWebhookDeliveries(time.Now().Add(-h.cfg.Webhooks.Retention), endpoints)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to delete old webhook deliveries")
+
+		return
+	}
+
+	if deleted > 0 {
+		log.Debug().Int64("count", deleted).Msg("deleted old webhook deliveries")
+	}
+}
+
+// notifyExpiringNodes sends the node.key_expiring event for the nodes
+// whose key expires within the configured warning period. The event is
+// sent once per node key expiry.
+func (h *Headscale) notifyExpiringNodes() {
+	if !h.webhooks.Wants(types.WebhookEventNodeKeyExpiring) {
+		return
+	}
+
+	now := time.Now()
+	nodes, err := h.db.ListNodesExpiringBetween(now, now.Add(h.cfg.Webhooks.KeyExpiryWarning))
+	if err != nil {
+		log.Error().Err(err).Msg("failed to list nodes with expiring keys")
+
+		return
+	}
+
+	for _, node := range nodes {
+		h.webhooks.SendOnce(
+			types.WebhookEventNodeKeyExpiring,
+			fmt.Sprintf("%s:%d:%d", types.WebhookEventNodeKeyExpiring, node.ID, node.Expiry.Unix()),
+			map[string]any{
+				"node":   webhookNode(node),
+				"expiry": node.Expiry,
+			},
+		)
+	}
+}
+
+// notifyPendingRoutes sends the route.approval_needed event if the node
+// announces routes that are not approved. The event is sent once for each
+// set of pending routes.
+func (h *Headscale) notifyPendingRoutes(node *types.Node) {
+	if !h.webhooks.Wants(types.WebhookEventRouteApprovalNeeded) || node.PendingApproval {
+		return
+	}
+
+	var pending []netip.Prefix
+	for _, route := range node.AnnouncedRoutes() {
+		if !slices.Contains(node.ApprovedRoutes, route) {
+			pending = append(pending, route)
+		}
+	}
+
+	if len(pending) == 0 {
+		return
+	}
+
+	tsaddr.SortPrefixes(pending)
+
+	routes := make([]string, len(pending))
+	for i, route := range pending {
+		routes[i] = route.String()
+	}
+
+	h.webhooks.SendOnce(
+		types.WebhookEventRouteApprovalNeeded,
+		fmt.Sprintf("%s:%d:%s", types.WebhookEventRouteApprovalNeeded, node.ID, strings.Join(routes, ",")),
+		map[string]any{
+			"node":   webhookNode(node),
+			"routes": routes,
+		},
+	)
+}
+
+// webhookNode returns the JSON representation of the node used in the
+// webhook events, the same as in the API.
+func webhookNode(node *types.Node) json.RawMessage {
+	b, err := protojson.Marshal(node.Proto())
+	if err != nil {
+		log.Error().Err(err).Msg("failed to marshal node for webhook event")
+
+		return nil
+	}
+
+	return b
+}
@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"encoding/json"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+)
+
+// auditEvents maps the audited actions to the events they send, and the
+// name of the changed object in the event data.
+var auditEvents = map[string]struct {
+	event  string
+	object string
+}{
+	"node.register":   {types.WebhookEventNodeRegistered, "node"},
+	"node.delete":     {types.WebhookEventNodeDeleted, "node"},
+	"node.reject":     {types.WebhookEventNodeDeleted, "node"},
+	"user.create":     {types.WebhookEventUserCreated, "user"},
+	"policy.set":      {types.WebhookEventPolicyChanged, "policy"},
+	"policy.rollback": {types.WebhookEventPolicyChanged, "policy"},
+}
+
+// RecordAuditEvent implements audit.Sink, it sends the events of the
+// audited changes. The data contains the actor, the target and the state
+// of the object after the change, or before it for deletions.
+func (d *Dispatcher) RecordAuditEvent(e *types.AuditEvent) error {
+	mapping, ok := auditEvents[e.Action]
+	if !ok || !d.Wants(mapping.event) {
+		return nil
+	}
+
+	state := e.After
+	if state == "" {
+		state = e.Before
+	}
+
+	data := map[string]any{
+		"actor":  e.Actor,
+		"target": e.Target,
+	}
+	if state != "" {
+		data[mapping.object] = json.RawMessage(state)
+	}
+
+	return d.send(mapping.event, "", data)
+}
@@ -0,0 +1,316 @@
+// Package webhook delivers tailnet events to HTTP endpoints as signed
+// JSON POST requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// SignatureHeader contains the timestamp and the HMAC-SHA256 signature
+	// of a request, formatted as "t=<unix time>,v1=<hex signature>". The
+	// signature is computed over "<unix time>.<body>".
+	SignatureHeader = "X-Headscale-Signature"
+	EventHeader     = "X-Headscale-Event"
+	DeliveryHeader  = "X-Headscale-Delivery"
+
+	// MaxAttempts is the number of times a delivery is attempted before
+	// it is given up.
+	MaxAttempts = 10
+
+	initialBackoff = 10 * time.Second
+	maxBackoff     = time.Hour
+
+	pollInterval    = 10 * time.Second
+	requestTimeout  = 10 * time.Second
+	deliveryBatch   = 100
+	maxErrorBodyLen = 512
+)
+
+// Store keeps the queued deliveries.
+type Store interface {
+	QueueWebhookDelivery(delivery *types.WebhookDelivery) (bool, error)
+	ListPendingWebhookDeliveries(endpoint string, now time.Time, limit int) ([]types.WebhookDelivery, error)
+	SaveWebhookDelivery(delivery *types.WebhookDelivery) error
+}
+
+// Payload is the JSON body sent to the endpoints.
+type Payload struct {
+	// ID identifies the event, it is the same for all endpoints and
+	// retries.
+	ID    string          `json:"id"`
+	Event string          `json:"event"`
+	Time  time.Time       `json:"time"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Dispatcher queues events for the endpoints subscribed to them, and
+// delivers them with retries in the background.
+type Dispatcher struct {
+	store     Store
+	endpoints []types.WebhookEndpoint
+	client    *http.Client
+
+	// wake wakes the worker of an endpoint, by URL, when an event is
+	// queued for it.
+	wake map[string]chan struct{}
+}
+
+// NewDispatcher returns a Dispatcher delivering to the endpoints in the
+// configuration.
+func NewDispatcher(store Store, cfg types.WebhookConfig) *Dispatcher {
+	wake := make(map[string]chan struct{}, len(cfg.Endpoints))
+	for _, endpoint := range cfg.Endpoints {
+		wake[endpoint.URL] = make(chan struct{}, 1)
+	}
+
+	return &Dispatcher{
+		store:     store,
+		endpoints: cfg.Endpoints,
+		client:    &http.Client{Timeout: requestTimeout},
+		wake:      wake,
+	}
+}
+
+// Wants reports whether any endpoint receives the event.
+func (d *Dispatcher) Wants(event string) bool {
+	if d == nil {
+		return false
+	}
+
+	for _, endpoint := range d.endpoints {
+		if endpoint.Wants(event) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Send queues the event for the endpoints receiving it. The data is
+// marshalled to JSON.
+// Failing to queue an event is logged, it does not fail the caller.
+func (d *Dispatcher) Send(event string, data any) {
+	d.SendOnce(event, "", data)
+}
+
+// SendOnce is like Send, but the event is only queued once per endpoint
+// for the given key, also across restarts.
+func (d *Dispatcher) SendOnce(event, key string, data any) {
+	if !d.Wants(event) {
+		return
+	}
+
+	if err := d.send(event, key, data); err != nil {
+		log.Error().
+			Err(err).
+			Str("event", event).
+			Msg("failed to queue webhook event")
+	}
+}
+
+func (d *Dispatcher) send(event, key string, data any) error {
+	id, err := util.GenerateRandomStringURLSafe(16)
+	if err != nil {
+		return err
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("marshalling event data: %w", err)
+	}
+
+	now := time.Now()
+	payload, err := json.Marshal(Payload{
+		ID:    id,
+		Event: event,
+		Time:  now,
+		Data:  rawData,
+	})
+	if err != nil {
+		return fmt.Errorf("marshalling payload: %w", err)
+	}
+
+	for _, endpoint := range d.endpoints {
+		if !endpoint.Wants(event) {
+			continue
+		}
+
+		_, err := d.store.QueueWebhookDelivery(&types.WebhookDelivery{
+			CreatedAt:     now,
+			Endpoint:      endpoint.URL,
+			Event:         event,
+			DedupKey:      key,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		select {
+		case d.wake[endpoint.URL] <- struct{}{}:
+		default:
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the queued events until the context is done, including the
+// events left over from a previous run. Each endpoint has its own worker,
+// an endpoint failing or answering slowly does not hold up the others.
+func (d *Dispatcher) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, endpoint := range d.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.runEndpoint(ctx, endpoint)
+		}()
+	}
+
+	wg.Wait()
+}
+
+func (d *Dispatcher) runEndpoint(ctx context.Context, endpoint types.WebhookEndpoint) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.deliverPending(ctx, endpoint)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake[endpoint.URL]:
+		}
+	}
+}
+
+// deliverPending delivers the due events of the endpoint, oldest first. It
+// stops at the first failed delivery, the endpoint is tried again at the
+// next poll.
+func (d *Dispatcher) deliverPending(ctx context.Context, endpoint types.WebhookEndpoint) {
+	deliveries, err := d.store.ListPendingWebhookDeliveries(endpoint.URL, time.Now(), deliveryBatch)
+	if err != nil {
+		log.Error().Err(err).Str("endpoint", endpoint.URL).Msg("failed to list pending webhook deliveries")
+
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if !d.attempt(ctx, endpoint, &deliveries[i]) {
+			return
+		}
+	}
+}
+
+// attempt tries to deliver the event once and records the outcome,
+// scheduling a retry with exponential backoff on failure. It reports
+// whether the event was delivered.
+func (d *Dispatcher) attempt(ctx context.Context, endpoint types.WebhookEndpoint, delivery *types.WebhookDelivery) bool {
+	delivery.Attempts++
+	now := time.Now()
+
+	err := d.deliver(ctx, endpoint, delivery)
+	switch {
+	case err == nil:
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+	case delivery.Attempts >= MaxAttempts:
+		delivery.FailedAt = &now
+		delivery.LastError = err.Error()
+	default:
+		delivery.NextAttemptAt = now.Add(backoff(delivery.Attempts))
+		delivery.LastError = err.Error()
+	}
+
+	if err != nil {
+		log.Warn().
+			Err(err).
+			Uint64("delivery.id", delivery.ID).
+			Str("event", delivery.Event).
+			Str("endpoint", delivery.Endpoint).
+			Int("attempts", delivery.Attempts).
+			Bool("given_up", delivery.FailedAt != nil).
+			Msg("webhook delivery failed")
+	}
+
+	if err := d.store.SaveWebhookDelivery(delivery); err != nil {
+		log.Error().Err(err).Uint64("delivery.id", delivery.ID).Msg("failed to save webhook delivery")
+	}
+
+	return err == nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, endpoint types.WebhookEndpoint, delivery *types.WebhookDelivery) error {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(delivery.ID, util.Base10))
+	req.Header.Set(SignatureHeader, Sign(endpoint.Secret, time.Now(), body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyLen))
+
+		return fmt.Errorf("endpoint returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+
+	return nil
+}
+
+// Sign returns the value of the signature header for a body sent at the
+// given time.
+func Sign(secret string, t time.Time, body []byte) string {
+	timestamp := strconv.FormatInt(t.Unix(), util.Base10)
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "t=" + timestamp + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff returns the delay before the next attempt after the given
+// number of failed attempts.
+func backoff(attempts int) time.Duration {
+	delay := initialBackoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
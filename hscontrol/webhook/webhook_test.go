@@ -0,0 +1,283 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memStore struct {
+	mu         sync.Mutex
+	deliveries []*types.WebhookDelivery
+}
+
+func (s *memStore) QueueWebhookDelivery(delivery *types.WebhookDelivery) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, d := range s.deliveries {
+		if delivery.DedupKey != "" && d.Endpoint == delivery.Endpoint && d.DedupKey == delivery.DedupKey {
+			return false, nil
+		}
+	}
+
+	delivery.ID = uint64(len(s.deliveries) + 1)
+	s.deliveries = append(s.deliveries, delivery)
+
+	return true, nil
+}
+
+func (s *memStore) ListPendingWebhookDeliveries(endpoint string, now time.Time, limit int) ([]types.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var pending []types.WebhookDelivery
+	for _, d := range s.deliveries {
+		if d.Endpoint == endpoint && d.DeliveredAt == nil && d.FailedAt == nil && !d.NextAttemptAt.After(now) {
+			pending = append(pending, *d)
+		}
+	}
+
+	return pending, nil
+}
+
+func (s *memStore) SaveWebhookDelivery(delivery *types.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	*s.deliveries[delivery.ID-1] = *delivery
+
+	return nil
+}
+
+type received struct {
+	header http.Header
+	body   []byte
+}
+
+func newEndpoint(t *testing.T, statuses ...int) (*httptest.Server, *[]received) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var requests []received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body, _ := io.ReadAll(r.Body)
+		requests = append(requests, received{header: r.Header.Clone(), body: body})
+
+		status := http.StatusNoContent
+		if len(requests) <= len(statuses) {
+			status = statuses[len(requests)-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv, &requests
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	srv, requests := newEndpoint(t)
+	store := &memStore{}
+	endpoint := types.WebhookEndpoint{URL: srv.URL, Secret: "secret", Events: []string{types.WebhookEventUserCreated}}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{endpoint},
+	})
+
+	assert.True(t, d.Wants(types.WebhookEventUserCreated))
+	assert.False(t, d.Wants(types.WebhookEventNodeDeleted))
+
+	d.Send(types.WebhookEventNodeDeleted, map[string]any{"node": 1})
+	d.Send(types.WebhookEventUserCreated, map[string]any{"user": "alice"})
+	require.Len(t, store.deliveries, 1)
+
+	d.deliverPending(context.Background(), endpoint)
+	require.Len(t, *requests, 1)
+
+	req := (*requests)[0]
+	assert.Equal(t, "application/json", req.header.Get("Content-Type"))
+	assert.Equal(t, types.WebhookEventUserCreated, req.header.Get(EventHeader))
+	assert.Equal(t, "1", req.header.Get(DeliveryHeader))
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal(req.body, &payload))
+	assert.Equal(t, types.WebhookEventUserCreated, payload.Event)
+	assert.NotEmpty(t, payload.ID)
+	assert.JSONEq(t, `{"user":"alice"}`, string(payload.Data))
+
+	// The signature covers the timestamp and the body.
+	var ts int64
+	var sig string
+	_, err := fmt.Sscanf(req.header.Get(SignatureHeader), "t=%d,v1=%s", &ts, &sig)
+	require.NoError(t, err)
+	assert.Equal(t, req.header.Get(SignatureHeader), Sign("secret", time.Unix(ts, 0), req.body))
+	assert.NotEqual(t, req.header.Get(SignatureHeader), Sign("other", time.Unix(ts, 0), req.body))
+
+	assert.NotNil(t, store.deliveries[0].DeliveredAt)
+	assert.Equal(t, 1, store.deliveries[0].Attempts)
+
+	// Delivered events are not sent again.
+	d.deliverPending(context.Background(), endpoint)
+	assert.Len(t, *requests, 1)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	srv, requests := newEndpoint(t, http.StatusInternalServerError)
+	store := &memStore{}
+	endpoint := types.WebhookEndpoint{URL: srv.URL, Secret: "secret"}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{endpoint},
+	})
+
+	d.Send(types.WebhookEventNodeRegistered, nil)
+	d.deliverPending(context.Background(), endpoint)
+	require.Len(t, *requests, 1)
+
+	delivery := store.deliveries[0]
+	assert.Nil(t, delivery.DeliveredAt)
+	assert.Nil(t, delivery.FailedAt)
+	assert.Contains(t, delivery.LastError, "500")
+	assert.True(t, delivery.NextAttemptAt.After(time.Now()))
+
+	// The retry is not due yet.
+	d.deliverPending(context.Background(), endpoint)
+	require.Len(t, *requests, 1)
+
+	delivery.NextAttemptAt = time.Now()
+	d.deliverPending(context.Background(), endpoint)
+	require.Len(t, *requests, 2)
+	assert.NotNil(t, delivery.DeliveredAt)
+	assert.Equal(t, 2, delivery.Attempts)
+	assert.Empty(t, delivery.LastError)
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	store := &memStore{}
+	endpoint := types.WebhookEndpoint{URL: "http://127.0.0.1:1", Secret: "secret"}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{endpoint},
+	})
+
+	d.Send(types.WebhookEventNodeRegistered, nil)
+	delivery := store.deliveries[0]
+	for range MaxAttempts {
+		delivery.NextAttemptAt = time.Now()
+		d.deliverPending(context.Background(), endpoint)
+	}
+
+	assert.Equal(t, MaxAttempts, delivery.Attempts)
+	assert.NotNil(t, delivery.FailedAt)
+	assert.Nil(t, delivery.DeliveredAt)
+}
+
+func TestDispatcherEndpointsIndependent(t *testing.T) {
+	down, downRequests := newEndpoint(t, http.StatusInternalServerError, http.StatusInternalServerError)
+	up, _ := newEndpoint(t)
+	store := &memStore{}
+	downEndpoint := types.WebhookEndpoint{URL: down.URL, Secret: "down"}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{
+			downEndpoint,
+			{URL: up.URL, Secret: "up"},
+		},
+	})
+
+	d.Send(types.WebhookEventNodeRegistered, map[string]any{"node": 1})
+	d.Send(types.WebhookEventNodeRegistered, map[string]any{"node": 2})
+
+	// The failing endpoint stops at its first failed delivery instead of
+	// trying the next ones.
+	d.deliverPending(context.Background(), downEndpoint)
+	require.Len(t, *downRequests, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+
+	// The failing endpoint does not hold up the other one.
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		store.mu.Lock()
+		defer store.mu.Unlock()
+
+		for _, delivery := range store.deliveries {
+			if delivery.Endpoint == up.URL {
+				assert.NotNil(c, delivery.DeliveredAt)
+			}
+		}
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
+
+func TestDispatcherSendOnce(t *testing.T) {
+	store := &memStore{}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{
+			{URL: "https://a.example.com", Secret: "a"},
+			{URL: "https://b.example.com", Secret: "b"},
+		},
+	})
+
+	d.SendOnce(types.WebhookEventNodeKeyExpiring, "node:1", nil)
+	d.SendOnce(types.WebhookEventNodeKeyExpiring, "node:1", nil)
+	d.SendOnce(types.WebhookEventNodeKeyExpiring, "node:2", nil)
+
+	assert.Len(t, store.deliveries, 4)
+}
+
+func TestDispatcherAuditEvents(t *testing.T) {
+	store := &memStore{}
+	d := NewDispatcher(store, types.WebhookConfig{
+		Endpoints: []types.WebhookEndpoint{{URL: "https://a.example.com", Secret: "a"}},
+	})
+
+	require.NoError(t, d.RecordAuditEvent(&types.AuditEvent{
+		Actor:  "unix-socket",
+		Action: "node.delete",
+		Target: "node:1",
+		Before: `{"id":"1"}`,
+	}))
+	require.NoError(t, d.RecordAuditEvent(&types.AuditEvent{
+		Actor:  "unix-socket",
+		Action: "node.rename",
+		Target: "node:1",
+	}))
+	require.Len(t, store.deliveries, 1)
+
+	delivery := store.deliveries[0]
+	assert.Equal(t, types.WebhookEventNodeDeleted, delivery.Event)
+
+	var payload Payload
+	require.NoError(t, json.Unmarshal([]byte(delivery.Payload), &payload))
+	assert.JSONEq(t, `{"actor":"unix-socket","target":"node:1","node":{"id":"1"}}`, string(payload.Data))
+}
+
+func TestDispatcherNil(t *testing.T) {
+	var d *Dispatcher
+
+	assert.False(t, d.Wants(types.WebhookEventNodeRegistered))
+	d.Send(types.WebhookEventNodeRegistered, nil)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, backoff(1))
+	assert.Equal(t, 20*time.Second, backoff(2))
+	assert.Equal(t, 80*time.Second, backoff(4))
+	assert.Equal(t, time.Hour, backoff(20))
+}
@@ -0,0 +1,77 @@
+package hscontrol
+
+import (
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/juanfont/headscale/hscontrol/types"
+	"github.com/juanfont/headscale/hscontrol/util"
+	"github.com/juanfont/headscale/hscontrol/webhook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"tailscale.com/tailcfg"
+	"tailscale.com/types/key"
+	"tailscale.com/types/ptr"
+)
+
+func TestWebhookNodeEvents(t *testing.T) {
+	h := newTestHeadscale(t)
+	h.cfg.Webhooks = types.WebhookConfig{
+		KeyExpiryWarning: 24 * time.Hour,
+		Endpoints: []types.WebhookEndpoint{
+			{URL: "https://hooks.example.com", Secret: "secret"},
+		},
+	}
+	h.webhooks = webhook.NewDispatcher(h.db, h.cfg.Webhooks)
+
+	user, err := h.db.CreateUser(types.User{Name: "hooks"})
+	require.NoError(t, err)
+
+	route := netip.MustParsePrefix("10.0.0.0/24")
+	node := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "router",
+		UserID:         user.ID,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Expiry:         ptr.To(time.Now().Add(time.Hour)),
+		Hostinfo: &tailcfg.Hostinfo{
+			RoutableIPs: []netip.Prefix{route},
+		},
+	}
+	require.NoError(t, h.db.DB.Save(node).Error)
+
+	later := &types.Node{
+		MachineKey:     key.NewMachine().Public(),
+		NodeKey:        key.NewNode().Public(),
+		Hostname:       "later",
+		UserID:         user.ID,
+		RegisterMethod: util.RegisterMethodAuthKey,
+		Expiry:         ptr.To(time.Now().Add(48 * time.Hour)),
+		Hostinfo:       &tailcfg.Hostinfo{},
+	}
+	require.NoError(t, h.db.DB.Save(later).Error)
+
+	// The events are only queued once.
+	for range 2 {
+		h.notifyExpiringNodes()
+		h.notifyPendingRoutes(node)
+	}
+
+	pending, err := h.db.ListPendingWebhookDeliveries("https://hooks.example.com", time.Now(), 10)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, types.WebhookEventNodeKeyExpiring, pending[0].Event)
+	assert.Contains(t, pending[0].Payload, `"name":"router"`)
+	assert.Equal(t, types.WebhookEventRouteApprovalNeeded, pending[1].Event)
+	assert.Contains(t, pending[1].Payload, `"routes":["10.0.0.0/24"]`)
+
+	// Approved routes are not pending.
+	node.ApprovedRoutes = []netip.Prefix{route}
+	h.notifyPendingRoutes(node)
+
+	pending, err = h.db.ListPendingWebhookDeliveries("https://hooks.example.com", time.Now(), 10)
+	require.NoError(t, err)
+	assert.Len(t, pending, 2)
+}